@@ -87,7 +87,7 @@ func (x TokenInfo_TokenSource) String() string {
 }
 
 func (TokenInfo_TokenSource) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{15, 0}
+	return fileDescriptor_15ace9a5d0179ff3, []int{16, 0}
 }
 
 // ActivateRequest mirrors AuthenticateRequest. The caller is authenticated via
@@ -1074,23 +1074,89 @@ func (m *OTPInfo) GetSessionExpiration() *types.Timestamp {
 	return nil
 }
 
+// ScopeRestriction limits the access that a token grants to 'repo' to at most
+// 'scope' (even if the token's subject has more access)
+type ScopeRestriction struct {
+	Repo                 string   `protobuf:"bytes,1,opt,name=repo,proto3" json:"repo,omitempty"`
+	Scope                Scope    `protobuf:"varint,2,opt,name=scope,proto3,enum=auth.Scope" json:"scope,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ScopeRestriction) Reset()         { *m = ScopeRestriction{} }
+func (m *ScopeRestriction) String() string { return proto.CompactTextString(m) }
+func (*ScopeRestriction) ProtoMessage()    {}
+func (*ScopeRestriction) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15ace9a5d0179ff3, []int{15}
+}
+func (m *ScopeRestriction) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ScopeRestriction) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ScopeRestriction.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ScopeRestriction) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ScopeRestriction.Merge(m, src)
+}
+func (m *ScopeRestriction) XXX_Size() int {
+	return m.Size()
+}
+func (m *ScopeRestriction) XXX_DiscardUnknown() {
+	xxx_messageInfo_ScopeRestriction.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ScopeRestriction proto.InternalMessageInfo
+
+func (m *ScopeRestriction) GetRepo() string {
+	if m != nil {
+		return m.Repo
+	}
+	return ""
+}
+
+func (m *ScopeRestriction) GetScope() Scope {
+	if m != nil {
+		return m.Scope
+	}
+	return Scope_NONE
+}
+
 // TokenInfo is the 'value' of an auth token 'key' in the 'tokens' collection
 type TokenInfo struct {
 	// Subject (i.e. Pachyderm account) that a given token authorizes. Prefixed
 	// with "github:" or "robot:" to distinguish the two classes of
 	// Subject in Pachyderm
-	Subject              string                `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
-	Source               TokenInfo_TokenSource `protobuf:"varint,2,opt,name=source,proto3,enum=auth.TokenInfo_TokenSource" json:"source,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
-	XXX_unrecognized     []byte                `json:"-"`
-	XXX_sizecache        int32                 `json:"-"`
+	Subject string                `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
+	Source  TokenInfo_TokenSource `protobuf:"varint,2,opt,name=source,proto3,enum=auth.TokenInfo_TokenSource" json:"source,omitempty"`
+	// If set, this token may only be used to access the repos listed here, at
+	// (at most) the listed scopes. Tokens with restrictions never carry admin
+	// privileges, even if their subject is a cluster admin.
+	Restrictions []*ScopeRestriction `protobuf:"bytes,3,rep,name=restrictions,proto3" json:"restrictions,omitempty"`
+	// If true, this token grants at most READER access to any repo (including
+	// those in 'restrictions'), never carries admin privileges, and can't be
+	// used to create repos (e.g. with CreateRepo or CreatePipeline) or change
+	// their ACLs
+	ReadOnly             bool     `protobuf:"varint,4,opt,name=read_only,json=readOnly,proto3" json:"read_only,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *TokenInfo) Reset()         { *m = TokenInfo{} }
 func (m *TokenInfo) String() string { return proto.CompactTextString(m) }
 func (*TokenInfo) ProtoMessage()    {}
 func (*TokenInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{15}
+	return fileDescriptor_15ace9a5d0179ff3, []int{16}
 }
 func (m *TokenInfo) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1133,6 +1199,20 @@ func (m *TokenInfo) GetSource() TokenInfo_TokenSource {
 	return TokenInfo_INVALID
 }
 
+func (m *TokenInfo) GetRestrictions() []*ScopeRestriction {
+	if m != nil {
+		return m.Restrictions
+	}
+	return nil
+}
+
+func (m *TokenInfo) GetReadOnly() bool {
+	if m != nil {
+		return m.ReadOnly
+	}
+	return false
+}
+
 type AuthenticateRequest struct {
 	// This is the token returned by GitHub and used to authenticate the caller.
 	// When Pachyderm is deployed locally, setting this value to a given string
@@ -1153,7 +1233,7 @@ func (m *AuthenticateRequest) Reset()         { *m = AuthenticateRequest{} }
 func (m *AuthenticateRequest) String() string { return proto.CompactTextString(m) }
 func (*AuthenticateRequest) ProtoMessage()    {}
 func (*AuthenticateRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{16}
+	return fileDescriptor_15ace9a5d0179ff3, []int{17}
 }
 func (m *AuthenticateRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1210,7 +1290,7 @@ func (m *AuthenticateResponse) Reset()         { *m = AuthenticateResponse{} }
 func (m *AuthenticateResponse) String() string { return proto.CompactTextString(m) }
 func (*AuthenticateResponse) ProtoMessage()    {}
 func (*AuthenticateResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{17}
+	return fileDescriptor_15ace9a5d0179ff3, []int{18}
 }
 func (m *AuthenticateResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1256,7 +1336,7 @@ func (m *WhoAmIRequest) Reset()         { *m = WhoAmIRequest{} }
 func (m *WhoAmIRequest) String() string { return proto.CompactTextString(m) }
 func (*WhoAmIRequest) ProtoMessage()    {}
 func (*WhoAmIRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{18}
+	return fileDescriptor_15ace9a5d0179ff3, []int{19}
 }
 func (m *WhoAmIRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1286,19 +1366,23 @@ func (m *WhoAmIRequest) XXX_DiscardUnknown() {
 var xxx_messageInfo_WhoAmIRequest proto.InternalMessageInfo
 
 type WhoAmIResponse struct {
-	Username             string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
-	IsAdmin              bool     `protobuf:"varint,2,opt,name=is_admin,json=isAdmin,proto3" json:"is_admin,omitempty"`
-	TTL                  int64    `protobuf:"varint,3,opt,name=ttl,proto3" json:"ttl,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Username string `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	IsAdmin  bool   `protobuf:"varint,2,opt,name=is_admin,json=isAdmin,proto3" json:"is_admin,omitempty"`
+	TTL      int64  `protobuf:"varint,3,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	// restrictions and read_only are copied from the caller's token (see
+	// TokenInfo)
+	Restrictions         []*ScopeRestriction `protobuf:"bytes,4,rep,name=restrictions,proto3" json:"restrictions,omitempty"`
+	ReadOnly             bool                `protobuf:"varint,5,opt,name=read_only,json=readOnly,proto3" json:"read_only,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
 }
 
 func (m *WhoAmIResponse) Reset()         { *m = WhoAmIResponse{} }
 func (m *WhoAmIResponse) String() string { return proto.CompactTextString(m) }
 func (*WhoAmIResponse) ProtoMessage()    {}
 func (*WhoAmIResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{19}
+	return fileDescriptor_15ace9a5d0179ff3, []int{20}
 }
 func (m *WhoAmIResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1348,6 +1432,20 @@ func (m *WhoAmIResponse) GetTTL() int64 {
 	return 0
 }
 
+func (m *WhoAmIResponse) GetRestrictions() []*ScopeRestriction {
+	if m != nil {
+		return m.Restrictions
+	}
+	return nil
+}
+
+func (m *WhoAmIResponse) GetReadOnly() bool {
+	if m != nil {
+		return m.ReadOnly
+	}
+	return false
+}
+
 type ACL struct {
 	// principal -> scope. All principals are the default principal of a Pachyderm
 	// subject (i.e. all keys in this map are strings prefixed with either
@@ -1363,7 +1461,7 @@ func (m *ACL) Reset()         { *m = ACL{} }
 func (m *ACL) String() string { return proto.CompactTextString(m) }
 func (*ACL) ProtoMessage()    {}
 func (*ACL) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{20}
+	return fileDescriptor_15ace9a5d0179ff3, []int{21}
 }
 func (m *ACL) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1410,7 +1508,7 @@ func (m *Users) Reset()         { *m = Users{} }
 func (m *Users) String() string { return proto.CompactTextString(m) }
 func (*Users) ProtoMessage()    {}
 func (*Users) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{21}
+	return fileDescriptor_15ace9a5d0179ff3, []int{22}
 }
 func (m *Users) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1457,7 +1555,7 @@ func (m *Groups) Reset()         { *m = Groups{} }
 func (m *Groups) String() string { return proto.CompactTextString(m) }
 func (*Groups) ProtoMessage()    {}
 func (*Groups) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{22}
+	return fileDescriptor_15ace9a5d0179ff3, []int{23}
 }
 func (m *Groups) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1507,7 +1605,7 @@ func (m *AuthorizeRequest) Reset()         { *m = AuthorizeRequest{} }
 func (m *AuthorizeRequest) String() string { return proto.CompactTextString(m) }
 func (*AuthorizeRequest) ProtoMessage()    {}
 func (*AuthorizeRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{23}
+	return fileDescriptor_15ace9a5d0179ff3, []int{24}
 }
 func (m *AuthorizeRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1564,7 +1662,7 @@ func (m *AuthorizeResponse) Reset()         { *m = AuthorizeResponse{} }
 func (m *AuthorizeResponse) String() string { return proto.CompactTextString(m) }
 func (*AuthorizeResponse) ProtoMessage()    {}
 func (*AuthorizeResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{24}
+	return fileDescriptor_15ace9a5d0179ff3, []int{25}
 }
 func (m *AuthorizeResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1619,7 +1717,7 @@ func (m *GetScopeRequest) Reset()         { *m = GetScopeRequest{} }
 func (m *GetScopeRequest) String() string { return proto.CompactTextString(m) }
 func (*GetScopeRequest) ProtoMessage()    {}
 func (*GetScopeRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{25}
+	return fileDescriptor_15ace9a5d0179ff3, []int{26}
 }
 func (m *GetScopeRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1676,7 +1774,7 @@ func (m *GetScopeResponse) Reset()         { *m = GetScopeResponse{} }
 func (m *GetScopeResponse) String() string { return proto.CompactTextString(m) }
 func (*GetScopeResponse) ProtoMessage()    {}
 func (*GetScopeResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{26}
+	return fileDescriptor_15ace9a5d0179ff3, []int{27}
 }
 func (m *GetScopeResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1734,7 +1832,7 @@ func (m *SetScopeRequest) Reset()         { *m = SetScopeRequest{} }
 func (m *SetScopeRequest) String() string { return proto.CompactTextString(m) }
 func (*SetScopeRequest) ProtoMessage()    {}
 func (*SetScopeRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{27}
+	return fileDescriptor_15ace9a5d0179ff3, []int{28}
 }
 func (m *SetScopeRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1794,7 +1892,7 @@ func (m *SetScopeResponse) Reset()         { *m = SetScopeResponse{} }
 func (m *SetScopeResponse) String() string { return proto.CompactTextString(m) }
 func (*SetScopeResponse) ProtoMessage()    {}
 func (*SetScopeResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{28}
+	return fileDescriptor_15ace9a5d0179ff3, []int{29}
 }
 func (m *SetScopeResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1834,7 +1932,7 @@ func (m *GetACLRequest) Reset()         { *m = GetACLRequest{} }
 func (m *GetACLRequest) String() string { return proto.CompactTextString(m) }
 func (*GetACLRequest) ProtoMessage()    {}
 func (*GetACLRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{29}
+	return fileDescriptor_15ace9a5d0179ff3, []int{30}
 }
 func (m *GetACLRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1887,7 +1985,7 @@ func (m *ACLEntry) Reset()         { *m = ACLEntry{} }
 func (m *ACLEntry) String() string { return proto.CompactTextString(m) }
 func (*ACLEntry) ProtoMessage()    {}
 func (*ACLEntry) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{30}
+	return fileDescriptor_15ace9a5d0179ff3, []int{31}
 }
 func (m *ACLEntry) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1954,7 +2052,7 @@ func (m *GetACLResponse) Reset()         { *m = GetACLResponse{} }
 func (m *GetACLResponse) String() string { return proto.CompactTextString(m) }
 func (*GetACLResponse) ProtoMessage()    {}
 func (*GetACLResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{31}
+	return fileDescriptor_15ace9a5d0179ff3, []int{32}
 }
 func (m *GetACLResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2009,7 +2107,7 @@ func (m *SetACLRequest) Reset()         { *m = SetACLRequest{} }
 func (m *SetACLRequest) String() string { return proto.CompactTextString(m) }
 func (*SetACLRequest) ProtoMessage()    {}
 func (*SetACLRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{32}
+	return fileDescriptor_15ace9a5d0179ff3, []int{33}
 }
 func (m *SetACLRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2062,7 +2160,7 @@ func (m *SetACLResponse) Reset()         { *m = SetACLResponse{} }
 func (m *SetACLResponse) String() string { return proto.CompactTextString(m) }
 func (*SetACLResponse) ProtoMessage()    {}
 func (*SetACLResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{33}
+	return fileDescriptor_15ace9a5d0179ff3, []int{34}
 }
 func (m *SetACLResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2097,7 +2195,15 @@ type GetAuthTokenRequest struct {
 	Subject string `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
 	// ttl indicates the requested (approximate) remaining lifetime of this token,
 	// in seconds
-	TTL                  int64    `protobuf:"varint,2,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	TTL int64 `protobuf:"varint,2,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	// restrictions, if set, limits the returned token to the listed repos (see
+	// TokenInfo.restrictions). Callers whose own token has restrictions may only
+	// get tokens that are at least as restricted.
+	Restrictions []*ScopeRestriction `protobuf:"bytes,3,rep,name=restrictions,proto3" json:"restrictions,omitempty"`
+	// read_only, if set, limits the returned token to READER access (see
+	// TokenInfo.read_only). Tokens obtained by read-only callers are always
+	// read-only.
+	ReadOnly             bool     `protobuf:"varint,4,opt,name=read_only,json=readOnly,proto3" json:"read_only,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -2107,7 +2213,7 @@ func (m *GetAuthTokenRequest) Reset()         { *m = GetAuthTokenRequest{} }
 func (m *GetAuthTokenRequest) String() string { return proto.CompactTextString(m) }
 func (*GetAuthTokenRequest) ProtoMessage()    {}
 func (*GetAuthTokenRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{34}
+	return fileDescriptor_15ace9a5d0179ff3, []int{35}
 }
 func (m *GetAuthTokenRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2150,6 +2256,20 @@ func (m *GetAuthTokenRequest) GetTTL() int64 {
 	return 0
 }
 
+func (m *GetAuthTokenRequest) GetRestrictions() []*ScopeRestriction {
+	if m != nil {
+		return m.Restrictions
+	}
+	return nil
+}
+
+func (m *GetAuthTokenRequest) GetReadOnly() bool {
+	if m != nil {
+		return m.ReadOnly
+	}
+	return false
+}
+
 type GetAuthTokenResponse struct {
 	// A canonicalized version of the subject in the request
 	Subject string `protobuf:"bytes,2,opt,name=subject,proto3" json:"subject,omitempty"`
@@ -2164,7 +2284,7 @@ func (m *GetAuthTokenResponse) Reset()         { *m = GetAuthTokenResponse{} }
 func (m *GetAuthTokenResponse) String() string { return proto.CompactTextString(m) }
 func (*GetAuthTokenResponse) ProtoMessage()    {}
 func (*GetAuthTokenResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{35}
+	return fileDescriptor_15ace9a5d0179ff3, []int{36}
 }
 func (m *GetAuthTokenResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2221,7 +2341,7 @@ func (m *ExtendAuthTokenRequest) Reset()         { *m = ExtendAuthTokenRequest{}
 func (m *ExtendAuthTokenRequest) String() string { return proto.CompactTextString(m) }
 func (*ExtendAuthTokenRequest) ProtoMessage()    {}
 func (*ExtendAuthTokenRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{36}
+	return fileDescriptor_15ace9a5d0179ff3, []int{37}
 }
 func (m *ExtendAuthTokenRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2274,7 +2394,7 @@ func (m *ExtendAuthTokenResponse) Reset()         { *m = ExtendAuthTokenResponse
 func (m *ExtendAuthTokenResponse) String() string { return proto.CompactTextString(m) }
 func (*ExtendAuthTokenResponse) ProtoMessage()    {}
 func (*ExtendAuthTokenResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{37}
+	return fileDescriptor_15ace9a5d0179ff3, []int{38}
 }
 func (m *ExtendAuthTokenResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2314,7 +2434,7 @@ func (m *RevokeAuthTokenRequest) Reset()         { *m = RevokeAuthTokenRequest{}
 func (m *RevokeAuthTokenRequest) String() string { return proto.CompactTextString(m) }
 func (*RevokeAuthTokenRequest) ProtoMessage()    {}
 func (*RevokeAuthTokenRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{38}
+	return fileDescriptor_15ace9a5d0179ff3, []int{39}
 }
 func (m *RevokeAuthTokenRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2360,7 +2480,7 @@ func (m *RevokeAuthTokenResponse) Reset()         { *m = RevokeAuthTokenResponse
 func (m *RevokeAuthTokenResponse) String() string { return proto.CompactTextString(m) }
 func (*RevokeAuthTokenResponse) ProtoMessage()    {}
 func (*RevokeAuthTokenResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{39}
+	return fileDescriptor_15ace9a5d0179ff3, []int{40}
 }
 func (m *RevokeAuthTokenResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2401,7 +2521,7 @@ func (m *SetGroupsForUserRequest) Reset()         { *m = SetGroupsForUserRequest
 func (m *SetGroupsForUserRequest) String() string { return proto.CompactTextString(m) }
 func (*SetGroupsForUserRequest) ProtoMessage()    {}
 func (*SetGroupsForUserRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{40}
+	return fileDescriptor_15ace9a5d0179ff3, []int{41}
 }
 func (m *SetGroupsForUserRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2454,7 +2574,7 @@ func (m *SetGroupsForUserResponse) Reset()         { *m = SetGroupsForUserRespon
 func (m *SetGroupsForUserResponse) String() string { return proto.CompactTextString(m) }
 func (*SetGroupsForUserResponse) ProtoMessage()    {}
 func (*SetGroupsForUserResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{41}
+	return fileDescriptor_15ace9a5d0179ff3, []int{42}
 }
 func (m *SetGroupsForUserResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2496,7 +2616,7 @@ func (m *ModifyMembersRequest) Reset()         { *m = ModifyMembersRequest{} }
 func (m *ModifyMembersRequest) String() string { return proto.CompactTextString(m) }
 func (*ModifyMembersRequest) ProtoMessage()    {}
 func (*ModifyMembersRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{42}
+	return fileDescriptor_15ace9a5d0179ff3, []int{43}
 }
 func (m *ModifyMembersRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2556,7 +2676,7 @@ func (m *ModifyMembersResponse) Reset()         { *m = ModifyMembersResponse{} }
 func (m *ModifyMembersResponse) String() string { return proto.CompactTextString(m) }
 func (*ModifyMembersResponse) ProtoMessage()    {}
 func (*ModifyMembersResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{43}
+	return fileDescriptor_15ace9a5d0179ff3, []int{44}
 }
 func (m *ModifyMembersResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2596,7 +2716,7 @@ func (m *GetGroupsRequest) Reset()         { *m = GetGroupsRequest{} }
 func (m *GetGroupsRequest) String() string { return proto.CompactTextString(m) }
 func (*GetGroupsRequest) ProtoMessage()    {}
 func (*GetGroupsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{44}
+	return fileDescriptor_15ace9a5d0179ff3, []int{45}
 }
 func (m *GetGroupsRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2643,7 +2763,7 @@ func (m *GetGroupsResponse) Reset()         { *m = GetGroupsResponse{} }
 func (m *GetGroupsResponse) String() string { return proto.CompactTextString(m) }
 func (*GetGroupsResponse) ProtoMessage()    {}
 func (*GetGroupsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{45}
+	return fileDescriptor_15ace9a5d0179ff3, []int{46}
 }
 func (m *GetGroupsResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2690,7 +2810,7 @@ func (m *GetUsersRequest) Reset()         { *m = GetUsersRequest{} }
 func (m *GetUsersRequest) String() string { return proto.CompactTextString(m) }
 func (*GetUsersRequest) ProtoMessage()    {}
 func (*GetUsersRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{46}
+	return fileDescriptor_15ace9a5d0179ff3, []int{47}
 }
 func (m *GetUsersRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2737,7 +2857,7 @@ func (m *GetUsersResponse) Reset()         { *m = GetUsersResponse{} }
 func (m *GetUsersResponse) String() string { return proto.CompactTextString(m) }
 func (*GetUsersResponse) ProtoMessage()    {}
 func (*GetUsersResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{47}
+	return fileDescriptor_15ace9a5d0179ff3, []int{48}
 }
 func (m *GetUsersResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2796,7 +2916,7 @@ func (m *GetOneTimePasswordRequest) Reset()         { *m = GetOneTimePasswordReq
 func (m *GetOneTimePasswordRequest) String() string { return proto.CompactTextString(m) }
 func (*GetOneTimePasswordRequest) ProtoMessage()    {}
 func (*GetOneTimePasswordRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{48}
+	return fileDescriptor_15ace9a5d0179ff3, []int{49}
 }
 func (m *GetOneTimePasswordRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2855,7 +2975,7 @@ func (m *GetOneTimePasswordResponse) Reset()         { *m = GetOneTimePasswordRe
 func (m *GetOneTimePasswordResponse) String() string { return proto.CompactTextString(m) }
 func (*GetOneTimePasswordResponse) ProtoMessage()    {}
 func (*GetOneTimePasswordResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_15ace9a5d0179ff3, []int{49}
+	return fileDescriptor_15ace9a5d0179ff3, []int{50}
 }
 func (m *GetOneTimePasswordResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -2898,211 +3018,473 @@ func (m *GetOneTimePasswordResponse) GetOTPExpiration() *types.Timestamp {
 	return nil
 }
 
-func init() {
-	proto.RegisterEnum("auth.Scope", Scope_name, Scope_value)
-	proto.RegisterEnum("auth.TokenInfo_TokenSource", TokenInfo_TokenSource_name, TokenInfo_TokenSource_value)
-	proto.RegisterType((*ActivateRequest)(nil), "auth.ActivateRequest")
-	proto.RegisterType((*ActivateResponse)(nil), "auth.ActivateResponse")
-	proto.RegisterType((*DeactivateRequest)(nil), "auth.DeactivateRequest")
-	proto.RegisterType((*DeactivateResponse)(nil), "auth.DeactivateResponse")
-	proto.RegisterType((*IDProvider)(nil), "auth.IDProvider")
-	proto.RegisterType((*IDProvider_SAMLOptions)(nil), "auth.IDProvider.SAMLOptions")
-	proto.RegisterType((*IDProvider_GitHubOptions)(nil), "auth.IDProvider.GitHubOptions")
-	proto.RegisterType((*AuthConfig)(nil), "auth.AuthConfig")
-	proto.RegisterType((*AuthConfig_SAMLServiceOptions)(nil), "auth.AuthConfig.SAMLServiceOptions")
-	proto.RegisterType((*GetConfigurationRequest)(nil), "auth.GetConfigurationRequest")
-	proto.RegisterType((*GetConfigurationResponse)(nil), "auth.GetConfigurationResponse")
-	proto.RegisterType((*SetConfigurationRequest)(nil), "auth.SetConfigurationRequest")
-	proto.RegisterType((*SetConfigurationResponse)(nil), "auth.SetConfigurationResponse")
-	proto.RegisterType((*GetAdminsRequest)(nil), "auth.GetAdminsRequest")
-	proto.RegisterType((*GetAdminsResponse)(nil), "auth.GetAdminsResponse")
-	proto.RegisterType((*ModifyAdminsRequest)(nil), "auth.ModifyAdminsRequest")
-	proto.RegisterType((*ModifyAdminsResponse)(nil), "auth.ModifyAdminsResponse")
-	proto.RegisterType((*OTPInfo)(nil), "auth.OTPInfo")
-	proto.RegisterType((*TokenInfo)(nil), "auth.TokenInfo")
-	proto.RegisterType((*AuthenticateRequest)(nil), "auth.AuthenticateRequest")
-	proto.RegisterType((*AuthenticateResponse)(nil), "auth.AuthenticateResponse")
-	proto.RegisterType((*WhoAmIRequest)(nil), "auth.WhoAmIRequest")
-	proto.RegisterType((*WhoAmIResponse)(nil), "auth.WhoAmIResponse")
-	proto.RegisterType((*ACL)(nil), "auth.ACL")
-	proto.RegisterMapType((map[string]Scope)(nil), "auth.ACL.EntriesEntry")
-	proto.RegisterType((*Users)(nil), "auth.Users")
-	proto.RegisterMapType((map[string]bool)(nil), "auth.Users.UsernamesEntry")
-	proto.RegisterType((*Groups)(nil), "auth.Groups")
-	proto.RegisterMapType((map[string]bool)(nil), "auth.Groups.GroupsEntry")
-	proto.RegisterType((*AuthorizeRequest)(nil), "auth.AuthorizeRequest")
-	proto.RegisterType((*AuthorizeResponse)(nil), "auth.AuthorizeResponse")
-	proto.RegisterType((*GetScopeRequest)(nil), "auth.GetScopeRequest")
-	proto.RegisterType((*GetScopeResponse)(nil), "auth.GetScopeResponse")
-	proto.RegisterType((*SetScopeRequest)(nil), "auth.SetScopeRequest")
-	proto.RegisterType((*SetScopeResponse)(nil), "auth.SetScopeResponse")
-	proto.RegisterType((*GetACLRequest)(nil), "auth.GetACLRequest")
-	proto.RegisterType((*ACLEntry)(nil), "auth.ACLEntry")
-	proto.RegisterType((*GetACLResponse)(nil), "auth.GetACLResponse")
-	proto.RegisterType((*SetACLRequest)(nil), "auth.SetACLRequest")
-	proto.RegisterType((*SetACLResponse)(nil), "auth.SetACLResponse")
-	proto.RegisterType((*GetAuthTokenRequest)(nil), "auth.GetAuthTokenRequest")
-	proto.RegisterType((*GetAuthTokenResponse)(nil), "auth.GetAuthTokenResponse")
-	proto.RegisterType((*ExtendAuthTokenRequest)(nil), "auth.ExtendAuthTokenRequest")
-	proto.RegisterType((*ExtendAuthTokenResponse)(nil), "auth.ExtendAuthTokenResponse")
-	proto.RegisterType((*RevokeAuthTokenRequest)(nil), "auth.RevokeAuthTokenRequest")
-	proto.RegisterType((*RevokeAuthTokenResponse)(nil), "auth.RevokeAuthTokenResponse")
-	proto.RegisterType((*SetGroupsForUserRequest)(nil), "auth.SetGroupsForUserRequest")
-	proto.RegisterType((*SetGroupsForUserResponse)(nil), "auth.SetGroupsForUserResponse")
-	proto.RegisterType((*ModifyMembersRequest)(nil), "auth.ModifyMembersRequest")
-	proto.RegisterType((*ModifyMembersResponse)(nil), "auth.ModifyMembersResponse")
-	proto.RegisterType((*GetGroupsRequest)(nil), "auth.GetGroupsRequest")
-	proto.RegisterType((*GetGroupsResponse)(nil), "auth.GetGroupsResponse")
-	proto.RegisterType((*GetUsersRequest)(nil), "auth.GetUsersRequest")
-	proto.RegisterType((*GetUsersResponse)(nil), "auth.GetUsersResponse")
-	proto.RegisterType((*GetOneTimePasswordRequest)(nil), "auth.GetOneTimePasswordRequest")
-	proto.RegisterType((*GetOneTimePasswordResponse)(nil), "auth.GetOneTimePasswordResponse")
+// AuditEvent records a single mutating PFS, PPS, or auth API call
+type AuditEvent struct {
+	Time *types.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	// username is the caller, as reported by WhoAmI (empty if auth isn't
+	// activated)
+	Username string `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	// method is the full name of the API call, e.g. "/pfs.API/DeleteCommit"
+	Method string `protobuf:"bytes,3,opt,name=method,proto3" json:"method,omitempty"`
+	// repo, pipeline, commit, and job identify the target of the call (each is
+	// empty if the call doesn't target that kind of resource)
+	Repo     string `protobuf:"bytes,4,opt,name=repo,proto3" json:"repo,omitempty"`
+	Pipeline string `protobuf:"bytes,5,opt,name=pipeline,proto3" json:"pipeline,omitempty"`
+	Commit   string `protobuf:"bytes,6,opt,name=commit,proto3" json:"commit,omitempty"`
+	Job      string `protobuf:"bytes,7,opt,name=job,proto3" json:"job,omitempty"`
+	// error is empty if the call succeeded
+	Error string `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"`
+	// hash is the hex-encoded HMAC-SHA256 of the previous event's hash and this
+	// event (with 'hash' unset), keyed with a secret that isn't stored with the
+	// log. This chains events together so that modifying or removing an event
+	// can be detected
+	Hash                 string   `protobuf:"bytes,9,opt,name=hash,proto3" json:"hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func init() { proto.RegisterFile("client/auth/auth.proto", fileDescriptor_15ace9a5d0179ff3) }
+func (m *AuditEvent) Reset()         { *m = AuditEvent{} }
+func (m *AuditEvent) String() string { return proto.CompactTextString(m) }
+func (*AuditEvent) ProtoMessage()    {}
+func (*AuditEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15ace9a5d0179ff3, []int{51}
+}
+func (m *AuditEvent) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *AuditEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_AuditEvent.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *AuditEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AuditEvent.Merge(m, src)
+}
+func (m *AuditEvent) XXX_Size() int {
+	return m.Size()
+}
+func (m *AuditEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_AuditEvent.DiscardUnknown(m)
+}
 
-var fileDescriptor_15ace9a5d0179ff3 = []byte{
-	// 1930 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x58, 0xdb, 0x72, 0x22, 0xc9,
-	0xd1, 0x16, 0x20, 0x21, 0x48, 0xc4, 0x41, 0x25, 0x16, 0xa1, 0xde, 0x1d, 0xa1, 0xbf, 0x15, 0xf1,
-	0xaf, 0xbc, 0x8e, 0x40, 0x63, 0x8d, 0xc7, 0x5e, 0xef, 0x6c, 0xd8, 0x81, 0x24, 0x96, 0x65, 0x8d,
-	0x0e, 0xee, 0x66, 0x66, 0xd6, 0xbe, 0x21, 0x9a, 0xee, 0x1a, 0xd4, 0x1e, 0xa0, 0x71, 0x1f, 0xf0,
-	0x8c, 0x6f, 0xec, 0x2b, 0xbf, 0x82, 0xef, 0xfc, 0x38, 0x0e, 0x5f, 0xda, 0x2f, 0xa0, 0x70, 0x10,
-	0xe1, 0x37, 0xf0, 0x03, 0x38, 0xea, 0xd4, 0x54, 0x37, 0x8d, 0x56, 0xb3, 0xbe, 0x91, 0xba, 0xf2,
-	0xf0, 0x55, 0x56, 0x66, 0x56, 0x66, 0x16, 0x50, 0x33, 0xc7, 0x36, 0x9e, 0xfa, 0xa7, 0x46, 0xe0,
-	0xdf, 0xd1, 0x3f, 0xcd, 0x99, 0xeb, 0xf8, 0x0e, 0xda, 0x24, 0xdf, 0x4a, 0x75, 0xe4, 0x8c, 0x1c,
-	0x4a, 0x38, 0x25, 0x5f, 0x8c, 0xa7, 0x34, 0x46, 0x8e, 0x33, 0x1a, 0xe3, 0x53, 0xba, 0x1a, 0x06,
-	0x6f, 0x4e, 0x7d, 0x7b, 0x82, 0x3d, 0xdf, 0x98, 0xcc, 0x98, 0x80, 0x3a, 0x80, 0x72, 0xcb, 0xf4,
-	0xed, 0xb9, 0xe1, 0x63, 0x0d, 0xff, 0x2e, 0xc0, 0x9e, 0x8f, 0xea, 0xb0, 0xed, 0x05, 0xc3, 0xdf,
-	0x62, 0xd3, 0xaf, 0xa7, 0x8f, 0x52, 0x27, 0x79, 0x4d, 0x2c, 0xd1, 0x19, 0xec, 0x8c, 0x6c, 0xff,
-	0x2e, 0x18, 0x0e, 0x7c, 0xe7, 0x2d, 0x9e, 0xd6, 0x53, 0x84, 0x7d, 0x5e, 0x5e, 0xdc, 0x37, 0x0a,
-	0x1d, 0xdb, 0xff, 0x3a, 0x18, 0xf6, 0x09, 0x59, 0x2b, 0x30, 0x21, 0xba, 0x50, 0x7f, 0x04, 0x95,
-	0xe5, 0x06, 0xde, 0xcc, 0x99, 0x7a, 0x18, 0x3d, 0x01, 0x98, 0x19, 0xe6, 0x9d, 0x8c, 0xa2, 0xe5,
-	0x09, 0x85, 0xa9, 0xec, 0xc1, 0xee, 0x25, 0x36, 0xa2, 0x56, 0xa9, 0x55, 0x40, 0x32, 0x91, 0x21,
-	0xa9, 0xff, 0x49, 0x03, 0x74, 0x2f, 0x6f, 0x5d, 0x67, 0x6e, 0x5b, 0xd8, 0x45, 0x08, 0x36, 0xa7,
-	0xc6, 0x04, 0x73, 0x48, 0xfa, 0x8d, 0x8e, 0xa0, 0x60, 0x61, 0xcf, 0x74, 0xed, 0x99, 0x6f, 0x3b,
-	0x53, 0x7e, 0x24, 0x99, 0x84, 0xbe, 0x80, 0x4d, 0xcf, 0x98, 0x8c, 0xeb, 0x99, 0xa3, 0xd4, 0x49,
-	0xe1, 0xec, 0x93, 0x26, 0xf5, 0xed, 0x12, 0xb5, 0xa9, 0xb7, 0xae, 0x7a, 0x37, 0x54, 0xd4, 0x3b,
-	0xcf, 0x2d, 0xee, 0x1b, 0x9b, 0x84, 0xa0, 0x51, 0x1d, 0x74, 0x0e, 0x59, 0x76, 0xda, 0xfa, 0x26,
-	0xd5, 0x3e, 0x5c, 0xd1, 0x66, 0x9e, 0x11, 0xfa, 0xb0, 0xb8, 0x6f, 0x64, 0x19, 0x49, 0xe3, 0x9a,
-	0xca, 0x5f, 0x53, 0x50, 0x90, 0xf6, 0x20, 0x6e, 0x9e, 0x60, 0xdf, 0xb0, 0x0c, 0xdf, 0x18, 0x04,
-	0xee, 0x58, 0x76, 0xf3, 0x15, 0xa7, 0xbf, 0xd4, 0x7a, 0x5a, 0x41, 0x08, 0xbd, 0x74, 0xc7, 0x11,
-	0x9d, 0x77, 0x93, 0x31, 0x3d, 0xe6, 0x4e, 0x54, 0xe7, 0xdb, 0x2b, 0x49, 0xe7, 0xdb, 0xc9, 0x18,
-	0x7d, 0x0a, 0xe5, 0x91, 0xeb, 0x04, 0xb3, 0x81, 0xe1, 0xfb, 0xae, 0x3d, 0x0c, 0x7c, 0x4c, 0x5d,
-	0x90, 0xd7, 0x4a, 0x94, 0xdc, 0x12, 0x54, 0xa5, 0x0c, 0xc5, 0xc8, 0x29, 0xd4, 0x7f, 0x66, 0x00,
-	0x5a, 0x81, 0x7f, 0x77, 0xe1, 0x4c, 0xdf, 0xd8, 0x23, 0xd4, 0x84, 0xbd, 0xb1, 0x3d, 0xc7, 0x03,
-	0x93, 0x2e, 0x07, 0x73, 0xec, 0x7a, 0xc4, 0xd5, 0xc4, 0xee, 0x8c, 0xb6, 0x4b, 0x58, 0x4c, 0xf0,
-	0x15, 0x63, 0xa0, 0x4b, 0xd8, 0xb1, 0xad, 0xc1, 0x8c, 0x7b, 0xc8, 0xab, 0xa7, 0x8f, 0x32, 0x27,
-	0x85, 0xb3, 0x4a, 0xdc, 0x75, 0xcc, 0xfc, 0xe5, 0xda, 0xd3, 0x0a, 0xb6, 0x15, 0x2e, 0x10, 0x86,
-	0x0a, 0x09, 0xc1, 0xc0, 0x9b, 0x9b, 0x03, 0x87, 0x19, 0xc6, 0x43, 0x78, 0xcc, 0x90, 0x96, 0x16,
-	0xd2, 0x10, 0xea, 0xd8, 0x9d, 0xdb, 0x26, 0x16, 0x91, 0xa8, 0x2d, 0xee, 0x1b, 0x68, 0x95, 0xae,
-	0x95, 0x08, 0xa8, 0x3e, 0x37, 0xf9, 0x5a, 0xf9, 0x77, 0x0a, 0x12, 0xc4, 0xd0, 0x31, 0x6c, 0x1b,
-	0xa6, 0x27, 0xc5, 0x87, 0x46, 0xb6, 0x75, 0xa1, 0x93, 0xd0, 0x64, 0x0d, 0xd3, 0x8b, 0x47, 0x85,
-	0x48, 0xa6, 0x1f, 0x11, 0xc9, 0xff, 0x87, 0x9c, 0x65, 0x78, 0x77, 0x54, 0x9e, 0x86, 0xe3, 0xbc,
-	0xb0, 0xb8, 0x6f, 0x6c, 0x5f, 0x1a, 0xde, 0x1d, 0x91, 0xdd, 0x26, 0x4c, 0x22, 0xf7, 0x03, 0xa8,
-	0x78, 0xd8, 0x23, 0xfe, 0x1c, 0x58, 0x81, 0x6b, 0xd0, 0xe4, 0xde, 0xa4, 0xe1, 0x2b, 0x73, 0xfa,
-	0x25, 0x27, 0xa3, 0x63, 0x28, 0x5a, 0x78, 0x18, 0x8c, 0x06, 0x63, 0x67, 0x34, 0xb2, 0xa7, 0xa3,
-	0xfa, 0xd6, 0x51, 0xea, 0x24, 0xa7, 0xed, 0x50, 0x62, 0x8f, 0xd1, 0xd4, 0x03, 0xd8, 0xef, 0x60,
-	0x9f, 0xf9, 0x8b, 0x2b, 0x8a, 0xbb, 0xa7, 0x41, 0x7d, 0x95, 0xc5, 0xef, 0xf2, 0x4f, 0xa0, 0x68,
-	0xca, 0x0c, 0xea, 0x8d, 0x30, 0x98, 0xcb, 0x10, 0x68, 0x51, 0x31, 0xf5, 0x57, 0xb0, 0xaf, 0x27,
-	0x6f, 0xf7, 0xbd, 0x21, 0x15, 0xa8, 0xeb, 0x6b, 0xcc, 0x54, 0x11, 0x54, 0x3a, 0xd8, 0x6f, 0x59,
-	0x13, 0x7b, 0xea, 0x89, 0x63, 0xfd, 0x10, 0x76, 0x25, 0x1a, 0x3f, 0x4f, 0x0d, 0xb2, 0x06, 0xa5,
-	0xd4, 0x53, 0x47, 0x99, 0x93, 0xbc, 0xc6, 0x57, 0xea, 0x2f, 0x60, 0xef, 0xca, 0xb1, 0xec, 0x37,
-	0xef, 0x23, 0x18, 0xa8, 0x02, 0x19, 0xc3, 0xb2, 0xb8, 0x2c, 0xf9, 0x24, 0x00, 0x2e, 0x9e, 0x38,
-	0x73, 0x4c, 0xd3, 0x3a, 0xaf, 0xf1, 0x95, 0x5a, 0x83, 0x6a, 0x14, 0x80, 0x5b, 0x36, 0x85, 0xed,
-	0x9b, 0xfe, 0x6d, 0x77, 0xfa, 0xc6, 0x91, 0x2b, 0x6f, 0x2a, 0x5a, 0x79, 0xbb, 0x80, 0x44, 0xb0,
-	0xf1, 0xbb, 0x99, 0xcd, 0xfd, 0x92, 0xa6, 0x7e, 0x51, 0x9a, 0xac, 0xc8, 0x37, 0x45, 0x91, 0x6f,
-	0xf6, 0x45, 0x91, 0xd7, 0x76, 0xb9, 0x56, 0x3b, 0x54, 0x52, 0xff, 0x92, 0x82, 0x3c, 0xad, 0xb3,
-	0xdf, 0xb1, 0xe5, 0x33, 0xc8, 0x7a, 0x4e, 0xe0, 0x9a, 0x98, 0x6e, 0x53, 0x3a, 0xfb, 0x98, 0xb9,
-	0x3f, 0x54, 0x65, 0x5f, 0x3a, 0x15, 0xd1, 0xb8, 0xa8, 0xfa, 0x02, 0x0a, 0x12, 0x19, 0x15, 0x60,
-	0xbb, 0x7b, 0xfd, 0xaa, 0xd5, 0xeb, 0x5e, 0x56, 0x36, 0x50, 0x05, 0x76, 0x5a, 0x2f, 0xfb, 0x5f,
-	0xb7, 0xaf, 0xfb, 0xdd, 0x8b, 0x56, 0xbf, 0x5d, 0x49, 0xa1, 0x22, 0xe4, 0x3b, 0xed, 0xfe, 0xa0,
-	0x7f, 0xf3, 0xcb, 0xf6, 0x75, 0x25, 0xad, 0x06, 0xb0, 0x47, 0x82, 0x8b, 0xa7, 0xbe, 0x6d, 0x4a,
-	0xfd, 0xe8, 0x7b, 0x74, 0x1d, 0xf4, 0x19, 0xec, 0x3a, 0x53, 0x3c, 0x20, 0xdd, 0x6e, 0x30, 0x33,
-	0x3c, 0xef, 0xf7, 0x8e, 0x6b, 0xf1, 0xd2, 0x5f, 0x76, 0xa6, 0x98, 0x38, 0xe8, 0x96, 0x93, 0xd5,
-	0xe7, 0x50, 0x8d, 0x6e, 0xfb, 0xb8, 0x2e, 0x55, 0x86, 0xe2, 0xeb, 0x3b, 0xa7, 0x35, 0xe9, 0x8a,
-	0x74, 0x1a, 0x42, 0x49, 0x10, 0x38, 0x82, 0x02, 0xb9, 0xc0, 0xc3, 0xae, 0xd4, 0x92, 0xc2, 0x35,
-	0x3a, 0x80, 0x9c, 0xed, 0x0d, 0x68, 0x72, 0x51, 0xc3, 0x72, 0xda, 0xb6, 0xed, 0xd1, 0xd4, 0x40,
-	0x07, 0x90, 0xf1, 0x7d, 0x76, 0xf9, 0x33, 0xe7, 0xdb, 0x8b, 0xfb, 0x46, 0xa6, 0xdf, 0xef, 0x69,
-	0x84, 0xa6, 0xfe, 0x29, 0x05, 0x99, 0xd6, 0x45, 0x0f, 0x3d, 0x85, 0x6d, 0x3c, 0xf5, 0x5d, 0x1b,
-	0xb3, 0x34, 0x2d, 0x9c, 0xd5, 0xf8, 0xe5, 0xb8, 0xe8, 0x35, 0xdb, 0x8c, 0x41, 0xfe, 0xbd, 0xd7,
-	0x84, 0x98, 0xd2, 0x81, 0x1d, 0x99, 0x41, 0x12, 0xf7, 0x2d, 0x7e, 0xcf, 0xcd, 0x22, 0x9f, 0xe8,
-	0xff, 0x60, 0x6b, 0x6e, 0x8c, 0x03, 0x11, 0xef, 0x02, 0x43, 0xd4, 0x4d, 0x67, 0x86, 0x35, 0xc6,
-	0xf9, 0x22, 0xfd, 0x79, 0x4a, 0xfd, 0x23, 0x6c, 0xbd, 0xf4, 0x48, 0xfd, 0xfd, 0x1c, 0xf2, 0xe2,
-	0x34, 0xc2, 0x0a, 0x85, 0xe9, 0x50, 0x3e, 0xfd, 0x4b, 0x99, 0xcc, 0x92, 0xa5, 0xb0, 0xf2, 0x25,
-	0x94, 0xa2, 0xcc, 0x04, 0x6b, 0xaa, 0xb2, 0x35, 0x39, 0xd9, 0x80, 0x00, 0xb2, 0x1d, 0xd2, 0x9f,
-	0x3c, 0xf4, 0x14, 0xb2, 0xb4, 0x53, 0x89, 0xed, 0xeb, 0x6c, 0x7b, 0xc6, 0xe5, 0xff, 0xd8, 0xe6,
-	0x5c, 0x4e, 0xf9, 0x19, 0x14, 0x24, 0xf2, 0x07, 0x6d, 0xdb, 0x85, 0x0a, 0x49, 0x13, 0xc7, 0xb5,
-	0xff, 0x10, 0xa6, 0x26, 0x82, 0x4d, 0x17, 0xcf, 0x1c, 0x31, 0x6f, 0x90, 0x6f, 0xe2, 0x46, 0x8f,
-	0xf8, 0x2c, 0xd1, 0x8d, 0x94, 0xa3, 0x3e, 0x83, 0x5d, 0x09, 0x8a, 0x27, 0xcb, 0x21, 0x80, 0x21,
-	0x88, 0x16, 0x45, 0xcc, 0x69, 0x12, 0x45, 0xbd, 0x80, 0x72, 0x07, 0xfb, 0x0c, 0x87, 0x6f, 0xff,
-	0x50, 0x7e, 0x55, 0x61, 0x8b, 0x98, 0xe3, 0xf1, 0x2a, 0xc4, 0x16, 0xea, 0x4f, 0x69, 0x19, 0xe4,
-	0x20, 0x7c, 0xe3, 0x63, 0xc8, 0x52, 0xb3, 0x98, 0x17, 0x63, 0x16, 0x73, 0x96, 0x6a, 0x41, 0x59,
-	0xff, 0x80, 0xdd, 0x85, 0x63, 0xd2, 0x49, 0x8e, 0xc9, 0xac, 0x75, 0x0c, 0x82, 0x8a, 0x1e, 0x33,
-	0x4f, 0x3d, 0x86, 0x22, 0xa9, 0xd2, 0x17, 0xbd, 0x07, 0x9c, 0xae, 0x76, 0x21, 0xd7, 0xba, 0xe8,
-	0xb1, 0xa0, 0x3e, 0x64, 0xd7, 0x23, 0x82, 0xe3, 0x40, 0x49, 0xec, 0xc7, 0x1d, 0x74, 0x12, 0xbf,
-	0x6c, 0xa5, 0xf0, 0xb2, 0x45, 0x2f, 0x19, 0x7a, 0x06, 0x45, 0xd7, 0x19, 0x3a, 0xfe, 0x40, 0xc8,
-	0xa7, 0x13, 0xe5, 0x77, 0xa8, 0x10, 0xbf, 0x8e, 0xea, 0x15, 0x14, 0xf5, 0xef, 0x3a, 0xa0, 0x6c,
-	0x43, 0xfa, 0x41, 0x1b, 0xd4, 0x0a, 0x94, 0xf4, 0x88, 0xfd, 0xea, 0x37, 0xb0, 0x47, 0x4e, 0x14,
-	0xf8, 0xac, 0x72, 0x25, 0xcc, 0xf9, 0xb1, 0xd2, 0xcf, 0x0b, 0x50, 0x3a, 0xa1, 0x00, 0x7d, 0x05,
-	0xd5, 0x28, 0x16, 0xf7, 0xd1, 0xfa, 0x47, 0x43, 0x15, 0xb6, 0xe4, 0x0a, 0xca, 0x16, 0x6a, 0x17,
-	0x6a, 0xed, 0x77, 0x3e, 0x9e, 0x5a, 0x2b, 0x66, 0x25, 0xca, 0x3f, 0x64, 0xd2, 0x01, 0xec, 0xaf,
-	0x40, 0xf1, 0x93, 0x37, 0xa1, 0xa6, 0xe1, 0xb9, 0xf3, 0x16, 0x3f, 0x6e, 0x17, 0x02, 0xb5, 0x22,
-	0xcf, 0xa1, 0xae, 0xe8, 0xbc, 0xc2, 0x8a, 0xc7, 0x57, 0x8e, 0x4b, 0xea, 0xd7, 0x63, 0x2e, 0x42,
-	0x2d, 0x2c, 0x51, 0x7c, 0x1a, 0x60, 0x2b, 0x3e, 0xab, 0xc4, 0xe0, 0xf8, 0x56, 0xaf, 0xc4, 0xa4,
-	0x70, 0x85, 0x27, 0x43, 0x32, 0xf6, 0x2e, 0x6d, 0xa6, 0xda, 0xc2, 0x66, 0xba, 0x10, 0x13, 0x48,
-	0x3a, 0x69, 0x02, 0xc9, 0x44, 0x26, 0x90, 0x7d, 0xf8, 0x28, 0x86, 0x1b, 0xba, 0x89, 0x54, 0x05,
-	0x66, 0xcc, 0x23, 0x0e, 0xc5, 0x07, 0x27, 0x21, 0xbf, 0x1c, 0x9c, 0xa4, 0x62, 0xbc, 0x3c, 0xe9,
-	0xa7, 0xb4, 0x6e, 0xd1, 0x96, 0xf0, 0xe0, 0x41, 0xd4, 0xa7, 0xd4, 0x0a, 0x2e, 0xc8, 0x41, 0x3f,
-	0x89, 0xf7, 0x98, 0xbc, 0xd4, 0x47, 0xd4, 0x5b, 0x38, 0xe8, 0x60, 0xff, 0x26, 0xda, 0xcf, 0xff,
-	0xa7, 0xf4, 0xfe, 0x73, 0x0a, 0x94, 0x24, 0x48, 0x6e, 0x0e, 0x82, 0x4d, 0xd3, 0xb1, 0xc2, 0xf7,
-	0x25, 0xf9, 0x46, 0x7d, 0x28, 0x39, 0xfe, 0xec, 0x83, 0xc6, 0xb2, 0xf3, 0xdd, 0xc5, 0x7d, 0xa3,
-	0x78, 0xd3, 0xbf, 0x5d, 0x8e, 0x65, 0x5a, 0xd1, 0xf1, 0x67, 0xcb, 0xe5, 0x67, 0x3f, 0x86, 0x2d,
-	0x5a, 0x95, 0x50, 0x0e, 0x36, 0xaf, 0x6f, 0xae, 0xdb, 0x95, 0x0d, 0x04, 0x90, 0xd5, 0xda, 0xad,
-	0xcb, 0xb6, 0x56, 0x49, 0x91, 0xef, 0xd7, 0x5a, 0xb7, 0xdf, 0xd6, 0x2a, 0x69, 0x94, 0x87, 0xad,
-	0x9b, 0xd7, 0xd7, 0x6d, 0xad, 0x92, 0x39, 0xfb, 0x5b, 0x01, 0x32, 0xad, 0xdb, 0x2e, 0x7a, 0x01,
-	0x39, 0xf1, 0xe8, 0x46, 0x1f, 0xf1, 0x42, 0x11, 0x7d, 0x4f, 0x2b, 0xb5, 0x38, 0x99, 0xe7, 0xc2,
-	0x06, 0x6a, 0x01, 0x2c, 0x5f, 0xda, 0x68, 0x9f, 0xc9, 0xad, 0x3c, 0xc8, 0x95, 0xfa, 0x2a, 0x23,
-	0x84, 0xd0, 0x69, 0x28, 0x23, 0x93, 0x38, 0x7a, 0xc2, 0x9b, 0x73, 0xf2, 0xd0, 0xaf, 0x1c, 0xae,
-	0x63, 0xcb, 0xa0, 0xfa, 0x1a, 0x50, 0xfd, 0x61, 0x50, 0x7d, 0x3d, 0xe8, 0xcf, 0x21, 0x1f, 0xbe,
-	0x01, 0x50, 0x2d, 0xb4, 0x21, 0x32, 0xe4, 0x2b, 0xfb, 0x2b, 0xf4, 0x50, 0xbf, 0x03, 0x3b, 0xf2,
-	0x54, 0x8f, 0x0e, 0x98, 0x68, 0xc2, 0x53, 0x41, 0x51, 0x92, 0x58, 0x32, 0x90, 0x3c, 0x85, 0x0a,
-	0xa0, 0x84, 0x81, 0x58, 0x00, 0x25, 0x0d, 0xad, 0xec, 0x44, 0xe1, 0x70, 0x21, 0x4e, 0x14, 0x1f,
-	0x5c, 0xc4, 0x89, 0x56, 0xa6, 0x10, 0x75, 0x03, 0x3d, 0x87, 0x2c, 0x1b, 0x63, 0xd1, 0x1e, 0x13,
-	0x8a, 0x4c, 0xb9, 0x4a, 0x35, 0x4a, 0x0c, 0xd5, 0x5e, 0x40, 0x4e, 0x4c, 0x16, 0x22, 0xe5, 0x62,
-	0xe3, 0x8a, 0x52, 0x8b, 0x93, 0x65, 0x65, 0x3d, 0xa6, 0xac, 0x27, 0x2b, 0xeb, 0xab, 0xca, 0xcf,
-	0x21, 0xcb, 0x1a, 0xb6, 0x30, 0x38, 0x32, 0x2e, 0x08, 0x83, 0xa3, 0x3d, 0x9d, 0xa9, 0xe9, 0x11,
-	0x35, 0x3d, 0x49, 0x4d, 0x8f, 0xab, 0x75, 0x60, 0x47, 0x6e, 0x80, 0x22, 0x4e, 0x09, 0x0d, 0x56,
-	0xc4, 0x29, 0xa9, 0x5f, 0xaa, 0x1b, 0xe8, 0x16, 0xca, 0xb1, 0xb6, 0x85, 0xf8, 0x4f, 0x4f, 0xc9,
-	0x8d, 0x51, 0x79, 0xb2, 0x86, 0x2b, 0x23, 0xc6, 0xba, 0x97, 0x40, 0x4c, 0x6e, 0x82, 0x02, 0x71,
-	0x5d, 0xcb, 0x13, 0x57, 0x2e, 0xd2, 0xa5, 0xa4, 0x2b, 0x97, 0xd4, 0x0c, 0xa5, 0x2b, 0x97, 0xdc,
-	0xdc, 0x36, 0xd0, 0x37, 0x50, 0x8c, 0xb4, 0x21, 0x14, 0xb9, 0x18, 0xd1, 0x9e, 0xa7, 0x7c, 0x9c,
-	0xc8, 0x8b, 0x5d, 0x5f, 0xfe, 0x1c, 0x58, 0xe6, 0x57, 0xa4, 0x95, 0x49, 0xd7, 0x37, 0xda, 0xb2,
-	0xc2, 0xac, 0x65, 0xef, 0x99, 0x65, 0xd6, 0xca, 0xcd, 0x4a, 0xca, 0xda, 0x48, 0x6b, 0x52, 0x37,
-	0xd0, 0xaf, 0x01, 0xad, 0xf6, 0x0a, 0xd4, 0x08, 0xe5, 0x93, 0x1b, 0x93, 0x72, 0xb4, 0x5e, 0x40,
-	0x40, 0x9f, 0x7f, 0xf9, 0xf7, 0xc5, 0x61, 0xea, 0x1f, 0x8b, 0xc3, 0xd4, 0xbf, 0x16, 0x87, 0xa9,
-	0xdf, 0x34, 0xd9, 0xd3, 0xb6, 0x69, 0x3a, 0x93, 0x53, 0xf2, 0x00, 0x7d, 0x6f, 0x61, 0x57, 0xfe,
-	0xf2, 0x5c, 0xf3, 0x54, 0xfa, 0x71, 0x78, 0x98, 0xa5, 0x2d, 0xe7, 0xd9, 0x7f, 0x03, 0x00, 0x00,
-	0xff, 0xff, 0x98, 0x57, 0x62, 0x2e, 0x32, 0x16, 0x00, 0x00,
+var xxx_messageInfo_AuditEvent proto.InternalMessageInfo
+
+func (m *AuditEvent) GetTime() *types.Timestamp {
+	if m != nil {
+		return m.Time
+	}
+	return nil
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (m *AuditEvent) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+func (m *AuditEvent) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
 
-// APIClient is the client API for API service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type APIClient interface {
-	// Activate/Deactivate the auth API. 'Activate' sets an initial set of admins
-	// for the Pachyderm cluster, and 'Deactivate' removes all ACLs, tokens, and
-	// admins from the Pachyderm cluster, making all data publicly accessable
-	Activate(ctx context.Context, in *ActivateRequest, opts ...grpc.CallOption) (*ActivateResponse, error)
-	Deactivate(ctx context.Context, in *DeactivateRequest, opts ...grpc.CallOption) (*DeactivateResponse, error)
+func (m *AuditEvent) GetRepo() string {
+	if m != nil {
+		return m.Repo
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetPipeline() string {
+	if m != nil {
+		return m.Pipeline
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetCommit() string {
+	if m != nil {
+		return m.Commit
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetJob() string {
+	if m != nil {
+		return m.Job
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *AuditEvent) GetHash() string {
+	if m != nil {
+		return m.Hash
+	}
+	return ""
+}
+
+type ListAuditEventsRequest struct {
+	// If set, only events whose caller is 'username' are returned
+	Username string `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	// If set, only events that target this repo or pipeline are returned
+	Resource string `protobuf:"bytes,2,opt,name=resource,proto3" json:"resource,omitempty"`
+	// If set, only events in ['since', 'until') are returned
+	Since                *types.Timestamp `protobuf:"bytes,3,opt,name=since,proto3" json:"since,omitempty"`
+	Until                *types.Timestamp `protobuf:"bytes,4,opt,name=until,proto3" json:"until,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *ListAuditEventsRequest) Reset()         { *m = ListAuditEventsRequest{} }
+func (m *ListAuditEventsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListAuditEventsRequest) ProtoMessage()    {}
+func (*ListAuditEventsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15ace9a5d0179ff3, []int{52}
+}
+func (m *ListAuditEventsRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ListAuditEventsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ListAuditEventsRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ListAuditEventsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListAuditEventsRequest.Merge(m, src)
+}
+func (m *ListAuditEventsRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *ListAuditEventsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListAuditEventsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListAuditEventsRequest proto.InternalMessageInfo
+
+func (m *ListAuditEventsRequest) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *ListAuditEventsRequest) GetResource() string {
+	if m != nil {
+		return m.Resource
+	}
+	return ""
+}
+
+func (m *ListAuditEventsRequest) GetSince() *types.Timestamp {
+	if m != nil {
+		return m.Since
+	}
+	return nil
+}
+
+func (m *ListAuditEventsRequest) GetUntil() *types.Timestamp {
+	if m != nil {
+		return m.Until
+	}
+	return nil
+}
+
+type ListAuditEventsResponse struct {
+	Events               []*AuditEvent `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *ListAuditEventsResponse) Reset()         { *m = ListAuditEventsResponse{} }
+func (m *ListAuditEventsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListAuditEventsResponse) ProtoMessage()    {}
+func (*ListAuditEventsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_15ace9a5d0179ff3, []int{53}
+}
+func (m *ListAuditEventsResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ListAuditEventsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ListAuditEventsResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ListAuditEventsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListAuditEventsResponse.Merge(m, src)
+}
+func (m *ListAuditEventsResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *ListAuditEventsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListAuditEventsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListAuditEventsResponse proto.InternalMessageInfo
+
+func (m *ListAuditEventsResponse) GetEvents() []*AuditEvent {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("auth.Scope", Scope_name, Scope_value)
+	proto.RegisterEnum("auth.TokenInfo_TokenSource", TokenInfo_TokenSource_name, TokenInfo_TokenSource_value)
+	proto.RegisterType((*ActivateRequest)(nil), "auth.ActivateRequest")
+	proto.RegisterType((*ActivateResponse)(nil), "auth.ActivateResponse")
+	proto.RegisterType((*DeactivateRequest)(nil), "auth.DeactivateRequest")
+	proto.RegisterType((*DeactivateResponse)(nil), "auth.DeactivateResponse")
+	proto.RegisterType((*IDProvider)(nil), "auth.IDProvider")
+	proto.RegisterType((*IDProvider_SAMLOptions)(nil), "auth.IDProvider.SAMLOptions")
+	proto.RegisterType((*IDProvider_GitHubOptions)(nil), "auth.IDProvider.GitHubOptions")
+	proto.RegisterType((*AuthConfig)(nil), "auth.AuthConfig")
+	proto.RegisterType((*AuthConfig_SAMLServiceOptions)(nil), "auth.AuthConfig.SAMLServiceOptions")
+	proto.RegisterType((*GetConfigurationRequest)(nil), "auth.GetConfigurationRequest")
+	proto.RegisterType((*GetConfigurationResponse)(nil), "auth.GetConfigurationResponse")
+	proto.RegisterType((*SetConfigurationRequest)(nil), "auth.SetConfigurationRequest")
+	proto.RegisterType((*SetConfigurationResponse)(nil), "auth.SetConfigurationResponse")
+	proto.RegisterType((*GetAdminsRequest)(nil), "auth.GetAdminsRequest")
+	proto.RegisterType((*GetAdminsResponse)(nil), "auth.GetAdminsResponse")
+	proto.RegisterType((*ModifyAdminsRequest)(nil), "auth.ModifyAdminsRequest")
+	proto.RegisterType((*ModifyAdminsResponse)(nil), "auth.ModifyAdminsResponse")
+	proto.RegisterType((*OTPInfo)(nil), "auth.OTPInfo")
+	proto.RegisterType((*ScopeRestriction)(nil), "auth.ScopeRestriction")
+	proto.RegisterType((*TokenInfo)(nil), "auth.TokenInfo")
+	proto.RegisterType((*AuthenticateRequest)(nil), "auth.AuthenticateRequest")
+	proto.RegisterType((*AuthenticateResponse)(nil), "auth.AuthenticateResponse")
+	proto.RegisterType((*WhoAmIRequest)(nil), "auth.WhoAmIRequest")
+	proto.RegisterType((*WhoAmIResponse)(nil), "auth.WhoAmIResponse")
+	proto.RegisterType((*ACL)(nil), "auth.ACL")
+	proto.RegisterMapType((map[string]Scope)(nil), "auth.ACL.EntriesEntry")
+	proto.RegisterType((*Users)(nil), "auth.Users")
+	proto.RegisterMapType((map[string]bool)(nil), "auth.Users.UsernamesEntry")
+	proto.RegisterType((*Groups)(nil), "auth.Groups")
+	proto.RegisterMapType((map[string]bool)(nil), "auth.Groups.GroupsEntry")
+	proto.RegisterType((*AuthorizeRequest)(nil), "auth.AuthorizeRequest")
+	proto.RegisterType((*AuthorizeResponse)(nil), "auth.AuthorizeResponse")
+	proto.RegisterType((*GetScopeRequest)(nil), "auth.GetScopeRequest")
+	proto.RegisterType((*GetScopeResponse)(nil), "auth.GetScopeResponse")
+	proto.RegisterType((*SetScopeRequest)(nil), "auth.SetScopeRequest")
+	proto.RegisterType((*SetScopeResponse)(nil), "auth.SetScopeResponse")
+	proto.RegisterType((*GetACLRequest)(nil), "auth.GetACLRequest")
+	proto.RegisterType((*ACLEntry)(nil), "auth.ACLEntry")
+	proto.RegisterType((*GetACLResponse)(nil), "auth.GetACLResponse")
+	proto.RegisterType((*SetACLRequest)(nil), "auth.SetACLRequest")
+	proto.RegisterType((*SetACLResponse)(nil), "auth.SetACLResponse")
+	proto.RegisterType((*GetAuthTokenRequest)(nil), "auth.GetAuthTokenRequest")
+	proto.RegisterType((*GetAuthTokenResponse)(nil), "auth.GetAuthTokenResponse")
+	proto.RegisterType((*ExtendAuthTokenRequest)(nil), "auth.ExtendAuthTokenRequest")
+	proto.RegisterType((*ExtendAuthTokenResponse)(nil), "auth.ExtendAuthTokenResponse")
+	proto.RegisterType((*RevokeAuthTokenRequest)(nil), "auth.RevokeAuthTokenRequest")
+	proto.RegisterType((*RevokeAuthTokenResponse)(nil), "auth.RevokeAuthTokenResponse")
+	proto.RegisterType((*SetGroupsForUserRequest)(nil), "auth.SetGroupsForUserRequest")
+	proto.RegisterType((*SetGroupsForUserResponse)(nil), "auth.SetGroupsForUserResponse")
+	proto.RegisterType((*ModifyMembersRequest)(nil), "auth.ModifyMembersRequest")
+	proto.RegisterType((*ModifyMembersResponse)(nil), "auth.ModifyMembersResponse")
+	proto.RegisterType((*GetGroupsRequest)(nil), "auth.GetGroupsRequest")
+	proto.RegisterType((*GetGroupsResponse)(nil), "auth.GetGroupsResponse")
+	proto.RegisterType((*GetUsersRequest)(nil), "auth.GetUsersRequest")
+	proto.RegisterType((*GetUsersResponse)(nil), "auth.GetUsersResponse")
+	proto.RegisterType((*GetOneTimePasswordRequest)(nil), "auth.GetOneTimePasswordRequest")
+	proto.RegisterType((*GetOneTimePasswordResponse)(nil), "auth.GetOneTimePasswordResponse")
+	proto.RegisterType((*AuditEvent)(nil), "auth.AuditEvent")
+	proto.RegisterType((*ListAuditEventsRequest)(nil), "auth.ListAuditEventsRequest")
+	proto.RegisterType((*ListAuditEventsResponse)(nil), "auth.ListAuditEventsResponse")
+}
+
+func init() { proto.RegisterFile("client/auth/auth.proto", fileDescriptor_15ace9a5d0179ff3) }
+
+var fileDescriptor_15ace9a5d0179ff3 = []byte{
+	// 2167 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x19, 0xcb, 0x72, 0x1b, 0x59,
+	0x35, 0x2d, 0xc9, 0xb2, 0x74, 0x24, 0xd9, 0xed, 0x6b, 0x8f, 0xac, 0x74, 0x26, 0xb6, 0xe9, 0x54,
+	0x31, 0x66, 0xa8, 0x92, 0x43, 0x42, 0x60, 0x48, 0xa6, 0xa0, 0x64, 0x5b, 0xa3, 0x11, 0xf8, 0x45,
+	0xb7, 0x92, 0x0c, 0x6c, 0x54, 0xad, 0xee, 0x1b, 0xb9, 0x13, 0xa9, 0x5b, 0xf4, 0x43, 0xc4, 0x6c,
+	0x60, 0xc5, 0x37, 0xb0, 0x62, 0xc3, 0x2f, 0xb0, 0xe3, 0x07, 0x58, 0xc2, 0x0f, 0xb8, 0x28, 0x55,
+	0xb1, 0x67, 0x31, 0x1f, 0x40, 0xdd, 0x47, 0xb7, 0x6e, 0xb7, 0x5a, 0xb6, 0x13, 0xd8, 0x58, 0xf7,
+	0x9e, 0xf7, 0x3d, 0xe7, 0x9e, 0xc7, 0x6d, 0x43, 0xdd, 0x1c, 0xd9, 0xd8, 0x09, 0x0e, 0x8c, 0x30,
+	0xb8, 0xa4, 0x7f, 0x9a, 0x13, 0xcf, 0x0d, 0x5c, 0x54, 0x20, 0x6b, 0x65, 0x6b, 0xe8, 0x0e, 0x5d,
+	0x0a, 0x38, 0x20, 0x2b, 0x86, 0x53, 0x76, 0x87, 0xae, 0x3b, 0x1c, 0xe1, 0x03, 0xba, 0x1b, 0x84,
+	0x6f, 0x0e, 0x02, 0x7b, 0x8c, 0xfd, 0xc0, 0x18, 0x4f, 0x18, 0x81, 0xda, 0x87, 0xf5, 0x96, 0x19,
+	0xd8, 0x53, 0x23, 0xc0, 0x1a, 0xfe, 0x4d, 0x88, 0xfd, 0x00, 0x35, 0x60, 0xd5, 0x0f, 0x07, 0x6f,
+	0xb1, 0x19, 0x34, 0x72, 0x7b, 0xd2, 0x7e, 0x59, 0x8b, 0xb6, 0xe8, 0x09, 0x54, 0x87, 0x76, 0x70,
+	0x19, 0x0e, 0xfa, 0x81, 0xfb, 0x0e, 0x3b, 0x0d, 0x89, 0xa0, 0x0f, 0xd7, 0x67, 0xd7, 0xbb, 0x95,
+	0x8e, 0x1d, 0x7c, 0x1d, 0x0e, 0x7a, 0x04, 0xac, 0x55, 0x18, 0x11, 0xdd, 0xa8, 0x3f, 0x00, 0x79,
+	0xae, 0xc0, 0x9f, 0xb8, 0x8e, 0x8f, 0xd1, 0x43, 0x80, 0x89, 0x61, 0x5e, 0x8a, 0x52, 0xb4, 0x32,
+	0x81, 0x30, 0x96, 0x4d, 0xd8, 0x38, 0xc6, 0x46, 0xd2, 0x2a, 0x75, 0x0b, 0x90, 0x08, 0x64, 0x92,
+	0xd4, 0x6f, 0x73, 0x00, 0xdd, 0xe3, 0x0b, 0xcf, 0x9d, 0xda, 0x16, 0xf6, 0x10, 0x82, 0x82, 0x63,
+	0x8c, 0x31, 0x17, 0x49, 0xd7, 0x68, 0x0f, 0x2a, 0x16, 0xf6, 0x4d, 0xcf, 0x9e, 0x04, 0xb6, 0xeb,
+	0xf0, 0x23, 0x89, 0x20, 0xf4, 0x1c, 0x0a, 0xbe, 0x31, 0x1e, 0x35, 0xf2, 0x7b, 0xd2, 0x7e, 0xe5,
+	0xc9, 0xa7, 0x4d, 0xea, 0xdb, 0xb9, 0xd4, 0xa6, 0xde, 0x3a, 0x3d, 0x39, 0xa7, 0xa4, 0xfe, 0x61,
+	0x69, 0x76, 0xbd, 0x5b, 0x20, 0x00, 0x8d, 0xf2, 0xa0, 0x43, 0x28, 0xb2, 0xd3, 0x36, 0x0a, 0x94,
+	0x7b, 0x67, 0x81, 0x9b, 0x79, 0x26, 0xe2, 0x87, 0xd9, 0xf5, 0x6e, 0x91, 0x81, 0x34, 0xce, 0xa9,
+	0xfc, 0x59, 0x82, 0x8a, 0xa0, 0x83, 0xb8, 0x79, 0x8c, 0x03, 0xc3, 0x32, 0x02, 0xa3, 0x1f, 0x7a,
+	0x23, 0xd1, 0xcd, 0xa7, 0x1c, 0xfe, 0x52, 0x3b, 0xd1, 0x2a, 0x11, 0xd1, 0x4b, 0x6f, 0x94, 0xe0,
+	0x79, 0x3f, 0x1e, 0xd1, 0x63, 0x56, 0x93, 0x3c, 0xdf, 0x9c, 0x0a, 0x3c, 0xdf, 0x8c, 0x47, 0xe8,
+	0x33, 0x58, 0x1f, 0x7a, 0x6e, 0x38, 0xe9, 0x1b, 0x41, 0xe0, 0xd9, 0x83, 0x30, 0xc0, 0xd4, 0x05,
+	0x65, 0x6d, 0x8d, 0x82, 0x5b, 0x11, 0x54, 0x59, 0x87, 0x5a, 0xe2, 0x14, 0xea, 0x3f, 0xf3, 0x00,
+	0xad, 0x30, 0xb8, 0x3c, 0x72, 0x9d, 0x37, 0xf6, 0x10, 0x35, 0x61, 0x73, 0x64, 0x4f, 0x71, 0xdf,
+	0xa4, 0xdb, 0xfe, 0x14, 0x7b, 0x3e, 0x71, 0x35, 0xb1, 0x3b, 0xaf, 0x6d, 0x10, 0x14, 0x23, 0x7c,
+	0xc5, 0x10, 0xe8, 0x18, 0xaa, 0xb6, 0xd5, 0x9f, 0x70, 0x0f, 0xf9, 0x8d, 0xdc, 0x5e, 0x7e, 0xbf,
+	0xf2, 0x44, 0x4e, 0xbb, 0x8e, 0x99, 0x3f, 0xdf, 0xfb, 0x5a, 0xc5, 0xb6, 0xe2, 0x0d, 0xc2, 0x20,
+	0x93, 0x10, 0xf4, 0xfd, 0xa9, 0xd9, 0x77, 0x99, 0x61, 0x3c, 0x84, 0x8f, 0x98, 0xa4, 0xb9, 0x85,
+	0x34, 0x84, 0x3a, 0xf6, 0xa6, 0xb6, 0x89, 0xa3, 0x48, 0xd4, 0x67, 0xd7, 0xbb, 0x68, 0x11, 0xae,
+	0xad, 0x11, 0xa1, 0xfa, 0xd4, 0xe4, 0x7b, 0xe5, 0xdf, 0x12, 0x64, 0x90, 0xa1, 0x47, 0xb0, 0x6a,
+	0x98, 0xbe, 0x10, 0x1f, 0x1a, 0xd9, 0xd6, 0x91, 0x4e, 0x42, 0x53, 0x34, 0x4c, 0x3f, 0x1d, 0x95,
+	0xd0, 0x63, 0x51, 0xb9, 0x2d, 0x92, 0xdf, 0x85, 0x92, 0x65, 0xf8, 0x97, 0x94, 0x9e, 0x86, 0xe3,
+	0xb0, 0x32, 0xbb, 0xde, 0x5d, 0x3d, 0x36, 0xfc, 0x4b, 0x42, 0xbb, 0x4a, 0x90, 0x84, 0xee, 0x7b,
+	0x20, 0xfb, 0xd8, 0x27, 0xfe, 0xec, 0x5b, 0xa1, 0x67, 0xd0, 0xcb, 0x5d, 0xa0, 0xe1, 0x5b, 0xe7,
+	0xf0, 0x63, 0x0e, 0x46, 0x8f, 0xa0, 0x66, 0xe1, 0x41, 0x38, 0xec, 0x8f, 0xdc, 0xe1, 0xd0, 0x76,
+	0x86, 0x8d, 0x95, 0x3d, 0x69, 0xbf, 0xa4, 0x55, 0x29, 0xf0, 0x84, 0xc1, 0xd4, 0xfb, 0xb0, 0xdd,
+	0xc1, 0x01, 0xf3, 0x17, 0x67, 0x8c, 0x72, 0x4f, 0x83, 0xc6, 0x22, 0x8a, 0xe7, 0xf2, 0x8f, 0xa0,
+	0x66, 0x8a, 0x08, 0xea, 0x8d, 0x38, 0x98, 0xf3, 0x10, 0x68, 0x49, 0x32, 0xf5, 0x97, 0xb0, 0xad,
+	0x67, 0xab, 0xfb, 0x68, 0x91, 0x0a, 0x34, 0xf4, 0x25, 0x66, 0xaa, 0x08, 0xe4, 0x0e, 0x0e, 0x5a,
+	0xd6, 0xd8, 0x76, 0xfc, 0xe8, 0x58, 0xdf, 0x87, 0x0d, 0x01, 0xc6, 0xcf, 0x53, 0x87, 0xa2, 0x41,
+	0x21, 0x0d, 0x69, 0x2f, 0xbf, 0x5f, 0xd6, 0xf8, 0x4e, 0xfd, 0x19, 0x6c, 0x9e, 0xba, 0x96, 0xfd,
+	0xe6, 0x2a, 0x21, 0x03, 0xc9, 0x90, 0x37, 0x2c, 0x8b, 0xd3, 0x92, 0x25, 0x11, 0xe0, 0xe1, 0xb1,
+	0x3b, 0xc5, 0xf4, 0x5a, 0x97, 0x35, 0xbe, 0x53, 0xeb, 0xb0, 0x95, 0x14, 0xc0, 0x2d, 0x73, 0x60,
+	0xf5, 0xbc, 0x77, 0xd1, 0x75, 0xde, 0xb8, 0x62, 0xe5, 0x95, 0x92, 0x95, 0xb7, 0x0b, 0x28, 0x0a,
+	0x36, 0x7e, 0x3f, 0xb1, 0xb9, 0x5f, 0x72, 0xd4, 0x2f, 0x4a, 0x93, 0x15, 0xf9, 0x66, 0x54, 0xe4,
+	0x9b, 0xbd, 0xa8, 0xc8, 0x6b, 0x1b, 0x9c, 0xab, 0x1d, 0x33, 0xa9, 0x5d, 0x90, 0x75, 0xd3, 0x9d,
+	0x90, 0x1a, 0x1a, 0x78, 0xb6, 0x49, 0x60, 0xa4, 0x6e, 0x7a, 0x78, 0xe2, 0x46, 0x75, 0x93, 0xac,
+	0xd1, 0x77, 0x60, 0xc5, 0x27, 0x74, 0x54, 0xcb, 0xda, 0x93, 0x0a, 0xf3, 0x3e, 0x63, 0x65, 0x18,
+	0xf5, 0x3f, 0x12, 0x94, 0x69, 0xc9, 0xbe, 0xc5, 0xfa, 0xa7, 0x50, 0xf4, 0xdd, 0xd0, 0x33, 0x23,
+	0x59, 0x0f, 0x98, 0xac, 0x98, 0x95, 0xad, 0x74, 0x4a, 0xa2, 0x71, 0x52, 0xf4, 0x1c, 0xaa, 0xde,
+	0xdc, 0x44, 0x92, 0xda, 0xa4, 0x48, 0xd4, 0x45, 0x33, 0xe6, 0x68, 0x2d, 0x41, 0x8b, 0x1e, 0x40,
+	0xd9, 0xc3, 0x86, 0xd5, 0x77, 0x9d, 0xd1, 0x15, 0x4d, 0x8a, 0x92, 0x56, 0x22, 0x80, 0x73, 0x67,
+	0x74, 0xa5, 0xbe, 0x80, 0x8a, 0xa0, 0x0f, 0x55, 0x60, 0xb5, 0x7b, 0xf6, 0xaa, 0x75, 0xd2, 0x3d,
+	0x96, 0xef, 0x21, 0x19, 0xaa, 0xad, 0x97, 0xbd, 0xaf, 0xdb, 0x67, 0xbd, 0xee, 0x51, 0xab, 0xd7,
+	0x96, 0x25, 0x54, 0x83, 0x72, 0xa7, 0xdd, 0xeb, 0xf7, 0xce, 0x7f, 0xd1, 0x3e, 0x93, 0x73, 0x6a,
+	0x08, 0x9b, 0xe4, 0x02, 0x62, 0x27, 0xb0, 0x4d, 0xa1, 0x67, 0x7e, 0x44, 0x67, 0x44, 0x9f, 0xc3,
+	0x86, 0xeb, 0xe0, 0x3e, 0xe9, 0xc8, 0xfd, 0x89, 0xe1, 0xfb, 0xbf, 0x75, 0x3d, 0x8b, 0xb7, 0xa7,
+	0x75, 0xd7, 0xc1, 0x24, 0x88, 0x17, 0x1c, 0xac, 0x3e, 0x83, 0xad, 0xa4, 0xda, 0xbb, 0x75, 0xd2,
+	0x75, 0xa8, 0xbd, 0xbe, 0x74, 0x5b, 0xe3, 0x6e, 0x74, 0xe5, 0xff, 0x26, 0xc1, 0x5a, 0x04, 0xe1,
+	0x22, 0x14, 0x28, 0x85, 0x3e, 0xf6, 0x84, 0xbe, 0x19, 0xef, 0xd1, 0x7d, 0x28, 0xd9, 0x7e, 0x9f,
+	0x66, 0x00, 0xb5, 0xac, 0xa4, 0xad, 0xda, 0x3e, 0xbd, 0xbf, 0xe8, 0x3e, 0xe4, 0x83, 0x80, 0x55,
+	0xa8, 0xfc, 0xe1, 0xea, 0xec, 0x7a, 0x37, 0xdf, 0xeb, 0x9d, 0x68, 0x04, 0xb6, 0x10, 0xb9, 0xc2,
+	0xc7, 0x46, 0x6e, 0x25, 0x15, 0xb9, 0x3f, 0x48, 0x90, 0x6f, 0x1d, 0x9d, 0xa0, 0xc7, 0xb0, 0x8a,
+	0x9d, 0xc0, 0xb3, 0x31, 0x4b, 0xd2, 0x58, 0x76, 0xeb, 0xe8, 0xa4, 0xd9, 0x66, 0x08, 0xf2, 0x73,
+	0xa5, 0x45, 0x64, 0x4a, 0x07, 0xaa, 0x22, 0x82, 0xa4, 0xed, 0x3b, 0x7c, 0xc5, 0xcf, 0x4b, 0x96,
+	0xe4, 0xba, 0x4f, 0x8d, 0x51, 0x98, 0x7d, 0xdd, 0x29, 0xe6, 0x79, 0xee, 0x0b, 0x49, 0xfd, 0x3d,
+	0xac, 0xbc, 0xf4, 0x49, 0xf7, 0xf9, 0x02, 0xca, 0x91, 0x9b, 0x22, 0x2b, 0x14, 0xc6, 0x43, 0xf1,
+	0xcd, 0x97, 0x11, 0x92, 0x59, 0x32, 0x27, 0x56, 0xbe, 0x84, 0xb5, 0x24, 0x32, 0xc3, 0x9a, 0x2d,
+	0xd1, 0x9a, 0x92, 0x68, 0x40, 0x08, 0xc5, 0x0e, 0xe9, 0xce, 0x3e, 0x7a, 0x0c, 0x45, 0xda, 0xa7,
+	0x23, 0xf5, 0x0d, 0xa6, 0x9e, 0x61, 0xf9, 0x0f, 0x53, 0xce, 0xe9, 0x94, 0x9f, 0x40, 0x45, 0x00,
+	0x7f, 0x90, 0xda, 0x2e, 0xc8, 0xe4, 0x02, 0xba, 0x9e, 0xfd, 0xbb, 0xf8, 0xd2, 0x7f, 0x64, 0xd5,
+	0x78, 0x0a, 0x1b, 0x82, 0x28, 0x7e, 0x0b, 0x77, 0x00, 0x8c, 0x08, 0x68, 0x51, 0x89, 0x25, 0x4d,
+	0x80, 0xa8, 0x47, 0xb0, 0xde, 0xc1, 0x01, 0xbf, 0x3c, 0x4c, 0xfd, 0x4d, 0x17, 0x77, 0x0b, 0x56,
+	0x88, 0x39, 0x3e, 0xaf, 0xc1, 0x6c, 0xa3, 0xfe, 0x18, 0xe4, 0xb9, 0x10, 0xae, 0xf8, 0x11, 0x14,
+	0xa9, 0x59, 0xcc, 0x8b, 0x29, 0x8b, 0x39, 0x4a, 0xb5, 0x60, 0x5d, 0xff, 0x00, 0xed, 0x91, 0x63,
+	0x72, 0x59, 0x8e, 0xc9, 0x2f, 0x75, 0x0c, 0x02, 0x59, 0x4f, 0x99, 0xa7, 0x3e, 0x82, 0x1a, 0xe9,
+	0x51, 0x47, 0x27, 0x37, 0x38, 0x5d, 0xed, 0x42, 0xa9, 0x75, 0x74, 0xc2, 0x82, 0x7a, 0x93, 0x5d,
+	0x77, 0x08, 0x8e, 0x0b, 0x6b, 0x91, 0x3e, 0xee, 0xa0, 0xfd, 0x74, 0xb2, 0xad, 0xc5, 0xc9, 0x96,
+	0x4c, 0x32, 0xf4, 0x14, 0x6a, 0x9e, 0x3b, 0x70, 0x83, 0x7e, 0x44, 0x9f, 0xcb, 0xa4, 0xaf, 0x52,
+	0x22, 0x9e, 0x8e, 0xea, 0x29, 0xd4, 0xf4, 0xdb, 0x0e, 0x28, 0xda, 0x90, 0xbb, 0xd1, 0x06, 0x55,
+	0x86, 0x35, 0x3d, 0x61, 0xbf, 0xfa, 0x17, 0x09, 0x36, 0xc9, 0x91, 0xc2, 0x80, 0x15, 0xc5, 0x8c,
+	0x67, 0x4e, 0xaa, 0x5d, 0xf1, 0xd2, 0x96, 0xbb, 0x43, 0x69, 0xfb, 0xbf, 0x35, 0xa5, 0xaf, 0x60,
+	0x2b, 0x69, 0x24, 0xf7, 0xfe, 0xf2, 0xc7, 0xd8, 0x16, 0xac, 0x88, 0x55, 0x9f, 0x6d, 0xd4, 0x2e,
+	0xd4, 0xdb, 0xef, 0x03, 0xec, 0x58, 0x0b, 0xe7, 0xcd, 0xa4, 0xbf, 0xe1, 0xac, 0x64, 0x20, 0x5c,
+	0x10, 0xc5, 0x7d, 0xda, 0x84, 0xba, 0x86, 0xa7, 0xee, 0x3b, 0x7c, 0x37, 0x2d, 0x44, 0xd4, 0x02,
+	0x3d, 0x17, 0x75, 0x4a, 0xe7, 0x40, 0x56, 0x96, 0xbe, 0x72, 0x3d, 0x52, 0x19, 0xef, 0x92, 0x62,
+	0xf5, 0xb8, 0xf8, 0xf1, 0x29, 0x8b, 0xed, 0xf8, 0x0c, 0x98, 0x12, 0xc7, 0x55, 0xbd, 0x8a, 0x26,
+	0xb0, 0x53, 0x3c, 0x1e, 0x90, 0xe7, 0xc4, 0xdc, 0x66, 0xca, 0x1d, 0xd9, 0x4c, 0x37, 0xd1, 0x64,
+	0x97, 0xcb, 0x9a, 0xec, 0xf2, 0x89, 0xc9, 0x6e, 0x1b, 0x3e, 0x49, 0xc9, 0x8d, 0xdd, 0x24, 0x77,
+	0x22, 0x63, 0xee, 0x70, 0x28, 0x3e, 0x90, 0x46, 0xf4, 0xf3, 0x81, 0x54, 0x28, 0xf3, 0xf3, 0x93,
+	0x7e, 0x46, 0x2b, 0x22, 0x6d, 0x36, 0x37, 0x1e, 0x44, 0x7d, 0x0c, 0xf2, 0x9c, 0x90, 0x0b, 0xfd,
+	0x34, 0xdd, 0xbd, 0xca, 0x42, 0x87, 0x52, 0x2f, 0xe0, 0x7e, 0x07, 0x07, 0xe7, 0xc9, 0x19, 0xe4,
+	0x7f, 0xc9, 0x1b, 0xf5, 0x8f, 0x12, 0x28, 0x59, 0x22, 0xb9, 0x39, 0x08, 0x0a, 0xa6, 0x6b, 0xc5,
+	0xef, 0x76, 0xb2, 0x46, 0x3d, 0x58, 0x73, 0x83, 0xc9, 0x07, 0x8d, 0xbb, 0x87, 0x1b, 0xb3, 0xeb,
+	0xdd, 0xda, 0x79, 0xef, 0x62, 0x3e, 0xee, 0x6a, 0x35, 0x37, 0x98, 0xcc, 0xb7, 0xea, 0xb7, 0x12,
+	0x79, 0xb9, 0x5a, 0x76, 0xd0, 0x9e, 0x62, 0x27, 0x40, 0x4d, 0x28, 0x90, 0xf9, 0xab, 0x21, 0xdd,
+	0x26, 0x5a, 0xa3, 0x74, 0x89, 0xe8, 0xe5, 0x16, 0xaf, 0xe4, 0x18, 0x07, 0x97, 0xae, 0xc5, 0x5f,
+	0xd1, 0x7c, 0x17, 0x17, 0xb4, 0x82, 0x50, 0xd0, 0x14, 0x28, 0x4d, 0xec, 0x09, 0x1e, 0xd9, 0x0e,
+	0xa6, 0x53, 0x4e, 0x59, 0x8b, 0xf7, 0x44, 0x8e, 0xe9, 0x8e, 0xc7, 0x76, 0xd0, 0x28, 0x32, 0x39,
+	0x6c, 0x47, 0x2e, 0xe4, 0x5b, 0x77, 0xd0, 0x58, 0x65, 0xed, 0xfa, 0xad, 0x3b, 0x20, 0xf1, 0xc6,
+	0x9e, 0xe7, 0x7a, 0x8d, 0x12, 0x8b, 0x37, 0xdd, 0x10, 0x7d, 0x97, 0x86, 0x7f, 0xd9, 0x28, 0x33,
+	0x7d, 0x64, 0xad, 0xfe, 0x55, 0x82, 0xfa, 0x89, 0xed, 0x07, 0xf3, 0xa3, 0xdf, 0xe5, 0x42, 0x12,
+	0x9c, 0x87, 0x85, 0xd1, 0xbd, 0xac, 0xc5, 0x7b, 0xf4, 0x18, 0x56, 0x7c, 0xdb, 0x31, 0x71, 0x23,
+	0x7f, 0xab, 0xef, 0x18, 0x21, 0xe1, 0x08, 0x9d, 0xc0, 0x1e, 0x35, 0x0a, 0xb7, 0x73, 0x50, 0x42,
+	0xf5, 0x08, 0xb6, 0x17, 0xac, 0x8e, 0xdb, 0x52, 0x11, 0x53, 0x08, 0xef, 0x4a, 0xf1, 0xeb, 0x30,
+	0x22, 0xd5, 0x38, 0xfe, 0xf3, 0x1f, 0xc2, 0x0a, 0xad, 0xcc, 0xa8, 0x04, 0x85, 0xb3, 0xf3, 0xb3,
+	0xb6, 0x7c, 0x0f, 0x01, 0x14, 0xb5, 0x76, 0xeb, 0xb8, 0xad, 0xc9, 0x12, 0x59, 0xbf, 0xd6, 0xba,
+	0xbd, 0xb6, 0x26, 0xe7, 0x50, 0x19, 0x56, 0xce, 0x5f, 0x9f, 0xb5, 0x35, 0x39, 0xff, 0xe4, 0x4f,
+	0x55, 0xc8, 0xb7, 0x2e, 0xba, 0xe8, 0x05, 0x94, 0xa2, 0xef, 0x57, 0xe8, 0x13, 0xae, 0x23, 0xf9,
+	0x69, 0x4a, 0xa9, 0xa7, 0xc1, 0x3c, 0xfd, 0xef, 0xa1, 0x16, 0xc0, 0xfc, 0xa3, 0x15, 0xda, 0x66,
+	0x74, 0x0b, 0xdf, 0xb6, 0x94, 0xc6, 0x22, 0x22, 0x16, 0xa1, 0xd3, 0xec, 0x4d, 0x3c, 0x6a, 0xd1,
+	0x43, 0x3e, 0xe9, 0x65, 0xbf, 0x9f, 0x95, 0x9d, 0x65, 0x68, 0x51, 0xa8, 0xbe, 0x44, 0xa8, 0x7e,
+	0xb3, 0x50, 0x7d, 0xb9, 0xd0, 0x9f, 0x42, 0x39, 0x7e, 0x4e, 0xa3, 0x7a, 0x6c, 0x43, 0xe2, 0xbd,
+	0xac, 0x6c, 0x2f, 0xc0, 0x63, 0xfe, 0x0e, 0x54, 0xc5, 0x07, 0x32, 0xba, 0xcf, 0x48, 0x33, 0x5e,
+	0xdd, 0x8a, 0x92, 0x85, 0x12, 0x05, 0x89, 0x8f, 0xa5, 0x48, 0x50, 0xc6, 0xbb, 0x4d, 0x51, 0xb2,
+	0x50, 0xe2, 0x89, 0xe2, 0x49, 0x35, 0x3a, 0x51, 0x7a, 0x0a, 0x56, 0xb6, 0x17, 0xe0, 0x31, 0xff,
+	0x33, 0x28, 0xb2, 0xc7, 0x16, 0xda, 0x64, 0x44, 0x89, 0xc7, 0x98, 0xb2, 0x95, 0x04, 0xc6, 0x6c,
+	0x2f, 0xa0, 0x14, 0x8d, 0xa9, 0xd1, 0x95, 0x4b, 0xcd, 0xbe, 0x4a, 0x3d, 0x0d, 0x16, 0x99, 0xf5,
+	0x14, 0xb3, 0x9e, 0xcd, 0xac, 0x2f, 0x32, 0x3f, 0x83, 0x22, 0x9b, 0xfe, 0x22, 0x83, 0x13, 0xb3,
+	0xa7, 0xb2, 0x95, 0x04, 0x8a, 0x6c, 0x7a, 0x82, 0x4d, 0xcf, 0x62, 0xd3, 0xd3, 0x6c, 0x1d, 0xa8,
+	0x8a, 0x33, 0x4f, 0x14, 0xa7, 0x8c, 0x61, 0x4d, 0x51, 0xb2, 0x50, 0xb1, 0xa0, 0x0b, 0x58, 0x4f,
+	0x4d, 0x2a, 0x88, 0x7f, 0xc5, 0xcd, 0x9e, 0x85, 0x94, 0x87, 0x4b, 0xb0, 0xa2, 0xc4, 0xd4, 0xc0,
+	0x12, 0x49, 0xcc, 0x9e, 0x7b, 0x94, 0x87, 0x4b, 0xb0, 0xa9, 0x94, 0x4b, 0x0c, 0x26, 0x42, 0xca,
+	0x65, 0xcd, 0x3f, 0xca, 0xce, 0x32, 0x74, 0x2c, 0xf4, 0xe7, 0x50, 0x4b, 0x4c, 0x1e, 0x28, 0x91,
+	0x18, 0xc9, 0x31, 0x47, 0x79, 0x90, 0x89, 0x4b, 0xa5, 0x2f, 0xd3, 0x24, 0xa4, 0x6f, 0x62, 0x7a,
+	0x51, 0xb6, 0x17, 0xe0, 0xa9, 0x5b, 0xcb, 0x1e, 0xc7, 0xf3, 0x5b, 0x2b, 0xce, 0x27, 0x4a, 0x3d,
+	0x0d, 0x8e, 0x99, 0x7f, 0x05, 0x68, 0x71, 0x3c, 0x40, 0xbb, 0x31, 0x7d, 0xf6, 0x2c, 0xa2, 0xec,
+	0x2d, 0x27, 0x10, 0x43, 0x99, 0xea, 0x21, 0x51, 0x28, 0xb3, 0x1b, 0xa2, 0xf2, 0x70, 0x09, 0x36,
+	0x92, 0x78, 0xf8, 0xe5, 0xdf, 0x67, 0x3b, 0xd2, 0x3f, 0x66, 0x3b, 0xd2, 0xbf, 0x66, 0x3b, 0xd2,
+	0xaf, 0x9b, 0xec, 0x9b, 0x4e, 0xd3, 0x74, 0xc7, 0x07, 0xe4, 0xcb, 0xcb, 0x95, 0x85, 0x3d, 0x71,
+	0xe5, 0x7b, 0xe6, 0x81, 0xf0, 0x9f, 0x9b, 0x41, 0x91, 0xb6, 0xbb, 0xa7, 0xff, 0x1d, 0x00, 0xbb,
+	0x70, 0x5c, 0x67, 0xcf, 0x19, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// APIClient is the client API for API service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type APIClient interface {
+	// Activate/Deactivate the auth API. 'Activate' sets an initial set of admins
+	// for the Pachyderm cluster, and 'Deactivate' removes all ACLs, tokens, and
+	// admins from the Pachyderm cluster, making all data publicly accessable
+	Activate(ctx context.Context, in *ActivateRequest, opts ...grpc.CallOption) (*ActivateResponse, error)
+	Deactivate(ctx context.Context, in *DeactivateRequest, opts ...grpc.CallOption) (*DeactivateResponse, error)
 	GetConfiguration(ctx context.Context, in *GetConfigurationRequest, opts ...grpc.CallOption) (*GetConfigurationResponse, error)
 	SetConfiguration(ctx context.Context, in *SetConfigurationRequest, opts ...grpc.CallOption) (*SetConfigurationResponse, error)
 	// GetAdmins returns the current list of cluster admins
@@ -3124,6 +3506,7 @@ type APIClient interface {
 	GetGroups(ctx context.Context, in *GetGroupsRequest, opts ...grpc.CallOption) (*GetGroupsResponse, error)
 	GetUsers(ctx context.Context, in *GetUsersRequest, opts ...grpc.CallOption) (*GetUsersResponse, error)
 	GetOneTimePassword(ctx context.Context, in *GetOneTimePasswordRequest, opts ...grpc.CallOption) (*GetOneTimePasswordResponse, error)
+	ListAuditEvents(ctx context.Context, in *ListAuditEventsRequest, opts ...grpc.CallOption) (*ListAuditEventsResponse, error)
 }
 
 type aPIClient struct {
@@ -3323,6 +3706,15 @@ func (c *aPIClient) GetOneTimePassword(ctx context.Context, in *GetOneTimePasswo
 	return out, nil
 }
 
+func (c *aPIClient) ListAuditEvents(ctx context.Context, in *ListAuditEventsRequest, opts ...grpc.CallOption) (*ListAuditEventsResponse, error) {
+	out := new(ListAuditEventsResponse)
+	err := c.cc.Invoke(ctx, "/auth.API/ListAuditEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // APIServer is the server API for API service.
 type APIServer interface {
 	// Activate/Deactivate the auth API. 'Activate' sets an initial set of admins
@@ -3351,6 +3743,7 @@ type APIServer interface {
 	GetGroups(context.Context, *GetGroupsRequest) (*GetGroupsResponse, error)
 	GetUsers(context.Context, *GetUsersRequest) (*GetUsersResponse, error)
 	GetOneTimePassword(context.Context, *GetOneTimePasswordRequest) (*GetOneTimePasswordResponse, error)
+	ListAuditEvents(context.Context, *ListAuditEventsRequest) (*ListAuditEventsResponse, error)
 }
 
 // UnimplementedAPIServer can be embedded to have forward compatible implementations.
@@ -3420,6 +3813,9 @@ func (*UnimplementedAPIServer) GetUsers(ctx context.Context, req *GetUsersReques
 func (*UnimplementedAPIServer) GetOneTimePassword(ctx context.Context, req *GetOneTimePasswordRequest) (*GetOneTimePasswordResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetOneTimePassword not implemented")
 }
+func (*UnimplementedAPIServer) ListAuditEvents(ctx context.Context, req *ListAuditEventsRequest) (*ListAuditEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAuditEvents not implemented")
+}
 
 func RegisterAPIServer(s *grpc.Server, srv APIServer) {
 	s.RegisterService(&_API_serviceDesc, srv)
@@ -3803,6 +4199,24 @@ func _API_GetOneTimePassword_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_ListAuditEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAuditEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).ListAuditEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/auth.API/ListAuditEvents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).ListAuditEvents(ctx, req.(*ListAuditEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _API_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "auth.API",
 	HandlerType: (*APIServer)(nil),
@@ -3891,6 +4305,10 @@ var _API_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetOneTimePassword",
 			Handler:    _API_GetOneTimePassword_Handler,
 		},
+		{
+			MethodName: "ListAuditEvents",
+			Handler:    _API_ListAuditEvents_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "client/auth/auth.proto",
@@ -4601,7 +5019,7 @@ func (m *OTPInfo) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
-func (m *TokenInfo) Marshal() (dAtA []byte, err error) {
+func (m *ScopeRestriction) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -4611,12 +5029,12 @@ func (m *TokenInfo) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *TokenInfo) MarshalTo(dAtA []byte) (int, error) {
+func (m *ScopeRestriction) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *TokenInfo) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *ScopeRestriction) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
@@ -4625,22 +5043,22 @@ func (m *TokenInfo) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	if m.Source != 0 {
-		i = encodeVarintAuth(dAtA, i, uint64(m.Source))
+	if m.Scope != 0 {
+		i = encodeVarintAuth(dAtA, i, uint64(m.Scope))
 		i--
 		dAtA[i] = 0x10
 	}
-	if len(m.Subject) > 0 {
-		i -= len(m.Subject)
-		copy(dAtA[i:], m.Subject)
-		i = encodeVarintAuth(dAtA, i, uint64(len(m.Subject)))
+	if len(m.Repo) > 0 {
+		i -= len(m.Repo)
+		copy(dAtA[i:], m.Repo)
+		i = encodeVarintAuth(dAtA, i, uint64(len(m.Repo)))
 		i--
 		dAtA[i] = 0xa
 	}
 	return len(dAtA) - i, nil
 }
 
-func (m *AuthenticateRequest) Marshal() (dAtA []byte, err error) {
+func (m *TokenInfo) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -4650,12 +5068,12 @@ func (m *AuthenticateRequest) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *AuthenticateRequest) MarshalTo(dAtA []byte) (int, error) {
+func (m *TokenInfo) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *AuthenticateRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *TokenInfo) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
@@ -4664,32 +5082,95 @@ func (m *AuthenticateRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	if len(m.OneTimePassword) > 0 {
-		i -= len(m.OneTimePassword)
-		copy(dAtA[i:], m.OneTimePassword)
-		i = encodeVarintAuth(dAtA, i, uint64(len(m.OneTimePassword)))
+	if m.ReadOnly {
 		i--
-		dAtA[i] = 0x12
-	}
-	if len(m.GitHubToken) > 0 {
-		i -= len(m.GitHubToken)
-		copy(dAtA[i:], m.GitHubToken)
-		i = encodeVarintAuth(dAtA, i, uint64(len(m.GitHubToken)))
+		if m.ReadOnly {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
 		i--
-		dAtA[i] = 0xa
-	}
-	return len(dAtA) - i, nil
-}
-
-func (m *AuthenticateResponse) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalToSizedBuffer(dAtA[:size])
-	if err != nil {
-		return nil, err
+		dAtA[i] = 0x20
 	}
-	return dAtA[:n], nil
-}
+	if len(m.Restrictions) > 0 {
+		for iNdEx := len(m.Restrictions) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Restrictions[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintAuth(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if m.Source != 0 {
+		i = encodeVarintAuth(dAtA, i, uint64(m.Source))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Subject) > 0 {
+		i -= len(m.Subject)
+		copy(dAtA[i:], m.Subject)
+		i = encodeVarintAuth(dAtA, i, uint64(len(m.Subject)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *AuthenticateRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AuthenticateRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *AuthenticateRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.OneTimePassword) > 0 {
+		i -= len(m.OneTimePassword)
+		copy(dAtA[i:], m.OneTimePassword)
+		i = encodeVarintAuth(dAtA, i, uint64(len(m.OneTimePassword)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.GitHubToken) > 0 {
+		i -= len(m.GitHubToken)
+		copy(dAtA[i:], m.GitHubToken)
+		i = encodeVarintAuth(dAtA, i, uint64(len(m.GitHubToken)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *AuthenticateResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
 
 func (m *AuthenticateResponse) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
@@ -4766,6 +5247,30 @@ func (m *WhoAmIResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.ReadOnly {
+		i--
+		if m.ReadOnly {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x28
+	}
+	if len(m.Restrictions) > 0 {
+		for iNdEx := len(m.Restrictions) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Restrictions[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintAuth(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x22
+		}
+	}
 	if m.TTL != 0 {
 		i = encodeVarintAuth(dAtA, i, uint64(m.TTL))
 		i--
@@ -5397,6 +5902,30 @@ func (m *GetAuthTokenRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.ReadOnly {
+		i--
+		if m.ReadOnly {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x20
+	}
+	if len(m.Restrictions) > 0 {
+		for iNdEx := len(m.Restrictions) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Restrictions[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintAuth(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
 	if m.TTL != 0 {
 		i = encodeVarintAuth(dAtA, i, uint64(m.TTL))
 		i--
@@ -5954,116 +6483,317 @@ func (m *GetOneTimePasswordResponse) MarshalToSizedBuffer(dAtA []byte) (int, err
 	return len(dAtA) - i, nil
 }
 
-func encodeVarintAuth(dAtA []byte, offset int, v uint64) int {
-	offset -= sovAuth(v)
-	base := offset
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
-	}
-	dAtA[offset] = uint8(v)
-	return base
-}
-func (m *ActivateRequest) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	l = len(m.GitHubToken)
-	if l > 0 {
-		n += 1 + l + sovAuth(uint64(l))
-	}
-	l = len(m.Subject)
-	if l > 0 {
-		n += 1 + l + sovAuth(uint64(l))
-	}
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+func (m *AuditEvent) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
 	}
-	return n
+	return dAtA[:n], nil
 }
 
-func (m *ActivateResponse) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	l = len(m.PachToken)
-	if l > 0 {
-		n += 1 + l + sovAuth(uint64(l))
-	}
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
-	}
-	return n
+func (m *AuditEvent) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *DeactivateRequest) Size() (n int) {
-	if m == nil {
-		return 0
-	}
+func (m *AuditEvent) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
 	var l int
 	_ = l
 	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
 	}
-	return n
-}
-
-func (m *DeactivateResponse) Size() (n int) {
-	if m == nil {
-		return 0
+	if len(m.Hash) > 0 {
+		i -= len(m.Hash)
+		copy(dAtA[i:], m.Hash)
+		i = encodeVarintAuth(dAtA, i, uint64(len(m.Hash)))
+		i--
+		dAtA[i] = 0x4a
 	}
-	var l int
-	_ = l
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+	if len(m.Error) > 0 {
+		i -= len(m.Error)
+		copy(dAtA[i:], m.Error)
+		i = encodeVarintAuth(dAtA, i, uint64(len(m.Error)))
+		i--
+		dAtA[i] = 0x42
 	}
-	return n
-}
-
-func (m *IDProvider) Size() (n int) {
-	if m == nil {
-		return 0
+	if len(m.Job) > 0 {
+		i -= len(m.Job)
+		copy(dAtA[i:], m.Job)
+		i = encodeVarintAuth(dAtA, i, uint64(len(m.Job)))
+		i--
+		dAtA[i] = 0x3a
 	}
-	var l int
-	_ = l
-	l = len(m.Name)
-	if l > 0 {
-		n += 1 + l + sovAuth(uint64(l))
+	if len(m.Commit) > 0 {
+		i -= len(m.Commit)
+		copy(dAtA[i:], m.Commit)
+		i = encodeVarintAuth(dAtA, i, uint64(len(m.Commit)))
+		i--
+		dAtA[i] = 0x32
 	}
-	l = len(m.Description)
-	if l > 0 {
-		n += 1 + l + sovAuth(uint64(l))
+	if len(m.Pipeline) > 0 {
+		i -= len(m.Pipeline)
+		copy(dAtA[i:], m.Pipeline)
+		i = encodeVarintAuth(dAtA, i, uint64(len(m.Pipeline)))
+		i--
+		dAtA[i] = 0x2a
 	}
-	if m.SAML != nil {
-		l = m.SAML.Size()
-		n += 1 + l + sovAuth(uint64(l))
+	if len(m.Repo) > 0 {
+		i -= len(m.Repo)
+		copy(dAtA[i:], m.Repo)
+		i = encodeVarintAuth(dAtA, i, uint64(len(m.Repo)))
+		i--
+		dAtA[i] = 0x22
 	}
-	if m.GitHub != nil {
-		l = m.GitHub.Size()
-		n += 1 + l + sovAuth(uint64(l))
+	if len(m.Method) > 0 {
+		i -= len(m.Method)
+		copy(dAtA[i:], m.Method)
+		i = encodeVarintAuth(dAtA, i, uint64(len(m.Method)))
+		i--
+		dAtA[i] = 0x1a
 	}
-	if m.XXX_unrecognized != nil {
-		n += len(m.XXX_unrecognized)
+	if len(m.Username) > 0 {
+		i -= len(m.Username)
+		copy(dAtA[i:], m.Username)
+		i = encodeVarintAuth(dAtA, i, uint64(len(m.Username)))
+		i--
+		dAtA[i] = 0x12
 	}
-	return n
+	if m.Time != nil {
+		{
+			size, err := m.Time.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintAuth(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
 }
 
-func (m *IDProvider_SAMLOptions) Size() (n int) {
-	if m == nil {
-		return 0
+func (m *ListAuditEventsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
 	}
+	return dAtA[:n], nil
+}
+
+func (m *ListAuditEventsRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ListAuditEventsRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
 	var l int
 	_ = l
-	l = len(m.MetadataURL)
-	if l > 0 {
-		n += 1 + l + sovAuth(uint64(l))
-	}
-	l = len(m.MetadataXML)
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if m.Until != nil {
+		{
+			size, err := m.Until.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintAuth(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x22
+	}
+	if m.Since != nil {
+		{
+			size, err := m.Since.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintAuth(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.Resource) > 0 {
+		i -= len(m.Resource)
+		copy(dAtA[i:], m.Resource)
+		i = encodeVarintAuth(dAtA, i, uint64(len(m.Resource)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Username) > 0 {
+		i -= len(m.Username)
+		copy(dAtA[i:], m.Username)
+		i = encodeVarintAuth(dAtA, i, uint64(len(m.Username)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ListAuditEventsResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ListAuditEventsResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ListAuditEventsResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		i -= len(m.XXX_unrecognized)
+		copy(dAtA[i:], m.XXX_unrecognized)
+	}
+	if len(m.Events) > 0 {
+		for iNdEx := len(m.Events) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Events[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintAuth(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintAuth(dAtA []byte, offset int, v uint64) int {
+	offset -= sovAuth(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *ActivateRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.GitHubToken)
+	if l > 0 {
+		n += 1 + l + sovAuth(uint64(l))
+	}
+	l = len(m.Subject)
+	if l > 0 {
+		n += 1 + l + sovAuth(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ActivateResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.PachToken)
+	if l > 0 {
+		n += 1 + l + sovAuth(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *DeactivateRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *DeactivateResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *IDProvider) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovAuth(uint64(l))
+	}
+	l = len(m.Description)
+	if l > 0 {
+		n += 1 + l + sovAuth(uint64(l))
+	}
+	if m.SAML != nil {
+		l = m.SAML.Size()
+		n += 1 + l + sovAuth(uint64(l))
+	}
+	if m.GitHub != nil {
+		l = m.GitHub.Size()
+		n += 1 + l + sovAuth(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *IDProvider_SAMLOptions) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.MetadataURL)
+	if l > 0 {
+		n += 1 + l + sovAuth(uint64(l))
+	}
+	l = len(m.MetadataXML)
 	if l > 0 {
 		n += 1 + l + sovAuth(uint64(l))
 	}
@@ -6287,6 +7017,25 @@ func (m *OTPInfo) Size() (n int) {
 	return n
 }
 
+func (m *ScopeRestriction) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Repo)
+	if l > 0 {
+		n += 1 + l + sovAuth(uint64(l))
+	}
+	if m.Scope != 0 {
+		n += 1 + sovAuth(uint64(m.Scope))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
 func (m *TokenInfo) Size() (n int) {
 	if m == nil {
 		return 0
@@ -6300,6 +7049,15 @@ func (m *TokenInfo) Size() (n int) {
 	if m.Source != 0 {
 		n += 1 + sovAuth(uint64(m.Source))
 	}
+	if len(m.Restrictions) > 0 {
+		for _, e := range m.Restrictions {
+			l = e.Size()
+			n += 1 + l + sovAuth(uint64(l))
+		}
+	}
+	if m.ReadOnly {
+		n += 2
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -6370,6 +7128,15 @@ func (m *WhoAmIResponse) Size() (n int) {
 	if m.TTL != 0 {
 		n += 1 + sovAuth(uint64(m.TTL))
 	}
+	if len(m.Restrictions) > 0 {
+		for _, e := range m.Restrictions {
+			l = e.Size()
+			n += 1 + l + sovAuth(uint64(l))
+		}
+	}
+	if m.ReadOnly {
+		n += 2
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -6652,6 +7419,15 @@ func (m *GetAuthTokenRequest) Size() (n int) {
 	if m.TTL != 0 {
 		n += 1 + sovAuth(uint64(m.TTL))
 	}
+	if len(m.Restrictions) > 0 {
+		for _, e := range m.Restrictions {
+			l = e.Size()
+			n += 1 + l + sovAuth(uint64(l))
+		}
+	}
+	if m.ReadOnly {
+		n += 2
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -6918,44 +7694,138 @@ func (m *GetOneTimePasswordResponse) Size() (n int) {
 	return n
 }
 
-func sovAuth(x uint64) (n int) {
-	return (math_bits.Len64(x|1) + 6) / 7
-}
-func sozAuth(x uint64) (n int) {
-	return sovAuth(uint64((x << 1) ^ uint64((int64(x) >> 63))))
-}
-func (m *ActivateRequest) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowAuth
-			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
-			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
-			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: ActivateRequest: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: ActivateRequest: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field GitHubToken", wireType)
+func (m *AuditEvent) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Time != nil {
+		l = m.Time.Size()
+		n += 1 + l + sovAuth(uint64(l))
+	}
+	l = len(m.Username)
+	if l > 0 {
+		n += 1 + l + sovAuth(uint64(l))
+	}
+	l = len(m.Method)
+	if l > 0 {
+		n += 1 + l + sovAuth(uint64(l))
+	}
+	l = len(m.Repo)
+	if l > 0 {
+		n += 1 + l + sovAuth(uint64(l))
+	}
+	l = len(m.Pipeline)
+	if l > 0 {
+		n += 1 + l + sovAuth(uint64(l))
+	}
+	l = len(m.Commit)
+	if l > 0 {
+		n += 1 + l + sovAuth(uint64(l))
+	}
+	l = len(m.Job)
+	if l > 0 {
+		n += 1 + l + sovAuth(uint64(l))
+	}
+	l = len(m.Error)
+	if l > 0 {
+		n += 1 + l + sovAuth(uint64(l))
+	}
+	l = len(m.Hash)
+	if l > 0 {
+		n += 1 + l + sovAuth(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ListAuditEventsRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Username)
+	if l > 0 {
+		n += 1 + l + sovAuth(uint64(l))
+	}
+	l = len(m.Resource)
+	if l > 0 {
+		n += 1 + l + sovAuth(uint64(l))
+	}
+	if m.Since != nil {
+		l = m.Since.Size()
+		n += 1 + l + sovAuth(uint64(l))
+	}
+	if m.Until != nil {
+		l = m.Until.Size()
+		n += 1 + l + sovAuth(uint64(l))
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func (m *ListAuditEventsResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Events) > 0 {
+		for _, e := range m.Events {
+			l = e.Size()
+			n += 1 + l + sovAuth(uint64(l))
+		}
+	}
+	if m.XXX_unrecognized != nil {
+		n += len(m.XXX_unrecognized)
+	}
+	return n
+}
+
+func sovAuth(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozAuth(x uint64) (n int) {
+	return sovAuth(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *ActivateRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAuth
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ActivateRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ActivateRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GitHubToken", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -8699,7 +9569,7 @@ func (m *OTPInfo) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *TokenInfo) Unmarshal(dAtA []byte) error {
+func (m *ScopeRestriction) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -8722,15 +9592,15 @@ func (m *TokenInfo) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: TokenInfo: wiretype end group for non-group")
+			return fmt.Errorf("proto: ScopeRestriction: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: TokenInfo: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: ScopeRestriction: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Subject", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Repo", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -8758,13 +9628,13 @@ func (m *TokenInfo) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Subject = string(dAtA[iNdEx:postIndex])
+			m.Repo = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Source", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Scope", wireType)
 			}
-			m.Source = 0
+			m.Scope = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowAuth
@@ -8774,7 +9644,7 @@ func (m *TokenInfo) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Source |= TokenInfo_TokenSource(b&0x7F) << shift
+				m.Scope |= Scope(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
@@ -8804,7 +9674,7 @@ func (m *TokenInfo) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *AuthenticateRequest) Unmarshal(dAtA []byte) error {
+func (m *TokenInfo) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -8827,15 +9697,15 @@ func (m *AuthenticateRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: AuthenticateRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: TokenInfo: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: AuthenticateRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: TokenInfo: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field GitHubToken", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Subject", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -8863,13 +9733,32 @@ func (m *AuthenticateRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.GitHubToken = string(dAtA[iNdEx:postIndex])
+			m.Subject = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Source", wireType)
+			}
+			m.Source = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Source |= TokenInfo_TokenSource(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field OneTimePassword", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Restrictions", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowAuth
@@ -8879,24 +9768,46 @@ func (m *AuthenticateRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthAuth
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthAuth
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.OneTimePassword = string(dAtA[iNdEx:postIndex])
+			m.Restrictions = append(m.Restrictions, &ScopeRestriction{})
+			if err := m.Restrictions[len(m.Restrictions)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
 			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReadOnly", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.ReadOnly = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipAuth(dAtA[iNdEx:])
@@ -8922,7 +9833,7 @@ func (m *AuthenticateRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *AuthenticateResponse) Unmarshal(dAtA []byte) error {
+func (m *AuthenticateRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -8945,15 +9856,15 @@ func (m *AuthenticateResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: AuthenticateResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: AuthenticateRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: AuthenticateResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: AuthenticateRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field PachToken", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field GitHubToken", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -8981,7 +9892,39 @@ func (m *AuthenticateResponse) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.PachToken = string(dAtA[iNdEx:postIndex])
+			m.GitHubToken = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OneTimePassword", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAuth
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.OneTimePassword = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -9008,7 +9951,7 @@ func (m *AuthenticateResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *WhoAmIRequest) Unmarshal(dAtA []byte) error {
+func (m *AuthenticateResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -9031,31 +9974,117 @@ func (m *WhoAmIRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: WhoAmIRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: AuthenticateResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: WhoAmIRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: AuthenticateResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
-		default:
-			iNdEx = preIndex
-			skippy, err := skipAuth(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if skippy < 0 {
-				return ErrInvalidLengthAuth
-			}
-			if (iNdEx + skippy) < 0 {
-				return ErrInvalidLengthAuth
-			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PachToken", wireType)
 			}
-			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
-			iNdEx += skippy
-		}
-	}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAuth
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.PachToken = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAuth(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *WhoAmIRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAuth
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: WhoAmIRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: WhoAmIRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAuth(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
 
 	if iNdEx > l {
 		return io.ErrUnexpectedEOF
@@ -9162,6 +10191,60 @@ func (m *WhoAmIResponse) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Restrictions", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthAuth
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Restrictions = append(m.Restrictions, &ScopeRestriction{})
+			if err := m.Restrictions[len(m.Restrictions)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReadOnly", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.ReadOnly = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipAuth(dAtA[iNdEx:])
@@ -10870,6 +11953,60 @@ func (m *GetAuthTokenRequest) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Restrictions", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthAuth
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Restrictions = append(m.Restrictions, &ScopeRestriction{})
+			if err := m.Restrictions[len(m.Restrictions)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReadOnly", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.ReadOnly = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipAuth(dAtA[iNdEx:])
@@ -12259,6 +13396,630 @@ func (m *GetOneTimePasswordResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *AuditEvent) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAuth
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: AuditEvent: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: AuditEvent: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Time", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthAuth
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Time == nil {
+				m.Time = &types.Timestamp{}
+			}
+			if err := m.Time.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Username", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAuth
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Username = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Method", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAuth
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Method = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Repo", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAuth
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Repo = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Pipeline", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAuth
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Pipeline = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Commit", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAuth
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Commit = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Job", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAuth
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Job = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAuth
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Error = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Hash", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAuth
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Hash = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAuth(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ListAuditEventsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAuth
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ListAuditEventsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ListAuditEventsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Username", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAuth
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Username = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Resource", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAuth
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Resource = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Since", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthAuth
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Since == nil {
+				m.Since = &types.Timestamp{}
+			}
+			if err := m.Since.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Until", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthAuth
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Until == nil {
+				m.Until = &types.Timestamp{}
+			}
+			if err := m.Until.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAuth(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ListAuditEventsResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAuth
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ListAuditEventsResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ListAuditEventsResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Events", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAuth
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthAuth
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Events = append(m.Events, &AuditEvent{})
+			if err := m.Events[len(m.Events)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAuth(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthAuth
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.XXX_unrecognized = append(m.XXX_unrecognized, dAtA[iNdEx:iNdEx+skippy]...)
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipAuth(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
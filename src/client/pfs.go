@@ -26,6 +26,11 @@ func NewBranch(repoName string, branchName string) *pfs.Branch {
 	}
 }
 
+// NewSnapshot creates a pfs.Snapshot
+func NewSnapshot(name string) *pfs.Snapshot {
+	return &pfs.Snapshot{Name: name}
+}
+
 // NewCommit creates a pfs.Commit.
 func NewCommit(repoName string, commitID string) *pfs.Commit {
 	return &pfs.Commit{
@@ -124,7 +129,13 @@ func (c APIClient) InspectRepo(repoName string) (*pfs.RepoInfo, error) {
 // the specified repos as provenance will be returned unless provenance is nil
 // in which case it is ignored.
 func (c APIClient) ListRepo() ([]*pfs.RepoInfo, error) {
-	request := &pfs.ListRepoRequest{}
+	return c.ListRepoByLabel(nil)
+}
+
+// ListRepoByLabel returns info about the Repos that have all of the given
+// labels.
+func (c APIClient) ListRepoByLabel(labels map[string]string) ([]*pfs.RepoInfo, error) {
+	request := &pfs.ListRepoRequest{Labels: labels}
 	repoInfos, err := c.PfsAPIClient.ListRepo(
 		c.Ctx(),
 		request,
@@ -135,6 +146,20 @@ func (c APIClient) ListRepo() ([]*pfs.RepoInfo, error) {
 	return repoInfos.RepoInfo, nil
 }
 
+// LabelRepo adds labels to a repo, overwriting any existing labels with the
+// same keys, and removes the labels whose keys are in remove.
+func (c APIClient) LabelRepo(repoName string, labels map[string]string, remove []string) error {
+	_, err := c.PfsAPIClient.LabelRepo(
+		c.Ctx(),
+		&pfs.LabelRepoRequest{
+			Repo:   NewRepo(repoName),
+			Labels: labels,
+			Remove: remove,
+		},
+	)
+	return grpcutil.ScrubGRPC(err)
+}
+
 // DeleteRepo deletes a repo and reclaims the storage space it was using. Note
 // that as of 1.0 we do not reclaim the blocks that the Repo was referencing,
 // this is because they may also be referenced by other Repos and deleting them
@@ -301,10 +326,18 @@ func (c APIClient) ListCommit(repoName string, to string, from string, number ui
 // `reverse` lists the commits from oldest to newest, rather than newest to oldest
 // all commits that match the aforementioned criteria are passed to f.
 func (c APIClient) ListCommitF(repoName string, to string, from string, number uint64, reverse bool, f func(*pfs.CommitInfo) error) error {
+	return c.ListCommitByLabelF(repoName, to, from, number, reverse, nil, f)
+}
+
+// ListCommitByLabelF is like ListCommitF, but only passes commits that have
+// all of the given labels to f. Commits without the labels don't count
+// towards `number`.
+func (c APIClient) ListCommitByLabelF(repoName string, to string, from string, number uint64, reverse bool, labels map[string]string, f func(*pfs.CommitInfo) error) error {
 	req := &pfs.ListCommitRequest{
 		Repo:    NewRepo(repoName),
 		Number:  number,
 		Reverse: reverse,
+		Labels:  labels,
 	}
 	if from != "" {
 		req.From = NewCommit(repoName, from)
@@ -338,6 +371,20 @@ func (c APIClient) ListCommitByRepo(repoName string) ([]*pfs.CommitInfo, error)
 	return c.ListCommit(repoName, "", "", 0)
 }
 
+// LabelCommit adds labels to a commit, overwriting any existing labels with
+// the same keys, and removes the labels whose keys are in remove.
+func (c APIClient) LabelCommit(repoName string, commitID string, labels map[string]string, remove []string) error {
+	_, err := c.PfsAPIClient.LabelCommit(
+		c.Ctx(),
+		&pfs.LabelCommitRequest{
+			Commit: NewCommit(repoName, commitID),
+			Labels: labels,
+			Remove: remove,
+		},
+	)
+	return grpcutil.ScrubGRPC(err)
+}
+
 // CreateBranch creates a new branch
 func (c APIClient) CreateBranch(repoName string, branch string, commit string, provenance []*pfs.Branch) error {
 	var head *pfs.Commit
@@ -400,6 +447,72 @@ func (c APIClient) DeleteBranch(repoName string, branch string, force bool) erro
 	return grpcutil.ScrubGRPC(err)
 }
 
+// MergeBranch applies the changes made on srcBranch since its common ancestor
+// with dstBranch to dstBranch, in a new commit. It returns the new commit (nil
+// if nothing was merged) and the paths that were changed differently on both
+// branches, which are resolved according to strategy.
+func (c APIClient) MergeBranch(repoName string, srcBranch string, dstBranch string, strategy pfs.MergeStrategy) (*pfs.Commit, []string, error) {
+	resp, err := c.PfsAPIClient.MergeBranch(
+		c.Ctx(),
+		&pfs.MergeBranchRequest{
+			Src:      NewBranch(repoName, srcBranch),
+			Dst:      NewBranch(repoName, dstBranch),
+			Strategy: strategy,
+		},
+	)
+	if err != nil {
+		return nil, nil, grpcutil.ScrubGRPC(err)
+	}
+	return resp.Commit, resp.Conflicts, nil
+}
+
+// CreateSnapshot atomically records the head of every branch, and the spec
+// commit of every pipeline, in a new snapshot called 'name'.
+func (c APIClient) CreateSnapshot(name string) (*pfs.SnapshotInfo, error) {
+	snapshotInfo, err := c.PfsAPIClient.CreateSnapshot(
+		c.Ctx(),
+		&pfs.CreateSnapshotRequest{
+			Snapshot: NewSnapshot(name),
+		},
+	)
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	return snapshotInfo, nil
+}
+
+// InspectSnapshot returns info about a snapshot.
+func (c APIClient) InspectSnapshot(name string) (*pfs.SnapshotInfo, error) {
+	snapshotInfo, err := c.PfsAPIClient.InspectSnapshot(
+		c.Ctx(),
+		&pfs.InspectSnapshotRequest{
+			Snapshot: NewSnapshot(name),
+		},
+	)
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	return snapshotInfo, nil
+}
+
+// RestoreBranchesFromSnapshot points every branch in a snapshot back at the
+// commit that it pointed to when the snapshot was created. If 'repos' are
+// given, only the branches in those repos are restored.
+func (c APIClient) RestoreBranchesFromSnapshot(name string, repos ...string) error {
+	var pfsRepos []*pfs.Repo
+	for _, repo := range repos {
+		pfsRepos = append(pfsRepos, NewRepo(repo))
+	}
+	_, err := c.PfsAPIClient.RestoreBranchesFromSnapshot(
+		c.Ctx(),
+		&pfs.RestoreBranchesFromSnapshotRequest{
+			Snapshot: NewSnapshot(name),
+			Repos:    pfsRepos,
+		},
+	)
+	return grpcutil.ScrubGRPC(err)
+}
+
 // DeleteCommit deletes a commit.
 func (c APIClient) DeleteCommit(repoName string, commitID string) error {
 	_, err := c.PfsAPIClient.DeleteCommit(
@@ -944,6 +1057,11 @@ type PutFileClient interface {
 	// recursive allows for recursive scraping of some types URLs. For example on s3:// urls.
 	PutFileURL(repoName string, commitID string, path string, url string, recursive bool, overwrite bool) error
 
+	// PutFileURLWithSecret is like PutFileURL, but fetches the URL with the
+	// credentials in the Pachyderm secret 'secret' (if it's set), and fails if
+	// expectedSHA256 is set and doesn't match the content.
+	PutFileURLWithSecret(repoName string, commitID string, path string, url string, recursive bool, overwrite bool, secret string, expectedSHA256 string) error
+
 	// Close must be called after you're done using a PutFileClient.
 	// Further requests will throw errors.
 	Close() error
@@ -1038,6 +1156,13 @@ func (c *putFileClient) PutFileSplit(repoName string, commitID string, path stri
 // The URL is sent to the server which performs the request.
 // recursive allow for recursive scraping of some types URLs for example on s3:// urls.
 func (c *putFileClient) PutFileURL(repoName string, commitID string, path string, url string, recursive bool, overwrite bool) (retErr error) {
+	return c.PutFileURLWithSecret(repoName, commitID, path, url, recursive, overwrite, "", "")
+}
+
+// PutFileURLWithSecret is like PutFileURL, but fetches the URL with the
+// credentials in the Pachyderm secret 'secret' (if it's set), and fails if
+// expectedSHA256 is set and doesn't match the content.
+func (c *putFileClient) PutFileURLWithSecret(repoName string, commitID string, path string, url string, recursive bool, overwrite bool, secret string, expectedSHA256 string) (retErr error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	var overwriteIndex *pfs.OverwriteIndex
@@ -1056,6 +1181,8 @@ func (c *putFileClient) PutFileURL(repoName string, commitID string, path string
 		Url:            url,
 		Recursive:      recursive,
 		OverwriteIndex: overwriteIndex,
+		UrlSecret:      secret,
+		ExpectedSha256: expectedSHA256,
 	}); err != nil {
 		return grpcutil.ScrubGRPC(err)
 	}
@@ -1136,6 +1263,17 @@ func (c APIClient) PutFileURL(repoName string, commitID string, path string, url
 	return pfc.PutFileURL(repoName, commitID, path, url, recursive, overwrite)
 }
 
+// PutFileURLWithSecret is like PutFileURL, but fetches the URL with the
+// credentials in the Pachyderm secret 'secret' (if it's set), and fails if
+// expectedSHA256 is set and doesn't match the content.
+func (c APIClient) PutFileURLWithSecret(repoName string, commitID string, path string, url string, recursive bool, overwrite bool, secret string, expectedSHA256 string) (retErr error) {
+	pfc, err := c.newOneoffPutFileClient()
+	if err != nil {
+		return err
+	}
+	return pfc.PutFileURLWithSecret(repoName, commitID, path, url, recursive, overwrite, secret, expectedSHA256)
+}
+
 // CopyFile copys a file from one pfs location to another. It can be used on
 // directories or regular files.
 func (c APIClient) CopyFile(srcRepo, srcCommit, srcPath, dstRepo, dstCommit, dstPath string, overwrite bool) error {
@@ -1367,6 +1505,58 @@ func (c APIClient) DiffFile(newRepoName, newCommitID, newPath, oldRepoName,
 	return resp.NewFiles, resp.OldFiles, nil
 }
 
+// DiffFileContent is like DiffFile, but returns how the content of each
+// changed file differs (see pfs.FileContentDiff). Files larger than
+// maxContentBytes (or 1MB, if it's 0) aren't diffed.
+func (c APIClient) DiffFileContent(newRepoName, newCommitID, newPath, oldRepoName,
+	oldCommitID, oldPath string, shallow bool, maxContentBytes int64) ([]*pfs.FileContentDiff, error) {
+	var result []*pfs.FileContentDiff
+	if err := c.DiffFileContentF(newRepoName, newCommitID, newPath, oldRepoName,
+		oldCommitID, oldPath, shallow, maxContentBytes, func(diff *pfs.FileContentDiff) error {
+			result = append(result, diff)
+			return nil
+		}); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DiffFileContentF is like DiffFileContent, but calls f with each file's
+// diff as it's received.
+func (c APIClient) DiffFileContentF(newRepoName, newCommitID, newPath, oldRepoName,
+	oldCommitID, oldPath string, shallow bool, maxContentBytes int64, f func(diff *pfs.FileContentDiff) error) error {
+	var oldFile *pfs.File
+	if oldRepoName != "" {
+		oldFile = NewFile(oldRepoName, oldCommitID, oldPath)
+	}
+	diffClient, err := c.PfsAPIClient.DiffFileContent(
+		c.Ctx(),
+		&pfs.DiffFileRequest{
+			NewFile:         NewFile(newRepoName, newCommitID, newPath),
+			OldFile:         oldFile,
+			Shallow:         shallow,
+			MaxContentBytes: maxContentBytes,
+		},
+	)
+	if err != nil {
+		return grpcutil.ScrubGRPC(err)
+	}
+	for {
+		diff, err := diffClient.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return grpcutil.ScrubGRPC(err)
+		}
+		if err := f(diff); err != nil {
+			if err == errutil.ErrBreak {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
 // WalkFn is the type of the function called for each file in Walk.
 // Returning a non-nil error from WalkFn will result in Walk aborting and
 // returning said error.
@@ -1424,7 +1614,19 @@ type putFileWriteCloser struct {
 // prevent the completion of fsck. Errors that do prevent completion will be
 // returned from the function.
 func (c APIClient) Fsck(fix bool, cb func(*pfs.FsckResponse) error) error {
-	fsckClient, err := c.PfsAPIClient.Fsck(c.Ctx(), &pfs.FsckRequest{Fix: fix})
+	return c.fsck(&pfs.FsckRequest{Fix: fix}, cb)
+}
+
+// FsckDeep is like Fsck, but also checks that the trees, objects and blocks
+// referenced by each finished commit exist in object storage. sampleRate is
+// the fraction (from 0 to 1) of objects and blocks whose content is also
+// verified. If fix is true, commits whose data has problems are quarantined.
+func (c APIClient) FsckDeep(fix bool, sampleRate float64, cb func(*pfs.FsckResponse) error) error {
+	return c.fsck(&pfs.FsckRequest{Fix: fix, Deep: true, SampleRate: sampleRate}, cb)
+}
+
+func (c APIClient) fsck(request *pfs.FsckRequest, cb func(*pfs.FsckResponse) error) error {
+	fsckClient, err := c.PfsAPIClient.Fsck(c.Ctx(), request)
 	if err != nil {
 		return grpcutil.ScrubGRPC(err)
 	}
@@ -1469,6 +1671,27 @@ func (c APIClient) FsckFastExit() error {
 	}
 }
 
+// InspectReplication returns how far behind the primary object store each of
+// pachd's object storage replicas is.
+func (c APIClient) InspectReplication() (*pfs.ReplicationInfo, error) {
+	info, err := c.PfsAPIClient.InspectReplication(c.Ctx(), &types.Empty{})
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	return info, nil
+}
+
+// ReconcileReplication copies the objects that are missing from each of
+// pachd's object storage replicas, applies their pending replications, and
+// returns their resulting state.
+func (c APIClient) ReconcileReplication() (*pfs.ReplicationInfo, error) {
+	info, err := c.PfsAPIClient.ReconcileReplication(c.Ctx(), &types.Empty{})
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	return info, nil
+}
+
 func (c *putFileClient) newPutFileWriteCloser(repoName string, commitID string, path string, delimiter pfs.Delimiter, targetFileDatums int64, targetFileBytes int64, headerRecords int64, overwriteIndex *pfs.OverwriteIndex) (*putFileWriteCloser, error) {
 	c.mu.Lock() // Unlocked in Close()
 	return &putFileWriteCloser{
@@ -33,7 +33,20 @@ type Server struct {
 // corresponding private key in 'TLSVolumePath', this will serve GRPC traffic
 // over TLS. If either are missing this will serve GRPC traffic over
 // unencrypted HTTP,
-func NewServer(ctx context.Context, publicPortTLSAllowed bool) (*Server, error) {
+//
+// Any 'interceptors' are run on each call, after the server's tracing
+// interceptors and in the order given.
+func NewServer(ctx context.Context, publicPortTLSAllowed bool, interceptors ...Interceptor) (*Server, error) {
+	unary := []grpc.UnaryServerInterceptor{tracing.UnaryServerInterceptor()}
+	stream := []grpc.StreamServerInterceptor{tracing.StreamServerInterceptor()}
+	for _, i := range interceptors {
+		if i.Unary != nil {
+			unary = append(unary, i.Unary)
+		}
+		if i.Stream != nil {
+			stream = append(stream, i.Stream)
+		}
+	}
 	opts := []grpc.ServerOption{
 		grpc.MaxConcurrentStreams(math.MaxUint32),
 		grpc.MaxRecvMsgSize(MaxMsgSize),
@@ -42,8 +55,8 @@ func NewServer(ctx context.Context, publicPortTLSAllowed bool) (*Server, error)
 			MinTime:             5 * time.Second,
 			PermitWithoutStream: true,
 		}),
-		grpc.UnaryInterceptor(tracing.UnaryServerInterceptor()),
-		grpc.StreamInterceptor(tracing.StreamServerInterceptor()),
+		grpc.UnaryInterceptor(chainUnaryInterceptors(unary)),
+		grpc.StreamInterceptor(chainStreamInterceptors(stream)),
 	}
 
 	if publicPortTLSAllowed {
@@ -76,6 +89,43 @@ func NewServer(ctx context.Context, publicPortTLSAllowed bool) (*Server, error)
 	}, nil
 }
 
+// Interceptor is a pair of grpc interceptors that a Server runs on each unary
+// and streaming call. Either may be nil.
+type Interceptor struct {
+	Unary  grpc.UnaryServerInterceptor
+	Stream grpc.StreamServerInterceptor
+}
+
+// chainUnaryInterceptors combines 'interceptors' into a single interceptor
+// that runs them in order (grpc only accepts one)
+func chainUnaryInterceptors(interceptors []grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		next := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, h := interceptors[i], next
+			next = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, h)
+			}
+		}
+		return next(ctx, req)
+	}
+}
+
+// chainStreamInterceptors is like chainUnaryInterceptors, but for streaming
+// calls
+func chainStreamInterceptors(interceptors []grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		next := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, h := interceptors[i], next
+			next = func(srv interface{}, stream grpc.ServerStream) error {
+				return interceptor(srv, stream, info, h)
+			}
+		}
+		return next(srv, stream)
+	}
+}
+
 // ListenTCP causes the gRPC server to listen on a given TCP host and port
 func (s *Server) ListenTCP(host string, port uint16) (net.Listener, error) {
 	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", host, port))
@@ -44,6 +44,9 @@ const (
 	// PPSWorkerPortEnv is environment variable name for the port that workers
 	// use for their gRPC server
 	PPSWorkerPortEnv = "PPS_WORKER_GRPC_PORT"
+	// PPSEgressSQLDSNEnv is the env var that holds the connection string for
+	// a pipeline's SQL egress, which is read from the egress's secret.
+	PPSEgressSQLDSNEnv = "PPS_EGRESS_SQL_DSN"
 	// PPSWorkerVolume is the name of the volume in which workers store
 	// data.
 	PPSWorkerVolume = "pachyderm-worker"
@@ -173,6 +176,23 @@ func NewCronInputOpts(name string, repo string, spec string, overwrite bool) *pp
 	}
 }
 
+// NewKafkaInput returns an input which consumes a Kafka topic partition. Each
+// message is exposed to jobs as `/pfs/<name>/<offset>`, and messages are
+// committed in windows of at most 'maxMessages' messages or 'window' of time,
+// whichever comes first.
+func NewKafkaInput(name string, brokers []string, topic string, partition int32, maxMessages int64, window time.Duration) *pps.Input {
+	return &pps.Input{
+		Kafka: &pps.KafkaInput{
+			Name:        name,
+			Brokers:     brokers,
+			Topic:       topic,
+			Partition:   partition,
+			MaxMessages: maxMessages,
+			Window:      types.DurationProto(window),
+		},
+	}
+}
+
 // NewJobInput creates a pps.JobInput.
 func NewJobInput(repoName string, commitID string, glob string) *pps.JobInput {
 	return &pps.JobInput{
@@ -574,6 +594,17 @@ func (c APIClient) CreatePipeline(
 	return grpcutil.ScrubGRPC(err)
 }
 
+// DryRunPipeline validates the pipeline described by 'request' and reports the
+// datums it would process over its inputs' current heads, without creating or
+// updating the pipeline.
+func (c APIClient) DryRunPipeline(request *pps.CreatePipelineRequest) (*pps.DryRunPipelineResponse, error) {
+	response, err := c.PpsAPIClient.DryRunPipeline(
+		c.Ctx(),
+		request,
+	)
+	return response, grpcutil.ScrubGRPC(err)
+}
+
 // InspectPipeline returns info about a specific pipeline.
 func (c APIClient) InspectPipeline(pipelineName string) (*pps.PipelineInfo, error) {
 	pipelineInfo, err := c.PpsAPIClient.InspectPipeline(
@@ -659,6 +690,75 @@ func (c APIClient) StopPipeline(name string) error {
 	return grpcutil.ScrubGRPC(err)
 }
 
+// RollbackPipeline updates a pipeline to the spec it had at an earlier
+// version. If reprocess is true, the pipeline reprocesses all datums under the
+// restored spec.
+func (c APIClient) RollbackPipeline(name string, version uint64, reprocess bool) error {
+	_, err := c.PpsAPIClient.RollbackPipeline(
+		c.Ctx(),
+		&pps.RollbackPipelineRequest{
+			Pipeline:  NewPipeline(name),
+			Version:   version,
+			Reprocess: reprocess,
+		},
+	)
+	return grpcutil.ScrubGRPC(err)
+}
+
+// InspectShadow compares the outputs and job stats of a pipeline and its
+// shadow (see CreatePipelineRequest.Shadow) on each set of input commits that
+// the shadow has processed.
+func (c APIClient) InspectShadow(name string) (*pps.ShadowReport, error) {
+	report, err := c.PpsAPIClient.InspectShadow(
+		c.Ctx(),
+		&pps.InspectShadowRequest{
+			Pipeline: NewPipeline(name),
+		},
+	)
+	return report, grpcutil.ScrubGRPC(err)
+}
+
+// PromoteShadow updates a pipeline to the spec of its shadow, and deletes the
+// shadow. If reprocess is true, the pipeline reprocesses all datums under the
+// promoted spec.
+func (c APIClient) PromoteShadow(name string, reprocess bool) error {
+	_, err := c.PpsAPIClient.PromoteShadow(
+		c.Ctx(),
+		&pps.PromoteShadowRequest{
+			Pipeline:  NewPipeline(name),
+			Reprocess: reprocess,
+		},
+	)
+	return grpcutil.ScrubGRPC(err)
+}
+
+// GetUsage rolls up the resources used by jobs that started between 'from'
+// and 'to' (either of which may be zero, for no bound), by pipeline or, if
+// 'label' is set, by the value of that label in each pipeline's metadata. If
+// 'pipeline' is set, only its jobs are counted.
+func (c APIClient) GetUsage(pipeline string, label string, from, to time.Time) ([]*pps.UsageInfo, error) {
+	request := &pps.GetUsageRequest{Label: label}
+	if pipeline != "" {
+		request.Pipeline = NewPipeline(pipeline)
+	}
+	var err error
+	if !from.IsZero() {
+		if request.From, err = types.TimestampProto(from); err != nil {
+			return nil, err
+		}
+	}
+	if !to.IsZero() {
+		if request.To, err = types.TimestampProto(to); err != nil {
+			return nil, err
+		}
+	}
+	usageInfos, err := c.PpsAPIClient.GetUsage(c.Ctx(), request)
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	return usageInfos.UsageInfo, nil
+}
+
 // RunPipeline runs a pipeline. It can be passed a list of commit provenance.
 // This will trigger a new job provenant on those commits, effectively running the pipeline on the data in those commits.
 func (c APIClient) RunPipeline(name string, provenance []*pfs.CommitProvenance, jobID string) error {
@@ -727,6 +827,121 @@ func (c APIClient) ListSecret() ([]*pps.SecretInfo, error) {
 	return secretInfos.SecretInfo, grpcutil.ScrubGRPC(err)
 }
 
+// CreatePipelineTemplate stores a jsonnet pipeline template in pachd, which
+// clients can later retrieve with InspectPipelineTemplate and render into
+// pipeline specs. If update is true, an existing template with the same name
+// is replaced.
+func (c APIClient) CreatePipelineTemplate(name string, jsonnet string, update bool) error {
+	_, err := c.PpsAPIClient.CreatePipelineTemplate(
+		c.Ctx(),
+		&pps.CreatePipelineTemplateRequest{
+			Template: &pps.PipelineTemplate{Name: name},
+			Jsonnet:  jsonnet,
+			Update:   update,
+		},
+	)
+	return grpcutil.ScrubGRPC(err)
+}
+
+// InspectPipelineTemplate returns info about a specific pipeline template.
+func (c APIClient) InspectPipelineTemplate(name string) (*pps.PipelineTemplateInfo, error) {
+	templateInfo, err := c.PpsAPIClient.InspectPipelineTemplate(
+		c.Ctx(),
+		&pps.InspectPipelineTemplateRequest{
+			Template: &pps.PipelineTemplate{Name: name},
+		},
+	)
+	return templateInfo, grpcutil.ScrubGRPC(err)
+}
+
+// ListPipelineTemplate returns info about all pipeline templates.
+func (c APIClient) ListPipelineTemplate() ([]*pps.PipelineTemplateInfo, error) {
+	templateInfos, err := c.PpsAPIClient.ListPipelineTemplate(
+		c.Ctx(),
+		&types.Empty{},
+	)
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	return templateInfos.TemplateInfo, nil
+}
+
+// DeletePipelineTemplate deletes a pipeline template. Pipelines created from
+// the template are unaffected.
+func (c APIClient) DeletePipelineTemplate(name string) error {
+	_, err := c.PpsAPIClient.DeletePipelineTemplate(
+		c.Ctx(),
+		&pps.DeletePipelineTemplateRequest{
+			Template: &pps.PipelineTemplate{Name: name},
+		},
+	)
+	return grpcutil.ScrubGRPC(err)
+}
+
+// CreateNotificationSink configures pachd to send notifications of commit,
+// job and pipeline state changes to a sink (see pps.NotificationSinkInfo). If
+// update is true, an existing sink with the same name is replaced.
+func (c APIClient) CreateNotificationSink(sinkInfo *pps.NotificationSinkInfo, update bool) error {
+	_, err := c.PpsAPIClient.CreateNotificationSink(
+		c.Ctx(),
+		&pps.CreateNotificationSinkRequest{
+			SinkInfo: sinkInfo,
+			Update:   update,
+		},
+	)
+	return grpcutil.ScrubGRPC(err)
+}
+
+// InspectNotificationSink returns info about a specific notification sink.
+func (c APIClient) InspectNotificationSink(name string) (*pps.NotificationSinkInfo, error) {
+	sinkInfo, err := c.PpsAPIClient.InspectNotificationSink(
+		c.Ctx(),
+		&pps.InspectNotificationSinkRequest{
+			Sink: &pps.NotificationSink{Name: name},
+		},
+	)
+	return sinkInfo, grpcutil.ScrubGRPC(err)
+}
+
+// ListNotificationSink returns info about all notification sinks.
+func (c APIClient) ListNotificationSink() ([]*pps.NotificationSinkInfo, error) {
+	sinkInfos, err := c.PpsAPIClient.ListNotificationSink(
+		c.Ctx(),
+		&types.Empty{},
+	)
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	return sinkInfos.SinkInfo, nil
+}
+
+// DeleteNotificationSink deletes a notification sink, along with the events
+// that couldn't be delivered to it.
+func (c APIClient) DeleteNotificationSink(name string) error {
+	_, err := c.PpsAPIClient.DeleteNotificationSink(
+		c.Ctx(),
+		&pps.DeleteNotificationSinkRequest{
+			Sink: &pps.NotificationSink{Name: name},
+		},
+	)
+	return grpcutil.ScrubGRPC(err)
+}
+
+// ListDeadLetter returns the events that couldn't be delivered to a
+// notification sink, oldest first.
+func (c APIClient) ListDeadLetter(name string) ([]*pps.DeadLetter, error) {
+	deadLetters, err := c.PpsAPIClient.ListDeadLetter(
+		c.Ctx(),
+		&pps.ListDeadLetterRequest{
+			Sink: &pps.NotificationSink{Name: name},
+		},
+	)
+	if err != nil {
+		return nil, grpcutil.ScrubGRPC(err)
+	}
+	return deadLetters.DeadLetter, nil
+}
+
 // CreatePipelineService creates a new pipeline service.
 func (c APIClient) CreatePipelineService(
 	name string,
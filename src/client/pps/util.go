@@ -93,6 +93,38 @@ func InputBranches(input *Input) []*pfs.Branch {
 				Name: input.Git.Branch,
 			})
 		}
+		if input.Kafka != nil {
+			result = append(result, &pfs.Branch{
+				Repo: &pfs.Repo{Name: input.Kafka.Repo},
+				Name: "master",
+			})
+		}
+	})
+	return result
+}
+
+// InputCommits returns the commits in an Input. Inputs whose commit isn't set
+// (e.g. in a pipeline's spec, rather than a job's) are skipped.
+func InputCommits(input *Input) []*pfs.Commit {
+	var result []*pfs.Commit
+	VisitInput(input, func(input *Input) {
+		var repo, commit string
+		switch {
+		case input.Pfs != nil:
+			repo, commit = input.Pfs.Repo, input.Pfs.Commit
+		case input.Cron != nil:
+			repo, commit = input.Cron.Repo, input.Cron.Commit
+		case input.Git != nil:
+			repo, commit = input.Git.Name, input.Git.Commit
+		case input.Kafka != nil:
+			repo, commit = input.Kafka.Repo, input.Kafka.Commit
+		}
+		if commit != "" {
+			result = append(result, &pfs.Commit{
+				Repo: &pfs.Repo{Name: repo},
+				ID:   commit,
+			})
+		}
 	})
 	return result
 }
@@ -284,6 +284,9 @@ func (c *pfsBuilderClient) DeleteRepo(ctx context.Context, req *pfs.DeleteRepoRe
 	c.tb.requests = append(c.tb.requests, &transaction.TransactionRequest{DeleteRepo: req})
 	return nil, nil
 }
+func (c *pfsBuilderClient) LabelRepo(ctx context.Context, req *pfs.LabelRepoRequest, opts ...grpc.CallOption) (*types.Empty, error) {
+	return nil, unsupportedError("LabelRepo")
+}
 func (c *pfsBuilderClient) StartCommit(ctx context.Context, req *pfs.StartCommitRequest, opts ...grpc.CallOption) (*pfs.Commit, error) {
 	// Note that since we are batching requests (no extra round-trips), we do not
 	// have the commit id to return here. If you need an operation that relies
@@ -341,12 +344,27 @@ func (c *pfsBuilderClient) SubscribeCommit(ctx context.Context, req *pfs.Subscri
 func (c *pfsBuilderClient) BuildCommit(ctx context.Context, req *pfs.BuildCommitRequest, opts ...grpc.CallOption) (*pfs.Commit, error) {
 	return nil, unsupportedError("BuildCommit")
 }
+func (c *pfsBuilderClient) LabelCommit(ctx context.Context, req *pfs.LabelCommitRequest, opts ...grpc.CallOption) (*types.Empty, error) {
+	return nil, unsupportedError("LabelCommit")
+}
 func (c *pfsBuilderClient) InspectBranch(ctx context.Context, req *pfs.InspectBranchRequest, opts ...grpc.CallOption) (*pfs.BranchInfo, error) {
 	return nil, unsupportedError("InspectBranch")
 }
 func (c *pfsBuilderClient) ListBranch(ctx context.Context, req *pfs.ListBranchRequest, opts ...grpc.CallOption) (*pfs.BranchInfos, error) {
 	return nil, unsupportedError("ListBranch")
 }
+func (c *pfsBuilderClient) MergeBranch(ctx context.Context, req *pfs.MergeBranchRequest, opts ...grpc.CallOption) (*pfs.MergeBranchResponse, error) {
+	return nil, unsupportedError("MergeBranch")
+}
+func (c *pfsBuilderClient) CreateSnapshot(ctx context.Context, req *pfs.CreateSnapshotRequest, opts ...grpc.CallOption) (*pfs.SnapshotInfo, error) {
+	return nil, unsupportedError("CreateSnapshot")
+}
+func (c *pfsBuilderClient) InspectSnapshot(ctx context.Context, req *pfs.InspectSnapshotRequest, opts ...grpc.CallOption) (*pfs.SnapshotInfo, error) {
+	return nil, unsupportedError("InspectSnapshot")
+}
+func (c *pfsBuilderClient) RestoreBranchesFromSnapshot(ctx context.Context, req *pfs.RestoreBranchesFromSnapshotRequest, opts ...grpc.CallOption) (*types.Empty, error) {
+	return nil, unsupportedError("RestoreBranchesFromSnapshot")
+}
 func (c *pfsBuilderClient) PutFile(ctx context.Context, opts ...grpc.CallOption) (pfs.API_PutFileClient, error) {
 	return nil, unsupportedError("PutFile")
 }
@@ -377,6 +395,9 @@ func (c *pfsBuilderClient) GlobFileStream(ctx context.Context, req *pfs.GlobFile
 func (c *pfsBuilderClient) DiffFile(ctx context.Context, req *pfs.DiffFileRequest, opts ...grpc.CallOption) (*pfs.DiffFileResponse, error) {
 	return nil, unsupportedError("DiffFile")
 }
+func (c *pfsBuilderClient) DiffFileContent(ctx context.Context, req *pfs.DiffFileRequest, opts ...grpc.CallOption) (pfs.API_DiffFileContentClient, error) {
+	return nil, unsupportedError("DiffFileContent")
+}
 func (c *pfsBuilderClient) DeleteFile(ctx context.Context, req *pfs.DeleteFileRequest, opts ...grpc.CallOption) (*types.Empty, error) {
 	return nil, unsupportedError("DeleteFile")
 }
@@ -386,6 +407,12 @@ func (c *pfsBuilderClient) DeleteAll(ctx context.Context, req *types.Empty, opts
 func (c *pfsBuilderClient) Fsck(ctx context.Context, req *pfs.FsckRequest, opts ...grpc.CallOption) (pfs.API_FsckClient, error) {
 	return nil, unsupportedError("Fsck")
 }
+func (c *pfsBuilderClient) InspectReplication(ctx context.Context, req *types.Empty, opts ...grpc.CallOption) (*pfs.ReplicationInfo, error) {
+	return nil, unsupportedError("InspectReplication")
+}
+func (c *pfsBuilderClient) ReconcileReplication(ctx context.Context, req *types.Empty, opts ...grpc.CallOption) (*pfs.ReplicationInfo, error) {
+	return nil, unsupportedError("ReconcileReplication")
+}
 func (c *pfsBuilderClient) PutTar(ctx context.Context, opts ...grpc.CallOption) (pfs.API_PutTarClient, error) {
 	return nil, unsupportedError("PutTar")
 }
@@ -499,6 +526,9 @@ func (c *ppsBuilderClient) RestartDatum(ctx context.Context, req *pps.RestartDat
 func (c *ppsBuilderClient) CreatePipeline(ctx context.Context, req *pps.CreatePipelineRequest, opts ...grpc.CallOption) (*types.Empty, error) {
 	return nil, unsupportedError("CreatePipeline")
 }
+func (c *ppsBuilderClient) DryRunPipeline(ctx context.Context, req *pps.CreatePipelineRequest, opts ...grpc.CallOption) (*pps.DryRunPipelineResponse, error) {
+	return nil, unsupportedError("DryRunPipeline")
+}
 func (c *ppsBuilderClient) InspectPipeline(ctx context.Context, req *pps.InspectPipelineRequest, opts ...grpc.CallOption) (*pps.PipelineInfo, error) {
 	return nil, unsupportedError("InspectPipeline")
 }
@@ -514,6 +544,33 @@ func (c *ppsBuilderClient) StartPipeline(ctx context.Context, req *pps.StartPipe
 func (c *ppsBuilderClient) StopPipeline(ctx context.Context, req *pps.StopPipelineRequest, opts ...grpc.CallOption) (*types.Empty, error) {
 	return nil, unsupportedError("StopPipeline")
 }
+func (c *ppsBuilderClient) RollbackPipeline(ctx context.Context, req *pps.RollbackPipelineRequest, opts ...grpc.CallOption) (*types.Empty, error) {
+	return nil, unsupportedError("RollbackPipeline")
+}
+func (c *ppsBuilderClient) InspectShadow(ctx context.Context, req *pps.InspectShadowRequest, opts ...grpc.CallOption) (*pps.ShadowReport, error) {
+	return nil, unsupportedError("InspectShadow")
+}
+func (c *ppsBuilderClient) PromoteShadow(ctx context.Context, req *pps.PromoteShadowRequest, opts ...grpc.CallOption) (*types.Empty, error) {
+	return nil, unsupportedError("PromoteShadow")
+}
+func (c *ppsBuilderClient) GetUsage(ctx context.Context, req *pps.GetUsageRequest, opts ...grpc.CallOption) (*pps.UsageInfos, error) {
+	return nil, unsupportedError("GetUsage")
+}
+func (c *ppsBuilderClient) ListDeadLetter(ctx context.Context, req *pps.ListDeadLetterRequest, opts ...grpc.CallOption) (*pps.DeadLetters, error) {
+	return nil, unsupportedError("ListDeadLetter")
+}
+func (c *ppsBuilderClient) DeleteNotificationSink(ctx context.Context, req *pps.DeleteNotificationSinkRequest, opts ...grpc.CallOption) (*types.Empty, error) {
+	return nil, unsupportedError("DeleteNotificationSink")
+}
+func (c *ppsBuilderClient) ListNotificationSink(ctx context.Context, req *types.Empty, opts ...grpc.CallOption) (*pps.NotificationSinkInfos, error) {
+	return nil, unsupportedError("ListNotificationSink")
+}
+func (c *ppsBuilderClient) InspectNotificationSink(ctx context.Context, req *pps.InspectNotificationSinkRequest, opts ...grpc.CallOption) (*pps.NotificationSinkInfo, error) {
+	return nil, unsupportedError("InspectNotificationSink")
+}
+func (c *ppsBuilderClient) CreateNotificationSink(ctx context.Context, req *pps.CreateNotificationSinkRequest, opts ...grpc.CallOption) (*types.Empty, error) {
+	return nil, unsupportedError("CreateNotificationSink")
+}
 func (c *ppsBuilderClient) RunPipeline(ctx context.Context, req *pps.RunPipelineRequest, opts ...grpc.CallOption) (*types.Empty, error) {
 	return nil, unsupportedError("RunPipeline")
 }
@@ -541,6 +598,18 @@ func (c *ppsBuilderClient) DeleteSecret(ctx context.Context, req *pps.DeleteSecr
 func (c *ppsBuilderClient) InspectSecret(ctx context.Context, req *pps.InspectSecretRequest, opt ...grpc.CallOption) (*pps.SecretInfo, error) {
 	return nil, unsupportedError("InspectSecret")
 }
+func (c *ppsBuilderClient) DeletePipelineTemplate(ctx context.Context, req *pps.DeletePipelineTemplateRequest, opts ...grpc.CallOption) (*types.Empty, error) {
+	return nil, unsupportedError("DeletePipelineTemplate")
+}
+func (c *ppsBuilderClient) ListPipelineTemplate(ctx context.Context, req *types.Empty, opts ...grpc.CallOption) (*pps.PipelineTemplateInfos, error) {
+	return nil, unsupportedError("ListPipelineTemplate")
+}
+func (c *ppsBuilderClient) InspectPipelineTemplate(ctx context.Context, req *pps.InspectPipelineTemplateRequest, opts ...grpc.CallOption) (*pps.PipelineTemplateInfo, error) {
+	return nil, unsupportedError("InspectPipelineTemplate")
+}
+func (c *ppsBuilderClient) CreatePipelineTemplate(ctx context.Context, req *pps.CreatePipelineTemplateRequest, opts ...grpc.CallOption) (*types.Empty, error) {
+	return nil, unsupportedError("CreatePipelineTemplate")
+}
 func (c *ppsBuilderClient) ListSecret(ctx context.Context, in *types.Empty, opt ...grpc.CallOption) (*pps.SecretInfos, error) {
 	return nil, unsupportedError("ListSecret")
 }
@@ -608,6 +677,9 @@ func (c *authBuilderClient) GetUsers(ctx context.Context, req *auth.GetUsersRequ
 func (c *authBuilderClient) GetOneTimePassword(ctx context.Context, req *auth.GetOneTimePasswordRequest, opts ...grpc.CallOption) (*auth.GetOneTimePasswordResponse, error) {
 	return nil, unsupportedError("GetOneTimePassword")
 }
+func (c *authBuilderClient) ListAuditEvents(ctx context.Context, req *auth.ListAuditEventsRequest, opts ...grpc.CallOption) (*auth.ListAuditEventsResponse, error) {
+	return nil, unsupportedError("ListAuditEvents")
+}
 
 func (c *enterpriseBuilderClient) Activate(ctx context.Context, req *enterprise.ActivateRequest, opts ...grpc.CallOption) (*enterprise.ActivateResponse, error) {
 	return nil, unsupportedError("Activate")
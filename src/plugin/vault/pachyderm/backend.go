@@ -23,6 +23,9 @@ func Factory(ctx context.Context, c *logical.BackendConfig) (logical.Backend, er
 		Paths: []*framework.Path{
 			result.configPath(),
 			result.loginPath(),
+			result.rolesListPath(),
+			result.rolesPath(),
+			result.credsPath(),
 			result.versionPath(),
 		},
 		Secrets: []*framework.Secret{{
@@ -80,3 +80,33 @@ func getStringField(data *framework.FieldData, key string) (string, *logical.Res
 	}
 	return value, nil
 }
+
+// putRole writes the role 'name' to the storage backend.
+func putRole(ctx context.Context, s logical.Storage, name string, r *role) error {
+	entry, err := logical.StorageEntryJSON("roles/"+name, r)
+	if err != nil {
+		return errors.Wrapf(err, "failed to generate storage entry")
+	}
+	if err := s.Put(ctx, entry); err != nil {
+		return errors.Wrapf(err, "failed to write role to storage")
+	}
+	return nil
+}
+
+// getRole parses and returns the role 'name' from the storage backend, or nil
+// if no such role exists.
+func getRole(ctx context.Context, s logical.Storage, name string) (*role, error) {
+	entry, err := s.Get(ctx, "roles/"+name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get role from storage")
+	}
+	if entry == nil || len(entry.Value) == 0 {
+		return nil, nil
+	}
+
+	var result role
+	if err := entry.DecodeJSON(&result); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode role")
+	}
+	return &result, nil
+}
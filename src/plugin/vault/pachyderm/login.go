@@ -77,7 +77,7 @@ func (b *backend) pathAuthLogin(ctx context.Context, req *logical.Request, d *fr
 		return nil, err
 	}
 
-	userToken, err := generateUserCredentials(ctx, config.PachdAddress, config.AdminToken, username, ttl)
+	userToken, err := generateUserCredentials(ctx, config.PachdAddress, config.AdminToken, username, ttl, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -102,8 +102,9 @@ func (b *backend) pathAuthLogin(ctx context.Context, req *logical.Request, d *fr
 
 // generateUserCredentials uses the vault plugin's Admin credentials to generate
 // a new Pachyderm authentication token for 'username' (i.e. the user who is
-// currently requesting a Pachyderm token from Vault).
-func generateUserCredentials(ctx context.Context, pachdAddress string, adminToken string, username string, ttl time.Duration) (string, error) {
+// currently requesting a Pachyderm token from Vault). If 'restrictions' is
+// set, the new token may only access the repos listed there.
+func generateUserCredentials(ctx context.Context, pachdAddress string, adminToken string, username string, ttl time.Duration, restrictions []*auth.ScopeRestriction) (string, error) {
 	// Setup a single use client w the given admin token / address
 	client, err := pclient.NewFromAddress(pachdAddress)
 	if err != nil {
@@ -115,8 +116,9 @@ func generateUserCredentials(ctx context.Context, pachdAddress string, adminToke
 	client.SetAuthToken(adminToken)
 
 	resp, err := client.AuthAPIClient.GetAuthToken(client.Ctx(), &auth.GetAuthTokenRequest{
-		Subject: username,
-		TTL:     int64(ttl.Seconds()),
+		Subject:      username,
+		TTL:          int64(ttl.Seconds()),
+		Restrictions: restrictions,
 	})
 	if err != nil {
 		return "", err
@@ -54,6 +54,36 @@ func (b *backend) Renew(ctx context.Context, req *logical.Request, d *framework.
 		}
 	}
 
+	// Creds issued for a role can't be renewed past the role's TTL
+	if roleIface, ok := req.Secret.InternalData["role"]; ok {
+		name, ok := roleIface.(string)
+		if !ok {
+			return nil, errors.Errorf("secret.role has wrong type (expected string but was %T)", roleIface)
+		}
+		r, err := getRole(ctx, req.Storage, name)
+		if err != nil {
+			return nil, err
+		}
+		if r == nil {
+			return nil, errors.Errorf("cannot renew creds for role %q, which no longer exists", name)
+		}
+		ttlStr := r.TTL
+		if ttlStr == "" {
+			ttlStr = config.TTL
+		}
+		maxTTL, _, err = sanitizeTTLStr(ttlStr, b.System().MaxLeaseTTL().String())
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not sanitize role TTL")
+		}
+		remaining := time.Until(req.Secret.IssueTime.Add(maxTTL))
+		if remaining <= 0 {
+			return nil, errors.Errorf("cannot renew creds for role %q past the role's TTL (%v)", name, maxTTL)
+		}
+		if ttl > remaining {
+			ttl = remaining
+		}
+	}
+
 	// Renew creds in Pachyderm
 	err = renewUserCredentials(ctx, config.PachdAddress, config.AdminToken, userToken, ttl)
 	if err != nil {
@@ -0,0 +1,311 @@
+package pachyderm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/vault/logical"
+	"github.com/hashicorp/vault/logical/framework"
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+)
+
+// role maps a vault role to a Pachyderm subject and a set of repo scopes.
+// Tokens read from creds/<role> are restricted to exactly these repos.
+type role struct {
+	// Subject is the Pachyderm subject (e.g. "robot:ci") that tokens issued for
+	// this role authenticate as
+	Subject string `json:"subject"`
+
+	// ReaderRepos and WriterRepos are the repos that tokens issued for this role
+	// may read from and write to, respectively
+	ReaderRepos []string `json:"reader_repos"`
+	WriterRepos []string `json:"writer_repos"`
+
+	// TTL is the lifetime of tokens issued for this role (a duration string).
+	// Tokens can be renewed, but not past this TTL. If unset, the plugin's
+	// configured TTL is used.
+	TTL string `json:"ttl"`
+}
+
+// restrictions converts the role's repos into the restrictions of the tokens
+// issued for it
+func (r *role) restrictions() []*auth.ScopeRestriction {
+	var result []*auth.ScopeRestriction
+	for _, repo := range r.ReaderRepos {
+		result = append(result, &auth.ScopeRestriction{Repo: repo, Scope: auth.Scope_READER})
+	}
+	for _, repo := range r.WriterRepos {
+		result = append(result, &auth.ScopeRestriction{Repo: repo, Scope: auth.Scope_WRITER})
+	}
+	return result
+}
+
+func (b *backend) rolesListPath() *framework.Path {
+	return &framework.Path{
+		Pattern:      "roles/?$",
+		HelpSynopsis: "List the configured roles",
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ListOperation: b.pathRolesList,
+		},
+	}
+}
+
+func (b *backend) rolesPath() *framework.Path {
+	return &framework.Path{
+		Pattern:      "roles/" + framework.GenericNameRegex("name"),
+		HelpSynopsis: "Manage roles that issue Pachyderm tokens with restricted access",
+		HelpDescription: `
+Read or write a role, which maps to a Pachyderm subject and the repos that
+tokens issued for the role may access. For example:
+
+    $ vault write pachyderm/roles/ci \
+        subject="robot:ci" \
+        reader_repos="images,labels" \
+        writer_repos="models" \
+        ttl="1h" # ttl is optional
+
+    $ vault read pachyderm/creds/ci
+
+Tokens issued for a role can't access any repo that the role doesn't list,
+and never carry admin privileges.
+`,
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Name of the role",
+			},
+			"subject": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Pachyderm subject that issued tokens authenticate as, e.g. robot:ci",
+			},
+			"reader_repos": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Repos that issued tokens may read from",
+			},
+			"writer_repos": &framework.FieldSchema{
+				Type:        framework.TypeCommaStringSlice,
+				Description: "Repos that issued tokens may read from and write to",
+			},
+			"ttl": &framework.FieldSchema{
+				Type:        framework.TypeDurationSecond,
+				Description: "TTL for tokens issued for this role",
+			},
+		},
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.UpdateOperation: b.pathRoleWrite,
+			logical.CreateOperation: b.pathRoleWrite,
+			logical.ReadOperation:   b.pathRoleRead,
+			logical.DeleteOperation: b.pathRoleDelete,
+		},
+		ExistenceCheck: b.roleExists,
+	}
+}
+
+func (b *backend) credsPath() *framework.Path {
+	return &framework.Path{
+		Pattern:      "creds/" + framework.GenericNameRegex("name"),
+		HelpSynopsis: "Get a Pachyderm token restricted to a role's repos",
+		Fields: map[string]*framework.FieldSchema{
+			"name": &framework.FieldSchema{
+				Type:        framework.TypeString,
+				Description: "Name of the role",
+			},
+		},
+		Callbacks: map[logical.Operation]framework.OperationFunc{
+			logical.ReadOperation: b.pathCredsRead,
+		},
+	}
+}
+
+func (b *backend) roleExists(ctx context.Context, req *logical.Request, data *framework.FieldData) (bool, error) {
+	name, errResp := getStringField(data, "name")
+	if errResp != nil {
+		return false, nil
+	}
+	r, err := getRole(ctx, req.Storage, name)
+	if err != nil {
+		return false, err
+	}
+	return r != nil, nil
+}
+
+func (b *backend) pathRolesList(ctx context.Context, req *logical.Request, data *framework.FieldData) (resp *logical.Response, retErr error) {
+	b.Logger().Debug(fmt.Sprintf("(%s) %s received at %s", req.ID, req.Operation, req.Path))
+	defer func() {
+		b.Logger().Debug(fmt.Sprintf("(%s) %s finished at %s (success=%t)", req.ID, req.Operation, req.Path, retErr == nil && !resp.IsError()))
+	}()
+
+	names, err := req.Storage.List(ctx, "roles/")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list roles")
+	}
+	return logical.ListResponse(names), nil
+}
+
+func (b *backend) pathRoleRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (resp *logical.Response, retErr error) {
+	b.Logger().Debug(fmt.Sprintf("(%s) %s received at %s", req.ID, req.Operation, req.Path))
+	defer func() {
+		b.Logger().Debug(fmt.Sprintf("(%s) %s finished at %s (success=%t)", req.ID, req.Operation, req.Path, retErr == nil && !resp.IsError()))
+	}()
+
+	name, errResp := getStringField(data, "name")
+	if errResp != nil {
+		return errResp, nil
+	}
+	r, err := getRole(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, nil
+	}
+	return &logical.Response{
+		Data: map[string]interface{}{
+			"subject":      r.Subject,
+			"reader_repos": r.ReaderRepos,
+			"writer_repos": r.WriterRepos,
+			"ttl":          r.TTL,
+		},
+	}, nil
+}
+
+func (b *backend) pathRoleWrite(ctx context.Context, req *logical.Request, data *framework.FieldData) (resp *logical.Response, retErr error) {
+	b.Logger().Debug(fmt.Sprintf("(%s) %s received at %s", req.ID, req.Operation, req.Path))
+	defer func() {
+		b.Logger().Debug(fmt.Sprintf("(%s) %s finished at %s (success=%t)", req.ID, req.Operation, req.Path, retErr == nil && !resp.IsError()))
+	}()
+
+	// Validate we didn't get extraneous fields
+	if err := validateFields(req, data); err != nil {
+		return nil, logical.CodedError(422, err.Error())
+	}
+
+	name, errResp := getStringField(data, "name")
+	if errResp != nil {
+		return errResp, nil
+	}
+	subject, errResp := getStringField(data, "subject")
+	if errResp != nil {
+		return errResp, nil
+	}
+	if subject == "" {
+		return logical.ErrorResponse("invalid subject: empty string"), nil
+	}
+	r := &role{
+		Subject:     subject,
+		ReaderRepos: data.Get("reader_repos").([]string),
+		WriterRepos: data.Get("writer_repos").([]string),
+	}
+	if len(r.ReaderRepos) == 0 && len(r.WriterRepos) == 0 {
+		return logical.ErrorResponse("role must set 'reader_repos', 'writer_repos', or both"), nil
+	}
+	writers := make(map[string]bool)
+	for _, repo := range r.WriterRepos {
+		writers[repo] = true
+	}
+	for _, repo := range r.ReaderRepos {
+		if writers[repo] {
+			return logical.ErrorResponse(fmt.Sprintf("repo %q is in both 'reader_repos' and 'writer_repos'", repo)), nil
+		}
+	}
+	if ttlIface, ok := data.GetOk("ttl"); ok {
+		ttlSeconds, ok := ttlIface.(int)
+		if !ok {
+			return logical.ErrorResponse(fmt.Sprintf("invalid type for param 'ttl' (expected int but got %T)", ttlIface)), nil
+		}
+		if ttlSeconds <= 0 {
+			return logical.ErrorResponse("invalid TTL duration (must be > 0s)"), nil
+		}
+		ttl := time.Duration(ttlSeconds) * time.Second
+		// clamp TTL to vault's max lease
+		if ttl > b.System().MaxLeaseTTL() {
+			ttl = b.System().MaxLeaseTTL()
+		}
+		r.TTL = ttl.String()
+	}
+
+	if err := putRole(ctx, req.Storage, name, r); err != nil {
+		return logical.ErrorResponse(fmt.Sprintf("%v: could not put role", err)), nil
+	}
+	return &logical.Response{}, nil
+}
+
+func (b *backend) pathRoleDelete(ctx context.Context, req *logical.Request, data *framework.FieldData) (resp *logical.Response, retErr error) {
+	b.Logger().Debug(fmt.Sprintf("(%s) %s received at %s", req.ID, req.Operation, req.Path))
+	defer func() {
+		b.Logger().Debug(fmt.Sprintf("(%s) %s finished at %s (success=%t)", req.ID, req.Operation, req.Path, retErr == nil && !resp.IsError()))
+	}()
+
+	name, errResp := getStringField(data, "name")
+	if errResp != nil {
+		return errResp, nil
+	}
+	if err := req.Storage.Delete(ctx, "roles/"+name); err != nil {
+		return nil, errors.Wrapf(err, "failed to delete role")
+	}
+	return nil, nil
+}
+
+func (b *backend) pathCredsRead(ctx context.Context, req *logical.Request, data *framework.FieldData) (resp *logical.Response, retErr error) {
+	b.Logger().Debug(fmt.Sprintf("(%s) %s received at %s", req.ID, req.Operation, req.Path))
+	defer func() {
+		b.Logger().Debug(fmt.Sprintf("(%s) %s finished at %s (success=%t)", req.ID, req.Operation, req.Path, retErr == nil && !resp.IsError()))
+	}()
+
+	name, errResp := getStringField(data, "name")
+	if errResp != nil {
+		return errResp, nil
+	}
+	r, err := getRole(ctx, req.Storage, name)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return logical.ErrorResponse(fmt.Sprintf("unknown role: %s", name)), nil
+	}
+
+	config, err := getConfig(ctx, req.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if len(config.AdminToken) == 0 {
+		return nil, errors.New("plugin is missing admin_token")
+	}
+	if len(config.PachdAddress) == 0 {
+		return nil, errors.New("plugin is missing pachd_address")
+	}
+
+	ttlStr := r.TTL
+	if ttlStr == "" {
+		ttlStr = config.TTL
+	}
+	ttl, _, err := sanitizeTTLStr(ttlStr, b.System().MaxLeaseTTL().String())
+	if err != nil {
+		return nil, err
+	}
+
+	userToken, err := generateUserCredentials(ctx, config.PachdAddress, config.AdminToken, r.Subject, ttl, r.restrictions())
+	if err != nil {
+		return nil, err
+	}
+
+	return &logical.Response{
+		Secret: &logical.Secret{
+			InternalData: map[string]interface{}{
+				"user_token":  userToken,
+				"secret_type": "pachyderm_tokens",
+				"role":        name,
+			},
+			LeaseOptions: logical.LeaseOptions{
+				TTL:       ttl,
+				Renewable: true,
+			},
+		},
+		Data: map[string]interface{}{
+			"user_token":    userToken,
+			"pachd_address": config.PachdAddress,
+		},
+	}, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -213,6 +214,98 @@ func TestLogin(t *testing.T) {
 	}
 }
 
+// TestRoleCreds tests that tokens read from a role's creds path are restricted
+// to the role's repos
+func TestRoleCreds(t *testing.T) {
+	vaultClientConfig := vault.DefaultConfig()
+	vaultClientConfig.Address = vaultAddress
+	v, err := vault.NewClient(vaultClientConfig)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	v.SetToken("root")
+	if err := configurePlugin(t, v, ""); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// Create a repo for the role to read, as the role's subject
+	adminClient, _, _ := loginHelper(t, "")
+	repo := testutil.UniqueString("TestRoleCreds")
+	if err := adminClient.CreateRepo(repo); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	vl := v.Logical()
+	if _, err := vl.Write(fmt.Sprintf("/%v/roles/ci", pluginName), map[string]interface{}{
+		"subject":      "github:bogusgithubusername",
+		"reader_repos": repo,
+		"ttl":          "5m",
+	}); err != nil {
+		t.Fatalf(err.Error())
+	}
+	secret, err := vl.Read(fmt.Sprintf("/%v/creds/ci", pluginName))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	pachToken, ok := secret.Data["user_token"].(string)
+	if !ok {
+		t.Fatalf("vault creds response did not contain user token")
+	}
+	c := testutil.GetPachClient(t)
+	c.SetAuthToken(pachToken)
+
+	// The token can read the role's repo, but not write to it
+	if _, err := c.InspectRepo(repo); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if _, err := c.PutFile(repo, "master", "/file", strings.NewReader("data")); err == nil {
+		t.Fatalf("restricted token could write to a repo it can only read. this is likely a bug")
+	}
+}
+
+// TestRoleCredsRenewPastTTL tests that creds read from a role's creds path can
+// be renewed within the role's TTL, but not past it
+func TestRoleCredsRenewPastTTL(t *testing.T) {
+	vaultClientConfig := vault.DefaultConfig()
+	vaultClientConfig.Address = vaultAddress
+	v, err := vault.NewClient(vaultClientConfig)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	v.SetToken("root")
+	if err := configurePlugin(t, v, ""); err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	vl := v.Logical()
+	if _, err := vl.Write(fmt.Sprintf("/%v/roles/short", pluginName), map[string]interface{}{
+		"subject": "github:bogusgithubusername",
+		"ttl":     "4s",
+	}); err != nil {
+		t.Fatalf(err.Error())
+	}
+	secret, err := vl.Read(fmt.Sprintf("/%v/creds/short", pluginName))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// Renewing within the role's TTL succeeds, but doesn't extend the lease
+	// past it
+	renewed, err := v.Sys().Renew(secret.LeaseID, 60)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if renewed.LeaseDuration > 4 {
+		t.Fatalf("expected lease to be at most 4s, but was: %d", renewed.LeaseDuration)
+	}
+
+	// Renewing after the role's TTL has passed is refused
+	time.Sleep(5 * time.Second)
+	if _, err := v.Sys().Renew(secret.LeaseID, 60); err == nil {
+		t.Fatalf("expected an error renewing role creds past the role's TTL, but got none")
+	}
+}
+
 // TestLoginExpires tests two features:
 // 1. Returned Pachyderm tokens are revoked when their vault lease expires
 // 2. If a TTL is set in the plugin config and not in the login request, then
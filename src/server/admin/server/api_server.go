@@ -231,6 +231,7 @@ func (a *apiServer) Extract(request *admin.ExtractRequest, extractServer admin.A
 					Reason:        ji.Reason,
 					Started:       ji.Started,
 					Finished:      ji.Finished,
+					Metadata:      ji.Metadata,
 				}}})
 			}); err != nil {
 				return err
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"text/tabwriter"
 	"text/template"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
 	"github.com/pachyderm/pachyderm/src/server/pkg/cmdutil"
 
+	"github.com/gogo/protobuf/types"
 	"github.com/spf13/cobra"
 )
 
@@ -248,6 +250,12 @@ func WhoamiCmd() *cobra.Command {
 			if resp.IsAdmin {
 				fmt.Println("You are an administrator of this Pachyderm cluster")
 			}
+			if resp.ReadOnly {
+				fmt.Println("Your token is read-only")
+			}
+			for _, r := range resp.Restrictions {
+				fmt.Printf("Your token is restricted to %s access to \"%s\"\n", r.Scope, r.Repo)
+			}
 			return nil
 		}),
 	}
@@ -438,13 +446,18 @@ func ModifyAdminsCmd() *cobra.Command {
 func GetAuthTokenCmd() *cobra.Command {
 	var quiet bool
 	var ttl string
+	var repos []string
+	var scope string
+	var readOnly bool
 	getAuthToken := &cobra.Command{
 		Use: "{{alias}} [username]",
 		Short: "Get an auth token that authenticates the holder as \"username\", " +
 			"or the currently signed-in user, if no 'username' is provided",
 		Long: "Get an auth token that authenticates the holder as \"username\"; " +
 			"or the currently signed-in user, if no 'username' is provided. Only " +
-			"cluster admins can obtain an auth token on behalf of another user.",
+			"cluster admins can obtain an auth token on behalf of another user. " +
+			"If --repo or --read-only is set, the token only grants access to the " +
+			"given repos, or only grants read access, respectively.",
 		Run: cmdutil.RunBoundedArgs(0, 1, func(args []string) error {
 			c, err := client.NewOnUserMachine("user")
 			if err != nil {
@@ -463,6 +476,19 @@ func GetAuthTokenCmd() *cobra.Command {
 			if len(args) == 1 {
 				req.Subject = args[0]
 			}
+			if len(repos) > 0 {
+				s, err := auth.ParseScope(scope)
+				if err != nil {
+					return err
+				}
+				for _, repo := range repos {
+					req.Restrictions = append(req.Restrictions, &auth.ScopeRestriction{
+						Repo:  repo,
+						Scope: s,
+					})
+				}
+			}
+			req.ReadOnly = readOnly
 			resp, err := c.GetAuthToken(c.Ctx(), req)
 			if err != nil {
 				return grpcutil.ScrubGRPC(err)
@@ -483,6 +509,15 @@ func GetAuthTokenCmd() *cobra.Command {
 		"of the caller's current session, whichever is shorter). This flag should "+
 		"be a golang duration (e.g. \"30s\" or \"1h2m3s\"). If unset, tokens will "+
 		"have a lifetime of 30 days.")
+	getAuthToken.PersistentFlags().StringSliceVar(&repos, "repo", []string{},
+		"if set, the resulting auth token will only be able to access these "+
+			"repos (at the level given by --scope)")
+	getAuthToken.PersistentFlags().StringVar(&scope, "scope", "reader",
+		"the access level (reader, writer, or owner) that the resulting auth "+
+			"token has to the repos given with --repo")
+	getAuthToken.PersistentFlags().BoolVar(&readOnly, "read-only", false,
+		"if set, the resulting auth token will only be able to read data, and "+
+			"can't create repos or pipelines or change ACLs")
 	return cmdutil.CreateAlias(getAuthToken, "auth get-auth-token")
 }
 
@@ -553,6 +588,61 @@ func GetOneTimePasswordCmd() *cobra.Command {
 	return cmdutil.CreateAlias(getOneTimePassword, "auth get-otp")
 }
 
+// ListAuditEventsCmd returns a cobra command that lets a cluster admin list
+// the mutating API calls recorded in the cluster's audit log
+func ListAuditEventsCmd() *cobra.Command {
+	var user, resource, since string
+	listAuditEvents := &cobra.Command{
+		Short: "List the mutating API calls recorded in the audit log",
+		Long: "List the mutating API calls (to PFS, PPS, and auth) recorded in " +
+			"the cluster's audit log, oldest first. If auth is active, only cluster " +
+			"admins may read the audit log.",
+		Run: cmdutil.RunFixedArgs(0, func(args []string) error {
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return errors.Wrapf(err, "could not connect")
+			}
+			defer c.Close()
+			req := &auth.ListAuditEventsRequest{
+				Username: user,
+				Resource: resource,
+			}
+			if since != "" {
+				d, err := time.ParseDuration(since)
+				if err != nil {
+					return errors.Wrapf(err, "could not parse duration %q", since)
+				}
+				req.Since, err = types.TimestampProto(time.Now().Add(-d))
+				if err != nil {
+					return err
+				}
+			}
+			resp, err := c.ListAuditEvents(c.Ctx(), req)
+			if err != nil {
+				return grpcutil.ScrubGRPC(err)
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 1, 2, ' ', 0)
+			fmt.Fprintln(w, "TIME\tUSER\tMETHOD\tREPO\tPIPELINE\tCOMMIT\tJOB\tERROR")
+			for _, e := range resp.Events {
+				t, err := types.TimestampFromProto(e.Time)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", t.Format(time.RFC3339),
+					e.Username, e.Method, e.Repo, e.Pipeline, e.Commit, e.Job, e.Error)
+			}
+			return w.Flush()
+		}),
+	}
+	listAuditEvents.Flags().StringVar(&user, "user", "", "only list calls "+
+		"made by this user")
+	listAuditEvents.Flags().StringVar(&resource, "resource", "", "only list "+
+		"calls that target this repo or pipeline")
+	listAuditEvents.Flags().StringVar(&since, "since", "", "only list calls "+
+		"made within this long (a golang duration, e.g. \"24h\")")
+	return cmdutil.CreateAlias(listAuditEvents, "auth list-audit-events")
+}
+
 // Cmds returns a list of cobra commands for authenticating and authorizing
 // users in an auth-enabled Pachyderm cluster.
 func Cmds() []*cobra.Command {
@@ -579,6 +669,7 @@ func Cmds() []*cobra.Command {
 	commands = append(commands, GetConfigCmd())
 	commands = append(commands, SetConfigCmd())
 	commands = append(commands, GetOneTimePasswordCmd())
+	commands = append(commands, ListAuditEventsCmd())
 
 	return commands
 }
@@ -362,6 +362,31 @@ func TestGetAuthTokenTTL(t *testing.T) {
 	require.Matches(t, "try logging in", errMsg.String())
 }
 
+// TestGetAuthTokenRepoScope tests that the --repo and --scope arguments to
+// 'pachctl get-auth-token' restrict the returned token to the given repos
+func TestGetAuthTokenRepoScope(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+	activateAuth(t)
+	defer deactivateAuth(t)
+	require.NoError(t, tu.BashCmd(`
+		echo "{{.alice}}" | pachctl auth login
+		pachctl create repo {{.repo}}
+		pachctl create repo {{.other}}
+		pachctl auth get-auth-token --repo {{.repo}} --scope reader -q \
+		  | pachctl auth use-auth-token
+		pachctl auth whoami | match 'READER access to "{{.repo}}"'
+		pachctl auth check reader {{.repo}} | match true
+		pachctl auth check writer {{.repo}} | match false
+		pachctl auth check reader {{.other}} | match false
+		`,
+		"alice", tu.UniqueString("alice"),
+		"repo", tu.UniqueString("TestGetAuthTokenRepoScope-repo"),
+		"other", tu.UniqueString("TestGetAuthTokenRepoScope-other"),
+	).Run())
+}
+
 // TestGetOneTimePasswordNoSubject tests that 'pachctl get-otp' infers the
 // subject from the currently logged-in user if none is specified on the command
 // line
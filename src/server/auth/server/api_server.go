@@ -18,6 +18,7 @@ import (
 	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
 	"github.com/pachyderm/pachyderm/src/client/pps"
 	"github.com/pachyderm/pachyderm/src/client/version"
+	"github.com/pachyderm/pachyderm/src/server/pkg/audit"
 	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
 	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
 	"github.com/pachyderm/pachyderm/src/server/pkg/log"
@@ -173,6 +174,10 @@ type apiServer struct {
 	// service should export the SAML ACS and Metadata services, so if public
 	// is true and auth is active, this may export those SAML services
 	public bool
+
+	// auditLog is where mutating API calls are recorded. It may be nil (e.g. in
+	// sidecars), in which case ListAuditEvents returns an error
+	auditLog *audit.Log
 }
 
 // LogReq is like log.Logger.Log(), but it assumes that it's being called from
@@ -204,6 +209,7 @@ func NewAuthServer(
 	txnEnv *txnenv.TransactionEnv,
 	etcdPrefix string,
 	public bool,
+	auditLog *audit.Log,
 ) (APIServer, error) {
 	s := &apiServer{
 		env:        env,
@@ -266,7 +272,8 @@ func NewAuthServer(
 			nil,
 			nil,
 		),
-		public: public,
+		public:   public,
+		auditLog: auditLog,
 	}
 	go s.retrieveOrGeneratePPSToken()
 	go s.watchAdmins(path.Join(etcdPrefix, adminsPrefix))
@@ -587,7 +594,7 @@ func (a *apiServer) Deactivate(ctx context.Context, req *auth.DeactivateRequest)
 	if err != nil {
 		return nil, err
 	}
-	isAdmin, err := a.isAdmin(ctx, callerInfo.Subject)
+	isAdmin, err := a.isCallerAdmin(ctx, callerInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -736,7 +743,7 @@ func (a *apiServer) ModifyAdmins(ctx context.Context, req *auth.ModifyAdminsRequ
 	if err != nil {
 		return nil, err
 	}
-	isAdmin, err := a.isAdmin(ctx, callerInfo.Subject)
+	isAdmin, err := a.isCallerAdmin(ctx, callerInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -972,7 +979,7 @@ func (a *apiServer) GetOneTimePassword(ctx context.Context, req *auth.GetOneTime
 	if err != nil {
 		return nil, err
 	}
-	isAdmin, err := a.isAdmin(ctx, callerInfo.Subject)
+	isAdmin, err := a.isCallerAdmin(ctx, callerInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -989,6 +996,13 @@ func (a *apiServer) GetOneTimePassword(ctx context.Context, req *auth.GetOneTime
 		}
 		return nil, err
 	}
+	if isRestricted(callerInfo) {
+		// The OTP would be exchanged for an unrestricted token
+		return nil, &auth.ErrNotAuthorized{
+			Subject: callerInfo.Subject,
+			AdminOp: "GetOneTimePassword with a restricted token",
+		}
+	}
 
 	// compute the TTL for the OTP itself (default: 5m). This cannot be longer
 	// than the TTL for the token that the user will get once the OTP is exchanged
@@ -1109,7 +1123,7 @@ func (a *apiServer) AuthorizeInTransaction(
 	if err != nil {
 		return nil, err
 	}
-	isAdmin, err := a.isAdmin(txnCtx.ClientContext, callerInfo.Subject)
+	isAdmin, err := a.isCallerAdmin(txnCtx.ClientContext, callerInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -1146,7 +1160,7 @@ func (a *apiServer) AuthorizeInTransaction(
 		return nil, errors.Wrapf(err, "error getting ACL for repo \"%s\"", req.Repo)
 	}
 
-	scope, err := a.getScope(txnCtx.ClientContext, callerInfo.Subject, &acl)
+	scope, err := a.getCallerScope(txnCtx.ClientContext, callerInfo, req.Repo, &acl)
 	if err != nil {
 		return nil, err
 	}
@@ -1186,7 +1200,7 @@ func (a *apiServer) WhoAmI(ctx context.Context, req *auth.WhoAmIRequest) (resp *
 	if err != nil {
 		return nil, err
 	}
-	isAdmin, err := a.isAdmin(ctx, callerInfo.Subject)
+	isAdmin, err := a.isCallerAdmin(ctx, callerInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -1206,9 +1220,11 @@ func (a *apiServer) WhoAmI(ctx context.Context, req *auth.WhoAmIRequest) (resp *
 
 	// return final result
 	return &auth.WhoAmIResponse{
-		Username: callerInfo.Subject,
-		IsAdmin:  isAdmin,
-		TTL:      ttl,
+		Username:     callerInfo.Subject,
+		IsAdmin:      isAdmin,
+		TTL:          ttl,
+		Restrictions: callerInfo.Restrictions,
+		ReadOnly:     callerInfo.ReadOnly,
 	}, nil
 }
 
@@ -1245,6 +1261,52 @@ func (a *apiServer) isAdmin(ctx context.Context, subject string) (bool, error) {
 	return false, nil
 }
 
+// isRestricted returns true if 'tokenInfo' has restrictions or is read-only,
+// i.e. if it grants less access than its subject has
+func isRestricted(tokenInfo *auth.TokenInfo) bool {
+	return len(tokenInfo.Restrictions) > 0 || tokenInfo.ReadOnly
+}
+
+// isCallerAdmin is like isAdmin, but takes the caller's whole TokenInfo rather
+// than just its subject. Restricted tokens never carry admin privileges (see
+// auth.TokenInfo), even if their subject is an admin.
+func (a *apiServer) isCallerAdmin(ctx context.Context, callerInfo *auth.TokenInfo) (bool, error) {
+	if isRestricted(callerInfo) {
+		return false, nil
+	}
+	return a.isAdmin(ctx, callerInfo.Subject)
+}
+
+// restrictScope caps 'scope' (the access that the subject of 'callerInfo' has
+// to 'repo') by the restrictions on the caller's token, if there are any
+func restrictScope(callerInfo *auth.TokenInfo, repo string, scope auth.Scope) auth.Scope {
+	if callerInfo.ReadOnly && scope > auth.Scope_READER {
+		scope = auth.Scope_READER
+	}
+	if len(callerInfo.Restrictions) == 0 {
+		return scope
+	}
+	for _, r := range callerInfo.Restrictions {
+		if r.Repo == repo {
+			if r.Scope < scope {
+				return r.Scope
+			}
+			return scope
+		}
+	}
+	return auth.Scope_NONE // the token doesn't grant any access to 'repo'
+}
+
+// getCallerScope is like getScope, but returns the access that the caller's
+// token grants, which may be less than its subject has (see restrictScope)
+func (a *apiServer) getCallerScope(ctx context.Context, callerInfo *auth.TokenInfo, repo string, acl *auth.ACL) (auth.Scope, error) {
+	scope, err := a.getScope(ctx, callerInfo.Subject, acl)
+	if err != nil {
+		return auth.Scope_NONE, err
+	}
+	return restrictScope(callerInfo, repo, scope), nil
+}
+
 // SetScopeInTransaction is identical to SetScope except that it can run inside
 // an existing etcd STM transaction.  This is not an RPC.
 func (a *apiServer) SetScopeInTransaction(
@@ -1263,7 +1325,7 @@ func (a *apiServer) SetScopeInTransaction(
 	if err != nil {
 		return nil, err
 	}
-	isAdmin, err := a.isAdmin(txnCtx.ClientContext, callerInfo.Subject)
+	isAdmin, err := a.isCallerAdmin(txnCtx.ClientContext, callerInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -1305,7 +1367,7 @@ func (a *apiServer) SetScopeInTransaction(
 		}
 
 		// Check if the user or one of their groups is on the ACL directly
-		scope, err := a.getScope(txnCtx.ClientContext, callerInfo.Subject, &acl)
+		scope, err := a.getCallerScope(txnCtx.ClientContext, callerInfo, req.Repo, &acl)
 		if err != nil {
 			return false, err
 		}
@@ -1397,7 +1459,7 @@ func (a *apiServer) GetScopeInTransaction(
 	if err != nil {
 		return nil, err
 	}
-	callerIsAdmin, err := a.isAdmin(txnCtx.ClientContext, callerInfo.Subject)
+	callerIsAdmin, err := a.isCallerAdmin(txnCtx.ClientContext, callerInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -1446,7 +1508,7 @@ func (a *apiServer) GetScopeInTransaction(
 		if mustHaveReadAccess && !callerIsAdmin {
 			// Caller is getting another user's scopes. Check if the caller is
 			// authorized to view this repo's ACL
-			callerScope, err := a.getScope(txnCtx.ClientContext, callerInfo.Subject, &acl)
+			callerScope, err := a.getCallerScope(txnCtx.ClientContext, callerInfo, repo, &acl)
 			if err != nil {
 				return nil, err
 			}
@@ -1464,6 +1526,11 @@ func (a *apiServer) GetScopeInTransaction(
 		if err != nil {
 			return nil, err
 		}
+		if req.Username == "" {
+			// The caller is getting their own scopes, which are limited by their
+			// token's restrictions
+			targetScope = restrictScope(callerInfo, repo, targetScope)
+		}
 		response.Scopes = append(response.Scopes, targetScope)
 	}
 
@@ -1565,7 +1632,7 @@ func (a *apiServer) SetACLInTransaction(
 	if err != nil {
 		return nil, err
 	}
-	isAdmin, err := a.isAdmin(txnCtx.ClientContext, callerInfo.Subject)
+	isAdmin, err := a.isCallerAdmin(txnCtx.ClientContext, callerInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -1627,7 +1694,7 @@ func (a *apiServer) SetACLInTransaction(
 		}
 		if len(acl.Entries) > 0 {
 			// ACL is present; caller must be authorized directly
-			scope, err := a.getScope(txnCtx.ClientContext, callerInfo.Subject, &acl)
+			scope, err := a.getCallerScope(txnCtx.ClientContext, callerInfo, req.Repo, &acl)
 			if err != nil {
 				return false, err
 			}
@@ -1646,10 +1713,12 @@ func (a *apiServer) SetACLInTransaction(
 			// Unclear if repo exists -- return error
 			return false, errors.Wrapf(err, "could not inspect \"%s\"", req.Repo)
 		} else if len(newACL.Entries) == 1 &&
-			newACL.Entries[callerInfo.Subject] == auth.Scope_OWNER {
+			newACL.Entries[callerInfo.Subject] == auth.Scope_OWNER &&
+			restrictScope(callerInfo, req.Repo, auth.Scope_OWNER) == auth.Scope_OWNER {
 			// Special case: Repo doesn't exist, but user is creating a new Repo, and
 			// making themself the owner, e.g. for CreateRepo or CreatePipeline, then
-			// the request is authorized
+			// the request is authorized (unless the caller's token is read-only or
+			// doesn't cover the new repo)
 			return true, nil
 		}
 		return false, err
@@ -1732,6 +1801,38 @@ func (a *apiServer) authorizeNewToken(ctx context.Context, callerInfo *auth.Toke
 	return targetSubject, nil
 }
 
+// newTokenRestrictions validates the restrictions requested for a new token
+// ('requested') and returns the restrictions that the new token should have.
+// Callers whose own token is restricted can only get tokens that are at least
+// as restricted as theirs, so if 'requested' is empty, such callers' new
+// tokens inherit the caller's restrictions.
+func newTokenRestrictions(callerInfo *auth.TokenInfo, requested []*auth.ScopeRestriction) ([]*auth.ScopeRestriction, error) {
+	seen := make(map[string]bool)
+	for _, r := range requested {
+		if r.Repo == "" {
+			return nil, errors.Errorf("invalid restriction: must set repo")
+		}
+		if r.Scope == auth.Scope_NONE {
+			return nil, errors.Errorf("invalid restriction on repo \"%s\": scope must be READER, WRITER, or OWNER", r.Repo)
+		}
+		if seen[r.Repo] {
+			return nil, errors.Errorf("invalid restrictions: repo \"%s\" is restricted more than once", r.Repo)
+		}
+		seen[r.Repo] = true
+		if restrictScope(callerInfo, r.Repo, r.Scope) < r.Scope {
+			return nil, &auth.ErrNotAuthorized{
+				Subject:  callerInfo.Subject,
+				Repo:     r.Repo,
+				Required: r.Scope,
+			}
+		}
+	}
+	if len(requested) == 0 {
+		return callerInfo.Restrictions, nil
+	}
+	return requested, nil
+}
+
 // GetAuthToken implements the protobuf auth.GetAuthToken RPC
 func (a *apiServer) GetAuthToken(ctx context.Context, req *auth.GetAuthTokenRequest) (resp *auth.GetAuthTokenResponse, retErr error) {
 	a.LogReq(req)
@@ -1754,7 +1855,7 @@ func (a *apiServer) GetAuthToken(ctx context.Context, req *auth.GetAuthTokenRequ
 	if err != nil {
 		return nil, err
 	}
-	isAdmin, err := a.isAdmin(ctx, callerInfo.Subject)
+	isAdmin, err := a.isCallerAdmin(ctx, callerInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -1771,6 +1872,10 @@ func (a *apiServer) GetAuthToken(ctx context.Context, req *auth.GetAuthTokenRequ
 		}
 		return nil, err
 	}
+	restrictions, err := newTokenRestrictions(callerInfo, req.Restrictions)
+	if err != nil {
+		return nil, err
+	}
 
 	// Compute TTL for new token that the user will get once OTP is exchanged
 	// Note: For Pachyderm <1.10, admin tokens always come with an indefinite
@@ -1803,8 +1908,10 @@ func (a *apiServer) GetAuthToken(ctx context.Context, req *auth.GetAuthTokenRequ
 		req.TTL = defaultSessionTTLSecs
 	}
 	tokenInfo := auth.TokenInfo{
-		Source:  auth.TokenInfo_GET_TOKEN,
-		Subject: req.Subject,
+		Source:       auth.TokenInfo_GET_TOKEN,
+		Subject:      req.Subject,
+		Restrictions: restrictions,
+		ReadOnly:     req.ReadOnly || callerInfo.ReadOnly,
 	}
 
 	// generate new token, and write to etcd
@@ -1839,7 +1946,7 @@ func (a *apiServer) ExtendAuthToken(ctx context.Context, req *auth.ExtendAuthTok
 	if err != nil {
 		return nil, err
 	}
-	isAdmin, err := a.isAdmin(ctx, callerInfo.Subject)
+	isAdmin, err := a.isCallerAdmin(ctx, callerInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -1905,7 +2012,7 @@ func (a *apiServer) RevokeAuthToken(ctx context.Context, req *auth.RevokeAuthTok
 	if err != nil {
 		return nil, err
 	}
-	isAdmin, err := a.isAdmin(ctx, callerInfo.Subject)
+	isAdmin, err := a.isCallerAdmin(ctx, callerInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -1998,7 +2105,7 @@ func (a *apiServer) SetGroupsForUser(ctx context.Context, req *auth.SetGroupsFor
 	if err != nil {
 		return nil, err
 	}
-	isAdmin, err := a.isAdmin(ctx, callerInfo.Subject)
+	isAdmin, err := a.isCallerAdmin(ctx, callerInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -2033,7 +2140,7 @@ func (a *apiServer) ModifyMembers(ctx context.Context, req *auth.ModifyMembersRe
 	if err != nil {
 		return nil, err
 	}
-	isAdmin, err := a.isAdmin(ctx, callerInfo.Subject)
+	isAdmin, err := a.isCallerAdmin(ctx, callerInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -2148,7 +2255,7 @@ func (a *apiServer) GetGroups(ctx context.Context, req *auth.GetGroupsRequest) (
 	// infinite recursion
 	var target string
 	if req.Username != "" && req.Username != callerInfo.Subject {
-		isAdmin, err := a.isAdmin(ctx, callerInfo.Subject)
+		isAdmin, err := a.isCallerAdmin(ctx, callerInfo)
 		if err != nil {
 			return nil, err
 		}
@@ -2185,7 +2292,7 @@ func (a *apiServer) GetUsers(ctx context.Context, req *auth.GetUsersRequest) (re
 	if err != nil {
 		return nil, err
 	}
-	isAdmin, err := a.isAdmin(ctx, callerInfo.Subject)
+	isAdmin, err := a.isCallerAdmin(ctx, callerInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -2444,7 +2551,7 @@ func (a *apiServer) SetConfiguration(ctx context.Context, req *auth.SetConfigura
 	if err != nil {
 		return nil, err
 	}
-	isAdmin, err := a.isAdmin(ctx, callerInfo.Subject)
+	isAdmin, err := a.isCallerAdmin(ctx, callerInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -2497,3 +2604,66 @@ func (a *apiServer) SetConfiguration(ctx context.Context, req *auth.SetConfigura
 	}
 	return &auth.SetConfigurationResponse{}, nil
 }
+
+// ListAuditEvents implements the protobuf auth.ListAuditEvents RPC
+func (a *apiServer) ListAuditEvents(ctx context.Context, req *auth.ListAuditEventsRequest) (resp *auth.ListAuditEventsResponse, retErr error) {
+	a.LogReq(req)
+	defer func(start time.Time) { a.LogResp(req, resp, retErr, time.Since(start)) }(time.Now())
+	if a.auditLog == nil {
+		return nil, errors.Errorf("this pachd does not record audit events")
+	}
+
+	// Only admins can read the audit log. If auth isn't active, anyone can
+	// (as with every other API), so that calls made before auth was activated
+	// can be audited.
+	if a.activationState() == full {
+		callerInfo, err := a.getAuthenticatedUser(ctx)
+		if err != nil {
+			return nil, err
+		}
+		isAdmin, err := a.isCallerAdmin(ctx, callerInfo)
+		if err != nil {
+			return nil, err
+		}
+		if !isAdmin {
+			return nil, &auth.ErrNotAuthorized{
+				Subject: callerInfo.Subject,
+				AdminOp: "ListAuditEvents",
+			}
+		}
+	}
+
+	var err error
+	var since, until time.Time
+	if req.Since != nil {
+		if since, err = types.TimestampFromProto(req.Since); err != nil {
+			return nil, errors.Wrapf(err, "invalid ListAuditEventsRequest.Since")
+		}
+	}
+	if req.Until != nil {
+		if until, err = types.TimestampFromProto(req.Until); err != nil {
+			return nil, errors.Wrapf(err, "invalid ListAuditEventsRequest.Until")
+		}
+	}
+	resp = &auth.ListAuditEventsResponse{}
+	if err := a.auditLog.List(func(e *auth.AuditEvent) error {
+		if req.Username != "" && e.Username != req.Username {
+			return nil
+		}
+		if req.Resource != "" && e.Repo != req.Resource && e.Pipeline != req.Resource {
+			return nil
+		}
+		t, err := types.TimestampFromProto(e.Time)
+		if err != nil {
+			return err
+		}
+		if (!since.IsZero() && t.Before(since)) || (!until.IsZero() && !t.Before(until)) {
+			return nil
+		}
+		resp.Events = append(resp.Events, e)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
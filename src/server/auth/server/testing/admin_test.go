@@ -1390,6 +1390,206 @@ func TestGetAuthTokenErrorNonAdminUser(t *testing.T) {
 	require.Matches(t, "must be an admin", err.Error())
 }
 
+// TestGetAuthTokenRestrictions tests that tokens with restrictions can only
+// access the repos listed in their restrictions, at the listed scopes, and
+// that they don't carry their subject's admin privileges
+func TestGetAuthTokenRestrictions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+	deleteAll(t)
+	defer deleteAll(t)
+	adminClient := getPachClient(t, admin)
+
+	readRepo, writeRepo, otherRepo := tu.UniqueString("read"), tu.UniqueString("write"), tu.UniqueString("other")
+	for _, repo := range []string{readRepo, writeRepo, otherRepo} {
+		require.NoError(t, adminClient.CreateRepo(repo))
+		_, err := adminClient.PutFile(repo, "master", "/file", strings.NewReader("test data"))
+		require.NoError(t, err)
+	}
+
+	// Get a restricted token for the admin
+	resp, err := adminClient.GetAuthToken(adminClient.Ctx(), &auth.GetAuthTokenRequest{
+		Restrictions: []*auth.ScopeRestriction{
+			{Repo: readRepo, Scope: auth.Scope_READER},
+			{Repo: writeRepo, Scope: auth.Scope_WRITER},
+		},
+	})
+	require.NoError(t, err)
+	restrictedClient := getPachClient(t, "")
+	restrictedClient.SetAuthToken(resp.Token)
+
+	// The restricted token isn't an admin token
+	who, err := restrictedClient.WhoAmI(restrictedClient.Ctx(), &auth.WhoAmIRequest{})
+	require.NoError(t, err)
+	require.Equal(t, admin, who.Username)
+	require.False(t, who.IsAdmin)
+
+	// The restricted token can read 'readRepo' but not write to it
+	var buf bytes.Buffer
+	require.NoError(t, restrictedClient.GetFile(readRepo, "master", "/file", 0, 0, &buf))
+	require.Equal(t, "test data", buf.String())
+	_, err = restrictedClient.PutFile(readRepo, "master", "/file2", strings.NewReader("test data"))
+	require.YesError(t, err)
+	require.True(t, auth.IsErrNotAuthorized(err), err.Error())
+
+	// The restricted token can write to 'writeRepo'
+	_, err = restrictedClient.PutFile(writeRepo, "master", "/file2", strings.NewReader("test data"))
+	require.NoError(t, err)
+
+	// The restricted token can't access 'otherRepo' at all
+	buf.Reset()
+	err = restrictedClient.GetFile(otherRepo, "master", "/file", 0, 0, &buf)
+	require.YesError(t, err)
+	require.True(t, auth.IsErrNotAuthorized(err), err.Error())
+
+	// The restricted token can't get broader tokens, or tokens for other users
+	_, err = restrictedClient.GetAuthToken(restrictedClient.Ctx(), &auth.GetAuthTokenRequest{
+		Restrictions: []*auth.ScopeRestriction{
+			{Repo: readRepo, Scope: auth.Scope_WRITER},
+		},
+	})
+	require.YesError(t, err)
+	_, err = restrictedClient.GetAuthToken(restrictedClient.Ctx(), &auth.GetAuthTokenRequest{
+		Subject: robot(tu.UniqueString("t-1000")),
+	})
+	require.YesError(t, err)
+
+	// ...but it can get tokens that are at least as restricted as itself
+	resp, err = restrictedClient.GetAuthToken(restrictedClient.Ctx(), &auth.GetAuthTokenRequest{
+		Restrictions: []*auth.ScopeRestriction{
+			{Repo: writeRepo, Scope: auth.Scope_READER},
+		},
+	})
+	require.NoError(t, err)
+	narrowClient := getPachClient(t, "")
+	narrowClient.SetAuthToken(resp.Token)
+	buf.Reset()
+	require.NoError(t, narrowClient.GetFile(writeRepo, "master", "/file", 0, 0, &buf))
+	buf.Reset()
+	require.YesError(t, narrowClient.GetFile(readRepo, "master", "/file", 0, 0, &buf))
+}
+
+// TestGetAuthTokenReadOnly tests that read-only tokens only grant READER
+// access, and that GetScope reports the scopes that a restricted token grants
+func TestGetAuthTokenReadOnly(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+	deleteAll(t)
+	defer deleteAll(t)
+	alice := tu.UniqueString("alice")
+	aliceClient := getPachClient(t, alice)
+
+	repo := tu.UniqueString("TestGetAuthTokenReadOnly")
+	require.NoError(t, aliceClient.CreateRepo(repo))
+	_, err := aliceClient.PutFile(repo, "master", "/file", strings.NewReader("test data"))
+	require.NoError(t, err)
+
+	resp, err := aliceClient.GetAuthToken(aliceClient.Ctx(), &auth.GetAuthTokenRequest{
+		ReadOnly: true,
+	})
+	require.NoError(t, err)
+	readOnlyClient := getPachClient(t, "")
+	readOnlyClient.SetAuthToken(resp.Token)
+
+	who, err := readOnlyClient.WhoAmI(readOnlyClient.Ctx(), &auth.WhoAmIRequest{})
+	require.NoError(t, err)
+	require.True(t, who.ReadOnly)
+
+	// alice owns 'repo', but her read-only token only grants READER access
+	scopeResp, err := readOnlyClient.GetScope(readOnlyClient.Ctx(), &auth.GetScopeRequest{
+		Repos: []string{repo},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []auth.Scope{auth.Scope_READER}, scopeResp.Scopes)
+	var buf bytes.Buffer
+	require.NoError(t, readOnlyClient.GetFile(repo, "master", "/file", 0, 0, &buf))
+	_, err = readOnlyClient.PutFile(repo, "master", "/file2", strings.NewReader("test data"))
+	require.YesError(t, err)
+	require.True(t, auth.IsErrNotAuthorized(err), err.Error())
+
+	// Read-only tokens can't create anything, even where their subject could
+	err = readOnlyClient.CreateRepo(tu.UniqueString("TestGetAuthTokenReadOnly"))
+	require.YesError(t, err)
+	require.True(t, auth.IsErrNotAuthorized(err), err.Error())
+	err = readOnlyClient.CreatePipeline(
+		tu.UniqueString("pipeline"),
+		"", // default image: ubuntu:14.04
+		[]string{"bash"},
+		[]string{fmt.Sprintf("cp /pfs/%s/* /pfs/out/", repo)},
+		&pps.ParallelismSpec{Constant: 1},
+		client.NewPFSInput(repo, "/*"),
+		"", // default output branch: master
+		false,
+	)
+	require.YesError(t, err)
+	require.True(t, auth.IsErrNotAuthorized(err), err.Error())
+
+	// Read-only tokens can't change the ACL of a repo, even if their subject
+	// owns it
+	_, err = readOnlyClient.SetScope(readOnlyClient.Ctx(), &auth.SetScopeRequest{
+		Repo:     repo,
+		Username: tu.UniqueString("bob"),
+		Scope:    auth.Scope_READER,
+	})
+	require.YesError(t, err)
+	require.True(t, auth.IsErrNotAuthorized(err), err.Error())
+
+	// Tokens obtained with a read-only token are read-only as well
+	resp, err = readOnlyClient.GetAuthToken(readOnlyClient.Ctx(), &auth.GetAuthTokenRequest{})
+	require.NoError(t, err)
+	readOnlyClient.SetAuthToken(resp.Token)
+	who, err = readOnlyClient.WhoAmI(readOnlyClient.Ctx(), &auth.WhoAmIRequest{})
+	require.NoError(t, err)
+	require.True(t, who.ReadOnly)
+}
+
+// TestListAuditEvents tests that mutating calls are recorded in the audit log,
+// and that only admins can read it
+func TestListAuditEvents(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+	deleteAll(t)
+	defer deleteAll(t)
+	alice := tu.UniqueString("alice")
+	aliceClient, adminClient := getPachClient(t, alice), getPachClient(t, admin)
+
+	repo := tu.UniqueString("TestListAuditEvents")
+	require.NoError(t, aliceClient.CreateRepo(repo))
+	_, err := aliceClient.PutFile(repo, "master", "/file", strings.NewReader("test data"))
+	require.NoError(t, err)
+	require.NoError(t, aliceClient.DeleteCommit(repo, "master"))
+
+	// alice isn't an admin, so she can't read the audit log
+	_, err = aliceClient.ListAuditEvents(aliceClient.Ctx(), &auth.ListAuditEventsRequest{})
+	require.YesError(t, err)
+	require.Matches(t, "must be an admin", err.Error())
+
+	resp, err := adminClient.ListAuditEvents(adminClient.Ctx(), &auth.ListAuditEventsRequest{
+		Resource: repo,
+	})
+	require.NoError(t, err)
+	var methods []string
+	for _, e := range resp.Events {
+		require.Equal(t, alice, e.Username)
+		require.Equal(t, repo, e.Repo)
+		methods = append(methods, e.Method)
+	}
+	require.OneOfEquals(t, "/pfs.API/CreateRepo", methods)
+	require.OneOfEquals(t, "/pfs.API/PutFile", methods)
+	require.OneOfEquals(t, "/pfs.API/DeleteCommit", methods)
+
+	// Filtering by a different user returns none of alice's calls
+	resp, err = adminClient.ListAuditEvents(adminClient.Ctx(), &auth.ListAuditEventsRequest{
+		Username: admin,
+		Resource: repo,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0, len(resp.Events))
+}
+
 // TestGetAuthTokenDefaultTTL tests the default TTL of a token returned from
 // GetAuthToken
 func TestGetAuthTokenDefaultTTL(t *testing.T) {
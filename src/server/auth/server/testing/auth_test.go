@@ -3054,3 +3054,43 @@ func TestDisableGitHubAuth(t *testing.T) {
 
 	// clean up
 }
+
+// TestPipelineTemplateOwner tests that only a pipeline template's owner, or an
+// admin, can update or delete it
+func TestPipelineTemplateOwner(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+	deleteAll(t)
+	defer deleteAll(t)
+	alice, bob := tu.UniqueString("alice"), tu.UniqueString("bob")
+	aliceClient, bobClient := getPachClient(t, alice), getPachClient(t, bob)
+	adminClient := getPachClient(t, admin)
+
+	template := tu.UniqueString("template")
+	jsonnet := `function(name) { pipeline: { name: name } }`
+	require.NoError(t, aliceClient.CreatePipelineTemplate(template, jsonnet, false))
+	templateInfo, err := bobClient.InspectPipelineTemplate(template)
+	require.NoError(t, err)
+	require.Equal(t, alice, templateInfo.Owner)
+
+	// bob can't update or delete alice's template
+	err = bobClient.CreatePipelineTemplate(template, jsonnet, true)
+	require.YesError(t, err)
+	require.Matches(t, "not authorized", err.Error())
+	err = bobClient.DeletePipelineTemplate(template)
+	require.YesError(t, err)
+	require.Matches(t, "not authorized", err.Error())
+
+	// alice and admins can, and updates don't change the owner
+	require.NoError(t, aliceClient.CreatePipelineTemplate(template, jsonnet, true))
+	require.NoError(t, adminClient.CreatePipelineTemplate(template, jsonnet, true))
+	templateInfo, err = aliceClient.InspectPipelineTemplate(template)
+	require.NoError(t, err)
+	require.Equal(t, alice, templateInfo.Owner)
+	require.NoError(t, adminClient.DeletePipelineTemplate(template))
+
+	// template names are validated
+	err = aliceClient.CreatePipelineTemplate("../"+template, jsonnet, false)
+	require.YesError(t, err)
+}
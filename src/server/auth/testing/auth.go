@@ -147,3 +147,8 @@ func (a *InactiveAPIServer) GetConfiguration(context.Context, *auth.GetConfigura
 func (a *InactiveAPIServer) GetOneTimePassword(context.Context, *auth.GetOneTimePasswordRequest) (*auth.GetOneTimePasswordResponse, error) {
 	return nil, auth.ErrNotActivated
 }
+
+// ListAuditEvents implements the ListAuditEvents RPC, but just returns NotActivatedError
+func (a *InactiveAPIServer) ListAuditEvents(context.Context, *auth.ListAuditEventsRequest) (*auth.ListAuditEventsResponse, error) {
+	return nil, auth.ErrNotActivated
+}
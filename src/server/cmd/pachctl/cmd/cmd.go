@@ -804,6 +804,30 @@ This resets the cluster to its initial state.`,
 	}
 	subcommands = append(subcommands, cmdutil.CreateAlias(editDocs, "edit"))
 
+	labelDocs := &cobra.Command{
+		Short: "Set or remove labels on a Pachyderm resource.",
+		Long:  "Set or remove labels on a Pachyderm resource.",
+	}
+	subcommands = append(subcommands, cmdutil.CreateAlias(labelDocs, "label"))
+
+	rollbackDocs := &cobra.Command{
+		Short: "Restore a Pachyderm resource to an earlier version.",
+		Long:  "Restore a Pachyderm resource to an earlier version.",
+	}
+	subcommands = append(subcommands, cmdutil.CreateAlias(rollbackDocs, "rollback"))
+
+	promoteDocs := &cobra.Command{
+		Short: "Promote a Pachyderm resource.",
+		Long:  "Promote a Pachyderm resource.",
+	}
+	subcommands = append(subcommands, cmdutil.CreateAlias(promoteDocs, "promote"))
+
+	reconcileDocs := &cobra.Command{
+		Short: "Bring a copy of a Pachyderm resource up to date.",
+		Long:  "Bring a copy of a Pachyderm resource up to date.",
+	}
+	subcommands = append(subcommands, cmdutil.CreateAlias(reconcileDocs, "reconcile"))
+
 	subcommands = append(subcommands, pfscmds.Cmds()...)
 	subcommands = append(subcommands, ppscmds.Cmds()...)
 	subcommands = append(subcommands, deploycmds.Cmds()...)
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"net"
 	"net/http"
@@ -33,6 +35,7 @@ import (
 	pach_http "github.com/pachyderm/pachyderm/src/server/http"
 	"github.com/pachyderm/pachyderm/src/server/pfs/s3"
 	pfs_server "github.com/pachyderm/pachyderm/src/server/pfs/server"
+	"github.com/pachyderm/pachyderm/src/server/pkg/audit"
 	cache_pb "github.com/pachyderm/pachyderm/src/server/pkg/cache/groupcachepb"
 	cache_server "github.com/pachyderm/pachyderm/src/server/pkg/cache/server"
 	"github.com/pachyderm/pachyderm/src/server/pkg/cmdutil"
@@ -209,6 +212,7 @@ func doSidecarMode(config interface{}) (retErr error) {
 			txnEnv,
 			path.Join(env.EtcdPrefix, env.AuthEtcdPrefix),
 			false,
+			nil, // sidecars don't record audit events
 		)
 		if err != nil {
 			return err
@@ -351,8 +355,36 @@ func doFullMode(config interface{}) (retErr error) {
 		return errors.Wrapf(err, "lru.New")
 	}
 	kubeNamespace := env.Namespace
+	// Mutating calls to the external and internal servers are recorded in the
+	// audit log
+	auditLogPath := env.AuditLogPath
+	if auditLogPath == "" {
+		auditLogPath = path.Join(env.StorageRoot, "audit", "audit.log")
+	}
+	auditLogKey := env.AuditLogKey
+	if auditLogKey == "" {
+		auditLogKey, err = getAuditLogKey(env.GetEtcdClient())
+		if err != nil {
+			return errors.Wrapf(err, "getAuditLogKey")
+		}
+	}
+	auditLog, err := audit.NewLog(auditLogPath, []byte(auditLogKey), env.AuditLogMaxBytes, env.AuditLogBackups)
+	if err != nil {
+		return err
+	}
+	whoAmI := func(ctx context.Context) (string, error) {
+		pachClient := env.GetPachClient(ctx)
+		resp, err := pachClient.WhoAmI(pachClient.Ctx(), &authclient.WhoAmIRequest{})
+		if err != nil {
+			return "", err
+		}
+		return resp.Username, nil
+	}
 	// Setup External Pachd GRPC Server.
-	externalServer, err := grpcutil.NewServer(context.Background(), true)
+	externalServer, err := grpcutil.NewServer(context.Background(), true, grpcutil.Interceptor{
+		Unary:  audit.UnaryServerInterceptor(auditLog, whoAmI),
+		Stream: audit.StreamServerInterceptor(auditLog, whoAmI),
+	})
 	if err != nil {
 		return err
 	}
@@ -425,7 +457,7 @@ func doFullMode(config interface{}) (retErr error) {
 		var authAPIServer authserver.APIServer
 		if err := logGRPCServerSetup("Auth API", func() error {
 			authAPIServer, err = authserver.NewAuthServer(
-				env, txnEnv, path.Join(env.EtcdPrefix, env.AuthEtcdPrefix), true)
+				env, txnEnv, path.Join(env.EtcdPrefix, env.AuthEtcdPrefix), true, auditLog)
 			if err != nil {
 				return err
 			}
@@ -504,7 +536,10 @@ func doFullMode(config interface{}) (retErr error) {
 		return err
 	}
 	// Setup Internal Pachd GRPC Server.
-	internalServer, err := grpcutil.NewServer(context.Background(), false)
+	internalServer, err := grpcutil.NewServer(context.Background(), false, grpcutil.Interceptor{
+		Unary:  audit.UnaryServerInterceptor(auditLog, whoAmI),
+		Stream: audit.StreamServerInterceptor(auditLog, whoAmI),
+	})
 	if err != nil {
 		return err
 	}
@@ -597,6 +632,7 @@ func doFullMode(config interface{}) (retErr error) {
 				txnEnv,
 				path.Join(env.EtcdPrefix, env.AuthEtcdPrefix),
 				false,
+				auditLog,
 			)
 			if err != nil {
 				return err
@@ -729,6 +765,36 @@ func getClusterID(client *etcd.Client) (string, error) {
 	return getClusterID(client)
 }
 
+const auditLogKeyKey = "audit-log-key"
+
+// getAuditLogKey returns the key that audit events are hashed with, generating
+// it if this is the first pachd to start. It's stored in etcd rather than with
+// the audit log, so that someone who can modify the log can't recompute the
+// hashes of the events they've modified.
+func getAuditLogKey(client *etcd.Client) (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	// Only store the new key if no other pachd has stored one
+	resp, err := client.Txn(context.Background()).
+		If(etcd.Compare(etcd.CreateRevision(auditLogKeyKey), "=", 0)).
+		Then(etcd.OpPut(auditLogKeyKey, hex.EncodeToString(key))).
+		Else(etcd.OpGet(auditLogKeyKey)).
+		Commit()
+	if err != nil {
+		return "", err
+	}
+	if resp.Succeeded {
+		return hex.EncodeToString(key), nil
+	}
+	kvs := resp.Responses[0].GetResponseRange().Kvs
+	if len(kvs) == 0 {
+		return getAuditLogKey(client)
+	}
+	return string(kvs[0].Value), nil
+}
+
 func logGRPCServerSetup(name string, f func() error) (retErr error) {
 	log.Printf("started setting up %v GRPC Server", name)
 	defer func() {
@@ -38,6 +38,7 @@ import (
 	"github.com/pachyderm/pachyderm/src/server/pkg/ancestry"
 	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
 	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	"github.com/pachyderm/pachyderm/src/server/pkg/notify"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ppsconsts"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
 	"github.com/pachyderm/pachyderm/src/server/pkg/pretty"
@@ -1153,6 +1154,84 @@ func TestRunPipeline(t *testing.T) {
 	})
 
 }
+func TestDryRunPipeline(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+
+	c := tu.GetPachClient(t)
+	require.NoError(t, c.DeleteAll())
+
+	dataRepo := tu.UniqueString("TestDryRunPipeline_data")
+	require.NoError(t, c.CreateRepo(dataRepo))
+	emptyRepo := tu.UniqueString("TestDryRunPipeline_empty")
+	require.NoError(t, c.CreateRepo(emptyRepo))
+
+	commit1, err := c.StartCommit(dataRepo, "master")
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = c.PutFile(dataRepo, commit1.ID, fmt.Sprintf("file-%d", i), strings.NewReader("foo"))
+		require.NoError(t, err)
+	}
+	require.NoError(t, c.FinishCommit(dataRepo, commit1.ID))
+
+	pipeline := tu.UniqueString("pipeline")
+	request := &pps.CreatePipelineRequest{
+		Pipeline: client.NewPipeline(pipeline),
+		Transform: &pps.Transform{
+			Cmd: []string{"bash"},
+			Stdin: []string{
+				fmt.Sprintf("cp /pfs/%s/* /pfs/out/", dataRepo),
+			},
+		},
+		Input: client.NewPFSInput(dataRepo, "/*"),
+	}
+
+	// A dry run of a new pipeline sees all datums, and creates nothing
+	response, err := c.DryRunPipeline(request)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), response.Datums)
+	require.Equal(t, int64(0), response.DatumsSkipped)
+	require.Equal(t, uint64(9), response.InputBytes)
+	require.Equal(t, 0, len(response.EmptyInputs))
+	_, err = c.InspectPipeline(pipeline)
+	require.YesError(t, err)
+	_, err = c.InspectRepo(pipeline)
+	require.YesError(t, err)
+
+	_, err = c.PpsAPIClient.CreatePipeline(c.Ctx(), request)
+	require.NoError(t, err)
+	commitIter, err := c.FlushCommit([]*pfs.Commit{commit1}, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(collectCommitInfos(t, commitIter)))
+
+	// An update that doesn't reprocess would skip every datum
+	request.Update = true
+	response, err = c.DryRunPipeline(request)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), response.Datums)
+	require.Equal(t, int64(3), response.DatumsSkipped)
+	request.Reprocess = true
+	response, err = c.DryRunPipeline(request)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), response.DatumsSkipped)
+
+	// Crossing with an empty repo produces no datums, and is reported
+	request.Input = client.NewCrossInput(
+		client.NewPFSInput(dataRepo, "/*"),
+		client.NewPFSInput(emptyRepo, "/*"),
+	)
+	response, err = c.DryRunPipeline(request)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), response.Datums)
+	require.Equal(t, []string{emptyRepo}, response.EmptyInputs)
+
+	// Invalid specs are rejected, as they are by CreatePipeline
+	request.Input = client.NewPFSInput(dataRepo, "")
+	_, err = c.DryRunPipeline(request)
+	require.YesError(t, err)
+}
+
 func TestPipelineFailure(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration tests in short mode")
@@ -2861,6 +2940,283 @@ func TestUpdatePipeline(t *testing.T) {
 	require.Equal(t, "buzz\n", buffer.String())
 }
 
+func TestRollbackPipeline(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+
+	c := tu.GetPachClient(t)
+	require.NoError(t, c.DeleteAll())
+	dataRepo := tu.UniqueString("TestRollbackPipeline_data")
+	require.NoError(t, c.CreateRepo(dataRepo))
+	pipelineName := tu.UniqueString("pipeline")
+	createPipeline := func(output string, update bool) {
+		require.NoError(t, c.CreatePipeline(
+			pipelineName,
+			"",
+			[]string{"bash"},
+			[]string{fmt.Sprintf("echo %s >/pfs/out/file", output)},
+			&pps.ParallelismSpec{
+				Constant: 1,
+			},
+			client.NewPFSInput(dataRepo, "/*"),
+			"",
+			update,
+		))
+	}
+	checkOutput := func(expected string) {
+		iter, err := c.FlushCommit([]*pfs.Commit{client.NewCommit(dataRepo, "master")}, nil)
+		require.NoError(t, err)
+		collectCommitInfos(t, iter)
+		var buffer bytes.Buffer
+		require.NoError(t, c.GetFile(pipelineName, "master", "file", 0, 0, &buffer))
+		require.Equal(t, expected, buffer.String())
+	}
+	_, err := c.PutFile(dataRepo, "master", "file", strings.NewReader("1"))
+	require.NoError(t, err)
+	createPipeline("foo", false)
+	checkOutput("foo\n")
+	createPipeline("bar", true)
+	createPipeline("buzz", true)
+
+	// Rolling back to the current version or a nonexistent one fails
+	require.YesError(t, c.RollbackPipeline(pipelineName, 3, false))
+	require.YesError(t, c.RollbackPipeline(pipelineName, 4, false))
+
+	// Roll back to the first version, reprocessing the existing data
+	require.NoError(t, c.RollbackPipeline(pipelineName, 1, true))
+	pipelineInfo, err := c.InspectPipeline(pipelineName)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), pipelineInfo.Version)
+	require.Equal(t, uint64(1), pipelineInfo.RestoredFromVersion)
+	require.Equal(t, "echo foo >/pfs/out/file", pipelineInfo.Transform.Stdin[0])
+	checkOutput("foo\n")
+}
+
+func TestShadowPipeline(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+
+	c := tu.GetPachClient(t)
+	require.NoError(t, c.DeleteAll())
+	dataRepo := tu.UniqueString("TestShadowPipeline_data")
+	require.NoError(t, c.CreateRepo(dataRepo))
+	pipelineName := tu.UniqueString("pipeline")
+	createPipeline := func(stdin []string, update, shadow bool) error {
+		_, err := c.PpsAPIClient.CreatePipeline(
+			context.Background(),
+			&pps.CreatePipelineRequest{
+				Pipeline: client.NewPipeline(pipelineName),
+				Transform: &pps.Transform{
+					Cmd:   []string{"bash"},
+					Stdin: stdin,
+				},
+				Input:  client.NewPFSInput(dataRepo, "/"),
+				Update: update,
+				Shadow: shadow,
+			})
+		return err
+	}
+	liveStdin := []string{
+		"echo same >/pfs/out/same",
+		"echo old >/pfs/out/changed",
+		"echo gone >/pfs/out/deleted",
+	}
+	shadowStdin := []string{
+		"echo same >/pfs/out/same",
+		"echo new >/pfs/out/changed",
+		"echo added >/pfs/out/added",
+	}
+
+	// A pipeline that doesn't exist can't be shadowed
+	require.YesError(t, createPipeline(shadowStdin, false, true))
+
+	_, err := c.PutFile(dataRepo, "master", "file", strings.NewReader("1"))
+	require.NoError(t, err)
+	require.NoError(t, createPipeline(liveStdin, false, false))
+	require.NoError(t, createPipeline(shadowStdin, true, true))
+	shadowName := ppsutil.ShadowPipelineName(pipelineName)
+	shadowInfo, err := c.InspectPipeline(shadowName)
+	require.NoError(t, err)
+	require.Equal(t, pipelineName, shadowInfo.ShadowOf)
+
+	// Both pipelines process a new input commit
+	_, err = c.PutFile(dataRepo, "master", "file2", strings.NewReader("2"))
+	require.NoError(t, err)
+	iter, err := c.FlushCommit([]*pfs.Commit{client.NewCommit(dataRepo, "master")}, nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(collectCommitInfos(t, iter)))
+
+	// The live pipeline's output is unchanged
+	var buffer bytes.Buffer
+	require.NoError(t, c.GetFile(pipelineName, "master", "changed", 0, 0, &buffer))
+	require.Equal(t, "old\n", buffer.String())
+
+	report, err := c.InspectShadow(pipelineName)
+	require.NoError(t, err)
+	require.Equal(t, shadowName, report.Shadow.Name)
+	require.Equal(t, 2, len(report.Commits))
+	for _, commitReport := range report.Commits {
+		require.NotNil(t, commitReport.LiveJob)
+		require.Equal(t, pps.JobState_JOB_SUCCESS, commitReport.ShadowJob.State)
+		require.Equal(t, []string{"/added"}, commitReport.Added)
+		require.Equal(t, []string{"/deleted"}, commitReport.Deleted)
+		require.Equal(t, []string{"/changed"}, commitReport.Changed)
+	}
+
+	// Promoting the shadow updates the live pipeline and deletes the shadow
+	require.NoError(t, c.PromoteShadow(pipelineName, true))
+	_, err = c.InspectPipeline(shadowName)
+	require.YesError(t, err)
+	_, err = c.InspectRepo(shadowName)
+	require.YesError(t, err)
+	pipelineInfo, err := c.InspectPipeline(pipelineName)
+	require.NoError(t, err)
+	require.Equal(t, shadowStdin, pipelineInfo.Transform.Stdin)
+	require.Equal(t, "", pipelineInfo.ShadowOf)
+	iter, err = c.FlushCommit([]*pfs.Commit{client.NewCommit(dataRepo, "master")}, nil)
+	require.NoError(t, err)
+	collectCommitInfos(t, iter)
+	buffer.Reset()
+	require.NoError(t, c.GetFile(pipelineName, "master", "changed", 0, 0, &buffer))
+	require.Equal(t, "new\n", buffer.String())
+}
+
+func TestGetUsage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+
+	c := tu.GetPachClient(t)
+	require.NoError(t, c.DeleteAll())
+	dataRepo := tu.UniqueString("TestGetUsage_data")
+	require.NoError(t, c.CreateRepo(dataRepo))
+	var pipelines []string
+	for _, team := range []string{"a", "a", "b"} {
+		pipeline := tu.UniqueString("pipeline")
+		pipelines = append(pipelines, pipeline)
+		_, err := c.PpsAPIClient.CreatePipeline(
+			context.Background(),
+			&pps.CreatePipelineRequest{
+				Pipeline: client.NewPipeline(pipeline),
+				Transform: &pps.Transform{
+					Cmd:   []string{"bash"},
+					Stdin: []string{"cp /pfs/*/* /pfs/out/"},
+				},
+				Input:    client.NewPFSInput(dataRepo, "/*"),
+				Metadata: &pps.Metadata{Labels: map[string]string{"team": team}},
+			})
+		require.NoError(t, err)
+	}
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		_, err := c.PutFile(dataRepo, "master", fmt.Sprintf("file%d", i), strings.NewReader("foo"))
+		require.NoError(t, err)
+	}
+	iter, err := c.FlushCommit([]*pfs.Commit{client.NewCommit(dataRepo, "master")}, nil)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(collectCommitInfos(t, iter)))
+
+	usageInfos, err := c.GetUsage(pipelines[0], "", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(usageInfos))
+	require.Equal(t, pipelines[0], usageInfos[0].Name)
+	require.Equal(t, int64(2), usageInfos[0].Jobs)
+	require.Equal(t, int64(2), usageInfos[0].DataProcessed)
+
+	usageInfos, err = c.GetUsage("", "team", start.Add(-time.Minute), time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(usageInfos))
+	require.Equal(t, "a", usageInfos[0].Name)
+	require.Equal(t, int64(4), usageInfos[0].Jobs)
+	require.Equal(t, "b", usageInfos[1].Name)
+	require.Equal(t, int64(2), usageInfos[1].Jobs)
+	require.Equal(t, uint64(6), usageInfos[1].UploadBytes)
+
+	// No jobs started before the pipelines were created
+	usageInfos, err = c.GetUsage("", "team", time.Time{}, start.Add(-time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 0, len(usageInfos))
+
+	// Relabelling a pipeline doesn't move its past jobs to the new label
+	_, err = c.PpsAPIClient.CreatePipeline(
+		context.Background(),
+		&pps.CreatePipelineRequest{
+			Pipeline: client.NewPipeline(pipelines[2]),
+			Transform: &pps.Transform{
+				Cmd:   []string{"bash"},
+				Stdin: []string{"cp /pfs/*/* /pfs/out/"},
+			},
+			Input:    client.NewPFSInput(dataRepo, "/*"),
+			Metadata: &pps.Metadata{Labels: map[string]string{"team": "c"}},
+			Update:   true,
+		})
+	require.NoError(t, err)
+	iter, err = c.FlushCommit([]*pfs.Commit{client.NewCommit(dataRepo, "master")}, []*pfs.Repo{client.NewRepo(pipelines[2])})
+	require.NoError(t, err)
+	collectCommitInfos(t, iter)
+	usageInfos, err = c.GetUsage(pipelines[2], "team", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.True(t, len(usageInfos) >= 1)
+	require.Equal(t, "b", usageInfos[0].Name)
+	require.Equal(t, int64(2), usageInfos[0].Jobs)
+}
+
+func TestNotificationSinks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+
+	c := tu.GetPachClient(t)
+	require.NoError(t, c.DeleteAll())
+	sinkInfo := &pps.NotificationSinkInfo{
+		Sink:      &pps.NotificationSink{Name: "failures"},
+		File:      &pps.FileSink{},
+		Events:    []pps.NotificationEventType{pps.NotificationEventType_JOB_STATE},
+		JobStates: []pps.JobState{pps.JobState_JOB_FAILURE},
+	}
+	require.NoError(t, c.CreateNotificationSink(sinkInfo, false))
+	require.YesError(t, c.CreateNotificationSink(sinkInfo, false))
+
+	// A sink must have exactly one destination
+	sinkInfo.Slack = &pps.SlackSink{URL: "http://example.com"}
+	require.YesError(t, c.CreateNotificationSink(sinkInfo, true))
+	sinkInfo.File = nil
+	require.NoError(t, c.CreateNotificationSink(sinkInfo, true))
+
+	inspected, err := c.InspectNotificationSink("failures")
+	require.NoError(t, err)
+	require.Equal(t, "http://example.com", inspected.Slack.URL)
+	require.Nil(t, inspected.File)
+	sinkInfos, err := c.ListNotificationSink()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(sinkInfos))
+	deadLetters, err := c.ListDeadLetter("failures")
+	require.NoError(t, err)
+	require.Equal(t, 0, len(deadLetters))
+
+	// Webhook secrets are redacted, and kept by updates that pass the
+	// redacted value back
+	sinkInfo.Slack = nil
+	sinkInfo.Webhook = &pps.WebhookSink{URL: "http://example.com", Secret: "secret"}
+	require.NoError(t, c.CreateNotificationSink(sinkInfo, true))
+	inspected, err = c.InspectNotificationSink("failures")
+	require.NoError(t, err)
+	require.Equal(t, notify.RedactedSecret, inspected.Webhook.Secret)
+	sinkInfos, err = c.ListNotificationSink()
+	require.NoError(t, err)
+	require.Equal(t, notify.RedactedSecret, sinkInfos[0].Webhook.Secret)
+	require.NoError(t, c.CreateNotificationSink(inspected, true))
+	inspected.Sink = &pps.NotificationSink{Name: "copy"}
+	require.YesError(t, c.CreateNotificationSink(inspected, false))
+
+	require.NoError(t, c.DeleteNotificationSink("failures"))
+	_, err = c.InspectNotificationSink("failures")
+	require.YesError(t, err)
+	require.YesError(t, c.DeleteNotificationSink("failures"))
+}
+
 func TestUpdatePipelineWithInProgressCommitsAndStats(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration tests in short mode")
@@ -10670,6 +11026,71 @@ func TestCreatePipelineError(t *testing.T) {
 	require.Matches(t, "transform", err.Error())
 }
 
+// TestPipelinePinnedInput tests that a pipeline input pinned to a commit only
+// reads that commit, and that the pipeline's output is provenant on it
+func TestPipelinePinnedInput(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+
+	c := tu.GetPachClient(t)
+	require.NoError(t, c.DeleteAll())
+
+	dataRepo := tu.UniqueString(t.Name() + "-data")
+	require.NoError(t, c.CreateRepo(dataRepo))
+	commit1, err := c.StartCommit(dataRepo, "master")
+	require.NoError(t, err)
+	_, err = c.PutFile(dataRepo, commit1.ID, "a", strings.NewReader("a"))
+	require.NoError(t, err)
+	require.NoError(t, c.FinishCommit(dataRepo, commit1.ID))
+	commit2, err := c.StartCommit(dataRepo, "master")
+	require.NoError(t, err)
+	_, err = c.PutFile(dataRepo, commit2.ID, "b", strings.NewReader("b"))
+	require.NoError(t, err)
+	require.NoError(t, c.FinishCommit(dataRepo, commit2.ID))
+
+	// 'master^' resolves to commit1 when the pipeline is created
+	pipeline := tu.UniqueString("pinned")
+	input := client.NewPFSInput(dataRepo, "/*")
+	input.Pfs.Commit = "master^"
+	_, err = c.PpsAPIClient.CreatePipeline(
+		context.Background(),
+		&pps.CreatePipelineRequest{
+			Pipeline: client.NewPipeline(pipeline),
+			Transform: &pps.Transform{
+				Cmd:   []string{"bash"},
+				Stdin: []string{fmt.Sprintf("cp /pfs/%s/* /pfs/out/", dataRepo)},
+			},
+			Input: input,
+		})
+	require.NoError(t, err)
+	pipelineInfo, err := c.InspectPipeline(pipeline)
+	require.NoError(t, err)
+	require.Equal(t, commit1.ID, pipelineInfo.Input.Pfs.Commit)
+	require.Equal(t, ppsutil.PinnedBranchName(commit1.ID), pipelineInfo.Input.Pfs.Branch)
+
+	commitInfos, err := c.FlushCommitAll([]*pfs.Commit{commit1}, []*pfs.Repo{client.NewRepo(pipeline)})
+	require.NoError(t, err)
+	require.Equal(t, 1, len(commitInfos))
+	var provenant bool
+	for _, prov := range commitInfos[0].Provenance {
+		provenant = provenant || prov.Commit.ID == commit1.ID
+	}
+	require.True(t, provenant)
+	fileInfos, err := c.ListFile(pipeline, commitInfos[0].Commit.ID, "/")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(fileInfos))
+	require.Equal(t, "/a", fileInfos[0].File.Path)
+
+	// New commits to the input's branch don't start jobs
+	commit3, err := c.StartCommit(dataRepo, "master")
+	require.NoError(t, err)
+	require.NoError(t, c.FinishCommit(dataRepo, commit3.ID))
+	commitInfos, err = c.FlushCommitAll([]*pfs.Commit{commit3}, []*pfs.Repo{client.NewRepo(pipeline)})
+	require.NoError(t, err)
+	require.Equal(t, 0, len(commitInfos))
+}
+
 // TestCreatePipelineErrorNoCmd tests that sending a CreatePipeline request to
 // pachd with no 'transform.cmd' field doesn't kill pachd
 func TestCreatePipelineErrorNoCmd(t *testing.T) {
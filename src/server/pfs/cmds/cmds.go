@@ -29,6 +29,7 @@ import (
 	"github.com/pachyderm/pachyderm/src/server/pkg/progress"
 	"github.com/pachyderm/pachyderm/src/server/pkg/sync"
 	"github.com/pachyderm/pachyderm/src/server/pkg/tabwriter"
+	"github.com/pachyderm/pachyderm/src/server/pkg/uuid"
 	txncmds "github.com/pachyderm/pachyderm/src/server/transaction/cmds"
 
 	"github.com/spf13/cobra"
@@ -69,11 +70,16 @@ or type (e.g. csv, binary, images, etc).`,
 	commands = append(commands, cmdutil.CreateDocsAlias(repoDocs, "repo", " repo$"))
 
 	var description string
+	var labels []string
 	createRepo := &cobra.Command{
 		Use:   "{{alias}} <repo>",
 		Short: "Create a new repo.",
 		Long:  "Create a new repo.",
 		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			repoLabels, err := cmdutil.ParseLabels(labels)
+			if err != nil {
+				return err
+			}
 			c, err := client.NewOnUserMachine("user")
 			if err != nil {
 				return err
@@ -86,6 +92,7 @@ or type (e.g. csv, binary, images, etc).`,
 					&pfsclient.CreateRepoRequest{
 						Repo:        client.NewRepo(args[0]),
 						Description: description,
+						Labels:      repoLabels,
 					},
 				)
 				return err
@@ -94,6 +101,7 @@ or type (e.g. csv, binary, images, etc).`,
 		}),
 	}
 	createRepo.Flags().StringVarP(&description, "description", "d", "", "A description of the repo.")
+	createRepo.Flags().StringSliceVar(&labels, "label", nil, "A label to set on the repo, of the form key=value (may be repeated or comma-separated).")
 	commands = append(commands, cmdutil.CreateAlias(createRepo, "create repo"))
 
 	updateRepo := &cobra.Command{
@@ -101,6 +109,10 @@ or type (e.g. csv, binary, images, etc).`,
 		Short: "Update a repo.",
 		Long:  "Update a repo.",
 		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			repoLabels, err := cmdutil.ParseLabels(labels)
+			if err != nil {
+				return err
+			}
 			c, err := client.NewOnUserMachine("user")
 			if err != nil {
 				return err
@@ -114,6 +126,7 @@ or type (e.g. csv, binary, images, etc).`,
 						Repo:        client.NewRepo(args[0]),
 						Description: description,
 						Update:      true,
+						Labels:      repoLabels,
 					},
 				)
 				return err
@@ -122,6 +135,7 @@ or type (e.g. csv, binary, images, etc).`,
 		}),
 	}
 	updateRepo.Flags().StringVarP(&description, "description", "d", "", "A description of the repo.")
+	updateRepo.Flags().StringSliceVar(&labels, "label", nil, "A label to add to the repo, of the form key=value (may be repeated or comma-separated). Existing labels are kept.")
 	shell.RegisterCompletionFunc(updateRepo, shell.RepoCompletion)
 	commands = append(commands, cmdutil.CreateAlias(updateRepo, "update repo"))
 
@@ -160,13 +174,23 @@ or type (e.g. csv, binary, images, etc).`,
 	listRepo := &cobra.Command{
 		Short: "Return all repos.",
 		Long:  "Return all repos.",
+		Example: `
+# return all repos
+$ {{alias}}
+
+# return repos with the label "team=vision"
+$ {{alias}} --label team=vision`,
 		Run: cmdutil.RunFixedArgs(0, func(args []string) error {
+			selector, err := cmdutil.ParseLabels(labels)
+			if err != nil {
+				return err
+			}
 			c, err := client.NewOnUserMachine("user")
 			if err != nil {
 				return err
 			}
 			defer c.Close()
-			repoInfos, err := c.ListRepo()
+			repoInfos, err := c.ListRepoByLabel(selector)
 			if err != nil {
 				return err
 			}
@@ -190,6 +214,7 @@ or type (e.g. csv, binary, images, etc).`,
 			return writer.Flush()
 		}),
 	}
+	listRepo.Flags().StringSliceVar(&labels, "label", nil, "Only list repos with this label, of the form key=value (may be repeated or comma-separated).")
 	listRepo.Flags().AddFlagSet(rawFlags)
 	listRepo.Flags().AddFlagSet(fullTimestampsFlags)
 	commands = append(commands, cmdutil.CreateAlias(listRepo, "list repo"))
@@ -269,6 +294,10 @@ $ {{alias}} test -p XXX`,
 			if err != nil {
 				return err
 			}
+			commitLabels, err := cmdutil.ParseLabels(labels)
+			if err != nil {
+				return err
+			}
 			c, err := client.NewOnUserMachine("user")
 			if err != nil {
 				return err
@@ -284,6 +313,7 @@ $ {{alias}} test -p XXX`,
 						Branch:      branch.Name,
 						Parent:      client.NewCommit(branch.Repo.Name, parent),
 						Description: description,
+						Labels:      commitLabels,
 					},
 				)
 				return err
@@ -298,6 +328,7 @@ $ {{alias}} test -p XXX`,
 	startCommit.MarkFlagCustom("parent", "__pachctl_get_commit $(__parse_repo ${nouns[0]})")
 	startCommit.Flags().StringVarP(&description, "message", "m", "", "A description of this commit's contents")
 	startCommit.Flags().StringVar(&description, "description", "", "A description of this commit's contents (synonym for --message)")
+	startCommit.Flags().StringSliceVar(&labels, "label", nil, "A label to set on the commit, of the form key=value (may be repeated or comma-separated).")
 	shell.RegisterCompletionFunc(startCommit, shell.BranchCompletion)
 	commands = append(commands, cmdutil.CreateAlias(startCommit, "start commit"))
 
@@ -310,6 +341,10 @@ $ {{alias}} test -p XXX`,
 			if err != nil {
 				return err
 			}
+			commitLabels, err := cmdutil.ParseLabels(labels)
+			if err != nil {
+				return err
+			}
 			c, err := client.NewOnUserMachine("user")
 			if err != nil {
 				return err
@@ -322,6 +357,7 @@ $ {{alias}} test -p XXX`,
 					&pfsclient.FinishCommitRequest{
 						Commit:      commit,
 						Description: description,
+						Labels:      commitLabels,
 					},
 				)
 				return err
@@ -331,6 +367,7 @@ $ {{alias}} test -p XXX`,
 	}
 	finishCommit.Flags().StringVarP(&description, "message", "m", "", "A description of this commit's contents (overwrites any existing commit description)")
 	finishCommit.Flags().StringVar(&description, "description", "", "A description of this commit's contents (synonym for --message)")
+	finishCommit.Flags().StringSliceVar(&labels, "label", nil, "A label to set on the commit, of the form key=value (may be repeated or comma-separated).")
 	shell.RegisterCompletionFunc(finishCommit, shell.BranchCompletion)
 	commands = append(commands, cmdutil.CreateAlias(finishCommit, "finish commit"))
 
@@ -388,7 +425,10 @@ $ {{alias}} foo@master
 $ {{alias}} foo@master -n 20
 
 # return commits in repo "foo" since commit XXX
-$ {{alias}} foo@master --from XXX`,
+$ {{alias}} foo@master --from XXX
+
+# return commits in repo "foo" with the labels "source=vendorA" and "validated=true"
+$ {{alias}} foo --label source=vendorA,validated=true`,
 		Run: cmdutil.RunFixedArgs(1, func(args []string) (retErr error) {
 			c, err := client.NewOnUserMachine("user")
 			if err != nil {
@@ -400,14 +440,18 @@ $ {{alias}} foo@master --from XXX`,
 			if err != nil {
 				return err
 			}
+			selector, err := cmdutil.ParseLabels(labels)
+			if err != nil {
+				return err
+			}
 
 			if raw {
-				return c.ListCommitF(branch.Repo.Name, branch.Name, from, uint64(number), false, func(ci *pfsclient.CommitInfo) error {
+				return c.ListCommitByLabelF(branch.Repo.Name, branch.Name, from, uint64(number), false, selector, func(ci *pfsclient.CommitInfo) error {
 					return marshaller.Marshal(os.Stdout, ci)
 				})
 			}
 			writer := tabwriter.NewWriter(os.Stdout, pretty.CommitHeader)
-			if err := c.ListCommitF(branch.Repo.Name, branch.Name, from, uint64(number), false, func(ci *pfsclient.CommitInfo) error {
+			if err := c.ListCommitByLabelF(branch.Repo.Name, branch.Name, from, uint64(number), false, selector, func(ci *pfsclient.CommitInfo) error {
 				pretty.PrintCommitInfo(writer, ci, fullTimestamps)
 				return nil
 			}); err != nil {
@@ -418,12 +462,72 @@ $ {{alias}} foo@master --from XXX`,
 	}
 	listCommit.Flags().StringVarP(&from, "from", "f", "", "list all commits since this commit")
 	listCommit.Flags().IntVarP(&number, "number", "n", 0, "list only this many commits; if set to zero, list all commits")
+	listCommit.Flags().StringSliceVar(&labels, "label", nil, "list only commits with this label, of the form key=value (may be repeated or comma-separated)")
 	listCommit.MarkFlagCustom("from", "__pachctl_get_commit $(__parse_repo ${nouns[0]})")
 	listCommit.Flags().AddFlagSet(rawFlags)
 	listCommit.Flags().AddFlagSet(fullTimestampsFlags)
 	shell.RegisterCompletionFunc(listCommit, shell.RepoCompletion)
 	commands = append(commands, cmdutil.CreateAlias(listCommit, "list commit"))
 
+	var removeLabels []string
+	labelRepo := &cobra.Command{
+		Use:   "{{alias}} <repo> [<key>=<value>...]",
+		Short: "Add or remove labels on a repo.",
+		Long:  "Add labels to a repo, overwriting any existing labels with the same keys, or remove labels with --remove.",
+		Example: `
+# add the label "team=vision" to repo "foo"
+$ {{alias}} foo team=vision
+
+# remove the label "team" from repo "foo"
+$ {{alias}} foo --remove team`,
+		Run: cmdutil.RunMinimumArgs(1, func(args []string) error {
+			repoLabels, err := cmdutil.ParseLabels(args[1:])
+			if err != nil {
+				return err
+			}
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			return c.LabelRepo(args[0], repoLabels, removeLabels)
+		}),
+	}
+	labelRepo.Flags().StringSliceVar(&removeLabels, "remove", nil, "The key of a label to remove (may be repeated or comma-separated).")
+	shell.RegisterCompletionFunc(labelRepo, shell.RepoCompletion)
+	commands = append(commands, cmdutil.CreateAlias(labelRepo, "label repo"))
+
+	labelCommit := &cobra.Command{
+		Use:   "{{alias}} <repo>@<branch-or-commit> [<key>=<value>...]",
+		Short: "Add or remove labels on a commit.",
+		Long:  "Add labels to a commit, overwriting any existing labels with the same keys, or remove labels with --remove.",
+		Example: `
+# add the labels "source=vendorA" and "validated=true" to the head of master in repo "foo"
+$ {{alias}} foo@master source=vendorA validated=true
+
+# remove the label "validated" from commit XXX in repo "foo"
+$ {{alias}} foo@XXX --remove validated`,
+		Run: cmdutil.RunMinimumArgs(1, func(args []string) error {
+			commit, err := cmdutil.ParseCommit(args[0])
+			if err != nil {
+				return err
+			}
+			commitLabels, err := cmdutil.ParseLabels(args[1:])
+			if err != nil {
+				return err
+			}
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			return c.LabelCommit(commit.Repo.Name, commit.ID, commitLabels, removeLabels)
+		}),
+	}
+	labelCommit.Flags().StringSliceVar(&removeLabels, "remove", nil, "The key of a label to remove (may be repeated or comma-separated).")
+	shell.RegisterCompletionFunc(labelCommit, shell.BranchCompletion)
+	commands = append(commands, cmdutil.CreateAlias(labelCommit, "label commit"))
+
 	printCommitIter := func(commitIter client.CommitInfoIterator) error {
 		if raw {
 			for {
@@ -714,6 +818,157 @@ Any pachctl command that can take a Commit ID, can take a branch name instead.`,
 	shell.RegisterCompletionFunc(deleteBranch, shell.BranchCompletion)
 	commands = append(commands, cmdutil.CreateAlias(deleteBranch, "delete branch"))
 
+	var strategy string
+	merge := &cobra.Command{
+		Use:   "{{alias}} <repo>@<src-branch> <dst-branch>",
+		Short: "Merge one branch into another.",
+		Long: `Merge one branch into another.
+
+The changes made on <src-branch> since its common ancestor with <dst-branch>
+are applied to <dst-branch> in a new commit. Files that were changed
+differently on both branches, or that are a file on one branch and a
+directory on the other, are conflicts: by default, conflicts fail the merge,
+but --strategy=ours keeps the files from <dst-branch>, and --strategy=theirs
+takes them from <src-branch>. Merging the same branches again only applies
+the changes made on <src-branch> since the previous merge.`,
+		Example: `
+# Merge branch "feature" into "master" in repo "foo"
+$ {{alias}} foo@feature master
+
+# Merge "feature" into "master", preferring the files from "feature"
+$ {{alias}} foo@feature master --strategy=theirs`,
+		Run: cmdutil.RunFixedArgs(2, func(args []string) error {
+			src, err := cmdutil.ParseBranch(args[0])
+			if err != nil {
+				return err
+			}
+			var mergeStrategy pfsclient.MergeStrategy
+			switch strings.ToLower(strategy) {
+			case "":
+				mergeStrategy = pfsclient.MergeStrategy_CONFLICT
+			case "ours":
+				mergeStrategy = pfsclient.MergeStrategy_OURS
+			case "theirs":
+				mergeStrategy = pfsclient.MergeStrategy_THEIRS
+			default:
+				return errors.Errorf("unrecognized merge strategy %q (must be \"ours\" or \"theirs\")", strategy)
+			}
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			commit, conflicts, err := c.MergeBranch(src.Repo.Name, src.Name, args[1], mergeStrategy)
+			if err != nil {
+				return err
+			}
+			for _, path := range conflicts {
+				fmt.Fprintf(os.Stderr, "conflict: %s\n", path)
+			}
+			if len(conflicts) > 0 && mergeStrategy == pfsclient.MergeStrategy_CONFLICT {
+				return errors.Errorf("merge failed with %d conflicting path(s); use --strategy=ours or --strategy=theirs to resolve them", len(conflicts))
+			}
+			if commit == nil {
+				fmt.Fprintln(os.Stderr, "nothing to merge")
+				return nil
+			}
+			fmt.Println(commit.ID)
+			return nil
+		}),
+	}
+	merge.Flags().StringVar(&strategy, "strategy", "", "How to resolve conflicting paths: \"ours\" keeps the destination branch's files and \"theirs\" takes the source branch's files. By default, conflicts fail the merge.")
+	shell.RegisterCompletionFunc(merge, shell.BranchCompletion)
+	commands = append(commands, cmdutil.CreateAlias(merge, "merge"))
+
+	snapshotDocs := &cobra.Command{
+		Short: "Docs for snapshots.",
+		Long: `A snapshot in Pachyderm is a named record of the state of every repo and
+pipeline at one moment.
+
+Creating a snapshot atomically records the head commit of every branch, and
+the spec commit of every pipeline. Restoring a snapshot points branches back
+at the commits that they pointed to when it was created.`,
+	}
+	commands = append(commands, cmdutil.CreateDocsAlias(snapshotDocs, "snapshot", " snapshot$"))
+
+	createSnapshot := &cobra.Command{
+		Use:   "{{alias}} <name>",
+		Short: "Record the state of every branch and pipeline in a snapshot.",
+		Long:  "Record the head of every branch and the spec commit of every pipeline, atomically, in a new snapshot.",
+		Example: `
+# Record everything as of release 12
+$ {{alias}} release-12`,
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			_, err = c.CreateSnapshot(args[0])
+			return err
+		}),
+	}
+	commands = append(commands, cmdutil.CreateAlias(createSnapshot, "create snapshot"))
+
+	inspectSnapshot := &cobra.Command{
+		Use:   "{{alias}} <name>",
+		Short: "Return info about a snapshot.",
+		Long:  "Return info about a snapshot, including the head of each branch and the spec commit of each pipeline that it recorded.",
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			snapshotInfo, err := c.InspectSnapshot(args[0])
+			if err != nil {
+				return err
+			}
+			if raw {
+				return marshaller.Marshal(os.Stdout, snapshotInfo)
+			}
+			si := &pretty.PrintableSnapshotInfo{
+				SnapshotInfo:   snapshotInfo,
+				FullTimestamps: fullTimestamps,
+			}
+			return pretty.PrintDetailedSnapshotInfo(si)
+		}),
+	}
+	inspectSnapshot.Flags().AddFlagSet(rawFlags)
+	inspectSnapshot.Flags().AddFlagSet(fullTimestampsFlags)
+	commands = append(commands, cmdutil.CreateAlias(inspectSnapshot, "inspect snapshot"))
+
+	var restoreRepos []string
+	restoreSnapshot := &cobra.Command{
+		Use:   "{{alias}} <name>",
+		Short: "Point branches back at their heads in a snapshot.",
+		Long: `Point branches back at the commits that they pointed to when a snapshot was created.
+
+Branches keep their current provenance, and branches that were deleted after
+the snapshot was created are re-created. Pipelines aren't restored: if a
+pipeline has been updated since the snapshot, its output branch gets a new
+commit that processes the restored inputs with its current spec. To reproduce
+its output exactly, first roll the pipeline back (see 'pachctl rollback
+pipeline') to the version it had when the snapshot was created.`,
+		Example: `
+# Restore every branch to its head in snapshot "release-12"
+$ {{alias}} release-12
+
+# Restore only the branches in repos "images" and "labels"
+$ {{alias}} release-12 --repo images --repo labels`,
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			return c.RestoreBranchesFromSnapshot(args[0], restoreRepos...)
+		}),
+	}
+	restoreSnapshot.Flags().StringSliceVar(&restoreRepos, "repo", nil, "Only restore the branches in this repo (may be repeated or comma-separated).")
+	commands = append(commands, cmdutil.CreateAlias(restoreSnapshot, "restore-branches snapshot"))
+
 	fileDocs := &cobra.Command{
 		Short: "Docs for files.",
 		Long: `Files are the lowest level data objects in Pachyderm.
@@ -732,6 +987,8 @@ from commits with 'get file'.`,
 	var targetFileDatums uint
 	var targetFileBytes uint
 	var headerRecords uint
+	var urlSecret string
+	var expectedSHA256 string
 	var putFileCommit bool
 	var overwrite bool
 	putFile := &cobra.Command{
@@ -769,6 +1026,14 @@ $ {{alias}} repo@branch -f http://host/path
 # Put the data from an S3 bucket as repo/branch/s3_object:
 $ {{alias}} repo@branch -r -f s3://my_bucket
 
+# Put the data from a URL that requires a bearer token (stored in the 'token'
+# key of the secret 'drop-creds'), checking its content against a SHA-256:
+$ {{alias}} repo@branch:/path -f https://host/path --url-secret drop-creds --sha256 <hex>
+
+# Put the files in a directory on an SFTP server, logging in with the
+# password or private key in the secret 'sftp-creds':
+$ {{alias}} repo@branch:/drop -r -f sftp://user@host/outgoing --url-secret sftp-creds
+
 # Put several files or URLs that are listed in file.
 # Files and URLs should be newline delimited.
 $ {{alias}} repo@branch -i file
@@ -855,19 +1120,19 @@ $ {{alias}} repo@branch -i http://host/path`,
 						return errors.Errorf("must specify filename when reading data from stdin")
 					}
 					eg.Go(func() error {
-						return putFileHelper(c, pfc, file.Commit.Repo.Name, file.Commit.ID, joinPaths("", source), source, recursive, overwrite, limiter, split, targetFileDatums, targetFileBytes, headerRecords, filesPut)
+						return putFileHelper(c, pfc, file.Commit.Repo.Name, file.Commit.ID, joinPaths("", source), source, recursive, overwrite, limiter, split, targetFileDatums, targetFileBytes, headerRecords, urlSecret, expectedSHA256, filesPut)
 					})
 				} else if len(sources) == 1 {
 					// We have a single source and the user has specified a path,
 					// we use the path and ignore source (in terms of naming the file).
 					eg.Go(func() error {
-						return putFileHelper(c, pfc, file.Commit.Repo.Name, file.Commit.ID, file.Path, source, recursive, overwrite, limiter, split, targetFileDatums, targetFileBytes, headerRecords, filesPut)
+						return putFileHelper(c, pfc, file.Commit.Repo.Name, file.Commit.ID, file.Path, source, recursive, overwrite, limiter, split, targetFileDatums, targetFileBytes, headerRecords, urlSecret, expectedSHA256, filesPut)
 					})
 				} else {
 					// We have multiple sources and the user has specified a path,
 					// we use that path as a prefix for the filepaths.
 					eg.Go(func() error {
-						return putFileHelper(c, pfc, file.Commit.Repo.Name, file.Commit.ID, joinPaths(file.Path, source), source, recursive, overwrite, limiter, split, targetFileDatums, targetFileBytes, headerRecords, filesPut)
+						return putFileHelper(c, pfc, file.Commit.Repo.Name, file.Commit.ID, joinPaths(file.Path, source), source, recursive, overwrite, limiter, split, targetFileDatums, targetFileBytes, headerRecords, urlSecret, expectedSHA256, filesPut)
 					})
 				}
 			}
@@ -882,6 +1147,8 @@ $ {{alias}} repo@branch -i http://host/path`,
 	putFile.Flags().UintVar(&targetFileDatums, "target-file-datums", 0, "The upper bound of the number of datums that each file contains, the last file will contain fewer if the datums don't divide evenly; needs to be used with --split.")
 	putFile.Flags().UintVar(&targetFileBytes, "target-file-bytes", 0, "The target upper bound of the number of bytes that each file contains; needs to be used with --split.")
 	putFile.Flags().UintVar(&headerRecords, "header-records", 0, "the number of records that will be converted to a PFS 'header', and prepended to future retrievals of any subset of data from PFS; needs to be used with --split=(json|line|csv)")
+	putFile.Flags().StringVar(&urlSecret, "url-secret", "", "The name of a secret (created with 'create secret') holding the credentials used to fetch a URL: 'token' or 'username' and 'password' for http(s), and 'password' or 'ssh_private_key' for sftp, which also requires 'known_hosts'.")
+	putFile.Flags().StringVar(&expectedSHA256, "sha256", "", "The expected hex SHA-256 of the content at a URL; the put fails if it doesn't match.")
 	putFile.Flags().BoolVarP(&putFileCommit, "commit", "c", false, "DEPRECATED: Put file(s) in a new commit.")
 	putFile.Flags().BoolVarP(&overwrite, "overwrite", "o", false, "Overwrite the existing content of the file, either from previous commits or previous calls to 'put file' within this commit.")
 	shell.RegisterCompletionFunc(putFile,
@@ -942,7 +1209,11 @@ $ {{alias}} foo@master^:XXX
 
 # get file "XXX" in the grandparent of the current head of branch "master"
 # in repo "foo"
-$ {{alias}} foo@master^2:XXX`,
+$ {{alias}} foo@master^2:XXX
+
+# get file "XXX" in the last commit on branch "master" in repo "foo" that
+# finished by midnight UTC on May 1st, 2020
+$ {{alias}} foo@master@{2020-05-01T00:00:00Z}:XXX`,
 		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
 			file, err := cmdutil.ParseFile(args[0])
 			if err != nil {
@@ -981,6 +1252,76 @@ $ {{alias}} foo@master^2:XXX`,
 	shell.RegisterCompletionFunc(getFile, shell.FileCompletion)
 	commands = append(commands, cmdutil.CreateAlias(getFile, "get file"))
 
+	var syncDelete bool
+	var syncDryRun bool
+	syncCmd := &cobra.Command{
+		Use:   "{{alias}} (<local-dir> <repo>@<branch>[:<path>] | <repo>@<branch-or-commit>[:<path>] <local-dir>)",
+		Short: "Sync a local directory with a directory in PFS.",
+		Long: `Sync a local directory with a directory in PFS, in the direction given by
+the order of the arguments. Only files whose content differs (as determined by
+comparing the hashes of local files with the hashes of files in PFS) are
+transferred. When uploading, all changes are made in a single commit (and no
+commit is made if nothing changed).`,
+		Example: `
+# upload the changes in ./data to the "data" directory of the master branch of
+# repo "foo"
+$ {{alias}} ./data foo@master:data
+
+# also delete files from foo@master:data that aren't in ./data
+$ {{alias}} ./data foo@master:data --delete
+
+# see which files would be downloaded from foo@master into ./data
+$ {{alias}} foo@master ./data --dry-run`,
+		Run: cmdutil.RunFixedArgs(2, func(args []string) error {
+			var upload bool
+			var localDir string
+			var file *pfsclient.File
+			var err error
+			switch {
+			case strings.Contains(args[1], "@"):
+				upload, localDir = true, args[0]
+				file, err = cmdutil.ParseFile(args[1])
+			case strings.Contains(args[0], "@"):
+				localDir = args[1]
+				file, err = cmdutil.ParseFile(args[0])
+			default:
+				return errors.New("one argument must be of the form <repo>@<branch>[:<path>]")
+			}
+			if err != nil {
+				return err
+			}
+			c, err := client.NewOnUserMachine("user", client.WithMaxConcurrentStreams(parallelism))
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			var changes []*sync.Change
+			if upload {
+				if uuid.IsUUIDWithoutDashes(file.Commit.ID) {
+					return errors.New("can only upload to a branch, not a commit")
+				}
+				changes, err = sync.Upload(c, localDir, file.Commit.Repo.Name, file.Commit.ID, file.Path, syncDelete, syncDryRun, parallelism)
+			} else {
+				changes, err = sync.Download(c, localDir, file.Commit.Repo.Name, file.Commit.ID, file.Path, syncDelete, syncDryRun, parallelism)
+			}
+			if err != nil {
+				return err
+			}
+			for _, change := range changes {
+				fmt.Printf("%s\t%s\n", change.Type, change.Path)
+			}
+			if syncDryRun {
+				fmt.Fprintf(os.Stderr, "dry run: %d file(s) would be changed\n", len(changes))
+			}
+			return nil
+		}),
+	}
+	syncCmd.Flags().BoolVar(&syncDelete, "delete", false, "Delete files from the destination that aren't in the source.")
+	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Print the changes that would be made, without making them.")
+	syncCmd.Flags().IntVarP(&parallelism, "parallelism", "p", DefaultParallelism, "The maximum number of files that can be hashed or transferred in parallel.")
+	commands = append(commands, cmdutil.CreateAlias(syncCmd, "sync"))
+
 	inspectFile := &cobra.Command{
 		Use:   "{{alias}} <repo>@<branch-or-commit>:<path/in/pfs>",
 		Short: "Return info about a file.",
@@ -1032,6 +1373,10 @@ $ {{alias}} foo@master^
 # in repo "foo"
 $ {{alias}} foo@master^2
 
+# list top-level files in the last commit on branch "master" in repo "foo"
+# that finished by midnight UTC on May 1st, 2020
+$ {{alias}} foo@master@{2020-05-01T00:00:00Z}
+
 # list the last n versions of top-level files on branch "master" in repo "foo"
 $ {{alias}} foo@master --history n
 
@@ -1125,6 +1470,8 @@ $ {{alias}} "foo@master:data/*"`,
 	var shallow bool
 	var nameOnly bool
 	var diffCmdArg string
+	var content bool
+	var maxContentBytes int64
 	diffFile := &cobra.Command{
 		Use:   "{{alias}} <new-repo>@<new-branch-or-commit>:<new-path> [<old-repo>@<old-branch-or-commit>:<old-path>]",
 		Short: "Return a diff of two file trees.",
@@ -1136,7 +1483,11 @@ $ {{alias}} foo@master:path
 
 # Return the diff between the master branches of repos foo and bar at paths
 # path1 and path2, respectively.
-$ {{alias}} foo@master:path1 bar@master:path2`,
+$ {{alias}} foo@master:path1 bar@master:path2
+
+# Return the diff of the content of files under "labels" between two commits,
+# computed by pachd (CSV files are diffed by row).
+$ {{alias}} foo@master:labels foo@master^:labels --content`,
 		Run: cmdutil.RunBoundedArgs(1, 2, func(args []string) error {
 			newFile, err := cmdutil.ParseFile(args[0])
 			if err != nil {
@@ -1155,6 +1506,21 @@ $ {{alias}} foo@master:path1 bar@master:path2`,
 			}
 			defer c.Close()
 
+			if content {
+				if nameOnly || diffCmdArg != "" {
+					return errors.New("--content can't be used with --name-only or --diff-command")
+				}
+				return pager.Page(noPager, os.Stdout, func(w io.Writer) error {
+					return c.DiffFileContentF(
+						newFile.Commit.Repo.Name, newFile.Commit.ID, newFile.Path,
+						oldFile.Commit.Repo.Name, oldFile.Commit.ID, oldFile.Path,
+						shallow, maxContentBytes, func(diff *pfsclient.FileContentDiff) error {
+							pretty.PrintFileContentDiff(w, diff)
+							return nil
+						},
+					)
+				})
+			}
 			return pager.Page(noPager, os.Stdout, func(w io.Writer) (retErr error) {
 				var writer *tabwriter.Writer
 				if nameOnly {
@@ -1221,6 +1587,8 @@ $ {{alias}} foo@master:path1 bar@master:path2`,
 	diffFile.Flags().BoolVarP(&shallow, "shallow", "s", false, "Don't descend into sub directories.")
 	diffFile.Flags().BoolVar(&nameOnly, "name-only", false, "Show only the names of changed files.")
 	diffFile.Flags().StringVar(&diffCmdArg, "diff-command", "", "Use a program other than git to diff files.")
+	diffFile.Flags().BoolVar(&content, "content", false, "Diff the content of files in pachd, rather than downloading them and diffing them locally. CSV and TSV files are diffed by row.")
+	diffFile.Flags().Int64Var(&maxContentBytes, "max-content-bytes", 0, "With --content, the largest file to diff (default 1MB, at most 2MB).")
 	diffFile.Flags().AddFlagSet(fullTimestampsFlags)
 	diffFile.Flags().AddFlagSet(noPagerFlags)
 	shell.RegisterCompletionFunc(diffFile, shell.FileCompletion)
@@ -1294,10 +1662,14 @@ Tags are a low-level resource and should not be accessed directly by most users.
 	commands = append(commands, cmdutil.CreateAlias(getTag, "get tag"))
 
 	var fix bool
+	var deep bool
+	var sampleRate float64
 	fsck := &cobra.Command{
 		Use:   "{{alias}}",
 		Short: "Run a file system consistency check on pfs.",
-		Long:  "Run a file system consistency check on the pachyderm file system, ensuring the correct provenance relationships are satisfied.",
+		Long: `Run a file system consistency check on the pachyderm file system, ensuring the correct provenance relationships are satisfied.
+
+With --deep, it also checks that the trees, objects and blocks referenced by each finished commit exist in object storage, and verifies the content of a --sample-rate fraction of them. With --fix, commits whose data has problems are quarantined: their files can't be read, and jobs that use them as inputs fail, until a later deep fsck with --fix finds no problems.`,
 		Run: cmdutil.RunFixedArgs(0, func(args []string) error {
 			c, err := client.NewOnUserMachine("user")
 			if err != nil {
@@ -1305,7 +1677,7 @@ Tags are a low-level resource and should not be accessed directly by most users.
 			}
 			defer c.Close()
 			errors := false
-			if err = c.Fsck(fix, func(resp *pfsclient.FsckResponse) error {
+			cb := func(resp *pfsclient.FsckResponse) error {
 				if resp.Error != "" {
 					errors = true
 					fmt.Printf("Error: %s\n", resp.Error)
@@ -1313,7 +1685,13 @@ Tags are a low-level resource and should not be accessed directly by most users.
 					fmt.Printf("Fix applied: %v", resp.Fix)
 				}
 				return nil
-			}); err != nil {
+			}
+			if deep {
+				err = c.FsckDeep(fix, sampleRate, cb)
+			} else {
+				err = c.Fsck(fix, cb)
+			}
+			if err != nil {
 				return err
 			}
 			if !errors {
@@ -1323,8 +1701,69 @@ Tags are a low-level resource and should not be accessed directly by most users.
 		}),
 	}
 	fsck.Flags().BoolVarP(&fix, "fix", "f", false, "Attempt to fix as many issues as possible.")
+	fsck.Flags().BoolVar(&deep, "deep", false, "Also check that the data referenced by each commit exists in object storage.")
+	fsck.Flags().Float64Var(&sampleRate, "sample-rate", 0, "The fraction (from 0 to 1) of objects and blocks whose content is verified by --deep; the rest are only checked for existence.")
 	commands = append(commands, cmdutil.CreateAlias(fsck, "fsck"))
 
+	printReplicationInfo := func(info *pfsclient.ReplicationInfo) error {
+		if raw {
+			return marshaller.Marshal(os.Stdout, info)
+		}
+		writer := tabwriter.NewWriter(os.Stdout, pretty.ReplicaHeader)
+		for _, replicaInfo := range info.Replicas {
+			pretty.PrintReplicaInfo(writer, replicaInfo)
+		}
+		return writer.Flush()
+	}
+
+	replicationDocs := &cobra.Command{
+		Short: "Docs for object storage replication.",
+		Long: `When pachd is deployed with --storage-replica, everything it and the workers write to object storage is also written to each replica, asynchronously.
+
+Writes that haven't been applied to a replica yet are recorded in a replay log in the primary object store, and are retried until they succeed. If the primary object store fails, reads fall back to the replicas.`,
+	}
+	commands = append(commands, cmdutil.CreateDocsAlias(replicationDocs, "replication", " replication$"))
+
+	inspectReplication := &cobra.Command{
+		Use:   "{{alias}}",
+		Short: "Show how far behind each object storage replica is.",
+		Long:  "Show how far behind each object storage replica is: the number of writes and deletes that haven't been applied to it yet, and the age of the oldest of them.",
+		Run: cmdutil.RunFixedArgs(0, func(args []string) error {
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			info, err := c.InspectReplication()
+			if err != nil {
+				return err
+			}
+			return printReplicationInfo(info)
+		}),
+	}
+	inspectReplication.Flags().AddFlagSet(rawFlags)
+	commands = append(commands, cmdutil.CreateAlias(inspectReplication, "inspect replication"))
+
+	reconcileReplication := &cobra.Command{
+		Use:   "{{alias}}",
+		Short: "Copy missing objects to the object storage replicas.",
+		Long:  "Copy each object that's missing from an object storage replica to it (e.g. objects written before replication was enabled), and apply the replica's pending writes and deletes. Objects that only exist in a replica are left alone.",
+		Run: cmdutil.RunFixedArgs(0, func(args []string) error {
+			c, err := client.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			info, err := c.ReconcileReplication()
+			if err != nil {
+				return err
+			}
+			return printReplicationInfo(info)
+		}),
+	}
+	reconcileReplication.Flags().AddFlagSet(rawFlags)
+	commands = append(commands, cmdutil.CreateAlias(reconcileReplication, "reconcile replication"))
+
 	// Add the mount commands (which aren't available on Windows, so they're in
 	// their own file)
 	commands = append(commands, mountCmds()...)
@@ -1336,6 +1775,7 @@ func putFileHelper(c *client.APIClient, pfc client.PutFileClient,
 	repo, commit, path, source string, recursive, overwrite bool, // destination
 	limiter limit.ConcurrencyLimiter,
 	split string, targetFileDatums, targetFileBytes, headerRecords uint, // split
+	urlSecret, expectedSHA256 string, // url
 	filesPut *gosync.Map) (retErr error) {
 	// Resolve the path, then trim any prefixed '../' to avoid sending bad paths
 	// to the server
@@ -1398,7 +1838,10 @@ func putFileHelper(c *client.APIClient, pfc client.PutFileClient,
 	if url, err := url.Parse(source); err == nil && url.Scheme != "" {
 		limiter.Acquire()
 		defer limiter.Release()
-		return pfc.PutFileURL(repo, commit, path, url.String(), recursive, overwrite)
+		return pfc.PutFileURLWithSecret(repo, commit, path, url.String(), recursive, overwrite, urlSecret, expectedSHA256)
+	}
+	if urlSecret != "" || expectedSHA256 != "" {
+		return errors.New("--url-secret and --sha256 can only be used when putting a URL")
 	}
 	if recursive {
 		var eg errgroup.Group
@@ -1417,7 +1860,7 @@ func putFileHelper(c *client.APIClient, pfc client.PutFileClient,
 				// next one
 				return putFileHelper(c, pfc, repo, commit, childDest, filePath, false,
 					overwrite, limiter, split, targetFileDatums, targetFileBytes,
-					headerRecords, filesPut)
+					headerRecords, "", "", filesPut)
 			})
 			return nil
 		}); err != nil {
@@ -56,6 +56,13 @@ type ErrParentCommitNotFound struct {
 	Commit *pfs.Commit
 }
 
+// ErrCommitQuarantined represents an error where a commit's data can't be
+// read because a deep fsck found problems with it
+type ErrCommitQuarantined struct {
+	Commit *pfs.Commit
+	Reason string
+}
+
 // ErrOutputCommitNotFinished represents an error where the commit has not
 // been finished
 type ErrOutputCommitNotFinished struct {
@@ -99,6 +106,10 @@ func (e ErrParentCommitNotFound) Error() string {
 	return fmt.Sprintf("parent commit %v not found in repo %v", e.Commit.ID, e.Commit.Repo.Name)
 }
 
+func (e ErrCommitQuarantined) Error() string {
+	return fmt.Sprintf("commit %v in repo %v is quarantined: %s", e.Commit.ID, e.Commit.Repo.Name, e.Reason)
+}
+
 func (e ErrOutputCommitNotFinished) Error() string {
 	return fmt.Sprintf("output commit %v not finished", e.Commit.ID)
 }
@@ -117,6 +128,7 @@ var (
 	fileNotFoundRe            = regexp.MustCompile(`file .+ not found`)
 	hasNoHeadRe               = regexp.MustCompile(`the branch .+ has no head \(create one with 'start commit'\)`)
 	outputCommitNotFinishedRe = regexp.MustCompile("output commit .+ not finished")
+	commitQuarantinedRe       = regexp.MustCompile("commit [^ ]+ in repo [^ ]+ is quarantined")
 )
 
 // IsCommitNotFoundErr returns true if 'err' has an error message that matches
@@ -146,6 +158,15 @@ func IsCommitFinishedErr(err error) bool {
 	return commitFinishedRe.MatchString(grpcutil.ScrubGRPC(err).Error())
 }
 
+// IsCommitQuarantinedErr returns true if 'err' has an error message that
+// matches ErrCommitQuarantined
+func IsCommitQuarantinedErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	return commitQuarantinedRe.MatchString(grpcutil.ScrubGRPC(err).Error())
+}
+
 // IsRepoNotFoundErr returns true if 'err' is an error message about a repo
 // not being found
 func IsRepoNotFoundErr(err error) bool {
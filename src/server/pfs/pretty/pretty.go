@@ -5,6 +5,9 @@ import (
 	"html/template"
 	"io"
 	"os"
+	"path"
+	"sort"
+	"strings"
 
 	units "github.com/docker/go-units"
 	"github.com/fatih/color"
@@ -27,6 +30,8 @@ const (
 	FileHeaderWithCommit = "COMMIT\tNAME\tTYPE\tCOMMITTED\tSIZE\t\n"
 	// DiffFileHeader is the header for files produced by diff file.
 	DiffFileHeader = "OP\t" + FileHeader
+	// ReplicaHeader is the header for object storage replicas.
+	ReplicaHeader = "REPLICA\tPENDING\tLAG\tCOPIED\t\n"
 )
 
 // PrintRepoInfo pretty-prints repo info.
@@ -63,7 +68,8 @@ func NewPrintableRepoInfo(ri *pfs.RepoInfo) *PrintableRepoInfo {
 func PrintDetailedRepoInfo(repoInfo *PrintableRepoInfo) error {
 	template, err := template.New("RepoInfo").Funcs(funcMap).Parse(
 		`Name: {{.Repo.Name}}{{if .Description}}
-Description: {{.Description}}{{end}}{{if .FullTimestamps}}
+Description: {{.Description}}{{end}}{{if .Labels}}
+Labels: {{prettyLabels .Labels}}{{end}}{{if .FullTimestamps}}
 Created: {{.Created}}{{else}}
 Created: {{prettyAgo .Created}}{{end}}
 Size of HEAD on master: {{prettySize .SizeBytes}}{{if .AuthInfo}}
@@ -107,6 +113,31 @@ Provenance: {{range .Provenance}} {{.Repo.Name}}@{{.Name}} {{end}} {{end}}
 	return nil
 }
 
+// PrintableSnapshotInfo is a wrapper around SnapshotInfo containing any
+// formatting options used within the template to conditionally print
+// information.
+type PrintableSnapshotInfo struct {
+	*pfs.SnapshotInfo
+	FullTimestamps bool
+}
+
+// PrintDetailedSnapshotInfo pretty-prints detailed snapshot info.
+func PrintDetailedSnapshotInfo(snapshotInfo *PrintableSnapshotInfo) error {
+	template, err := template.New("SnapshotInfo").Funcs(funcMap).Parse(
+		`Name: {{.Snapshot.Name}}{{if .FullTimestamps}}
+Created: {{.Created}}{{else}}
+Created: {{prettyAgo .Created}}{{end}}{{if .Branches}}
+Branches:{{range .Branches}}
+  {{.Branch.Repo.Name}}@{{.Branch.Name}}: {{if .Head}}{{.Head.ID}}{{else}}-{{end}}{{if .Job}} (job {{.Job}}){{end}}{{end}}{{end}}{{if .Pipelines}}
+Pipelines:{{range .Pipelines}}
+  {{.Pipeline}}: {{.SpecCommit.ID}}{{end}}{{end}}
+`)
+	if err != nil {
+		return err
+	}
+	return template.Execute(os.Stdout, snapshotInfo)
+}
+
 // PrintCommitInfo pretty-prints commit info.
 func PrintCommitInfo(w io.Writer, commitInfo *pfs.CommitInfo, fullTimestamps bool) {
 	fmt.Fprintf(w, "%s\t", commitInfo.Commit.Repo.Name)
@@ -162,7 +193,9 @@ func PrintDetailedCommitInfo(commitInfo *PrintableCommitInfo) error {
 	template, err := template.New("CommitInfo").Funcs(funcMap).Parse(
 		`Commit: {{.Commit.Repo.Name}}@{{.Commit.ID}}{{if .Branch}}
 Original Branch: {{.Branch.Name}}{{end}}{{if .Description}}
-Description: {{.Description}}{{end}}{{if .ParentCommit}}
+Description: {{.Description}}{{end}}{{if .Labels}}
+Labels: {{prettyLabels .Labels}}{{end}}{{if .Quarantined}}
+Quarantined: {{.Quarantined}}{{end}}{{if .ParentCommit}}
 Parent: {{.ParentCommit.ID}}{{end}}{{if .FullTimestamps}}
 Started: {{.Started}}{{else}}
 Started: {{prettyAgo .Started}}{{end}}{{if .Finished}}{{if .FullTimestamps}}
@@ -217,6 +250,48 @@ func PrintDiffFileInfo(w io.Writer, added bool, fileInfo *pfs.FileInfo, fullTime
 	PrintFileInfo(w, fileInfo, fullTimestamps, false)
 }
 
+// PrintFileContentDiff pretty-prints how the content of a file changed, as a
+// unified diff (or, for CSV files, the rows that were added and deleted).
+func PrintFileContentDiff(w io.Writer, diff *pfs.FileContentDiff) {
+	oldName, newName := "/dev/null", "/dev/null"
+	if diff.OldFile != nil {
+		oldName = path.Join("a", diff.OldFile.Path)
+	}
+	if diff.NewFile != nil {
+		newName = path.Join("b", diff.NewFile.Path)
+	}
+	switch {
+	case diff.Binary:
+		fmt.Fprintf(w, "Binary files %s and %s differ\n", oldName, newName)
+	case diff.TooLarge:
+		fmt.Fprintf(w, "Files %s and %s differ (too large to diff)\n", oldName, newName)
+	case diff.Unified != "":
+		for _, line := range strings.SplitAfter(diff.Unified, "\n") {
+			switch {
+			case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+				fmt.Fprint(w, line)
+			case strings.HasPrefix(line, "+"):
+				fmt.Fprint(w, color.GreenString(line))
+			case strings.HasPrefix(line, "-"):
+				fmt.Fprint(w, color.RedString(line))
+			case strings.HasPrefix(line, "@@"):
+				fmt.Fprint(w, color.CyanString(line))
+			default:
+				fmt.Fprint(w, line)
+			}
+		}
+	case len(diff.AddedRows) > 0 || len(diff.DeletedRows) > 0:
+		fmt.Fprintf(w, "--- %s\n+++ %s\n", oldName, newName)
+		fmt.Fprintf(w, "@@ %d rows deleted, %d rows added @@\n", len(diff.DeletedRows), len(diff.AddedRows))
+		for _, row := range diff.DeletedRows {
+			fmt.Fprint(w, color.RedString("-%s\n", row))
+		}
+		for _, row := range diff.AddedRows {
+			fmt.Fprint(w, color.GreenString("+%s\n", row))
+		}
+	}
+}
+
 // PrintDetailedFileInfo pretty-prints detailed file info.
 func PrintDetailedFileInfo(fileInfo *pfs.FileInfo) error {
 	template, err := template.New("FileInfo").Funcs(funcMap).Parse(
@@ -231,6 +306,20 @@ Children: {{range .Children}} {{.}} {{end}}
 	return template.Execute(os.Stdout, fileInfo)
 }
 
+// PrintReplicaInfo pretty-prints the state of an object storage replica.
+// Its lag is the age of its oldest pending replication.
+func PrintReplicaInfo(w io.Writer, replicaInfo *pfs.ReplicaInfo) {
+	fmt.Fprintf(w, "%s\t", replicaInfo.Replica)
+	fmt.Fprintf(w, "%d\t", replicaInfo.Pending)
+	if replicaInfo.OldestPending == nil {
+		fmt.Fprintf(w, "-\t")
+	} else {
+		fmt.Fprintf(w, "%s\t", pretty.Since(replicaInfo.OldestPending))
+	}
+	fmt.Fprintf(w, "%d\t", replicaInfo.Copied)
+	fmt.Fprintln(w)
+}
+
 func fileType(fileType pfs.FileType) string {
 	if fileType == pfs.FileType_FILE {
 		return "file"
@@ -238,10 +327,21 @@ func fileType(fileType pfs.FileType) string {
 	return "dir"
 }
 
+// formatLabels renders 'labels' as a selector, e.g. "key1=value1,key2=value2"
+func formatLabels(labels map[string]string) string {
+	var result []string
+	for k, v := range labels {
+		result = append(result, k+"="+v)
+	}
+	sort.Strings(result)
+	return strings.Join(result, ",")
+}
+
 var funcMap = template.FuncMap{
-	"prettyAgo":  pretty.Ago,
-	"prettySize": pretty.Size,
-	"fileType":   fileType,
+	"prettyAgo":    pretty.Ago,
+	"prettySize":   pretty.Size,
+	"fileType":     fileType,
+	"prettyLabels": formatLabels,
 }
 
 // CompactPrintBranch renders 'b' as a compact string, e.g.
@@ -158,6 +158,9 @@ func (c *controller) CreateBucket(r *http.Request, bucketName string) error {
 	if err != nil {
 		return err
 	}
+	if isTimeTravelBucket(bucket) {
+		return s2.InvalidBucketNameError(r)
+	}
 
 	err = pc.CreateRepo(bucket.Repo)
 	if err != nil {
@@ -206,6 +209,9 @@ func (c *controller) DeleteBucket(r *http.Request, bucketName string) error {
 	if err != nil {
 		return err
 	}
+	if isTimeTravelBucket(bucket) {
+		return s2.InvalidBucketNameError(r)
+	}
 
 	// `DeleteBranch` does not return an error if a non-existing branch is
 	// deleting. So first, we verify that the branch exists so we can
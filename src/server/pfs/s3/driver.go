@@ -8,6 +8,8 @@ import (
 
 	"github.com/pachyderm/pachyderm/src/client"
 	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	pfsServer "github.com/pachyderm/pachyderm/src/server/pfs"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ancestry"
 
 	"github.com/gogo/protobuf/types"
 	"github.com/pachyderm/s2"
@@ -70,20 +72,43 @@ func (d *MasterDriver) listBuckets(pc *client.APIClient, r *http.Request, bucket
 }
 
 func (d *MasterDriver) bucket(pc *client.APIClient, r *http.Request, name string) (*Bucket, error) {
-	parts := strings.SplitN(name, ".", 2)
-	if len(parts) != 2 {
+	parts := strings.Split(name, ".")
+	var repo, commit string
+	switch len(parts) {
+	case 2:
+		commit, repo = parts[0], parts[1]
+	case 3:
+		// "<time>.<branch>.<repo>" is the branch as of <time>
+		t, err := parseBucketTime(parts[0])
+		if err != nil {
+			return nil, s2.InvalidBucketNameError(r)
+		}
+		commit, repo = ancestry.AddTime(parts[1], t), parts[2]
+	default:
 		return nil, s2.InvalidBucketNameError(r)
 	}
-	repo := parts[1]
-	branch := parts[0]
 	return &Bucket{
 		Repo:   repo,
-		Commit: branch,
+		Commit: commit,
 		Name:   name,
 	}, nil
 }
 
 func (d *MasterDriver) bucketCapabilities(pc *client.APIClient, r *http.Request, bucket *Bucket) (bucketCapabilities, error) {
+	if isTimeTravelBucket(bucket) {
+		// Buckets that refer to a branch as of a time are read-only snapshots
+		if _, err := pc.InspectCommit(bucket.Repo, bucket.Commit); err != nil {
+			if pfsServer.IsCommitNotFoundErr(err) {
+				return bucketCapabilities{}, s2.NoSuchBucketError(r)
+			}
+			return bucketCapabilities{}, maybeNotFoundError(r, err)
+		}
+		return bucketCapabilities{
+			readable:         true,
+			writable:         false,
+			historicVersions: false,
+		}, nil
+	}
 	branchInfo, err := pc.InspectBranch(bucket.Repo, bucket.Commit)
 	if err != nil {
 		return bucketCapabilities{}, maybeNotFoundError(r, err)
@@ -100,6 +125,30 @@ func (d *MasterDriver) canModifyBuckets() bool {
 	return true
 }
 
+// bucketTimeLayouts are the formats of the time in a time-travel bucket name,
+// which are restricted to lowercase letters and digits
+var bucketTimeLayouts = []string{"20060102t150405z", "20060102"}
+
+// parseBucketTime parses the time in a time-travel bucket name, e.g.
+// "20200501t000000z" or "20200501", as UTC
+func parseBucketTime(s string) (time.Time, error) {
+	var err error
+	for _, layout := range bucketTimeLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// isTimeTravelBucket returns true if 'bucket' refers to a branch as of a time
+// (see MasterDriver.bucket)
+func isTimeTravelBucket(bucket *Bucket) bool {
+	_, at, err := ancestry.ParseTime(bucket.Commit)
+	return err == nil && at != nil
+}
+
 // WorkerDriver is the driver for the s3gateway instance running on pachd
 // workers
 type WorkerDriver struct {
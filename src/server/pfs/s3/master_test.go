@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
@@ -12,6 +13,7 @@ import (
 	minio "github.com/minio/minio-go"
 	"github.com/pachyderm/pachyderm/src/client"
 	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ancestry"
 	tu "github.com/pachyderm/pachyderm/src/server/pkg/testutil"
 )
 
@@ -423,6 +425,64 @@ func masterAuthV2(t *testing.T, pachClient *client.APIClient, minioClient *minio
 	require.NoError(t, err)
 }
 
+func masterTimeTravelBucket(t *testing.T, pachClient *client.APIClient, minioClient *minio.Client) {
+	repo := tu.UniqueString("testtimetravelbucket")
+	require.NoError(t, pachClient.CreateRepo(repo))
+	_, err := pachClient.PutFile(repo, "master", "file", strings.NewReader("old"))
+	require.NoError(t, err)
+	// Bucket times have a granularity of seconds
+	at := time.Now().UTC().Add(time.Second).Truncate(time.Second)
+	time.Sleep(2 * time.Second)
+	_, err = pachClient.PutFileOverwrite(repo, "master", "file", strings.NewReader("new"), 0)
+	require.NoError(t, err)
+
+	bucket := fmt.Sprintf("%s.master.%s", at.Format("20060102t150405z"), repo)
+	fetchedContent, err := getObject(t, minioClient, bucket, "file")
+	require.NoError(t, err)
+	require.Equal(t, "old", fetchedContent)
+	fetchedContent, err = getObject(t, minioClient, fmt.Sprintf("master.%s", repo), "file")
+	require.NoError(t, err)
+	require.Equal(t, "new", fetchedContent)
+
+	// Time-travel buckets are read-only
+	_, err = minioClient.PutObject(bucket, "file", strings.NewReader("content"), int64(len("content")), minio.PutObjectOptions{ContentType: "text/plain"})
+	require.YesError(t, err)
+	require.YesError(t, minioClient.MakeBucket(bucket, ""))
+	require.YesError(t, minioClient.RemoveBucket(bucket))
+
+	// A time before the branch's first commit has no bucket
+	_, err = getObject(t, minioClient, fmt.Sprintf("20000101.master.%s", repo), "file")
+	bucketNotFoundError(t, err)
+}
+
+func TestMasterDriverBucket(t *testing.T) {
+	d := NewMasterDriver()
+	r := httptest.NewRequest("GET", "/", nil)
+	bucket, err := d.bucket(nil, r, "master.images")
+	require.NoError(t, err)
+	require.Equal(t, "images", bucket.Repo)
+	require.Equal(t, "master", bucket.Commit)
+	require.False(t, isTimeTravelBucket(bucket))
+
+	at := time.Date(2020, 5, 1, 12, 30, 0, 0, time.UTC)
+	for name, expected := range map[string]string{
+		"20200501t123000z.master.images": ancestry.AddTime("master", at),
+		"20200501.master.images":         ancestry.AddTime("master", at.Truncate(24*time.Hour)),
+	} {
+		bucket, err := d.bucket(nil, r, name)
+		require.NoError(t, err)
+		require.Equal(t, "images", bucket.Repo)
+		require.Equal(t, expected, bucket.Commit)
+		require.Equal(t, name, bucket.Name)
+		require.True(t, isTimeTravelBucket(bucket))
+	}
+
+	for _, name := range []string{"images", "yesterday.master.images", "2020-05-01.master.images", "20200501.master.images.extra"} {
+		_, err := d.bucket(nil, r, name)
+		require.YesError(t, err, name)
+	}
+}
+
 func TestMasterDriver(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration tests in short mode")
@@ -498,5 +558,8 @@ func TestMasterDriver(t *testing.T) {
 		t.Run("AuthV2", func(t *testing.T) {
 			masterAuthV2(t, pachClient, minioClient)
 		})
+		t.Run("TimeTravelBucket", func(t *testing.T) {
+			masterTimeTravelBucket(t, pachClient, minioClient)
+		})
 	})
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/gogo/protobuf/types"
 	"github.com/pachyderm/pachyderm/src/client"
 	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
 	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
 	"github.com/pachyderm/pachyderm/src/client/pkg/tracing"
 	"github.com/pachyderm/pachyderm/src/server/pkg/hashtree"
@@ -60,7 +61,7 @@ func (a *apiServer) CreateRepoInTransaction(
 	txnCtx *txnenv.TransactionContext,
 	request *pfs.CreateRepoRequest,
 ) error {
-	return a.driver.createRepo(txnCtx, request.Repo, request.Description, request.Update)
+	return a.driver.createRepo(txnCtx, request.Repo, request.Description, request.Labels, request.Update)
 }
 
 // CreateRepo implements the protobuf pfs.CreateRepo RPC
@@ -109,7 +110,29 @@ func (a *apiServer) ListRepo(ctx context.Context, request *pfs.ListRepoRequest)
 	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
 
 	repoInfos, err := a.driver.listRepo(a.env.GetPachClient(ctx), true)
-	return repoInfos, err
+	if err != nil || len(request.Labels) == 0 {
+		return repoInfos, err
+	}
+	result := &pfs.ListRepoResponse{}
+	for _, repoInfo := range repoInfos.RepoInfo {
+		if hasLabels(repoInfo.Labels, request.Labels) {
+			result.RepoInfo = append(result.RepoInfo, repoInfo)
+		}
+	}
+	return result, nil
+}
+
+// LabelRepo implements the protobuf pfs.LabelRepo RPC
+func (a *apiServer) LabelRepo(ctx context.Context, request *pfs.LabelRepoRequest) (response *types.Empty, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	if err := a.txnEnv.WithWriteContext(ctx, func(txnCtx *txnenv.TransactionContext) error {
+		return a.driver.labelRepo(txnCtx, request.Repo, request.Labels, request.Remove)
+	}); err != nil {
+		return nil, err
+	}
+	return &types.Empty{}, nil
 }
 
 // DeleteRepoInTransaction is identical to DeleteRepo except that it can run
@@ -144,15 +167,37 @@ func (a *apiServer) Fsck(request *pfs.FsckRequest, fsckServer pfs.API_FsckServer
 	defer func(start time.Time) {
 		a.Log(request, fmt.Sprintf("stream containing %d messages", sent), retErr, time.Since(start))
 	}(time.Now())
-	if err := a.driver.fsck(a.env.GetPachClient(fsckServer.Context()), request.Fix, func(resp *pfs.FsckResponse) error {
+	if request.SampleRate < 0 || request.SampleRate > 1 {
+		return errors.Errorf("sample rate must be between 0 and 1, got %v", request.SampleRate)
+	}
+	pachClient := a.env.GetPachClient(fsckServer.Context())
+	send := func(resp *pfs.FsckResponse) error {
 		sent++
 		return fsckServer.Send(resp)
-	}); err != nil {
+	}
+	if err := a.driver.fsck(pachClient, request.Fix, send); err != nil {
 		return err
 	}
+	if request.Deep {
+		return a.driver.fsckDeep(pachClient, request.Fix, request.SampleRate, send)
+	}
 	return nil
 }
 
+// InspectReplication implements the protobuf pfs.InspectReplication RPC
+func (a *apiServer) InspectReplication(ctx context.Context, request *types.Empty) (response *pfs.ReplicationInfo, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	return a.driver.inspectReplication(a.env.GetPachClient(ctx))
+}
+
+// ReconcileReplication implements the protobuf pfs.ReconcileReplication RPC
+func (a *apiServer) ReconcileReplication(ctx context.Context, request *types.Empty) (response *pfs.ReplicationInfo, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	return a.driver.reconcileReplication(a.env.GetPachClient(ctx))
+}
+
 // StartCommitInTransaction is identical to StartCommit except that it can run
 // inside an existing etcd STM transaction.  This is not an RPC.  The target
 // commit can be specified but is optional.  This is so that the transaction can
@@ -167,10 +212,21 @@ func (a *apiServer) StartCommitInTransaction(
 	if commit != nil {
 		id = commit.ID
 	}
+	var err error
 	if a.env.NewStorageLayer {
-		return a.driver.startCommitNewStorageLayer(txnCtx, id, request.Parent, request.Branch, request.Provenance, request.Description)
+		commit, err = a.driver.startCommitNewStorageLayer(txnCtx, id, request.Parent, request.Branch, request.Provenance, request.Description)
+	} else {
+		commit, err = a.driver.startCommit(txnCtx, id, request.Parent, request.Branch, request.Provenance, request.Description)
+	}
+	if err != nil {
+		return nil, err
 	}
-	return a.driver.startCommit(txnCtx, id, request.Parent, request.Branch, request.Provenance, request.Description)
+	if len(request.Labels) > 0 {
+		if err := a.driver.labelCommit(txnCtx, commit, request.Labels, nil); err != nil {
+			return nil, err
+		}
+	}
+	return commit, nil
 }
 
 // StartCommit implements the protobuf pfs.StartCommit RPC
@@ -201,12 +257,30 @@ func (a *apiServer) BuildCommit(ctx context.Context, request *pfs.BuildCommitReq
 	return commit, nil
 }
 
+// LabelCommit implements the protobuf pfs.LabelCommit RPC
+func (a *apiServer) LabelCommit(ctx context.Context, request *pfs.LabelCommitRequest) (response *types.Empty, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	if err := a.txnEnv.WithWriteContext(ctx, func(txnCtx *txnenv.TransactionContext) error {
+		return a.driver.labelCommit(txnCtx, request.Commit, request.Labels, request.Remove)
+	}); err != nil {
+		return nil, err
+	}
+	return &types.Empty{}, nil
+}
+
 // FinishCommitInTransaction is identical to FinishCommit except that it can run
 // inside an existing etcd STM transaction.  This is not an RPC.
 func (a *apiServer) FinishCommitInTransaction(
 	txnCtx *txnenv.TransactionContext,
 	request *pfs.FinishCommitRequest,
 ) error {
+	if len(request.Labels) > 0 {
+		if err := a.driver.labelCommit(txnCtx, request.Commit, request.Labels, nil); err != nil {
+			return err
+		}
+	}
 	if a.env.NewStorageLayer {
 		return a.driver.finishCommitNewStorageLayer(txnCtx, request.Commit, request.Description)
 	}
@@ -242,7 +316,7 @@ func (a *apiServer) ListCommit(ctx context.Context, request *pfs.ListCommitReque
 	func() { a.Log(request, nil, nil, 0) }()
 	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
 
-	commitInfos, err := a.driver.listCommit(a.env.GetPachClient(ctx), request.Repo, request.To, request.From, request.Number, request.Reverse)
+	commitInfos, err := a.driver.listCommit(a.env.GetPachClient(ctx), request.Repo, request.To, request.From, request.Number, request.Reverse, request.Labels)
 	if err != nil {
 		return nil, err
 	}
@@ -258,7 +332,7 @@ func (a *apiServer) ListCommitStream(request *pfs.ListCommitRequest, respServer
 	defer func(start time.Time) {
 		a.Log(request, fmt.Sprintf("stream containing %d commits", sent), retErr, time.Since(start))
 	}(time.Now())
-	return a.driver.listCommitF(a.env.GetPachClient(respServer.Context()), request.Repo, request.To, request.From, request.Number, request.Reverse, func(ci *pfs.CommitInfo) error {
+	return a.driver.listCommitF(a.env.GetPachClient(respServer.Context()), request.Repo, request.To, request.From, request.Number, request.Reverse, request.Labels, func(ci *pfs.CommitInfo) error {
 		sent++
 		return respServer.Send(ci)
 	})
@@ -336,6 +410,40 @@ func (a *apiServer) DeleteBranch(ctx context.Context, request *pfs.DeleteBranchR
 	return &types.Empty{}, nil
 }
 
+// MergeBranch implements the protobuf pfs.MergeBranch RPC
+func (a *apiServer) MergeBranch(ctx context.Context, request *pfs.MergeBranchRequest) (response *pfs.MergeBranchResponse, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	return a.driver.mergeBranch(a.env.GetPachClient(ctx), request.Src, request.Dst, request.Strategy)
+}
+
+// CreateSnapshot implements the protobuf pfs.CreateSnapshot RPC
+func (a *apiServer) CreateSnapshot(ctx context.Context, request *pfs.CreateSnapshotRequest) (response *pfs.SnapshotInfo, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	return a.driver.createSnapshot(a.env.GetPachClient(ctx), request.Snapshot)
+}
+
+// InspectSnapshot implements the protobuf pfs.InspectSnapshot RPC
+func (a *apiServer) InspectSnapshot(ctx context.Context, request *pfs.InspectSnapshotRequest) (response *pfs.SnapshotInfo, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	return a.driver.inspectSnapshot(a.env.GetPachClient(ctx), request.Snapshot)
+}
+
+// RestoreBranchesFromSnapshot implements the protobuf
+// pfs.RestoreBranchesFromSnapshot RPC
+func (a *apiServer) RestoreBranchesFromSnapshot(ctx context.Context, request *pfs.RestoreBranchesFromSnapshotRequest) (response *types.Empty, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	if err := a.txnEnv.WithWriteContext(ctx, func(txnCtx *txnenv.TransactionContext) error {
+		return a.driver.restoreBranchesFromSnapshot(txnCtx, request.Snapshot, request.Repos)
+	}); err != nil {
+		return nil, err
+	}
+	return &types.Empty{}, nil
+}
+
 // DeleteCommitInTransaction is identical to DeleteCommit except that it can run
 // inside an existing etcd STM transaction.  This is not an RPC.
 func (a *apiServer) DeleteCommitInTransaction(
@@ -551,6 +659,24 @@ func (a *apiServer) DiffFile(ctx context.Context, request *pfs.DiffFileRequest)
 	}, nil
 }
 
+// DiffFileContent implements the protobuf pfs.DiffFileContent RPC
+func (a *apiServer) DiffFileContent(request *pfs.DiffFileRequest, respServer pfs.API_DiffFileContentServer) (retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	var sent int
+	defer func(start time.Time) {
+		a.Log(request, fmt.Sprintf("response stream with %d objects", sent), retErr, time.Since(start))
+	}(time.Now())
+	pachClient := a.env.GetPachClient(respServer.Context())
+	newFileInfos, oldFileInfos, err := a.driver.diffFile(pachClient, request.NewFile, request.OldFile, request.Shallow)
+	if err != nil {
+		return err
+	}
+	return a.driver.diffFileContent(pachClient, request, newFileInfos, oldFileInfos, func(diff *pfs.FileContentDiff) error {
+		sent++
+		return respServer.Send(diff)
+	})
+}
+
 // DeleteFile implements the protobuf pfs.DeleteFile RPC
 func (a *apiServer) DeleteFile(ctx context.Context, request *pfs.DeleteFileRequest) (response *types.Empty, retErr error) {
 	func() { a.Log(request, nil, nil, 0) }()
@@ -10,7 +10,6 @@ import (
 	"io"
 	"io/ioutil"
 	"math"
-	"net/http"
 	"net/url"
 	"os"
 	"path"
@@ -40,6 +39,7 @@ import (
 	"github.com/pachyderm/pachyderm/src/server/pkg/sql"
 	"github.com/pachyderm/pachyderm/src/server/pkg/storage/chunk"
 	"github.com/pachyderm/pachyderm/src/server/pkg/storage/fileset"
+	"github.com/pachyderm/pachyderm/src/server/pkg/textdiff"
 	txnenv "github.com/pachyderm/pachyderm/src/server/pkg/transactionenv"
 	"github.com/pachyderm/pachyderm/src/server/pkg/uuid"
 	"github.com/pachyderm/pachyderm/src/server/pkg/watch"
@@ -100,6 +100,7 @@ type driver struct {
 	commits        collectionFactory
 	branches       collectionFactory
 	openCommits    col.Collection
+	snapshots      col.Collection
 
 	// a cache for hashtrees
 	treeCache *hashtree.Cache
@@ -148,6 +149,7 @@ func newDriver(
 			return pfsdb.Branches(etcdClient, etcdPrefix, repo)
 		},
 		openCommits: pfsdb.OpenCommits(etcdClient, etcdPrefix),
+		snapshots:   pfsdb.Snapshots(etcdClient, etcdPrefix),
 		treeCache:   treeCache,
 		storageRoot: storageRoot,
 		// Allow up to a third of the requested memory to be used for memory intensive operations
@@ -233,11 +235,14 @@ func now() *types.Timestamp {
 	return t
 }
 
-func (d *driver) createRepo(txnCtx *txnenv.TransactionContext, repo *pfs.Repo, description string, update bool) error {
+func (d *driver) createRepo(txnCtx *txnenv.TransactionContext, repo *pfs.Repo, description string, labels map[string]string, update bool) error {
 	// Validate arguments
 	if repo == nil {
 		return errors.New("repo cannot be nil")
 	}
+	if err := validateLabels(labels); err != nil {
+		return err
+	}
 
 	// Check that the user is logged in (user doesn't need any access level to
 	// create a repo, but they must be authenticated if auth is active)
@@ -288,6 +293,10 @@ func (d *driver) createRepo(txnCtx *txnenv.TransactionContext, repo *pfs.Repo, d
 		Repo:        repo,
 		Created:     created,
 		Description: description,
+		Labels:      existingRepoInfo.Labels,
+	}
+	if len(labels) > 0 {
+		repoInfo.Labels = mergeLabels(repoInfo.Labels, labels, nil)
 	}
 	// Only Put the new repoInfo if something has changed.  This
 	// optimization is impactful because pps will frequently update the
@@ -298,6 +307,26 @@ func (d *driver) createRepo(txnCtx *txnenv.TransactionContext, repo *pfs.Repo, d
 	return nil
 }
 
+// labelRepo adds 'labels' to 'repo' and removes the labels whose keys are in
+// 'remove'
+func (d *driver) labelRepo(txnCtx *txnenv.TransactionContext, repo *pfs.Repo, labels map[string]string, remove []string) error {
+	// Validate arguments
+	if repo == nil {
+		return errors.New("repo cannot be nil")
+	}
+	if err := validateLabels(labels); err != nil {
+		return err
+	}
+	if err := d.checkIsAuthorizedInTransaction(txnCtx, repo, auth.Scope_WRITER); err != nil {
+		return err
+	}
+	repoInfo := &pfs.RepoInfo{}
+	return d.repos.ReadWrite(txnCtx.Stm).Update(repo.Name, repoInfo, func() error {
+		repoInfo.Labels = mergeLabels(repoInfo.Labels, labels, remove)
+		return nil
+	})
+}
+
 func (d *driver) inspectRepo(
 	txnCtx *txnenv.TransactionContext,
 	repo *pfs.Repo,
@@ -1117,6 +1146,78 @@ func (d *driver) buildCommit(ctx context.Context, ID string, parent *pfs.Commit,
 	return commit, err
 }
 
+// labelCommit adds 'labels' to 'commit' and removes the labels whose keys are
+// in 'remove'
+func (d *driver) labelCommit(txnCtx *txnenv.TransactionContext, commit *pfs.Commit, labels map[string]string, remove []string) error {
+	// Validate arguments
+	if commit == nil {
+		return errors.New("commit cannot be nil")
+	}
+	if commit.Repo == nil {
+		return errors.New("commit repo cannot be nil")
+	}
+	if err := validateLabels(labels); err != nil {
+		return err
+	}
+	if err := d.checkIsAuthorizedInTransaction(txnCtx, commit.Repo, auth.Scope_WRITER); err != nil {
+		return err
+	}
+	commitInfo, err := d.resolveCommit(txnCtx.Stm, commit)
+	if err != nil {
+		return err
+	}
+	return d.commits(commit.Repo.Name).ReadWrite(txnCtx.Stm).Update(commitInfo.Commit.ID, commitInfo, func() error {
+		commitInfo.Labels = mergeLabels(commitInfo.Labels, labels, remove)
+		return nil
+	})
+}
+
+// validateLabels checks that 'labels' can be written as a selector, e.g.
+// "key1=value1,key2=value2"
+func validateLabels(labels map[string]string) error {
+	for k, v := range labels {
+		if k == "" {
+			return errors.New("label keys cannot be empty")
+		}
+		if strings.ContainsAny(k, "=,") {
+			return errors.Errorf("label key %q cannot contain '=' or ','", k)
+		}
+		if strings.Contains(v, ",") {
+			return errors.Errorf("value of label %q cannot contain ','", k)
+		}
+	}
+	return nil
+}
+
+// mergeLabels returns 'existing' with 'labels' added to it and the keys in
+// 'remove' removed from it
+func mergeLabels(existing, labels map[string]string, remove []string) map[string]string {
+	result := make(map[string]string)
+	for k, v := range existing {
+		result[k] = v
+	}
+	for k, v := range labels {
+		result[k] = v
+	}
+	for _, k := range remove {
+		delete(result, k)
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// hasLabels returns true if 'labels' contains every label in 'selector'
+func hasLabels(labels, selector map[string]string) bool {
+	for k, v := range selector {
+		if value, ok := labels[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
 // make commit makes a new commit in 'branch', with the parent 'parent' and the
 // direct provenance 'provenance'. Note that
 // - 'parent' must not be nil, but the only required field is 'parent.Repo'.
@@ -1830,9 +1931,9 @@ func (d *driver) inspectCommit(pachClient *client.APIClient, commit *pfs.Commit,
 }
 
 // resolveCommit contains the essential implementation of inspectCommit: it converts 'commit' (which may
-// be a commit ID or branch reference, plus '~' and/or '^') to a repo + commit
-// ID. It accepts an STM so that it can be used in a transaction and avoids an
-// inconsistent call to d.inspectCommit()
+// be a commit ID or branch reference, plus '~' and/or '^', and/or '@{<time>}')
+// to a repo + commit ID. It accepts an STM so that it can be used in a
+// transaction and avoids an inconsistent call to d.inspectCommit()
 func (d *driver) resolveCommit(stm col.STM, userCommit *pfs.Commit) (*pfs.CommitInfo, error) {
 	if userCommit == nil {
 		return nil, errors.Errorf("cannot resolve nil commit")
@@ -1841,9 +1942,15 @@ func (d *driver) resolveCommit(stm col.STM, userCommit *pfs.Commit) (*pfs.Commit
 		return nil, errors.Errorf("cannot resolve commit with no ID or branch")
 	}
 	commit := proto.Clone(userCommit).(*pfs.Commit) // back up user commit, for error reporting
-	// Extract any ancestor tokens from 'commit.ID' (i.e. ~, ^ and .)
+	// Extract any time-travel reference from 'commit.ID' (i.e. @{<time>}),
+	// and then any ancestor tokens (i.e. ~, ^ and .)
+	var at *time.Time
 	var ancestryLength int
 	var err error
+	commit.ID, at, err = ancestry.ParseTime(commit.ID)
+	if err != nil {
+		return nil, err
+	}
 	commit.ID, ancestryLength, err = ancestry.Parse(commit.ID)
 	if err != nil {
 		return nil, err
@@ -1906,6 +2013,30 @@ func (d *driver) resolveCommit(stm col.STM, userCommit *pfs.Commit) (*pfs.Commit
 			commit = cis[i%len(cis)].ParentCommit
 		}
 	}
+	// Traverse commits' parents until you've reached one that finished at or
+	// before 'at'
+	for at != nil {
+		if commitInfo.Finished != nil {
+			finished, err := types.TimestampFromProto(commitInfo.Finished)
+			if err != nil {
+				return nil, err
+			}
+			if !finished.After(*at) {
+				break
+			}
+		}
+		if commitInfo.ParentCommit == nil {
+			return nil, pfsserver.ErrCommitNotFound{Commit: userCommit}
+		}
+		parent := commitInfo.ParentCommit
+		commitInfo = &pfs.CommitInfo{}
+		if err := commits.Get(parent.ID, commitInfo); err != nil {
+			if col.IsErrNotFound(err) {
+				return nil, pfsserver.ErrParentCommitNotFound{Commit: parent}
+			}
+			return nil, err
+		}
+	}
 	if commitInfo.Branch == nil {
 		commitInfo.Branch = commitBranch
 	}
@@ -1914,9 +2045,9 @@ func (d *driver) resolveCommit(stm col.STM, userCommit *pfs.Commit) (*pfs.Commit
 }
 
 func (d *driver) listCommit(pachClient *client.APIClient, repo *pfs.Repo,
-	to *pfs.Commit, from *pfs.Commit, number uint64, reverse bool) ([]*pfs.CommitInfo, error) {
+	to *pfs.Commit, from *pfs.Commit, number uint64, reverse bool, labels map[string]string) ([]*pfs.CommitInfo, error) {
 	var result []*pfs.CommitInfo
-	if err := d.listCommitF(pachClient, repo, to, from, number, reverse, func(ci *pfs.CommitInfo) error {
+	if err := d.listCommitF(pachClient, repo, to, from, number, reverse, labels, func(ci *pfs.CommitInfo) error {
 		result = append(result, ci)
 		return nil
 	}); err != nil {
@@ -1926,7 +2057,7 @@ func (d *driver) listCommit(pachClient *client.APIClient, repo *pfs.Repo,
 }
 
 func (d *driver) listCommitF(pachClient *client.APIClient, repo *pfs.Repo,
-	to *pfs.Commit, from *pfs.Commit, number uint64, reverse bool, f func(*pfs.CommitInfo) error) error {
+	to *pfs.Commit, from *pfs.Commit, number uint64, reverse bool, labels map[string]string, f func(*pfs.CommitInfo) error) error {
 	// Validate arguments
 	if repo == nil {
 		return errors.New("repo cannot be nil")
@@ -1994,11 +2125,14 @@ func (d *driver) listCommitF(pachClient *client.APIClient, repo *pfs.Repo,
 				if number == 0 {
 					return errutil.ErrBreak
 				}
-				number--
-
 				if reverse {
 					ci = cis[len(cis)-1-i]
 				}
+				if !hasLabels(ci.Labels, labels) {
+					continue
+				}
+				number--
+
 				if err := f(ci); err != nil {
 					return err
 				}
@@ -2033,13 +2167,16 @@ func (d *driver) listCommitF(pachClient *client.APIClient, repo *pfs.Repo,
 			if err := commits.Get(cursor.ID, &commitInfo); err != nil {
 				return err
 			}
+			cursor = commitInfo.ParentCommit
+			if !hasLabels(commitInfo.Labels, labels) {
+				continue
+			}
 			if err := f(&commitInfo); err != nil {
 				if err == errutil.ErrBreak {
 					return nil
 				}
 				return err
 			}
-			cursor = commitInfo.ParentCommit
 			number--
 		}
 	}
@@ -2801,6 +2938,300 @@ func (d *driver) deleteBranch(txnCtx *txnenv.TransactionContext, branch *pfs.Bra
 	return nil
 }
 
+// walkAncestors calls 'f' on 'commit' and then on its ancestors, following
+// both parent commits and merge parents, nearest first. If 'f' returns false,
+// the commit's own ancestors aren't visited through it.
+func (d *driver) walkAncestors(pachClient *client.APIClient, commit *pfs.Commit, f func(*pfs.Commit) bool) error {
+	visited := make(map[string]bool)
+	queue := []*pfs.Commit{commit}
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		if visited[c.ID] {
+			continue
+		}
+		visited[c.ID] = true
+		if !f(c) {
+			continue
+		}
+		ci, err := d.inspectCommit(pachClient, c, pfs.CommitState_STARTED)
+		if err != nil {
+			// Merge parents aren't updated when commits are deleted
+			if c != commit && (pfsserver.IsCommitNotFoundErr(err) || pfsserver.IsCommitDeletedErr(err)) {
+				continue
+			}
+			return err
+		}
+		for _, parent := range []*pfs.Commit{ci.ParentCommit, ci.MergeParent} {
+			if parent != nil {
+				queue = append(queue, parent)
+			}
+		}
+	}
+	return nil
+}
+
+// mergeBase returns the most recent commit that's an ancestor of both 'src'
+// and 'dst' (each of which is considered its own ancestor), or nil if they
+// share no history. Because merge commits record the source head that they
+// merged, merging the same branches again only compares against the changes
+// made since the previous merge.
+func (d *driver) mergeBase(pachClient *client.APIClient, src, dst *pfs.Commit) (*pfs.Commit, error) {
+	srcAncestors := make(map[string]bool)
+	if err := d.walkAncestors(pachClient, src, func(c *pfs.Commit) bool {
+		srcAncestors[c.ID] = true
+		return true
+	}); err != nil {
+		return nil, err
+	}
+	// Find the common ancestors that aren't behind another common ancestor on
+	// the path from 'dst'
+	var candidates []*pfs.Commit
+	if err := d.walkAncestors(pachClient, dst, func(c *pfs.Commit) bool {
+		if srcAncestors[c.ID] {
+			candidates = append(candidates, c)
+			return false
+		}
+		return true
+	}); err != nil {
+		return nil, err
+	}
+	// A candidate may still be an ancestor of another candidate (through a
+	// different path), in which case it isn't the most recent
+	for i, c := range candidates {
+		isAncestor := false
+		for j, other := range candidates {
+			if i == j || isAncestor {
+				continue
+			}
+			if err := d.walkAncestors(pachClient, other, func(a *pfs.Commit) bool {
+				if a.ID == c.ID {
+					isAncestor = true
+				}
+				return !isAncestor
+			}); err != nil {
+				return nil, err
+			}
+		}
+		if !isAncestor {
+			return c, nil
+		}
+	}
+	return nil, nil
+}
+
+// changedFiles returns the files in 'commit' that differ from 'base', mapped
+// to their new node (or nil, if the file was deleted)
+func (d *driver) changedFiles(pachClient *client.APIClient, commit, base *pfs.Commit) (map[string]*hashtree.NodeProto, error) {
+	tree, err := d.getTreeForFile(pachClient, &pfs.File{Commit: commit, Path: "/"})
+	if err != nil {
+		return nil, err
+	}
+	defer destroyHashtree(tree)
+	baseTree, err := d.getTreeForFile(pachClient, &pfs.File{Commit: base, Path: "/"})
+	if err != nil {
+		return nil, err
+	}
+	defer destroyHashtree(baseTree)
+	changed := make(map[string]*hashtree.NodeProto)
+	if err := tree.Diff(baseTree, "/", "/", -1, func(path string, node *hashtree.NodeProto, isNewFile bool) error {
+		if node.FileNode == nil {
+			return nil
+		}
+		if isNewFile {
+			changed[path] = node
+		} else if _, ok := changed[path]; !ok {
+			changed[path] = nil
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+// typeConflicts returns the changed files in 'changes' that conflict with a
+// file written at 'p' because one of them is a directory in the other's
+// branch, i.e. files at a parent directory of 'p', and files under 'p'.
+// 'sortedFiles' are the paths of the files written in 'changes', in order.
+func typeConflicts(p string, changes map[string]*hashtree.NodeProto, sortedFiles []string) []string {
+	var result []string
+	for dir := path.Dir(p); dir != "/" && dir != "."; dir = path.Dir(dir) {
+		if changes[dir] != nil {
+			result = append(result, dir)
+		}
+	}
+	prefix := strings.TrimSuffix(p, "/") + "/"
+	for i := sort.SearchStrings(sortedFiles, prefix); i < len(sortedFiles) && strings.HasPrefix(sortedFiles[i], prefix); i++ {
+		result = append(result, sortedFiles[i])
+	}
+	return result
+}
+
+// mergeBranch applies the changes made on 'src' since its common ancestor
+// with 'dst' to 'dst', in a single new commit. Paths that were also changed
+// (differently) on 'dst', or that are a file on one branch and a directory on
+// the other, are conflicts, and are resolved according to 'strategy'. The new
+// commit records the head of 'src' as its merge parent.
+func (d *driver) mergeBranch(pachClient *client.APIClient, src, dst *pfs.Branch, strategy pfs.MergeStrategy) (*pfs.MergeBranchResponse, error) {
+	// Validate arguments
+	if src == nil || dst == nil {
+		return nil, errors.New("src and dst branches cannot be nil")
+	}
+	if src.Repo == nil || dst.Repo == nil {
+		return nil, errors.New("src and dst branch repos cannot be nil")
+	}
+	if src.Repo.Name != dst.Repo.Name {
+		return nil, errors.Errorf("cannot merge branches in different repos (%s and %s)", src.Repo.Name, dst.Repo.Name)
+	}
+	if src.Name == dst.Name {
+		return nil, errors.Errorf("cannot merge branch %s into itself", src.Name)
+	}
+	if err := d.checkIsAuthorized(pachClient, dst.Repo, auth.Scope_WRITER); err != nil {
+		return nil, err
+	}
+
+	var srcInfo, dstInfo *pfs.BranchInfo
+	if err := d.txnEnv.WithReadContext(pachClient.Ctx(), func(txnCtx *txnenv.TransactionContext) error {
+		var err error
+		if srcInfo, err = d.inspectBranch(txnCtx, src); err != nil {
+			return err
+		}
+		dstInfo, err = d.inspectBranch(txnCtx, dst)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if len(dstInfo.Provenance) > 0 {
+		return nil, errors.Errorf("cannot merge into branch %s, which has provenance", dst.Name)
+	}
+	if srcInfo.Head == nil {
+		return &pfs.MergeBranchResponse{}, nil // nothing to merge
+	}
+	for _, head := range []*pfs.Commit{srcInfo.Head, dstInfo.Head} {
+		if head == nil {
+			continue
+		}
+		ci, err := d.inspectCommit(pachClient, head, pfs.CommitState_STARTED)
+		if err != nil {
+			return nil, err
+		}
+		if ci.Finished == nil {
+			return nil, errors.Errorf("cannot merge while commit %s is open", head.ID)
+		}
+		if provenantOnInput(ci.Provenance) && ci.Tree == nil {
+			return nil, errors.Errorf("cannot merge output commit %s", head.ID)
+		}
+	}
+	base, err := d.mergeBase(pachClient, srcInfo.Head, dstInfo.Head)
+	if err != nil {
+		return nil, err
+	}
+	if base != nil && base.ID == srcInfo.Head.ID {
+		return &pfs.MergeBranchResponse{}, nil // 'dst' already contains 'src'
+	}
+
+	srcChanges, err := d.changedFiles(pachClient, srcInfo.Head, base)
+	if err != nil {
+		return nil, err
+	}
+	dstChanges, err := d.changedFiles(pachClient, dstInfo.Head, base)
+	if err != nil {
+		return nil, err
+	}
+	var dstFiles []string
+	for p, node := range dstChanges {
+		if node != nil {
+			dstFiles = append(dstFiles, p)
+		}
+	}
+	sort.Strings(dstFiles)
+	response := &pfs.MergeBranchResponse{}
+	var paths []string
+	for p, srcNode := range srcChanges {
+		if dstNode, ok := dstChanges[p]; ok {
+			if srcNode == nil && dstNode == nil ||
+				srcNode != nil && dstNode != nil && bytes.Equal(srcNode.Hash, dstNode.Hash) {
+				continue // both branches made the same change
+			}
+			response.Conflicts = append(response.Conflicts, p)
+			if strategy != pfs.MergeStrategy_THEIRS {
+				continue
+			}
+		} else if srcNode != nil {
+			if conflicts := typeConflicts(p, dstChanges, dstFiles); len(conflicts) > 0 {
+				response.Conflicts = append(response.Conflicts, p)
+				if strategy != pfs.MergeStrategy_THEIRS {
+					continue
+				}
+				// Writing 'p' deletes any directory at 'p' on 'dst', but files
+				// at p's parent directories must be deleted explicitly (they
+				// aren't in 'srcChanges', so they're written as deletions)
+				for _, c := range conflicts {
+					if strings.HasPrefix(p, c+"/") {
+						paths = append(paths, c)
+					}
+				}
+			}
+		}
+		paths = append(paths, p)
+	}
+	sort.Strings(response.Conflicts)
+	if len(response.Conflicts) > 0 && strategy == pfs.MergeStrategy_CONFLICT {
+		return response, nil
+	}
+	if len(paths) == 0 {
+		return response, nil
+	}
+	paths = dedupPaths(paths)
+	records := make([]*pfs.PutFileRecords, len(paths))
+	for i, p := range paths {
+		records[i] = &pfs.PutFileRecords{Tombstone: true}
+		if node := srcChanges[p]; node != nil {
+			if node.FileNode.HasHeaderFooter {
+				return nil, errors.Errorf("cannot merge %s, which has a header or footer", p)
+			}
+			appendRecords(records[i], node)
+		}
+	}
+
+	description := fmt.Sprintf("merge %s into %s", src.Name, dst.Name)
+	if err := d.txnEnv.WithWriteContext(pachClient.Ctx(), func(txnCtx *txnenv.TransactionContext) error {
+		// Make sure 'dst' hasn't moved since it was diffed
+		branchInfo, err := d.inspectBranch(txnCtx, dst)
+		if err != nil {
+			return err
+		}
+		if branchInfo.Head.GetID() != dstInfo.Head.GetID() {
+			return errors.Errorf("branch %s was updated during the merge", dst.Name)
+		}
+		response.Commit, err = d.makeCommit(txnCtx, "", client.NewCommit(dst.Repo.Name, ""), dst.Name, nil, nil, nil, nil, paths, records, description, 0)
+		if err != nil {
+			return err
+		}
+		commitInfo := &pfs.CommitInfo{}
+		return d.commits(dst.Repo.Name).ReadWrite(txnCtx.Stm).Update(response.Commit.ID, commitInfo, func() error {
+			commitInfo.MergeParent = srcInfo.Head
+			return nil
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// dedupPaths sorts 'paths' and removes duplicates
+func dedupPaths(paths []string) []string {
+	sort.Strings(paths)
+	result := paths[:0]
+	for _, p := range paths {
+		if len(result) == 0 || p != result[len(result)-1] {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
 // scratchCommitPrefix returns an etcd prefix that's used to temporarily
 // store the state of a file in an open commit.  Once the commit is finished,
 // the scratch space is removed.
@@ -3252,6 +3683,9 @@ func (d *driver) copyFile(pachClient *client.APIClient, src *pfs.File, dst *pfs.
 	if err != nil {
 		return err
 	}
+	if err := checkNotQuarantined(srcCi); err != nil {
+		return err
+	}
 	if !provenantOnInput(srcCi.Provenance) || srcCi.Tree != nil {
 		// handle input commits
 		srcTree, err = d.getTreeForFile(pachClient, src)
@@ -3533,6 +3967,9 @@ func (d *driver) getFile(pachClient *client.APIClient, file *pfs.File, offset in
 	if err != nil {
 		return nil, err
 	}
+	if err := checkNotQuarantined(commitInfo); err != nil {
+		return nil, err
+	}
 	// Handle commits that use the old hashtree format.
 	if !provenantOnInput(commitInfo.Provenance) || commitInfo.Tree != nil {
 		tree, err := d.getTreeForFile(pachClient, client.NewFile(file.Commit.Repo.Name, file.Commit.ID, ""))
@@ -3786,6 +4223,9 @@ func (d *driver) inspectFile(pachClient *client.APIClient, file *pfs.File) (fi *
 	if err != nil {
 		return nil, err
 	}
+	if err := checkNotQuarantined(commitInfo); err != nil {
+		return nil, err
+	}
 	// Handle commits that use the old hashtree format.
 	if !provenantOnInput(commitInfo.Provenance) || commitInfo.Tree != nil {
 		tree, err := d.getTreeForFile(pachClient, file)
@@ -3843,6 +4283,9 @@ func (d *driver) listFile(pachClient *client.APIClient, file *pfs.File, full boo
 	if err != nil {
 		return err
 	}
+	if err := checkNotQuarantined(commitInfo); err != nil {
+		return err
+	}
 	g, err := globlib.Compile(file.Path, '/')
 	if err != nil {
 		// TODO this should be a MalformedGlob error like the hashtree returns
@@ -3964,6 +4407,9 @@ func (d *driver) walkFile(pachClient *client.APIClient, file *pfs.File, f func(*
 	if err != nil {
 		return err
 	}
+	if err := checkNotQuarantined(commitInfo); err != nil {
+		return err
+	}
 	// Handle commits that use the old hashtree format.
 	if !provenantOnInput(commitInfo.Provenance) || commitInfo.Tree != nil {
 		tree, err := d.getTreeForFile(pachClient, client.NewFile(file.Commit.Repo.Name, file.Commit.ID, file.Path))
@@ -4018,6 +4464,9 @@ func (d *driver) globFile(pachClient *client.APIClient, commit *pfs.Commit, patt
 	if err != nil {
 		return err
 	}
+	if err := checkNotQuarantined(commitInfo); err != nil {
+		return err
+	}
 	// Handle commits that use the old hashtree format.
 	if !provenantOnInput(commitInfo.Provenance) || commitInfo.Tree != nil {
 		tree, err := d.getTreeForFile(pachClient, client.NewFile(commit.Repo.Name, commit.ID, ""))
@@ -4099,6 +4548,9 @@ func (d *driver) diffFile(pachClient *client.APIClient, newFile *pfs.File, oldFi
 	if err != nil {
 		return nil, nil, err
 	}
+	if err := checkNotQuarantined(newCommitInfo); err != nil {
+		return nil, nil, err
+	}
 	// if oldFile is nil we use the parent of newFile
 	if oldFile == nil {
 		oldFile = &pfs.File{}
@@ -4117,6 +4569,9 @@ func (d *driver) diffFile(pachClient *client.APIClient, newFile *pfs.File, oldFi
 		if err != nil {
 			return nil, nil, err
 		}
+		if err := checkNotQuarantined(oldCommitInfo); err != nil {
+			return nil, nil, err
+		}
 	}
 	oldTree, err := d.getTreeForFile(pachClient, oldFile)
 	if err != nil {
@@ -4150,6 +4605,124 @@ func (d *driver) diffFile(pachClient *client.APIClient, newFile *pfs.File, oldFi
 	return newFileInfos, oldFileInfos, nil
 }
 
+// defaultMaxDiffContentBytes is the largest file whose content diffFileContent
+// diffs, if the request doesn't set a limit
+const defaultMaxDiffContentBytes = 1024 * 1024
+
+// maxDiffContentBytes is the largest limit a request may set. A diff of two
+// files can be several times larger than the files themselves, and each diff
+// must fit in one grpc message.
+var maxDiffContentBytes = int64(grpcutil.MaxMsgSize / 10)
+
+// diffFileContent diffs the content of the files that diffFile found to have
+// changed, calling f with each file's diff. New and old files are paired up
+// by their paths relative to the roots of the diff.
+func (d *driver) diffFileContent(pachClient *client.APIClient, request *pfs.DiffFileRequest, newFileInfos []*pfs.FileInfo, oldFileInfos []*pfs.FileInfo, f func(*pfs.FileContentDiff) error) error {
+	maxBytes := request.MaxContentBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultMaxDiffContentBytes
+	}
+	if maxBytes > maxDiffContentBytes {
+		return errors.Errorf("max content bytes (%d) can't be more than %d", maxBytes, maxDiffContentBytes)
+	}
+	newRoot := path.Clean("/" + request.NewFile.Path)
+	oldRoot := newRoot
+	if request.OldFile != nil && request.OldFile.Commit != nil {
+		oldRoot = path.Clean("/" + request.OldFile.Path)
+	}
+	diffs := make(map[string]*pfs.FileContentDiff)
+	var relPaths []string
+	addFiles := func(root string, fileInfos []*pfs.FileInfo, isNew bool) {
+		for _, fileInfo := range fileInfos {
+			if fileInfo.FileType != pfs.FileType_FILE {
+				continue
+			}
+			relPath := strings.TrimPrefix(strings.TrimPrefix(path.Clean("/"+fileInfo.File.Path), root), "/")
+			diff, ok := diffs[relPath]
+			if !ok {
+				diff = &pfs.FileContentDiff{}
+				diffs[relPath] = diff
+				relPaths = append(relPaths, relPath)
+			}
+			if isNew {
+				diff.NewFile = fileInfo.File
+			} else {
+				diff.OldFile = fileInfo.File
+			}
+			if fileInfo.SizeBytes > uint64(maxBytes) {
+				diff.TooLarge = true
+			}
+		}
+	}
+	addFiles(newRoot, newFileInfos, true)
+	addFiles(oldRoot, oldFileInfos, false)
+	sort.Strings(relPaths)
+	for _, relPath := range relPaths {
+		diff := diffs[relPath]
+		if !diff.TooLarge {
+			if err := d.diffContent(pachClient, diff); err != nil {
+				return err
+			}
+		}
+		if err := f(diff); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// diffContent reads the new and old versions of the file in 'diff' and fills
+// in how its content changed
+func (d *driver) diffContent(pachClient *client.APIClient, diff *pfs.FileContentDiff) error {
+	readFile := func(file *pfs.File) ([]byte, error) {
+		if file == nil {
+			return nil, nil
+		}
+		r, err := d.getFile(pachClient, file, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.ReadAll(r)
+	}
+	newData, err := readFile(diff.NewFile)
+	if err != nil {
+		return err
+	}
+	oldData, err := readFile(diff.OldFile)
+	if err != nil {
+		return err
+	}
+	if textdiff.IsBinary(newData) || textdiff.IsBinary(oldData) {
+		diff.Binary = true
+		return nil
+	}
+	newName, oldName := "/dev/null", "/dev/null"
+	var filePath string
+	if diff.OldFile != nil {
+		oldName, filePath = path.Join("a", diff.OldFile.Path), diff.OldFile.Path
+	}
+	if diff.NewFile != nil {
+		newName, filePath = path.Join("b", diff.NewFile.Path), diff.NewFile.Path
+	}
+	var comma rune
+	switch strings.ToLower(path.Ext(filePath)) {
+	case ".csv":
+		comma = ','
+	case ".tsv":
+		comma = '\t'
+	}
+	if comma != 0 {
+		added, deleted, err := textdiff.Rows(oldData, newData, comma)
+		if err == nil {
+			diff.AddedRows, diff.DeletedRows = added, deleted
+			return nil
+		}
+		// Fall back to a line diff if the file can't be parsed
+	}
+	diff.Unified = textdiff.Unified(oldName, newName, string(oldData), string(newData), 3)
+	return nil
+}
+
 func (d *driver) deleteFile(pachClient *client.APIClient, file *pfs.File) error {
 	// Validate arguments
 	if file == nil {
@@ -4201,6 +4774,7 @@ func (d *driver) deleteAll(txnCtx *txnenv.TransactionContext) error {
 			return err
 		}
 	}
+	d.snapshots.ReadWrite(txnCtx.Stm).DeleteAll()
 	return nil
 }
 
@@ -4558,26 +5132,36 @@ func (d *driver) forEachPutFile(pachClient *client.APIClient, server pfs.API_Put
 				if err != nil {
 					return false, "", "", err
 				}
+				if req.Recursive && req.ExpectedSha256 != "" {
+					return false, "", "", errors.New("expected_sha256 cannot be set for recursive puts")
+				}
+				creds, err := d.urlSecret(pachClient, req.UrlSecret)
+				if err != nil {
+					return false, "", "", err
+				}
 				switch url.Scheme {
 				case "http":
 					fallthrough
 				case "https":
 					limiter.Acquire()
-					resp, err := http.Get(req.Url)
+					r, err := openHTTP(server.Context(), req.Url, creds)
 					if err != nil {
+						limiter.Release()
 						return false, "", "", err
-					} else if resp.StatusCode >= 400 {
-						return false, "", "", errors.Errorf("error retrieving content from %q: %s", req.Url, resp.Status)
 					}
 					eg.Go(func() (retErr error) {
 						defer limiter.Release()
 						defer func() {
-							if err := resp.Body.Close(); err != nil && retErr == nil {
+							if err := r.Close(); err != nil && retErr == nil {
 								retErr = err
 							}
 						}()
-						return f(req, resp.Body)
+						return f(req, withSHA256(req, r))
 					})
+				case "sftp":
+					if err := putFileSFTP(req, url, creds, limiter, &eg, f); err != nil {
+						return false, "", "", err
+					}
 				default:
 					url, err := obj.ParseURL(req.Url)
 					if err != nil {
@@ -4629,7 +5213,7 @@ func (d *driver) forEachPutFile(pachClient *client.APIClient, server pfs.API_Put
 									retErr = err
 								}
 							}()
-							return f(req, r)
+							return f(req, withSHA256(req, r))
 						})
 					}
 				}
@@ -4644,7 +5228,7 @@ func (d *driver) forEachPutFile(pachClient *client.APIClient, server pfs.API_Put
 			limiter.Acquire()
 			eg.Go(func() error {
 				defer limiter.Release()
-				if err := f(req, pr); err != nil {
+				if err := f(req, withSHA256(req, pr)); err != nil {
 					// needed so the parent goroutine doesn't block
 					pr.CloseWithError(err)
 					return err
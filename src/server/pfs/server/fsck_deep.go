@@ -0,0 +1,368 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	pfsserver "github.com/pachyderm/pachyderm/src/server/pfs"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	"github.com/pachyderm/pachyderm/src/server/pkg/hashtree"
+	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
+)
+
+// fsckDeep verifies that the data referenced by every finished commit is
+// intact in object storage: that the commit's trees exist and can be read,
+// and that the objects and blocks referenced by its files exist. The content
+// of a 'sampleRate' fraction of those objects and block ranges is also
+// verified (objects must match their hash, and blocks must not be truncated).
+// Each problem is sent to 'cb' as a finding. If fix is true, commits with
+// problems are quarantined, and quarantined commits without problems are
+// released.
+func (d *driver) fsckDeep(pachClient *client.APIClient, fix bool, sampleRate float64, cb func(*pfs.FsckResponse) error) error {
+	ctx := pachClient.Ctx()
+	objClient, err := obj.NewClientFromSecret(d.storageRoot)
+	if err != nil {
+		return err
+	}
+	// Check the primary directly, as replicas may still have objects that
+	// are missing from it
+	if replicatedClient, ok := objClient.(*obj.ReplicatedClient); ok {
+		objClient = replicatedClient.Primary()
+	}
+	c := &deepChecker{
+		pachClient: pachClient,
+		objClient:  objClient,
+		sampleRate: sampleRate,
+		checked:    make(map[string]*pfs.FsckFinding),
+	}
+
+	var commitInfos []*pfs.CommitInfo
+	repoInfo := &pfs.RepoInfo{}
+	if err := d.repos.ReadOnly(ctx).List(repoInfo, col.DefaultOptions, func(repoName string) error {
+		commitInfo := &pfs.CommitInfo{}
+		return d.commits(repoName).ReadOnly(ctx).List(commitInfo, col.DefaultOptions, func(string) error {
+			if commitInfo.Finished != nil {
+				commitInfos = append(commitInfos, proto.Clone(commitInfo).(*pfs.CommitInfo))
+			}
+			return nil
+		})
+	}); err != nil {
+		return err
+	}
+
+	for _, commitInfo := range commitInfos {
+		findings, err := d.fsckCommitData(c, commitInfo)
+		if err != nil {
+			return err
+		}
+		for _, finding := range findings {
+			if err := cb(&pfs.FsckResponse{
+				Error:   findingMessage(finding),
+				Finding: finding,
+			}); err != nil {
+				return err
+			}
+		}
+		if !fix {
+			continue
+		}
+		var reason string
+		if len(findings) > 0 {
+			reason = fmt.Sprintf("deep fsck found %d problem(s) with this commit's data, e.g.: %s", len(findings), findingMessage(findings[0]))
+		}
+		if reason == commitInfo.Quarantined {
+			continue
+		}
+		commit := commitInfo.Commit
+		if _, err := col.NewSTM(ctx, d.etcdClient, func(stm col.STM) error {
+			return d.commits(commit.Repo.Name).ReadWrite(stm).Update(commit.ID, commitInfo, func() error {
+				commitInfo.Quarantined = reason
+				return nil
+			})
+		}); err != nil {
+			return err
+		}
+		fixMsg := fmt.Sprintf("quarantining commit %s@%s", commit.Repo.Name, commit.ID)
+		if reason == "" {
+			fixMsg = fmt.Sprintf("releasing commit %s@%s from quarantine, as no problems were found with its data", commit.Repo.Name, commit.ID)
+		}
+		if err := cb(&pfs.FsckResponse{Fix: fixMsg}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkNotQuarantined returns an error if 'commitInfo' has been quarantined
+// by a deep fsck, so that its files aren't read while its data is damaged
+func checkNotQuarantined(commitInfo *pfs.CommitInfo) error {
+	if commitInfo.Quarantined != "" {
+		return pfsserver.ErrCommitQuarantined{
+			Commit: commitInfo.Commit,
+			Reason: commitInfo.Quarantined,
+		}
+	}
+	return nil
+}
+
+// fsckCommitData returns the problems with the data referenced by
+// 'commitInfo'. Errors reading object storage are findings; only other errors
+// (e.g. a cancelled context) are returned.
+func (d *driver) fsckCommitData(c *deepChecker, commitInfo *pfs.CommitInfo) ([]*pfs.FsckFinding, error) {
+	var findings []*pfs.FsckFinding
+	addFinding := func(finding *pfs.FsckFinding, path string) {
+		finding = proto.Clone(finding).(*pfs.FsckFinding)
+		finding.Commit = commitInfo.Commit
+		finding.Path = path
+		findings = append(findings, finding)
+	}
+	checkNode := func(path string, node *hashtree.NodeProto) error {
+		if node.FileNode == nil {
+			return nil
+		}
+		for _, object := range node.FileNode.Objects {
+			if finding := c.checkObject(object, c.sample()); finding != nil {
+				addFinding(finding, path)
+			}
+		}
+		for _, blockRef := range node.FileNode.BlockRefs {
+			if finding := c.checkBlockRef(blockRef, c.sample()); finding != nil {
+				addFinding(finding, path)
+			}
+		}
+		return nil
+	}
+
+	// Check the tree objects themselves before trying to read them, so that a
+	// missing tree is reported as such rather than as a corrupt tree
+	treeObjects := append([]*pfs.Object{}, commitInfo.Trees...)
+	if commitInfo.Tree != nil {
+		treeObjects = append(treeObjects, commitInfo.Tree)
+	}
+	for _, object := range treeObjects {
+		if finding := c.checkObject(object, false); finding != nil {
+			addFinding(finding, "")
+		}
+	}
+	if len(findings) > 0 {
+		return findings, nil
+	}
+
+	var treeErr error
+	if commitInfo.Trees != nil {
+		rs, err := d.getTrees(c.pachClient, commitInfo, "")
+		if err != nil {
+			treeErr = err
+		} else {
+			treeErr = hashtree.Walk(rs, "/", checkNode)
+			for _, r := range rs {
+				r.Close()
+			}
+		}
+	} else if commitInfo.Tree != nil {
+		tree, err := hashtree.GetHashTreeObject(c.pachClient, d.storageRoot, commitInfo.Tree)
+		if err != nil {
+			treeErr = err
+		} else {
+			treeErr = tree.Walk("/", checkNode)
+			tree.Destroy()
+		}
+	}
+	if treeErr != nil {
+		if err := c.pachClient.Ctx().Err(); err != nil {
+			return nil, err
+		}
+		object := commitInfo.Tree
+		if len(commitInfo.Trees) > 0 {
+			object = commitInfo.Trees[0]
+		}
+		findings = append(findings, &pfs.FsckFinding{
+			Type:   pfs.FsckFindingType_CORRUPT_TREE,
+			Commit: commitInfo.Commit,
+			Object: object,
+		})
+	}
+	return findings, nil
+}
+
+// deepChecker checks objects and block ranges in object storage, and
+// remembers the results, since most objects and blocks are referenced by many
+// commits
+type deepChecker struct {
+	pachClient *client.APIClient
+	objClient  obj.Client
+	sampleRate float64
+	// checked maps each object and block range that has been checked to the
+	// problem with it, or nil if there isn't one
+	checked map[string]*pfs.FsckFinding
+}
+
+// sample returns true if the next object or block range's content should be
+// verified
+func (c *deepChecker) sample() bool {
+	return c.sampleRate > 0 && rand.Float64() < c.sampleRate
+}
+
+// checkObject checks that 'object' exists and, if 'verify' is true, that its
+// content matches its hash
+func (c *deepChecker) checkObject(object *pfs.Object, verify bool) *pfs.FsckFinding {
+	key := "object/" + object.Hash
+	if finding, ok := c.checked[key]; ok {
+		return finding
+	}
+	finding := func() *pfs.FsckFinding {
+		missing := &pfs.FsckFinding{Type: pfs.FsckFindingType_MISSING_OBJECT, Object: object}
+		path, err := obj.ObjectPathFromEnv(object)
+		if err != nil {
+			return missing
+		}
+		data, err := c.readAll(path)
+		if err != nil {
+			return missing
+		}
+		blockRef := &pfs.BlockRef{}
+		if err := blockRef.Unmarshal(data); err != nil || blockRef.Block == nil || blockRef.Range == nil {
+			return missing
+		}
+		if finding := c.checkBlockRef(blockRef, false); finding != nil {
+			finding = proto.Clone(finding).(*pfs.FsckFinding)
+			finding.Object = object
+			return finding
+		}
+		if !verify {
+			return nil
+		}
+		path, err = obj.BlockPathFromEnv(blockRef.Block)
+		if err != nil {
+			return nil
+		}
+		hash := pfs.NewHash()
+		if err := c.read(path, blockRef.Range, hash); err != nil || pfs.EncodeHash(hash.Sum(nil)) != object.Hash {
+			return &pfs.FsckFinding{
+				Type:   pfs.FsckFindingType_HASH_MISMATCH,
+				Object: object,
+				Block:  blockRef.Block,
+			}
+		}
+		return nil
+	}()
+	// Only remember verified objects, so that an object that's only been
+	// checked for existence can still be sampled later
+	if verify || finding != nil {
+		c.checked[key] = finding
+	}
+	return finding
+}
+
+// checkBlockRef checks that the block in 'blockRef' exists and, if 'verify'
+// is true, that it isn't shorter than the range in 'blockRef'
+func (c *deepChecker) checkBlockRef(blockRef *pfs.BlockRef, verify bool) *pfs.FsckFinding {
+	if blockRef.Block == nil || blockRef.Range == nil {
+		return &pfs.FsckFinding{Type: pfs.FsckFindingType_MISSING_BLOCK}
+	}
+	key := fmt.Sprintf("block/%s|%d|%d", blockRef.Block.Hash, blockRef.Range.Lower, blockRef.Range.Upper)
+	existsKey := "block/" + blockRef.Block.Hash
+	if finding, ok := c.checked[key]; ok {
+		return finding
+	}
+	finding, ok := c.checked[existsKey]
+	if !ok {
+		finding = nil
+		path, err := obj.BlockPathFromEnv(blockRef.Block)
+		if err != nil || !c.objClient.Exists(c.pachClient.Ctx(), path) {
+			finding = &pfs.FsckFinding{Type: pfs.FsckFindingType_MISSING_BLOCK, Block: blockRef.Block}
+		}
+		c.checked[existsKey] = finding
+	}
+	if finding != nil || !verify {
+		return finding
+	}
+	path, err := obj.BlockPathFromEnv(blockRef.Block)
+	if err != nil {
+		return nil
+	}
+	if err := c.read(path, blockRef.Range, ioutil.Discard); err != nil {
+		finding = &pfs.FsckFinding{Type: pfs.FsckFindingType_TRUNCATED_BLOCK, Block: blockRef.Block}
+	}
+	c.checked[key] = finding
+	return finding
+}
+
+// read copies 'byteRange' of the object at 'path' to 'w', and returns an
+// error if it's shorter than the range
+func (c *deepChecker) read(path string, byteRange *pfs.ByteRange, w io.Writer) (retErr error) {
+	size := byteRange.Upper - byteRange.Lower
+	if size == 0 {
+		return nil
+	}
+	r, err := c.objClient.Reader(c.pachClient.Ctx(), path, byteRange.Lower, size)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := r.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	n, err := io.Copy(w, r)
+	if err != nil {
+		return err
+	}
+	if uint64(n) != size {
+		return errors.Errorf("read %d bytes of %s, expected %d", n, path, size)
+	}
+	return nil
+}
+
+func (c *deepChecker) readAll(path string) (_ []byte, retErr error) {
+	r, err := c.objClient.Reader(c.pachClient.Ctx(), path, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := r.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	return ioutil.ReadAll(r)
+}
+
+// findingMessage describes 'finding' in the style of the other fsck errors
+func findingMessage(finding *pfs.FsckFinding) string {
+	var msg strings.Builder
+	msg.WriteString("data error: ")
+	switch finding.Type {
+	case pfs.FsckFindingType_MISSING_OBJECT:
+		msg.WriteString("object " + finding.Object.Hash + " is missing")
+	case pfs.FsckFindingType_MISSING_BLOCK:
+		if finding.Block != nil {
+			msg.WriteString("block " + finding.Block.Hash + " is missing")
+		} else {
+			msg.WriteString("a block reference is empty")
+		}
+	case pfs.FsckFindingType_HASH_MISMATCH:
+		msg.WriteString("the content of object " + finding.Object.Hash + " doesn't match its hash")
+	case pfs.FsckFindingType_TRUNCATED_BLOCK:
+		msg.WriteString("block " + finding.Block.Hash + " is shorter than expected")
+	case pfs.FsckFindingType_CORRUPT_TREE:
+		msg.WriteString("the tree could not be read")
+	}
+	if finding.Type != pfs.FsckFindingType_MISSING_OBJECT && finding.Type != pfs.FsckFindingType_HASH_MISMATCH && finding.Object != nil {
+		msg.WriteString(" (referenced by object " + finding.Object.Hash + ")")
+	}
+	if finding.Commit != nil {
+		msg.WriteString(fmt.Sprintf(" in commit %s@%s", finding.Commit.Repo.Name, finding.Commit.ID))
+	}
+	if finding.Path != "" {
+		msg.WriteString(" at " + finding.Path)
+	}
+	return msg.String()
+}
@@ -45,6 +45,10 @@ const (
 	bufferSize            = 15 * 1024 * 1024 // 15 MB
 )
 
+// replicationReplayInterval is how often replications to secondary object
+// stores that failed or were interrupted are retried
+const replicationReplayInterval = time.Minute
+
 type objBlockAPIServer struct {
 	log.Logger
 	dir       string
@@ -81,6 +85,10 @@ type objBlockAPIServer struct {
 // 2. PFS storage tests, which create several local ObjBlockAPIServers (none of
 //    which are primary but cannot collide)
 func newObjBlockAPIServer(dir string, cacheBytes int64, etcdAddress string, objClient obj.Client, duplicate bool) (*objBlockAPIServer, error) {
+	objClient, err := obj.ReplicateFromEnv(objClient)
+	if err != nil {
+		return nil, err
+	}
 	// defensive measure to make sure storage is working and error early if it's not
 	// this is where we'll find out if the credentials have been misconfigured
 	if err := obj.TestStorage(context.Background(), objClient); err != nil {
@@ -120,9 +128,22 @@ func newObjBlockAPIServer(dir string, cacheBytes int64, etcdAddress string, objC
 	}
 
 	go s.watchGC(etcdAddress)
+	// Only one ObjBlockAPIServer per process replays the replication log
+	if replicatedClient, ok := objClient.(*obj.ReplicatedClient); ok && !duplicate {
+		go s.replayReplication(replicatedClient)
+	}
 	return s, nil
 }
 
+// replayReplication periodically replays 'c's replication log
+func (s *objBlockAPIServer) replayReplication(c *obj.ReplicatedClient) {
+	for range time.Tick(replicationReplayInterval) {
+		if err := c.Replay(context.Background()); err != nil {
+			logrus.Errorf("error replaying object storage replication log: %v", err)
+		}
+	}
+}
+
 // prettyObjPath renders an object hash as a path, for more readable traces
 // and logs
 func (s *objBlockAPIServer) prettyObjPath(obj *pfsclient.Object) string {
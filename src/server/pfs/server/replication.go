@@ -0,0 +1,100 @@
+package server
+
+import (
+	"github.com/gogo/protobuf/types"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
+	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
+)
+
+// replicatedObjClient returns a client for pachd's object storage, which
+// must be replicated to at least one secondary object store
+func (d *driver) replicatedObjClient() (*obj.ReplicatedClient, error) {
+	objClient, err := obj.NewClientFromSecret(d.storageRoot)
+	if err != nil {
+		return nil, err
+	}
+	replicatedClient, ok := objClient.(*obj.ReplicatedClient)
+	if !ok {
+		return nil, errors.Errorf("object storage replication is not enabled (deploy with --storage-replica to enable it)")
+	}
+	return replicatedClient, nil
+}
+
+// checkIsAdmin returns an error if auth is active and the caller isn't a
+// cluster admin
+func (d *driver) checkIsAdmin(pachClient *client.APIClient, op string) error {
+	me, err := pachClient.WhoAmI(pachClient.Ctx(), &auth.WhoAmIRequest{})
+	if auth.IsErrNotActivated(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrapf(grpcutil.ScrubGRPC(err), "error during authorization check")
+	}
+	if !me.IsAdmin {
+		return &auth.ErrNotAuthorized{
+			Subject: me.Username,
+			AdminOp: op,
+		}
+	}
+	return nil
+}
+
+func (d *driver) inspectReplication(pachClient *client.APIClient) (*pfs.ReplicationInfo, error) {
+	if err := d.checkIsAdmin(pachClient, "InspectReplication"); err != nil {
+		return nil, err
+	}
+	ctx := pachClient.Ctx()
+	c, err := d.replicatedObjClient()
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := c.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return replicationInfo(statuses)
+}
+
+func (d *driver) reconcileReplication(pachClient *client.APIClient) (*pfs.ReplicationInfo, error) {
+	if err := d.checkIsAdmin(pachClient, "ReconcileReplication"); err != nil {
+		return nil, err
+	}
+	ctx := pachClient.Ctx()
+	c, err := d.replicatedObjClient()
+	if err != nil {
+		return nil, err
+	}
+	storageRoot, err := obj.StorageRootFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := c.Reconcile(ctx, storageRoot)
+	if err != nil {
+		return nil, err
+	}
+	return replicationInfo(statuses)
+}
+
+func replicationInfo(statuses []*obj.ReplicaStatus) (*pfs.ReplicationInfo, error) {
+	info := &pfs.ReplicationInfo{}
+	for _, status := range statuses {
+		replicaInfo := &pfs.ReplicaInfo{
+			Replica: status.Name,
+			Pending: status.Pending,
+			Copied:  status.Copied,
+		}
+		if !status.OldestPending.IsZero() {
+			oldestPending, err := types.TimestampProto(status.OldestPending)
+			if err != nil {
+				return nil, err
+			}
+			replicaInfo.OldestPending = oldestPending
+		}
+		info.Replicas = append(info.Replicas, replicaInfo)
+	}
+	return info, nil
+}
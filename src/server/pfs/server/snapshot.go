@@ -0,0 +1,322 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ancestry"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsconsts"
+	txnenv "github.com/pachyderm/pachyderm/src/server/pkg/transactionenv"
+)
+
+// snapshotAttempts is the number of times createSnapshot re-reads every repo if
+// repos are created or deleted, or output branches move, while it runs
+const snapshotAttempts = 5
+
+// errReposChanged is returned by a snapshot transaction if the set of repos
+// that it read isn't the set of repos that exist
+var errReposChanged = errors.New("repos changed while the snapshot was being created")
+
+// errJobsChanged is returned by a snapshot transaction if an output branch's
+// head isn't a commit whose job was looked up before the transaction
+var errJobsChanged = errors.New("output branches changed while the snapshot was being created")
+
+// createSnapshot records the head of every branch (and, for output branches,
+// the job that created it) and the spec commit of every pipeline in a new
+// snapshot. Every branch is read in the same STM as the one that writes the
+// snapshot, so the snapshot is consistent even if commits are being made while
+// it's created.
+func (d *driver) createSnapshot(pachClient *client.APIClient, snapshot *pfs.Snapshot) (*pfs.SnapshotInfo, error) {
+	// Validate arguments
+	if snapshot == nil {
+		return nil, errors.New("snapshot cannot be nil")
+	}
+	if err := ancestry.ValidateName(snapshot.Name); err != nil {
+		return nil, err
+	}
+
+	// Output commits never change jobs, so jobs looked up in one attempt are
+	// reused by the next
+	jobs := make(map[string]string)
+	for i := 0; i < snapshotAttempts; i++ {
+		snapshotInfo, err := d.createSnapshotAttempt(pachClient, snapshot, jobs)
+		if err == errReposChanged || err == errJobsChanged {
+			continue
+		}
+		return snapshotInfo, err
+	}
+	return nil, errors.Errorf("could not create snapshot %s: repos or output branches changed in each of %d attempts", snapshot.Name, snapshotAttempts)
+}
+
+// createSnapshotAttempt writes 'snapshot' if the set of repos is the same
+// before and after its branches are read, and returns errReposChanged if not.
+// The jobs that created output branches' heads are looked up (in PPS) before
+// the snapshot's transaction, and errJobsChanged is returned if any of those
+// heads moved in the meantime.
+func (d *driver) createSnapshotAttempt(pachClient *client.APIClient, snapshot *pfs.Snapshot, jobs map[string]string) (*pfs.SnapshotInfo, error) {
+	// Collections can't be listed in an STM, so list the repos first, and again
+	// after they've been read. The STM reads every repo at the revision of its
+	// first read, so if no repo was created or deleted between the two lists,
+	// the repos that it read are all of the repos at that revision.
+	repos, err := d.listRepoNames(pachClient)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.resolveSnapshotJobs(pachClient, repos, jobs); err != nil {
+		return nil, err
+	}
+
+	var snapshotInfo *pfs.SnapshotInfo
+	if err := d.txnEnv.WithWriteContext(pachClient.Ctx(), func(txnCtx *txnenv.TransactionContext) error {
+		snapshotInfo = &pfs.SnapshotInfo{
+			Snapshot: snapshot,
+			Created:  now(),
+		}
+		for _, repoName := range repos {
+			repoInfo := &pfs.RepoInfo{}
+			if err := d.repos.ReadWrite(txnCtx.Stm).Get(repoName, repoInfo); err != nil {
+				if col.IsErrNotFound(err) {
+					return errReposChanged
+				}
+				return err
+			}
+			isSpecRepo := repoName == ppsconsts.SpecRepo
+			if !isSpecRepo {
+				if err := d.checkIsAuthorizedInTransaction(txnCtx, repoInfo.Repo, auth.Scope_READER); err != nil {
+					return err
+				}
+			}
+			branches := repoInfo.Branches
+			sort.Slice(branches, func(i, j int) bool { return branches[i].Name < branches[j].Name })
+			for _, branch := range branches {
+				branchInfo := &pfs.BranchInfo{}
+				if err := d.branches(repoName).ReadWrite(txnCtx.Stm).Get(branch.Name, branchInfo); err != nil {
+					return errors.Wrapf(err, "could not read branch %s@%s", repoName, branch.Name)
+				}
+				// Each pipeline's spec commits are on a branch of the spec repo
+				// named after it
+				if isSpecRepo {
+					if branchInfo.Head != nil {
+						snapshotInfo.Pipelines = append(snapshotInfo.Pipelines, &pfs.SnapshotPipeline{
+							Pipeline:   branch.Name,
+							SpecCommit: branchInfo.Head,
+						})
+					}
+					continue
+				}
+				snapshotBranch := &pfs.SnapshotBranch{
+					Branch: branchInfo.Branch,
+					Head:   branchInfo.Head,
+				}
+				if branchInfo.Head != nil && len(branchInfo.Provenance) > 0 {
+					job, ok := jobs[branchInfo.Head.ID]
+					if !ok {
+						return errJobsChanged
+					}
+					snapshotBranch.Job = job
+				}
+				snapshotInfo.Branches = append(snapshotInfo.Branches, snapshotBranch)
+			}
+		}
+		reposAfter, err := d.listRepoNames(pachClient)
+		if err != nil {
+			return err
+		}
+		if !stringsEqual(repos, reposAfter) {
+			return errReposChanged
+		}
+		return d.snapshots.ReadWrite(txnCtx.Stm).Create(snapshot.Name, snapshotInfo)
+	}); err != nil {
+		if col.IsErrExists(err) {
+			return nil, errors.Errorf("snapshot %s already exists", snapshot.Name)
+		}
+		return nil, err
+	}
+	return snapshotInfo, nil
+}
+
+// listRepoNames returns the sorted names of every repo
+func (d *driver) listRepoNames(pachClient *client.APIClient) ([]string, error) {
+	var repos []string
+	repoInfo := &pfs.RepoInfo{}
+	if err := d.repos.ReadOnly(pachClient.Ctx()).List(repoInfo, col.DefaultOptions, func(repoName string) error {
+		repos = append(repos, repoName)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.Strings(repos)
+	return repos, nil
+}
+
+// resolveSnapshotJobs looks up the job that created the head of each output
+// branch in 'repos', and caches it in 'jobs'. Repos and branches that are
+// deleted while it runs are skipped; createSnapshotAttempt notices them.
+func (d *driver) resolveSnapshotJobs(pachClient *client.APIClient, repos []string, jobs map[string]string) error {
+	for _, repoName := range repos {
+		if repoName == ppsconsts.SpecRepo {
+			continue
+		}
+		repoInfo := &pfs.RepoInfo{}
+		if err := d.repos.ReadOnly(pachClient.Ctx()).Get(repoName, repoInfo); err != nil {
+			if col.IsErrNotFound(err) {
+				continue
+			}
+			return err
+		}
+		for _, branch := range repoInfo.Branches {
+			branchInfo := &pfs.BranchInfo{}
+			if err := d.branches(repoName).ReadOnly(pachClient.Ctx()).Get(branch.Name, branchInfo); err != nil {
+				if col.IsErrNotFound(err) {
+					continue
+				}
+				return err
+			}
+			if branchInfo.Head != nil && len(branchInfo.Provenance) > 0 {
+				if _, err := outputCommitJob(pachClient, branchInfo.Head, jobs); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// outputCommitJob returns the ID of the job that created 'commit', or "" if no
+// job created it (e.g. if it's a commit to a spout's output branch). Results
+// are cached in 'jobs'.
+func outputCommitJob(pachClient *client.APIClient, commit *pfs.Commit, jobs map[string]string) (string, error) {
+	if job, ok := jobs[commit.ID]; ok {
+		return job, nil
+	}
+	jobInfo, err := pachClient.InspectJobOutputCommit(commit.Repo.Name, commit.ID, false)
+	if err != nil {
+		if !strings.Contains(err.Error(), fmt.Sprintf("job with output commit %s not found", commit.ID)) {
+			return "", errors.Wrapf(err, "could not find the job that created %s@%s", commit.Repo.Name, commit.ID)
+		}
+		jobs[commit.ID] = ""
+		return "", nil
+	}
+	jobs[commit.ID] = jobInfo.Job.ID
+	return jobInfo.Job.ID, nil
+}
+
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *driver) inspectSnapshot(pachClient *client.APIClient, snapshot *pfs.Snapshot) (*pfs.SnapshotInfo, error) {
+	// Validate arguments
+	if snapshot == nil {
+		return nil, errors.New("snapshot cannot be nil")
+	}
+
+	snapshotInfo := &pfs.SnapshotInfo{}
+	if err := d.snapshots.ReadOnly(pachClient.Ctx()).Get(snapshot.Name, snapshotInfo); err != nil {
+		if col.IsErrNotFound(err) {
+			return nil, errors.Errorf("snapshot %s not found", snapshot.Name)
+		}
+		return nil, err
+	}
+	checked := make(map[string]bool)
+	for _, snapshotBranch := range snapshotInfo.Branches {
+		repo := snapshotBranch.Branch.Repo
+		if checked[repo.Name] {
+			continue
+		}
+		if err := d.checkIsAuthorized(pachClient, repo, auth.Scope_READER); err != nil {
+			return nil, err
+		}
+		checked[repo.Name] = true
+	}
+	return snapshotInfo, nil
+}
+
+// restoreBranchesFromSnapshot points each branch in 'snapshot' (or, if
+// 'repos' is set, each of its branches in 'repos') back at the commit that it
+// pointed to when the snapshot was created. Branches keep their current
+// provenance, and branches that have been deleted since are re-created
+// without provenance. Downstream branches get new output commits if their
+// heads no longer match their restored provenance (e.g. if their pipeline has
+// been updated since the snapshot).
+func (d *driver) restoreBranchesFromSnapshot(txnCtx *txnenv.TransactionContext, snapshot *pfs.Snapshot, repos []*pfs.Repo) error {
+	// Validate arguments
+	if snapshot == nil {
+		return errors.New("snapshot cannot be nil")
+	}
+
+	snapshotInfo := &pfs.SnapshotInfo{}
+	if err := d.snapshots.ReadWrite(txnCtx.Stm).Get(snapshot.Name, snapshotInfo); err != nil {
+		if col.IsErrNotFound(err) {
+			return errors.Errorf("snapshot %s not found", snapshot.Name)
+		}
+		return err
+	}
+	restoreRepo := make(map[string]bool)
+	for _, repo := range repos {
+		restoreRepo[repo.Name] = true
+	}
+	for _, snapshotBranch := range snapshotInfo.Branches {
+		branch, head := snapshotBranch.Branch, snapshotBranch.Head
+		if len(restoreRepo) > 0 && !restoreRepo[branch.Repo.Name] {
+			continue
+		}
+		if err := d.checkIsAuthorizedInTransaction(txnCtx, branch.Repo, auth.Scope_WRITER); err != nil {
+			return err
+		}
+		var headInfo *pfs.CommitInfo
+		if head != nil {
+			var err error
+			if headInfo, err = d.resolveCommit(txnCtx.Stm, head); err != nil {
+				return errors.Wrapf(err, "cannot restore %s@%s to %s", branch.Repo.Name, branch.Name, head.ID)
+			}
+		}
+
+		branchInfo := &pfs.BranchInfo{}
+		if err := d.branches(branch.Repo.Name).ReadWrite(txnCtx.Stm).Get(branch.Name, branchInfo); err != nil {
+			if !col.IsErrNotFound(err) {
+				return err
+			}
+			if err := d.createBranch(txnCtx, branch, head, nil); err != nil {
+				return errors.Wrapf(err, "cannot re-create %s@%s", branch.Repo.Name, branch.Name)
+			}
+			continue
+		}
+		if proto.Equal(branchInfo.Head, head) {
+			continue
+		}
+		branchInfo.Head = head
+		if err := d.branches(branch.Repo.Name).ReadWrite(txnCtx.Stm).Put(branch.Name, branchInfo); err != nil {
+			return err
+		}
+		if branch.Name == "master" && headInfo != nil {
+			repoInfo := &pfs.RepoInfo{}
+			if err := d.repos.ReadWrite(txnCtx.Stm).Update(branch.Repo.Name, repoInfo, func() error {
+				repoInfo.SizeBytes = headInfo.SizeBytes
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		if err := txnCtx.PropagateCommit(branch, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
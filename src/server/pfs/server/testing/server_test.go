@@ -23,10 +23,13 @@ import (
 	"github.com/pachyderm/pachyderm/src/client/pfs"
 	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
 	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	pfsserver "github.com/pachyderm/pachyderm/src/server/pfs"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ancestry"
 	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
 	"github.com/pachyderm/pachyderm/src/server/pkg/hashtree"
 	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsconsts"
 	"github.com/pachyderm/pachyderm/src/server/pkg/sql"
 	pfssync "github.com/pachyderm/pachyderm/src/server/pkg/sync"
 	"github.com/pachyderm/pachyderm/src/server/pkg/testpachd"
@@ -1063,6 +1066,67 @@ func TestAncestrySyntax(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestTimeTravelSyntax(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := "test"
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+
+		var commits []*pfs.Commit
+		var finished []time.Time
+		for i := 1; i <= 3; i++ {
+			commit, err := env.PachClient.StartCommit(repo, "master")
+			require.NoError(t, err)
+			_, err = env.PachClient.PutFileOverwrite(repo, commit.ID, "file", strings.NewReader(fmt.Sprint(i)), 0)
+			require.NoError(t, err)
+			require.NoError(t, env.PachClient.FinishCommit(repo, commit.ID))
+			commitInfo, err := env.PachClient.InspectCommit(repo, commit.ID)
+			require.NoError(t, err)
+			f, err := types.TimestampFromProto(commitInfo.Finished)
+			require.NoError(t, err)
+			commits = append(commits, commit)
+			finished = append(finished, f)
+		}
+
+		// A commit's finish time resolves to that commit, as does any time
+		// before its child finished
+		commitInfo, err := env.PachClient.InspectCommit(repo, ancestry.AddTime("master", finished[1]))
+		require.NoError(t, err)
+		require.Equal(t, commits[1], commitInfo.Commit)
+		between := finished[1].Add(finished[2].Sub(finished[1]) / 2)
+		commitInfo, err = env.PachClient.InspectCommit(repo, ancestry.AddTime("master", between))
+		require.NoError(t, err)
+		require.Equal(t, commits[1], commitInfo.Commit)
+		commitInfo, err = env.PachClient.InspectCommit(repo, ancestry.AddTime("master", time.Now()))
+		require.NoError(t, err)
+		require.Equal(t, commits[2], commitInfo.Commit)
+
+		// Time travel can be combined with ancestry syntax
+		commitInfo, err = env.PachClient.InspectCommit(repo, ancestry.AddTime("master^", time.Now()))
+		require.NoError(t, err)
+		require.Equal(t, commits[1], commitInfo.Commit)
+		commitInfo, err = env.PachClient.InspectCommit(repo, ancestry.AddTime(commits[2].ID, finished[0]))
+		require.NoError(t, err)
+		require.Equal(t, commits[0], commitInfo.Commit)
+
+		// No commit had finished before the first one
+		_, err = env.PachClient.InspectCommit(repo, ancestry.AddTime("master", finished[0].Add(-time.Second)))
+		require.YesError(t, err)
+		_, err = env.PachClient.InspectCommit(repo, "master@{yesterday}")
+		require.YesError(t, err)
+
+		var buffer bytes.Buffer
+		require.NoError(t, env.PachClient.GetFile(repo, ancestry.AddTime("master", finished[0]), "file", 0, 0, &buffer))
+		require.Equal(t, "1", buffer.String())
+		fileInfos, err := env.PachClient.ListFile(repo, ancestry.AddTime("master", between), "/")
+		require.NoError(t, err)
+		require.Equal(t, 1, len(fileInfos))
+		require.Equal(t, commits[1].ID, fileInfos[0].File.Commit.ID)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
 // TestProvenance implements the following DAG
 //  A ─▶ B ─▶ C ─▶ D
 //            ▲
@@ -3615,6 +3679,67 @@ func TestDiff(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestDiffFileContent(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		repo := tu.UniqueString("TestDiffFileContent")
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		putFiles := func(files map[string]string, deleted ...string) {
+			_, err := env.PachClient.StartCommit(repo, "master")
+			require.NoError(t, err)
+			for _, file := range deleted {
+				require.NoError(t, env.PachClient.DeleteFile(repo, "master", file))
+			}
+			for file, content := range files {
+				require.NoError(t, env.PachClient.DeleteFile(repo, "master", file))
+				_, err = env.PachClient.PutFile(repo, "master", file, strings.NewReader(content))
+				require.NoError(t, err)
+			}
+			require.NoError(t, env.PachClient.FinishCommit(repo, "master"))
+		}
+		putFiles(map[string]string{
+			"config.txt":  "a\nb\nc\n",
+			"labels.csv":  "id,label\n1,cat\n2,dog\n",
+			"image.bin":   "\x00\x01",
+			"large.txt":   strings.Repeat("x\n", 100),
+			"deleted.txt": "gone\n",
+		})
+		putFiles(map[string]string{
+			"config.txt": "a\nB\nc\n",
+			"labels.csv": "id,label\n2,dog\n3,bird\n",
+			"image.bin":  "\x00\x02",
+			"large.txt":  strings.Repeat("y\n", 100),
+			"added.txt":  "new\n",
+		}, "deleted.txt")
+
+		diffs, err := env.PachClient.DiffFileContent(repo, "master", "", "", "", "", false, 100)
+		require.NoError(t, err)
+		require.Equal(t, 6, len(diffs))
+		byPath := make(map[string]*pfs.FileContentDiff)
+		for _, diff := range diffs {
+			if diff.NewFile != nil {
+				byPath[strings.TrimPrefix(diff.NewFile.Path, "/")] = diff
+			} else {
+				byPath[strings.TrimPrefix(diff.OldFile.Path, "/")] = diff
+			}
+		}
+		require.Equal(t, "--- a/config.txt\n+++ b/config.txt\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n", byPath["config.txt"].Unified)
+		require.Equal(t, []string{"3,bird"}, byPath["labels.csv"].AddedRows)
+		require.Equal(t, []string{"1,cat"}, byPath["labels.csv"].DeletedRows)
+		require.True(t, byPath["image.bin"].Binary)
+		require.True(t, byPath["large.txt"].TooLarge)
+		require.Equal(t, "--- /dev/null\n+++ b/added.txt\n@@ -0,0 +1 @@\n+new\n", byPath["added.txt"].Unified)
+		require.Nil(t, byPath["deleted.txt"].NewFile)
+		require.Equal(t, "--- a/deleted.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-gone\n", byPath["deleted.txt"].Unified)
+
+		// Diffs must fit in a grpc message, so large limits are rejected
+		_, err = env.PachClient.DiffFileContent(repo, "master", "", "", "", "", false, 100*1024*1024)
+		require.YesError(t, err)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
 func TestGlobFile(t *testing.T) {
 	t.Parallel()
 	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
@@ -3953,6 +4078,296 @@ func TestCopyFile(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestMergeBranch(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		if testing.Short() {
+			t.Skip("Skipping integration tests in short mode")
+		}
+
+		c := env.PachClient
+		repo := tu.UniqueString("TestMergeBranch")
+		require.NoError(t, c.CreateRepo(repo))
+		commit := func(branch string, f func()) {
+			_, err := c.StartCommit(repo, branch)
+			require.NoError(t, err)
+			f()
+			require.NoError(t, c.FinishCommit(repo, branch))
+		}
+		put := func(branch, path, data string) {
+			_, err := c.PutFileOverwrite(repo, branch, path, strings.NewReader(data), 0)
+			require.NoError(t, err)
+		}
+		checkFiles := func(branch string, expected map[string]string) {
+			fileInfos, err := c.ListFile(repo, branch, "/")
+			require.NoError(t, err)
+			require.Equal(t, len(expected), len(fileInfos))
+			for path, data := range expected {
+				var b bytes.Buffer
+				require.NoError(t, c.GetFile(repo, branch, path, 0, 0, &b))
+				require.Equal(t, data, b.String())
+			}
+		}
+
+		commit("master", func() {
+			put("master", "a", "a")
+			put("master", "b", "b")
+			put("master", "c", "c")
+		})
+		require.NoError(t, c.CreateBranch(repo, "base", "master", nil))
+		require.NoError(t, c.CreateBranch(repo, "feature", "master", nil))
+		commit("feature", func() {
+			put("feature", "a", "a-feature")
+			require.NoError(t, c.DeleteFile(repo, "feature", "b"))
+			put("feature", "c", "c-feature")
+			put("feature", "d", "d")
+		})
+		commit("master", func() {
+			put("master", "c", "c-master")
+			put("master", "e", "e")
+		})
+
+		// By default, conflicts fail the merge
+		mergeCommit, conflicts, err := c.MergeBranch(repo, "feature", "master", pfs.MergeStrategy_CONFLICT)
+		require.NoError(t, err)
+		require.Nil(t, mergeCommit)
+		require.Equal(t, []string{"/c"}, conflicts)
+
+		require.NoError(t, c.CreateBranch(repo, "other", "master", nil))
+
+		// "ours" applies the non-conflicting changes and keeps master's files
+		mergeCommit, conflicts, err = c.MergeBranch(repo, "feature", "master", pfs.MergeStrategy_OURS)
+		require.NoError(t, err)
+		require.NotNil(t, mergeCommit)
+		require.Equal(t, []string{"/c"}, conflicts)
+		checkFiles("master", map[string]string{"a": "a-feature", "c": "c-master", "d": "d", "e": "e"})
+		mergeInfo, err := c.InspectCommit(repo, mergeCommit.ID)
+		require.NoError(t, err)
+		require.NotNil(t, mergeInfo.MergeParent)
+
+		// "theirs" takes the conflicting files from the feature branch
+		mergeCommit, conflicts, err = c.MergeBranch(repo, "feature", "other", pfs.MergeStrategy_THEIRS)
+		require.NoError(t, err)
+		require.NotNil(t, mergeCommit)
+		require.Equal(t, []string{"/c"}, conflicts)
+		checkFiles("other", map[string]string{"a": "a-feature", "c": "c-feature", "d": "d", "e": "e"})
+
+		// Merging again only considers the changes made since the last merge,
+		// so conflicts that were already resolved aren't reported again
+		mergeCommit, conflicts, err = c.MergeBranch(repo, "feature", "master", pfs.MergeStrategy_CONFLICT)
+		require.NoError(t, err)
+		require.Nil(t, mergeCommit)
+		require.Equal(t, 0, len(conflicts))
+		commit("feature", func() {
+			put("feature", "d", "d-feature")
+		})
+		mergeCommit, conflicts, err = c.MergeBranch(repo, "feature", "master", pfs.MergeStrategy_CONFLICT)
+		require.NoError(t, err)
+		require.NotNil(t, mergeCommit)
+		require.Equal(t, 0, len(conflicts))
+		checkFiles("master", map[string]string{"a": "a-feature", "c": "c-master", "d": "d-feature", "e": "e"})
+
+		// Merging a branch that master already contains is a no-op
+		mergeCommit, conflicts, err = c.MergeBranch(repo, "base", "master", pfs.MergeStrategy_CONFLICT)
+		require.NoError(t, err)
+		require.Nil(t, mergeCommit)
+		require.Equal(t, 0, len(conflicts))
+
+		_, _, err = c.MergeBranch(repo, "master", "master", pfs.MergeStrategy_CONFLICT)
+		require.YesError(t, err)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+// TestMergeBranchTypeConflict tests that a path that's a file on one branch
+// and a directory on the other is a merge conflict
+func TestMergeBranchTypeConflict(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		if testing.Short() {
+			t.Skip("Skipping integration tests in short mode")
+		}
+
+		c := env.PachClient
+		repo := tu.UniqueString("TestMergeBranchTypeConflict")
+		require.NoError(t, c.CreateRepo(repo))
+		_, err := c.PutFile(repo, "master", "a", strings.NewReader("a"))
+		require.NoError(t, err)
+		require.NoError(t, c.CreateBranch(repo, "feature", "master", nil))
+		_, err = c.PutFile(repo, "feature", "dir/file", strings.NewReader("feature"))
+		require.NoError(t, err)
+		_, err = c.PutFile(repo, "feature", "other", strings.NewReader("feature"))
+		require.NoError(t, err)
+		_, err = c.PutFile(repo, "master", "dir", strings.NewReader("master"))
+		require.NoError(t, err)
+		_, err = c.PutFile(repo, "master", "other/file", strings.NewReader("master"))
+		require.NoError(t, err)
+
+		mergeCommit, conflicts, err := c.MergeBranch(repo, "feature", "master", pfs.MergeStrategy_CONFLICT)
+		require.NoError(t, err)
+		require.Nil(t, mergeCommit)
+		require.Equal(t, []string{"/dir/file", "/other"}, conflicts)
+
+		// "theirs" replaces master's file and directory with feature's
+		mergeCommit, conflicts, err = c.MergeBranch(repo, "feature", "master", pfs.MergeStrategy_THEIRS)
+		require.NoError(t, err)
+		require.NotNil(t, mergeCommit)
+		require.Equal(t, []string{"/dir/file", "/other"}, conflicts)
+		fileInfo, err := c.InspectFile(repo, "master", "dir")
+		require.NoError(t, err)
+		require.Equal(t, pfs.FileType_DIR, fileInfo.FileType)
+		fileInfo, err = c.InspectFile(repo, "master", "other")
+		require.NoError(t, err)
+		require.Equal(t, pfs.FileType_FILE, fileInfo.FileType)
+		var b bytes.Buffer
+		require.NoError(t, c.GetFile(repo, "master", "dir/file", 0, 0, &b))
+		require.Equal(t, "feature", b.String())
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestSnapshot(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		if testing.Short() {
+			t.Skip("Skipping integration tests in short mode")
+		}
+
+		c := env.PachClient
+		require.NoError(t, c.CreateRepo("in"))
+		require.NoError(t, c.CreateRepo("out"))
+		require.NoError(t, c.CreateBranch("out", "master", "", []*pfs.Branch{pclient.NewBranch("in", "master")}))
+		commit := func(repo, branch string) *pfs.Commit {
+			commit, err := c.StartCommit(repo, branch)
+			require.NoError(t, err)
+			require.NoError(t, c.FinishCommit(repo, commit.ID))
+			return commit
+		}
+		head := func(repo, branch string) *pfs.Commit {
+			branchInfo, err := c.InspectBranch(repo, branch)
+			require.NoError(t, err)
+			return branchInfo.Head
+		}
+		commit("in", "master")
+		inHead := commit("in", "master")
+		commit("in", "dev")
+		outHead := head("out", "master")
+		specCommit := commit(ppsconsts.SpecRepo, "pipeline")
+		// Only "out" has a pipeline, and only its current head has a job
+		env.MockPachd.PPS.InspectJob.Use(func(_ context.Context, req *pps.InspectJobRequest) (*pps.JobInfo, error) {
+			if req.OutputCommit.ID == outHead.ID {
+				return &pps.JobInfo{Job: pclient.NewJob("out-job")}, nil
+			}
+			return nil, errors.Errorf("job with output commit %s not found", req.OutputCommit.ID)
+		})
+
+		snapshotInfo, err := c.CreateSnapshot("release-12")
+		require.NoError(t, err)
+		require.Equal(t, 3, len(snapshotInfo.Branches))
+		require.Equal(t, "dev", snapshotInfo.Branches[0].Branch.Name)
+		require.Equal(t, inHead, snapshotInfo.Branches[1].Head)
+		require.Equal(t, "", snapshotInfo.Branches[1].Job)
+		require.Equal(t, outHead, snapshotInfo.Branches[2].Head)
+		require.Equal(t, "out-job", snapshotInfo.Branches[2].Job)
+		require.Equal(t, 1, len(snapshotInfo.Pipelines))
+		require.Equal(t, "pipeline", snapshotInfo.Pipelines[0].Pipeline)
+		require.Equal(t, specCommit, snapshotInfo.Pipelines[0].SpecCommit)
+		inspected, err := c.InspectSnapshot("release-12")
+		require.NoError(t, err)
+		require.Equal(t, snapshotInfo, inspected)
+		_, err = c.CreateSnapshot("release-12")
+		require.YesError(t, err)
+		_, err = c.InspectSnapshot("release-13")
+		require.YesError(t, err)
+
+		commit("in", "master")
+		require.NoError(t, c.DeleteBranch("in", "dev", false))
+		require.NotEqual(t, outHead.ID, head("out", "master").ID)
+
+		// Restoring only "in" gives "out" a new commit with the restored input
+		require.NoError(t, c.RestoreBranchesFromSnapshot("release-12", "in"))
+		require.Equal(t, inHead, head("in", "master"))
+		require.NotNil(t, head("in", "dev"))
+		outCommitInfo, err := c.InspectCommit("out", "master")
+		require.NoError(t, err)
+		require.NotEqual(t, outHead.ID, outCommitInfo.Commit.ID)
+		require.Equal(t, inHead.ID, outCommitInfo.Provenance[0].Commit.ID)
+
+		// Restoring everything puts "out" back, without a new commit
+		require.NoError(t, c.RestoreBranchesFromSnapshot("release-12"))
+		require.Equal(t, inHead, head("in", "master"))
+		require.Equal(t, outHead, head("out", "master"))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestLabels(t *testing.T) {
+	t.Parallel()
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		if testing.Short() {
+			t.Skip("Skipping integration tests in short mode")
+		}
+
+		c := env.PachClient
+		repo := tu.UniqueString("TestLabels")
+		_, err := c.PfsAPIClient.CreateRepo(c.Ctx(), &pfs.CreateRepoRequest{
+			Repo:   pclient.NewRepo(repo),
+			Labels: map[string]string{"team": "vision"},
+		})
+		require.NoError(t, err)
+		repoInfos, err := c.ListRepoByLabel(map[string]string{"team": "vision"})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(repoInfos))
+		require.Equal(t, repo, repoInfos[0].Repo.Name)
+		require.NoError(t, c.LabelRepo(repo, nil, []string{"team"}))
+		repoInfos, err = c.ListRepoByLabel(map[string]string{"team": "vision"})
+		require.NoError(t, err)
+		require.Equal(t, 0, len(repoInfos))
+
+		// Labels can be set in StartCommit, FinishCommit, and afterwards
+		commit1, err := c.PfsAPIClient.StartCommit(c.Ctx(), &pfs.StartCommitRequest{
+			Parent: pclient.NewCommit(repo, ""),
+			Branch: "master",
+			Labels: map[string]string{"source": "vendorA"},
+		})
+		require.NoError(t, err)
+		_, err = c.PfsAPIClient.FinishCommit(c.Ctx(), &pfs.FinishCommitRequest{
+			Commit: commit1,
+			Labels: map[string]string{"validated": "true"},
+		})
+		require.NoError(t, err)
+		commit2, err := c.StartCommit(repo, "master")
+		require.NoError(t, err)
+		require.NoError(t, c.FinishCommit(repo, commit2.ID))
+		require.NoError(t, c.LabelCommit(repo, commit2.ID, map[string]string{"source": "vendorB"}, nil))
+
+		commitInfo, err := c.InspectCommit(repo, commit1.ID)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"source": "vendorA", "validated": "true"}, commitInfo.Labels)
+
+		listCommit := func(selector map[string]string) []string {
+			var ids []string
+			require.NoError(t, c.ListCommitByLabelF(repo, "master", "", 0, false, selector, func(ci *pfs.CommitInfo) error {
+				ids = append(ids, ci.Commit.ID)
+				return nil
+			}))
+			return ids
+		}
+		require.Equal(t, []string{commit1.ID}, listCommit(map[string]string{"source": "vendorA", "validated": "true"}))
+		require.Equal(t, []string{commit2.ID}, listCommit(map[string]string{"source": "vendorB"}))
+		require.Equal(t, []string{commit2.ID, commit1.ID}, listCommit(nil))
+
+		require.NoError(t, c.LabelCommit(repo, commit1.ID, nil, []string{"validated"}))
+		require.Equal(t, 0, len(listCommit(map[string]string{"validated": "true"})))
+		require.YesError(t, c.LabelCommit(repo, commit1.ID, map[string]string{"a=b": "c"}, nil))
+		return nil
+	})
+	require.NoError(t, err)
+}
+
 func TestCopyFileHeaderFooter(t *testing.T) {
 	t.Parallel()
 	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
@@ -6011,6 +6426,92 @@ func TestFsckFix(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestFsckDeep(t *testing.T) {
+	// Deep fsck reads object storage directly, which requires the storage
+	// environment variables (paths are relative to the storage root). This
+	// test isn't parallel, so that they're only set while it runs.
+	os.Setenv(obj.StorageBackendEnvVar, obj.Local)
+	os.Setenv(obj.PachRootEnvVar, "")
+	defer os.Unsetenv(obj.StorageBackendEnvVar)
+	defer os.Unsetenv(obj.PachRootEnvVar)
+	err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		fsckDeep := func(fix bool) (findings []*pfs.FsckFinding, fixes []string) {
+			require.NoError(t, env.PachClient.FsckDeep(fix, 1, func(resp *pfs.FsckResponse) error {
+				if resp.Finding != nil {
+					findings = append(findings, resp.Finding)
+				}
+				if resp.Fix != "" {
+					fixes = append(fixes, resp.Fix)
+				}
+				return nil
+			}))
+			return findings, fixes
+		}
+
+		repo := "repo"
+		require.NoError(t, env.PachClient.CreateRepo(repo))
+		_, err := env.PachClient.PutFile(repo, "master", "file", strings.NewReader("foo"))
+		require.NoError(t, err)
+		findings, _ := fsckDeep(false)
+		require.Equal(t, 0, len(findings))
+
+		// Find the block holding the file's content
+		fileInfo, err := env.PachClient.InspectFile(repo, "master", "file")
+		require.NoError(t, err)
+		require.Equal(t, 1, len(fileInfo.Objects))
+		objectInfo, err := env.PachClient.InspectObject(fileInfo.Objects[0].Hash)
+		require.NoError(t, err)
+		objClient, err := obj.NewClientFromSecret(env.StorageRoot)
+		require.NoError(t, err)
+		blockPath, err := obj.BlockPathFromEnv(objectInfo.BlockRef.Block)
+		require.NoError(t, err)
+		block := &bytes.Buffer{}
+		r, err := objClient.Reader(context.Background(), blockPath, 0, 0)
+		require.NoError(t, err)
+		_, err = io.Copy(block, r)
+		require.NoError(t, err)
+		require.NoError(t, r.Close())
+
+		// Corrupt the block, without fixing
+		writeObj(t, objClient, blockPath, strings.Replace(block.String(), "foo", "bar", 1))
+		findings, fixes := fsckDeep(false)
+		require.Equal(t, 1, len(findings))
+		require.Equal(t, pfs.FsckFindingType_HASH_MISMATCH, findings[0].Type)
+		require.Equal(t, "file", strings.TrimPrefix(findings[0].Path, "/"))
+		require.Equal(t, 0, len(fixes))
+
+		// Fixing quarantines the commit, which can't be read until it's
+		// released
+		require.NoError(t, objClient.Delete(context.Background(), blockPath))
+		findings, fixes = fsckDeep(true)
+		require.Equal(t, 1, len(findings))
+		require.Equal(t, pfs.FsckFindingType_MISSING_BLOCK, findings[0].Type)
+		require.Equal(t, 1, len(fixes))
+		commitInfo, err := env.PachClient.InspectCommit(repo, "master")
+		require.NoError(t, err)
+		require.True(t, commitInfo.Quarantined != "")
+		err = env.PachClient.GetFile(repo, "master", "file", 0, 0, &bytes.Buffer{})
+		require.YesError(t, err)
+		require.True(t, pfsserver.IsCommitQuarantinedErr(err))
+		_, err = env.PachClient.ListFile(repo, "master", "")
+		require.YesError(t, err)
+
+		// Restoring the block and fixing again releases the commit
+		writeObj(t, objClient, blockPath, block.String())
+		findings, fixes = fsckDeep(true)
+		require.Equal(t, 0, len(findings))
+		require.Equal(t, 1, len(fixes))
+		commitInfo, err = env.PachClient.InspectCommit(repo, "master")
+		require.NoError(t, err)
+		require.Equal(t, "", commitInfo.Quarantined)
+		buf := &bytes.Buffer{}
+		require.NoError(t, env.PachClient.GetFile(repo, "master", "file", 0, 0, buf))
+		require.Equal(t, "foo", buf.String())
+		return nil
+	})
+	require.NoError(t, err)
+}
+
 const (
 	inputRepo          = iota // create a new input repo
 	inputBranch               // create a new branch on an existing input repo
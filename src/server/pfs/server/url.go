@@ -0,0 +1,328 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"golang.org/x/sync/errgroup"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/client/limit"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsconsts"
+)
+
+// maxHTTPResumes is how many times an interrupted HTTP download is resumed
+const maxHTTPResumes = 5
+
+// urlSecret returns the data in the Pachyderm secret 'name', which holds the
+// credentials for a put file URL. Only the user that created the secret (or
+// an admin) can use it.
+func (d *driver) urlSecret(pachClient *client.APIClient, name string) (map[string][]byte, error) {
+	if name == "" {
+		return nil, nil
+	}
+	secret, err := d.env.GetKubeClient().CoreV1().Secrets(d.env.Namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get secret %q", name)
+	}
+	// Only secrets created through pachyderm can be used, so that put file
+	// can't be used to read pachd's own secrets
+	if secret.Labels["secret-source"] != "pachyderm-user" {
+		return nil, errors.Errorf("secret %q was not created with 'pachctl create secret'", name)
+	}
+	me, err := pachClient.WhoAmI(pachClient.Ctx(), &auth.WhoAmIRequest{})
+	if auth.IsErrNotActivated(err) {
+		return secret.Data, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(grpcutil.ScrubGRPC(err), "error during authorization check")
+	}
+	if owner, ok := secret.Annotations[ppsconsts.SecretOwnerAnnotation]; !me.IsAdmin && (!ok || owner != me.Username) {
+		return nil, errors.Errorf("%s is not authorized to perform this operation; secret %q can only be used by the user that created it, or an admin", me.Username, name)
+	}
+	return secret.Data, nil
+}
+
+// withSHA256 returns a reader that reads 'r', and returns an error instead of
+// io.EOF if its content doesn't match req.ExpectedSha256
+func withSHA256(req *pfs.PutFileRequest, r io.Reader) io.Reader {
+	if req.ExpectedSha256 == "" {
+		return r
+	}
+	return &sha256Reader{
+		r:        r,
+		hash:     sha256.New(),
+		expected: strings.ToLower(req.ExpectedSha256),
+		path:     req.File.Path,
+	}
+}
+
+type sha256Reader struct {
+	r        io.Reader
+	hash     hash.Hash
+	expected string
+	path     string
+}
+
+func (r *sha256Reader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.hash.Write(p[:n])
+	if err == io.EOF {
+		if actual := hex.EncodeToString(r.hash.Sum(nil)); actual != r.expected {
+			return n, errors.Errorf("sha256 of %s is %s, but %s was expected", r.path, actual, r.expected)
+		}
+	}
+	return n, err
+}
+
+// httpGet GETs 'url' starting at 'offset', authenticated with the
+// credentials in 'creds'
+func httpGet(ctx context.Context, url string, creds map[string][]byte, offset int64, validator string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if token, ok := creds["token"]; ok {
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	} else if username, ok := creds["username"]; ok {
+		req.SetBasicAuth(string(username), string(creds["password"]))
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		// The server only returns the range if the content hasn't changed
+		req.Header.Set("If-Range", validator)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		resp.Body.Close()
+		return nil, errors.Errorf("error retrieving content from %q: %s", url, resp.Status)
+	}
+	return resp, nil
+}
+
+// httpReader reads the body of an HTTP response. If the server supports
+// range requests and identifies the content (with an ETag or Last-Modified
+// header), it resumes the download where it left off when reading fails.
+type httpReader struct {
+	ctx    context.Context
+	url    string
+	creds  map[string][]byte
+	body   io.ReadCloser
+	offset int64
+	// validator is sent in If-Range when resuming, so that the rest of the
+	// download comes from the same version of the content. It's empty if the
+	// download can't be resumed.
+	validator string
+	resumes   int
+}
+
+func openHTTP(ctx context.Context, url string, creds map[string][]byte) (*httpReader, error) {
+	resp, err := httpGet(ctx, url, creds, 0, "")
+	if err != nil {
+		return nil, err
+	}
+	r := &httpReader{
+		ctx:   ctx,
+		url:   url,
+		creds: creds,
+		body:  resp.Body,
+	}
+	if resp.Header.Get("Accept-Ranges") == "bytes" {
+		// Weak ETags can't be used in If-Range
+		if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			r.validator = etag
+		} else {
+			r.validator = resp.Header.Get("Last-Modified")
+		}
+	}
+	return r, nil
+}
+
+func (r *httpReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+		if err == nil || err == io.EOF || r.validator == "" || r.resumes >= maxHTTPResumes || r.ctx.Err() != nil {
+			return n, err
+		}
+		if resumeErr := r.resume(); resumeErr != nil {
+			return n, errors.Wrapf(err, "could not resume download (%v)", resumeErr)
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+func (r *httpReader) resume() error {
+	r.body.Close()
+	r.resumes++
+	resp, err := httpGet(r.ctx, r.url, r.creds, r.offset, r.validator)
+	if err != nil {
+		return err
+	}
+	// If the content has changed, the server returns all of it instead
+	if resp.StatusCode != http.StatusPartialContent ||
+		!strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", r.offset)) {
+		resp.Body.Close()
+		return errors.Errorf("%q didn't return the requested range (it may have changed since the download started)", r.url)
+	}
+	r.body = resp.Body
+	return nil
+}
+
+func (r *httpReader) Close() error {
+	return r.body.Close()
+}
+
+// sftpConnect connects to the SFTP server in 'u', logging in with the
+// credentials in 'u' and 'creds'
+func sftpConnect(u *url.URL, creds map[string][]byte) (*sftp.Client, func() error, error) {
+	username := u.User.Username()
+	if username == "" {
+		username = string(creds["username"])
+	}
+	var auth []ssh.AuthMethod
+	if key, ok := creds["ssh_private_key"]; ok {
+		signer, err := ssh.ParsePrivateKey(key)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "could not parse ssh_private_key")
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	password, ok := u.User.Password()
+	if p, found := creds["password"]; found {
+		password, ok = string(p), true
+	}
+	if ok {
+		auth = append(auth, ssh.Password(password))
+	}
+	// Host keys are always verified, so that credentials aren't sent to an
+	// impersonating server
+	knownHosts, ok := creds["known_hosts"]
+	if !ok {
+		return nil, nil, errors.New("sftp URLs require a secret with a 'known_hosts' key, to verify the server's host key")
+	}
+	hostKeyCallback, err := knownHostsCallback(knownHosts)
+	if err != nil {
+		return nil, nil, err
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "22")
+	}
+	conn, err := ssh.Dial("tcp", host, &ssh.ClientConfig{
+		User:            username,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+	})
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "could not connect to %s", host)
+	}
+	c, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return c, func() error {
+		if err := c.Close(); err != nil {
+			conn.Close()
+			return err
+		}
+		return conn.Close()
+	}, nil
+}
+
+// knownHostsCallback verifies host keys against 'data', in the format of an
+// ssh known_hosts file
+func knownHostsCallback(data []byte) (ssh.HostKeyCallback, error) {
+	f, err := ioutil.TempFile("", "known_hosts")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	return knownhosts.New(f.Name())
+}
+
+// putFileSFTP calls 'f' (in 'eg') with each file at the sftp URL 'u', or
+// each file under it if req.Recursive is set. The connection is closed once
+// they've all been read.
+func putFileSFTP(req *pfs.PutFileRequest, u *url.URL, creds map[string][]byte, limiter limit.ConcurrencyLimiter, eg *errgroup.Group, f func(*pfs.PutFileRequest, io.Reader) error) error {
+	c, closeConn, err := sftpConnect(u, creds)
+	if err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
+	defer eg.Go(func() error {
+		wg.Wait()
+		return closeConn()
+	})
+	put := func(req *pfs.PutFileRequest, p string) error {
+		limiter.Acquire()
+		r, err := c.Open(p)
+		if err != nil {
+			limiter.Release()
+			return errors.Wrapf(err, "could not open %s", p)
+		}
+		wg.Add(1)
+		eg.Go(func() (retErr error) {
+			defer wg.Done()
+			defer limiter.Release()
+			defer func() {
+				if err := r.Close(); err != nil && retErr == nil {
+					retErr = err
+				}
+			}()
+			return f(req, withSHA256(req, r))
+		})
+		return nil
+	}
+	if !req.Recursive {
+		return put(req, u.Path)
+	}
+	walker := c.Walk(u.Path)
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			return err
+		}
+		if walker.Stat().IsDir() {
+			continue
+		}
+		req := *req // copy req so we can make changes
+		req.File = client.NewFile(req.File.Commit.Repo.Name, req.File.Commit.ID, path.Join(req.File.Path, strings.TrimPrefix(walker.Path(), u.Path)))
+		if err := put(&req, walker.Path()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
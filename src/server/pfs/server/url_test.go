@@ -0,0 +1,230 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/limit"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+func TestSHA256Reader(t *testing.T) {
+	sum := sha256.Sum256([]byte("foo"))
+	req := &pfs.PutFileRequest{
+		File:           client.NewFile("repo", "master", "file"),
+		ExpectedSha256: hex.EncodeToString(sum[:]),
+	}
+	data, err := ioutil.ReadAll(withSHA256(req, bytes.NewReader([]byte("foo"))))
+	require.NoError(t, err)
+	require.Equal(t, "foo", string(data))
+	_, err = ioutil.ReadAll(withSHA256(req, bytes.NewReader([]byte("bar"))))
+	require.YesError(t, err)
+}
+
+// serveFlaky serves 'data' with the ETag returned by etag(), but drops the
+// connection halfway through the first response. It returns the number of
+// requests it has received, and the Authorization header of the last one.
+func serveFlaky(data []byte, etag func() string) (*httptest.Server, *int, *string) {
+	var requests int
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		authorization = r.Header.Get("Authorization")
+		w.Header().Set("ETag", etag())
+		if requests == 1 {
+			// Send half of the content, then drop the connection
+			w.Header().Set("Accept-Ranges", "bytes")
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			w.Write(data[:len(data)/2])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+	}))
+	return server, &requests, &authorization
+}
+
+func TestHTTPResume(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 10000)
+	server, requests, authorization := serveFlaky(data, func() string { return `"v1"` })
+	defer server.Close()
+
+	r, err := openHTTP(context.Background(), server.URL, map[string][]byte{"token": []byte("secret\n")})
+	require.NoError(t, err)
+	defer r.Close()
+	result, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(data, result))
+	require.Equal(t, 2, *requests)
+	require.Equal(t, "Bearer secret", *authorization)
+}
+
+func TestHTTPResumeChanged(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 10000)
+	var version int
+	server, requests, _ := serveFlaky(data, func() string {
+		version++
+		return fmt.Sprintf(`"v%d"`, version)
+	})
+	defer server.Close()
+
+	// The content's ETag changes between the requests, so the rest of it
+	// can't be fetched
+	r, err := openHTTP(context.Background(), server.URL, nil)
+	require.NoError(t, err)
+	defer r.Close()
+	_, err = ioutil.ReadAll(r)
+	require.YesError(t, err)
+	require.Equal(t, 2, *requests)
+}
+
+func TestHTTPBasicAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "pass" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("foo"))
+	}))
+	defer server.Close()
+	_, err := openHTTP(context.Background(), server.URL, nil)
+	require.YesError(t, err)
+	r, err := openHTTP(context.Background(), server.URL, map[string][]byte{
+		"username": []byte("user"),
+		"password": []byte("pass"),
+	})
+	require.NoError(t, err)
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, "foo", string(data))
+}
+
+// serveSFTP serves the local filesystem over sftp on 'l', to the user "user"
+// with the password "pass"
+func serveSFTP(l net.Listener, hostKey ssh.Signer) {
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if c.User() != "user" || string(password) != "pass" {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostKey)
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			_, chans, reqs, err := ssh.NewServerConn(conn, config)
+			if err != nil {
+				return
+			}
+			go ssh.DiscardRequests(reqs)
+			for newChannel := range chans {
+				channel, requests, err := newChannel.Accept()
+				if err != nil {
+					return
+				}
+				go func() {
+					for req := range requests {
+						req.Reply(req.Type == "subsystem" && string(req.Payload[4:]) == "sftp", nil)
+					}
+				}()
+				server, err := sftp.NewServer(channel)
+				if err != nil {
+					return
+				}
+				server.Serve()
+				server.Close()
+			}
+		}()
+	}
+}
+
+func TestPutFileSFTP(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sftp")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "a", "b"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a", "foo"), []byte("foo"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a", "b", "bar"), []byte("bar"), 0644))
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	hostKey, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+	go serveSFTP(l, hostKey)
+
+	u, err := url.Parse("sftp://user@" + l.Addr().String() + filepath.Join(dir, "a"))
+	require.NoError(t, err)
+	var mu sync.Mutex
+	files := make(map[string]string)
+	put := func(req *pfs.PutFileRequest, r io.Reader) error {
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		files[req.File.Path] = string(data)
+		return nil
+	}
+	req := &pfs.PutFileRequest{
+		File:      client.NewFile("repo", "master", "/drop"),
+		Recursive: true,
+	}
+
+	knownHosts := []byte(knownhosts.Line([]string{l.Addr().String()}, hostKey.PublicKey()))
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherHostKey, err := ssh.NewSignerFromKey(otherKey)
+	require.NoError(t, err)
+	wrongKnownHosts := []byte(knownhosts.Line([]string{l.Addr().String()}, otherHostKey.PublicKey()))
+
+	var eg errgroup.Group
+	require.YesError(t, putFileSFTP(req, u, map[string][]byte{"password": []byte("wrong"), "known_hosts": knownHosts}, limit.New(10), &eg, put))
+	// The host key must be verified
+	require.YesError(t, putFileSFTP(req, u, map[string][]byte{"password": []byte("pass")}, limit.New(10), &eg, put))
+	require.YesError(t, putFileSFTP(req, u, map[string][]byte{"password": []byte("pass"), "known_hosts": wrongKnownHosts}, limit.New(10), &eg, put))
+
+	require.NoError(t, putFileSFTP(req, u, map[string][]byte{"password": []byte("pass"), "known_hosts": knownHosts}, limit.New(10), &eg, put))
+	require.NoError(t, eg.Wait())
+	var paths []string
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	require.Equal(t, []string{"/drop/b/bar", "/drop/foo"}, paths)
+	require.Equal(t, "bar", files["/drop/b/bar"])
+}
@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
 )
@@ -72,6 +73,41 @@ func Add(s string, ancestors int) string {
 	return s
 }
 
+// timeLayouts are the formats accepted in time-travel references: an RFC 3339
+// timestamp, or a date (midnight UTC)
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02"}
+
+// ParseTime parses s for a time-travel reference, of the form <ref>@{<time>},
+// e.g. master@{2020-05-01T00:00:00Z}. It refers to the last commit on or
+// before <ref> that finished at or before <time>. ParseTime returns the base
+// reference (which may still contain ancestry references) and the time, or
+// nil if s doesn't contain a time-travel reference.
+// For example:
+// foo@{2020-05-01T00:00:00Z} -> foo, 2020-05-01T00:00:00Z
+// foo^2@{2020-05-01} -> foo^2, 2020-05-01T00:00:00Z
+// foo -> foo, nil
+func ParseTime(s string) (string, *time.Time, error) {
+	sepIndex := strings.Index(s, "@{")
+	if sepIndex == -1 {
+		return s, nil, nil
+	}
+	if !strings.HasSuffix(s, "}") {
+		return "", nil, errors.Errorf("invalid time-travel syntax %q, expected <ref>@{<time>}", s)
+	}
+	timeString := s[sepIndex+2 : len(s)-1]
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, timeString); err == nil {
+			return s[:sepIndex], &t, nil
+		}
+	}
+	return "", nil, errors.Errorf("invalid time %q in %q, expected an RFC 3339 timestamp (e.g. 2020-05-01T00:00:00Z) or a date (e.g. 2020-05-01)", timeString, s)
+}
+
+// AddTime adds a time-travel reference to the given string.
+func AddTime(s string, t time.Time) string {
+	return fmt.Sprintf("%s@{%s}", s, t.UTC().Format(time.RFC3339Nano))
+}
+
 var (
 	valid              = regexp.MustCompile("^[a-zA-Z0-9_-]+$") // Matches a valid name
 	invalid            = regexp.MustCompile("[^a-zA-Z0-9_-]")   // matches an invalid character
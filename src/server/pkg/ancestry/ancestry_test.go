@@ -2,6 +2,7 @@ package ancestry
 
 import (
 	"testing"
+	"time"
 
 	"github.com/pachyderm/pachyderm/src/client/pkg/require"
 )
@@ -32,6 +33,41 @@ func TestAncestry(t *testing.T) {
 	}
 }
 
+var timeTests = []struct {
+	in   string
+	name string
+	time string
+}{
+	{"foo", "foo", ""},
+	{"foo@{2020-05-01T00:00:00Z}", "foo", "2020-05-01T00:00:00Z"},
+	{"foo@{2020-05-01T02:00:00.5+02:00}", "foo", "2020-05-01T00:00:00.5Z"},
+	{"foo^2@{2020-05-01}", "foo^2", "2020-05-01T00:00:00Z"},
+}
+
+func TestTime(t *testing.T) {
+	for i, test := range timeTests {
+		name, at, err := ParseTime(test.in)
+		require.NoError(t, err, "timeTests[%d]", i)
+		require.Equal(t, test.name, name, "timeTests[%d]", i)
+		if test.time == "" {
+			require.True(t, at == nil, "timeTests[%d]", i)
+			continue
+		}
+		expected, err := time.Parse(time.RFC3339Nano, test.time)
+		require.NoError(t, err)
+		require.True(t, expected.Equal(*at), "timeTests[%d]: %v", i, at)
+		// AddTime is the inverse of ParseTime
+		name, roundTrip, err := ParseTime(AddTime(name, *at))
+		require.NoError(t, err, "timeTests[%d]", i)
+		require.Equal(t, test.name, name, "timeTests[%d]", i)
+		require.True(t, expected.Equal(*roundTrip), "timeTests[%d]: %v", i, roundTrip)
+	}
+	for _, in := range []string{"foo@{yesterday}", "foo@{2020-05-01", "foo@{}"} {
+		_, _, err := ParseTime(in)
+		require.YesError(t, err, in)
+	}
+}
+
 var validNames = []string{
 	"foo",
 	"foo2",
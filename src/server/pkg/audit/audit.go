@@ -0,0 +1,281 @@
+// Package audit records mutating PFS, PPS, and auth API calls to a rotating
+// local file. Events are chained together by an HMAC keyed with a secret that
+// isn't stored with the log (see auth.AuditEvent.Hash), so that modifying or
+// removing an event in the middle of the log can be detected when the log is
+// read, even by someone who can rewrite the log's files.
+package audit
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+)
+
+// maxLineSize is the largest event that List will read
+const maxLineSize = 1024 * 1024
+
+// ErrTampered is returned by List if an event's hash doesn't match the
+// events before it
+var ErrTampered = errors.New("audit log has been modified: event hashes do not match")
+
+// Log is an append-only, size-rotated log of audit events. When the log's
+// file grows past its max size, it's renamed to '<path>.1' (and any existing
+// '<path>.n' is renamed to '<path>.n+1'), and at most 'backups' old files are
+// kept.
+type Log struct {
+	path     string
+	key      []byte
+	maxBytes int64
+	backups  int
+
+	mu       sync.Mutex
+	f        *os.File
+	size     int64
+	lastHash string
+}
+
+// NewLog opens (or creates) the audit log at 'path', resuming the hash chain
+// from its most recent event. 'key' is the secret that events' hashes are
+// keyed with; it must be the same every time the log is opened, and should
+// not be stored alongside the log.
+func NewLog(path string, key []byte, maxBytes int64, backups int) (*Log, error) {
+	if len(key) == 0 {
+		return nil, errors.New("audit log key must not be empty")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, errors.Wrapf(err, "could not create audit log directory")
+	}
+	l := &Log{
+		path:     path,
+		key:      key,
+		maxBytes: maxBytes,
+		backups:  backups,
+	}
+	// Find the most recent event, which may be in a rotated file if the current
+	// file is empty
+	for i := 0; i <= backups && l.lastHash == ""; i++ {
+		if err := readFile(l.file(i), func(e *auth.AuditEvent) error {
+			l.lastHash = e.Hash
+			return nil
+		}); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// file returns the path of the i'th file in the log, where 0 is the current
+// file and higher numbers are older
+func (l *Log) file(i int) string {
+	if i == 0 {
+		return l.path
+	}
+	return fmt.Sprintf("%s.%d", l.path, i)
+}
+
+func (l *Log) open() error {
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "could not open audit log")
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return errors.Wrapf(err, "could not stat audit log")
+	}
+	l.f, l.size = f, info.Size()
+	return nil
+}
+
+// rotate moves the current file to '<path>.1' (shifting older files back and
+// discarding the oldest) and opens a new, empty file
+func (l *Log) rotate() error {
+	if err := l.f.Close(); err != nil {
+		return errors.Wrapf(err, "could not close audit log")
+	}
+	if err := os.Remove(l.file(l.backups)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "could not remove oldest audit log")
+	}
+	for i := l.backups - 1; i >= 0; i-- {
+		if err := os.Rename(l.file(i), l.file(i+1)); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "could not rotate audit log")
+		}
+	}
+	return l.open()
+}
+
+// hashEvent computes the hash of 'e' given the hash of the event before it
+func (l *Log) hashEvent(prevHash string, e *auth.AuditEvent) (string, error) {
+	unhashed := *e
+	unhashed.Hash = ""
+	data, err := proto.Marshal(&unhashed)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, l.key)
+	mac.Write([]byte(prevHash))
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Record appends 'e' to the log, setting its hash
+func (l *Log) Record(e *auth.AuditEvent) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	hash, err := l.hashEvent(l.lastHash, e)
+	if err != nil {
+		return errors.Wrapf(err, "could not hash audit event")
+	}
+	e.Hash = hash
+	line, err := (&jsonpb.Marshaler{}).MarshalToString(e)
+	if err != nil {
+		return errors.Wrapf(err, "could not serialize audit event")
+	}
+	n, err := l.f.WriteString(line + "\n")
+	l.size += int64(n)
+	if err != nil {
+		return errors.Wrapf(err, "could not write audit event")
+	}
+	l.lastHash = hash
+	if l.maxBytes > 0 && l.size >= l.maxBytes {
+		return l.rotate()
+	}
+	return nil
+}
+
+// List calls 'f' on every event in the log, from oldest to newest. It returns
+// ErrTampered if an event's hash doesn't match the events before it. Because
+// the oldest files are discarded, the first event that List reads isn't
+// checked against its predecessor.
+func (l *Log) List(f func(*auth.AuditEvent) error) error {
+	readers, err := l.snapshot()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		for _, r := range readers {
+			r.Close()
+		}
+	}()
+	var prevHash string
+	first := true
+	for _, r := range readers {
+		if err := readEvents(r, func(e *auth.AuditEvent) error {
+			if !first {
+				hash, err := l.hashEvent(prevHash, e)
+				if err != nil {
+					return err
+				}
+				if hash != e.Hash {
+					return ErrTampered
+				}
+			}
+			first = false
+			prevHash = e.Hash
+			return f(e)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshotReader reads a file in the log up to the size it had when the log
+// was snapshotted, so that events recorded after the snapshot (which may be
+// partially written) aren't read
+type snapshotReader struct {
+	io.Reader
+	file *os.File
+}
+
+func (r *snapshotReader) Close() error {
+	return r.file.Close()
+}
+
+// snapshot opens every file in the log, from oldest to newest. It holds the
+// log's lock only while opening the files (so that they aren't rotated
+// underneath it), so that List doesn't block Record while it reads them.
+func (l *Log) snapshot() (retReaders []*snapshotReader, retErr error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	defer func() {
+		if retErr != nil {
+			for _, r := range retReaders {
+				r.Close()
+			}
+		}
+	}()
+	for i := l.backups; i >= 0; i-- {
+		f, err := os.Open(l.file(i))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return retReaders, errors.Wrapf(err, "could not open audit log")
+		}
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return retReaders, errors.Wrapf(err, "could not stat audit log")
+		}
+		retReaders = append(retReaders, &snapshotReader{
+			Reader: io.LimitReader(f, info.Size()),
+			file:   f,
+		})
+	}
+	return retReaders, nil
+}
+
+// Close closes the log's file
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f.Close()
+}
+
+// readFile calls 'f' on each event in the file at 'path'
+func readFile(path string, f func(*auth.AuditEvent) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := readEvents(file, f); err != nil {
+		return errors.Wrapf(err, "could not read %s", path)
+	}
+	return nil
+}
+
+// readEvents calls 'f' on each event read from 'r'
+func readEvents(r io.Reader, f func(*auth.AuditEvent) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLineSize)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		e := &auth.AuditEvent{}
+		if err := jsonpb.UnmarshalString(scanner.Text(), e); err != nil {
+			return errors.Wrapf(err, "could not parse audit event")
+		}
+		if err := f(e); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,200 @@
+package audit
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gogo/protobuf/types"
+	"google.golang.org/grpc"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+var testKey = []byte("test-key")
+
+func newTestLog(t *testing.T, dir string, maxBytes int64, backups int) (*Log, string) {
+	path := filepath.Join(dir, "audit.log")
+	l, err := NewLog(path, testKey, maxBytes, backups)
+	require.NoError(t, err)
+	return l, path
+}
+
+func listMethods(t *testing.T, l *Log) []string {
+	var methods []string
+	require.NoError(t, l.List(func(e *auth.AuditEvent) error {
+		methods = append(methods, e.Method)
+		return nil
+	}))
+	return methods
+}
+
+func TestRecordAndList(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	l, path := newTestLog(t, dir, 0, 0)
+	require.NoError(t, l.Record(&auth.AuditEvent{Method: "a"}))
+	require.NoError(t, l.Record(&auth.AuditEvent{Method: "b"}))
+	require.Equal(t, []string{"a", "b"}, listMethods(t, l))
+
+	// Reopening the log resumes the hash chain
+	require.NoError(t, l.Close())
+	l, err = NewLog(path, testKey, 0, 0)
+	require.NoError(t, err)
+	require.NoError(t, l.Record(&auth.AuditEvent{Method: "c"}))
+	require.Equal(t, []string{"a", "b", "c"}, listMethods(t, l))
+}
+
+func TestRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	l, path := newTestLog(t, dir, 1, 2) // rotate after every event
+	for _, m := range []string{"a", "b", "c", "d"} {
+		require.NoError(t, l.Record(&auth.AuditEvent{Method: m}))
+	}
+	// Only the two most recent files are kept
+	require.Equal(t, []string{"c", "d"}, listMethods(t, l))
+	_, err = os.Stat(path + ".3")
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestTamperDetection(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	l, path := newTestLog(t, dir, 0, 0)
+	for _, m := range []string{"a", "b", "c"} {
+		require.NoError(t, l.Record(&auth.AuditEvent{Method: m, Username: "alice"}))
+	}
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+
+	// Modifying an event is detected
+	modified := strings.Join([]string{
+		lines[0], strings.Replace(lines[1], "alice", "mallory", 1), lines[2],
+	}, "\n") + "\n"
+	require.NoError(t, ioutil.WriteFile(path, []byte(modified), 0600))
+	require.Equal(t, ErrTampered, l.List(func(*auth.AuditEvent) error { return nil }))
+
+	// Removing an event is detected
+	removed := strings.Join([]string{lines[0], lines[2]}, "\n") + "\n"
+	require.NoError(t, ioutil.WriteFile(path, []byte(removed), 0600))
+	require.Equal(t, ErrTampered, l.List(func(*auth.AuditEvent) error { return nil }))
+}
+
+func TestKeyedHashes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	l, path := newTestLog(t, dir, 0, 0)
+	for _, m := range []string{"a", "b", "c"} {
+		require.NoError(t, l.Record(&auth.AuditEvent{Method: m, Username: "alice"}))
+	}
+	require.NoError(t, l.Close())
+
+	// Rewriting the log with a different key (i.e. without knowing the real
+	// one) and recomputing every hash is detected
+	forged, err := NewLog(path+".forged", []byte("wrong-key"), 0, 0)
+	require.NoError(t, err)
+	for _, m := range []string{"a", "b", "c"} {
+		require.NoError(t, forged.Record(&auth.AuditEvent{Method: m, Username: "mallory"}))
+	}
+	require.NoError(t, forged.Close())
+	require.NoError(t, os.Rename(path+".forged", path))
+	l, err = NewLog(path, testKey, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, ErrTampered, l.List(func(*auth.AuditEvent) error { return nil }))
+
+	_, err = NewLog(path, nil, 0, 0)
+	require.YesError(t, err)
+}
+
+func TestListDoesNotBlockRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	l, _ := newTestLog(t, dir, 0, 0)
+	require.NoError(t, l.Record(&auth.AuditEvent{Method: "a"}))
+	// Events recorded while List is reading the log aren't listed
+	var methods []string
+	require.NoError(t, l.List(func(e *auth.AuditEvent) error {
+		methods = append(methods, e.Method)
+		return l.Record(&auth.AuditEvent{Method: "b"})
+	}))
+	require.Equal(t, []string{"a"}, methods)
+	require.Equal(t, []string{"a", "b"}, listMethods(t, l))
+}
+
+func TestSetTarget(t *testing.T) {
+	e := &auth.AuditEvent{}
+	setTarget(e, &pfs.DeleteCommitRequest{Commit: client.NewCommit("repo", "abc")})
+	require.Equal(t, "repo", e.Repo)
+	require.Equal(t, "abc", e.Commit)
+
+	e = &auth.AuditEvent{}
+	setTarget(e, &pfs.PutFileRequest{File: client.NewFile("repo", "master", "/file")})
+	require.Equal(t, "repo", e.Repo)
+	require.Equal(t, "master", e.Commit)
+
+	e = &auth.AuditEvent{}
+	setTarget(e, &pps.DeletePipelineRequest{Pipeline: client.NewPipeline("pipeline")})
+	require.Equal(t, "pipeline", e.Pipeline)
+
+	e = &auth.AuditEvent{}
+	setTarget(e, &auth.SetACLRequest{Repo: "repo"})
+	require.Equal(t, "repo", e.Repo)
+
+	require.True(t, IsMutating("/pfs.API/DeleteCommit"))
+	require.False(t, IsMutating("/pfs.API/InspectCommit"))
+}
+
+func TestInterceptorUsername(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	l, _ := newTestLog(t, dir, 0, 0)
+
+	// The caller is resolved before the call is handled, so calls that
+	// invalidate the caller's credentials (e.g. Deactivate) are attributed
+	deactivated := false
+	whoAmI := func(context.Context) (string, error) {
+		if deactivated {
+			return "", auth.ErrNotActivated
+		}
+		return "alice", nil
+	}
+	interceptor := UnaryServerInterceptor(l, whoAmI)
+	_, err = interceptor(context.Background(), &auth.DeactivateRequest{},
+		&grpc.UnaryServerInfo{FullMethod: "/auth.API/Deactivate"},
+		func(context.Context, interface{}) (interface{}, error) {
+			deactivated = true
+			return &auth.DeactivateResponse{}, nil
+		})
+	require.NoError(t, err)
+	_, err = interceptor(context.Background(), &pfs.CreateRepoRequest{Repo: client.NewRepo("repo")},
+		&grpc.UnaryServerInfo{FullMethod: "/pfs.API/CreateRepo"},
+		func(context.Context, interface{}) (interface{}, error) {
+			return &types.Empty{}, nil
+		})
+	require.NoError(t, err)
+
+	var events []*auth.AuditEvent
+	require.NoError(t, l.List(func(e *auth.AuditEvent) error {
+		events = append(events, e)
+		return nil
+	}))
+	require.Equal(t, 2, len(events))
+	require.Equal(t, "alice", events[0].Username)
+	require.Equal(t, "", events[1].Username)
+	require.Equal(t, "repo", events[1].Repo)
+}
@@ -0,0 +1,198 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+
+	"github.com/pachyderm/pachyderm/src/client/auth"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// mutatingMethods are the API calls that are recorded in the audit log
+var mutatingMethods = map[string]bool{
+	"/pfs.API/CreateRepo":                  true,
+	"/pfs.API/DeleteRepo":                  true,
+	"/pfs.API/LabelRepo":                   true,
+	"/pfs.API/StartCommit":                 true,
+	"/pfs.API/FinishCommit":                true,
+	"/pfs.API/DeleteCommit":                true,
+	"/pfs.API/BuildCommit":                 true,
+	"/pfs.API/LabelCommit":                 true,
+	"/pfs.API/CreateBranch":                true,
+	"/pfs.API/DeleteBranch":                true,
+	"/pfs.API/MergeBranch":                 true,
+	"/pfs.API/CreateSnapshot":              true,
+	"/pfs.API/RestoreBranchesFromSnapshot": true,
+	"/pfs.API/PutFile":                     true,
+	"/pfs.API/CopyFile":                    true,
+	"/pfs.API/DeleteFile":                  true,
+	"/pfs.API/PutTar":                      true,
+	"/pfs.API/DeleteAll":                   true,
+	"/pfs.API/Fsck":                        true,
+	"/pfs.API/ReconcileReplication":        true,
+	"/pps.API/CreateJob":                   true,
+	"/pps.API/DeleteJob":                   true,
+	"/pps.API/StopJob":                     true,
+	"/pps.API/RestartDatum":                true,
+	"/pps.API/CreatePipeline":              true,
+	"/pps.API/DeletePipeline":              true,
+	"/pps.API/StartPipeline":               true,
+	"/pps.API/StopPipeline":                true,
+	"/pps.API/RollbackPipeline":            true,
+	"/pps.API/PromoteShadow":               true,
+	"/pps.API/RunPipeline":                 true,
+	"/pps.API/RunCron":                     true,
+	"/pps.API/CreateSecret":                true,
+	"/pps.API/DeleteSecret":                true,
+	"/pps.API/CreatePipelineTemplate":      true,
+	"/pps.API/DeletePipelineTemplate":      true,
+	"/pps.API/CreateNotificationSink":      true,
+	"/pps.API/DeleteNotificationSink":      true,
+	"/pps.API/DeleteAll":                   true,
+	"/pps.API/GarbageCollect":              true,
+	"/pps.API/ActivateAuth":                true,
+	"/pps.API/UpdateJobState":              true,
+	"/auth.API/Activate":                   true,
+	"/auth.API/Deactivate":                 true,
+	"/auth.API/SetConfiguration":           true,
+	"/auth.API/ModifyAdmins":               true,
+	"/auth.API/SetScope":                   true,
+	"/auth.API/SetACL":                     true,
+	"/auth.API/GetAuthToken":               true,
+	"/auth.API/ExtendAuthToken":            true,
+	"/auth.API/RevokeAuthToken":            true,
+	"/auth.API/SetGroupsForUser":           true,
+	"/auth.API/ModifyMembers":              true,
+	"/auth.API/GetOneTimePassword":         true,
+}
+
+// IsMutating returns true if calls to 'fullMethod' (e.g.
+// "/pfs.API/DeleteCommit") are recorded in the audit log
+func IsMutating(fullMethod string) bool {
+	return mutatingMethods[fullMethod]
+}
+
+// WhoAmIFunc returns the username of the caller whose credentials are in 'ctx'
+type WhoAmIFunc func(ctx context.Context) (string, error)
+
+// setTarget fills in the resources in 'e' that 'req' targets
+func setTarget(e *auth.AuditEvent, req interface{}) {
+	setCommit := func(c *pfs.Commit) {
+		if c != nil && c.Repo != nil {
+			e.Repo, e.Commit = c.Repo.Name, c.ID
+		}
+	}
+	if r, ok := req.(interface{ GetRepo() *pfs.Repo }); ok && r.GetRepo() != nil {
+		e.Repo = r.GetRepo().Name
+	}
+	if r, ok := req.(interface{ GetRepo() string }); ok {
+		e.Repo = r.GetRepo() // auth API requests (e.g. SetACL)
+	}
+	if r, ok := req.(interface{ GetCommit() *pfs.Commit }); ok {
+		setCommit(r.GetCommit())
+	}
+	if r, ok := req.(interface{ GetParent() *pfs.Commit }); ok {
+		setCommit(r.GetParent()) // StartCommit
+	}
+	if r, ok := req.(interface{ GetBranch() *pfs.Branch }); ok {
+		if b := r.GetBranch(); b != nil && b.Repo != nil {
+			e.Repo, e.Commit = b.Repo.Name, b.Name
+		}
+	}
+	if r, ok := req.(interface{ GetFile() *pfs.File }); ok && r.GetFile() != nil {
+		setCommit(r.GetFile().Commit)
+	}
+	if r, ok := req.(interface{ GetDst() *pfs.File }); ok && r.GetDst() != nil {
+		setCommit(r.GetDst().Commit) // CopyFile
+	}
+	if r, ok := req.(interface{ GetDst() *pfs.Branch }); ok {
+		if b := r.GetDst(); b != nil && b.Repo != nil {
+			e.Repo, e.Commit = b.Repo.Name, b.Name // MergeBranch
+		}
+	}
+	if r, ok := req.(interface{ GetPipeline() *pps.Pipeline }); ok && r.GetPipeline() != nil {
+		e.Pipeline = r.GetPipeline().Name
+	}
+	if r, ok := req.(interface{ GetJob() *pps.Job }); ok && r.GetJob() != nil {
+		e.Job = r.GetJob().ID
+	}
+}
+
+// caller returns the username of the caller whose credentials are in 'ctx'.
+// It's called before the call is handled, as some calls (e.g. Deactivate, or
+// revoking the caller's own token) invalidate the caller's credentials. Auth
+// may not be activated, in which case calls are recorded without a username.
+func caller(ctx context.Context, whoAmI WhoAmIFunc) string {
+	username, err := whoAmI(ctx)
+	if err != nil {
+		return ""
+	}
+	return username
+}
+
+// record builds an audit event for a call to 'method' by 'username' and
+// writes it to 'l'. Failing to record an event doesn't fail the call, but is
+// logged.
+func record(l *Log, username string, method string, req interface{}, callErr error) {
+	e := &auth.AuditEvent{Method: method, Username: username}
+	e.Time, _ = types.TimestampProto(time.Now())
+	if req != nil {
+		setTarget(e, req)
+	}
+	if callErr != nil {
+		e.Error = grpcutil.ScrubGRPC(callErr).Error()
+	}
+	if err := l.Record(e); err != nil {
+		log.Errorf("could not record audit event for %s: %v", method, err)
+	}
+}
+
+// UnaryServerInterceptor returns a grpc interceptor that records mutating
+// unary calls in 'l'
+func UnaryServerInterceptor(l *Log, whoAmI WhoAmIFunc) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !IsMutating(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		username := caller(ctx, whoAmI)
+		resp, err := handler(ctx, req)
+		record(l, username, info.FullMethod, req, err)
+		return resp, err
+	}
+}
+
+// recordingStream saves the first message received on a stream, which
+// identifies the target of streaming calls like PutFile
+type recordingStream struct {
+	grpc.ServerStream
+	first interface{}
+}
+
+func (s *recordingStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil && s.first == nil {
+		s.first = m
+	}
+	return err
+}
+
+// StreamServerInterceptor returns a grpc interceptor that records mutating
+// streaming calls in 'l'
+func StreamServerInterceptor(l *Log, whoAmI WhoAmIFunc) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !IsMutating(info.FullMethod) {
+			return handler(srv, stream)
+		}
+		username := caller(stream.Context(), whoAmI)
+		rs := &recordingStream{ServerStream: stream}
+		err := handler(srv, rs)
+		record(l, username, info.FullMethod, rs.first, err)
+		return err
+	}
+}
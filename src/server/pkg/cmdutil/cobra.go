@@ -219,7 +219,7 @@ func ParseFile(arg string) (*pfs.File, error) {
 		Path: "",
 	}
 	if len(repoAndRest) > 1 {
-		commitAndPath := strings.SplitN(repoAndRest[1], ":", 2)
+		commitAndPath := splitCommitAndPath(repoAndRest[1])
 		if commitAndPath[0] == "" {
 			return nil, errors.Errorf("invalid format \"%s\": commit cannot be empty", arg)
 		}
@@ -231,6 +231,20 @@ func ParseFile(arg string) (*pfs.File, error) {
 	return file, nil
 }
 
+// splitCommitAndPath splits "branch-or-commit[:path]" at the first ':' that
+// isn't inside a time-travel reference like "master@{2020-05-01T00:00:00Z}"
+func splitCommitAndPath(arg string) []string {
+	offset := 0
+	if start := strings.Index(arg, "@{"); start != -1 {
+		if end := strings.Index(arg[start:], "}"); end != -1 {
+			offset = start + end
+		}
+	}
+	parts := strings.SplitN(arg[offset:], ":", 2)
+	parts[0] = arg[:offset] + parts[0]
+	return parts
+}
+
 // ParsePartialFile returns the same thing as ParseFile, unless ParseFile would
 // error on this input, in which case it returns as much as it was able to
 // parse.
@@ -276,6 +290,23 @@ func ParseHistory(history string) (int64, error) {
 	return int64(result), err
 }
 
+// ParseLabels parses label arguments of the form "key=value" (e.g. from a
+// --label flag) into a map of labels.
+func ParseLabels(args []string) (map[string]string, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	labels := make(map[string]string)
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, errors.Errorf("invalid label %q (must be of the form key=value)", arg)
+		}
+		labels[parts[0]] = parts[1]
+	}
+	return labels, nil
+}
+
 // RepeatedStringArg is an alias for []string
 type RepeatedStringArg []string
 
@@ -0,0 +1,33 @@
+package cmdutil
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+func TestSplitCommitAndPath(t *testing.T) {
+	for arg, expected := range map[string][]string{
+		"master":                                   {"master"},
+		"master:/file":                             {"master", "/file"},
+		"master:/dir/a:b":                          {"master", "/dir/a:b"},
+		"master@{2020-05-01T00:00:00Z}":            {"master@{2020-05-01T00:00:00Z}"},
+		"master@{2020-05-01T00:00:00Z}:/file":      {"master@{2020-05-01T00:00:00Z}", "/file"},
+		"master^@{2020-05-01T12:30:00+02:00}:/a:b": {"master^@{2020-05-01T12:30:00+02:00}", "/a:b"},
+		"master@{2020-05-01}:":                     {"master@{2020-05-01}", ""},
+		"master@{2020-05-01T00:00:00Z":             {"master@{2020-05-01T00", "00:00Z"},
+	} {
+		require.Equal(t, expected, splitCommitAndPath(arg), arg)
+	}
+}
+
+func TestParseFileTimeTravel(t *testing.T) {
+	file, err := ParseFile("images@master@{2020-05-01T00:00:00Z}:/a/b.png")
+	require.NoError(t, err)
+	require.Equal(t, "images", file.Commit.Repo.Name)
+	require.Equal(t, "master@{2020-05-01T00:00:00Z}", file.Commit.ID)
+	require.Equal(t, "/a/b.png", file.Path)
+
+	_, err = ParseFile("images@:/a/b.png")
+	require.YesError(t, err)
+}
@@ -144,6 +144,9 @@ const (
 // StorageOpts are options that are applicable to the storage layer.
 type StorageOpts struct {
 	UploadConcurrencyLimit int
+	// Replicas are object store URLs (e.g. "s3://dr-bucket") that pachd and
+	// the workers replicate object storage writes to
+	Replicas []string
 }
 
 const (
@@ -490,9 +493,13 @@ func GetSecretEnvVars(storageBackend string) []v1.EnvVar {
 }
 
 func getStorageEnvVars(opts *AssetOpts) []v1.EnvVar {
-	return []v1.EnvVar{
+	envVars := []v1.EnvVar{
 		{Name: UploadConcurrencyLimitEnvVar, Value: strconv.Itoa(opts.StorageOpts.UploadConcurrencyLimit)},
 	}
+	if len(opts.StorageOpts.Replicas) > 0 {
+		envVars = append(envVars, v1.EnvVar{Name: obj.StorageReplicasEnvVar, Value: strings.Join(opts.StorageOpts.Replicas, ",")})
+	}
+	return envVars
 }
 
 func versionedPachdImage(opts *AssetOpts) string {
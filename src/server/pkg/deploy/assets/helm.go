@@ -0,0 +1,266 @@
+package assets
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
+)
+
+const helmChartName = "pachyderm"
+
+var (
+	// helmExpressionRE matches the placeholders that stand in for template
+	// expressions while a chart's templates are encoded as YAML
+	helmExpressionRE = regexp.MustCompile(`__HELM_EXPRESSION_([0-9]+)__`)
+
+	// semverRE matches the versions that helm accepts as a chart version
+	semverRE = regexp.MustCompile(`^v?[0-9]+\.[0-9]+\.[0-9]+(-[0-9A-Za-z.-]+)?$`)
+
+	// helmContainerValues maps the names of the containers in pachyderm's
+	// manifest to the key of their image in values.yaml, and the prefix of
+	// their resource requests (if those are parameterized)
+	helmContainerValues = map[string]struct{ image, resources string }{
+		pachdName:     {"pachd.image", "pachd"},
+		etcdName:      {"etcd.image", "etcd"},
+		dashName:      {"dash.image", ""},
+		grpcProxyName: {"dash.proxyImage", ""},
+	}
+
+	// helmPachdEnvValues maps the pachd environment variables that are
+	// parameterized in the chart to their key in values.yaml
+	helmPachdEnvValues = map[string]string{
+		"LOG_LEVEL":                      "pachd.logLevel",
+		"METRICS":                        "pachd.metrics",
+		"NUM_SHARDS":                     "pachd.shards",
+		"BLOCK_CACHE_BYTES":              "pachd.blockCacheSize",
+		"WORKER_IMAGE":                   "pachd.workerImage",
+		"WORKER_SIDECAR_IMAGE":           "pachd.image",
+		"EXPOSE_OBJECT_API":              "pachd.exposeObjectAPI",
+		"NO_EXPOSE_DOCKER_SOCKET":        "pachd.noExposeDockerSocket",
+		"CLUSTER_DEPLOYMENT_ID":          "pachd.clusterDeploymentID",
+		RequireCriticalServersOnlyEnvVar: "pachd.requireCriticalServersOnly",
+		UploadConcurrencyLimitEnvVar:     "pachd.uploadConcurrencyLimit",
+		obj.StorageReplicasEnvVar:        "pachd.storageReplicas",
+	}
+)
+
+// helmChart accumulates the default values of a chart while its templates are
+// generated from pachyderm's manifest
+type helmChart struct {
+	values      map[string]interface{}
+	expressions []string
+}
+
+// WriteHelmChart writes a helm chart that deploys 'objects' (as returned by
+// ParseManifest) to 'dir'. The images, resource requests and pachd settings
+// in the manifest are replaced by references to values.yaml, whose defaults
+// are the values that pachctl rendered, and the namespace is replaced by the
+// release's namespace. The storage secret's values are replaced by empty
+// placeholders, so that credentials aren't written to values.yaml.
+func WriteHelmChart(dir string, objects []map[string]interface{}, opts *AssetOpts) error {
+	if err := createOutputDir(dir, "templates"); err != nil {
+		return err
+	}
+	c := &helmChart{values: make(map[string]interface{})}
+	for _, object := range objects {
+		template, err := c.template(object, opts)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(dir, "templates", manifestFileName(object))
+		if err := ioutil.WriteFile(path, template, 0644); err != nil {
+			return errors.Wrapf(err, "could not write %s", path)
+		}
+	}
+	if err := writeYAMLFile(filepath.Join(dir, "values.yaml"),
+		"# Default values for the pachyderm chart, as rendered by 'pachctl deploy'.\n"+
+			"# Object storage credentials aren't included: set storageSecret.data when\n"+
+			"# installing the chart (e.g. from a separate values file), or set\n"+
+			"# storageSecret.create to false to use an existing secret named\n"+
+			"# "+client.StorageSecretName+".\n",
+		c.values); err != nil {
+		return err
+	}
+	chartVersion := "0.0.0"
+	if semverRE.MatchString(opts.Version) {
+		chartVersion = strings.TrimPrefix(opts.Version, "v")
+	}
+	return writeYAMLFile(filepath.Join(dir, "Chart.yaml"), "", map[string]interface{}{
+		"apiVersion":  "v2",
+		"name":        helmChartName,
+		"description": "Pachyderm, a data versioning and pipelining platform",
+		"type":        "application",
+		"version":     chartVersion,
+		"appVersion":  opts.Version,
+	})
+}
+
+// template parameterizes 'object' and returns it as a chart template
+func (c *helmChart) template(object map[string]interface{}, opts *AssetOpts) ([]byte, error) {
+	c.parameterize(object, opts)
+	data, err := encodeYAML(object)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not encode %s", manifestFileName(object))
+	}
+	template := helmExpressionRE.ReplaceAllStringFunc(string(data), func(token string) string {
+		i, _ := strconv.Atoi(helmExpressionRE.FindStringSubmatch(token)[1])
+		return c.expressions[i]
+	})
+	kind, name := objectKind(object)
+	if name == dashName && (kind == "Deployment" || kind == "Service") {
+		c.setValue("dash.enabled", true)
+		template = "{{- if .Values.dash.enabled }}\n" + template + "{{- end }}\n"
+	}
+	if name == client.StorageSecretName && kind == "Secret" {
+		template = "{{- if .Values.storageSecret.create }}\n" + template + "{{- end }}\n"
+	}
+	return []byte(template), nil
+}
+
+// parameterize replaces the fields of 'object' that are configurable in the
+// chart with placeholders for template expressions
+func (c *helmChart) parameterize(object map[string]interface{}, opts *AssetOpts) {
+	c.parameterizeNamespace(object, opts.Namespace)
+	kind, name := objectKind(object)
+	switch kind {
+	case "Deployment", "StatefulSet":
+		for _, container := range podContainers(object) {
+			containerName, _ := container["name"].(string)
+			keys, ok := helmContainerValues[containerName]
+			if !ok {
+				continue
+			}
+			c.param(container, "image", keys.image)
+			if keys.resources != "" {
+				resources, _ := container["resources"].(map[string]interface{})
+				for _, field := range []string{"requests", "limits"} {
+					requests, _ := resources[field].(map[string]interface{})
+					c.param(requests, "cpu", keys.resources+".cpuRequest")
+					c.param(requests, "memory", keys.resources+".memoryRequest")
+				}
+			}
+			if containerName != pachdName {
+				continue
+			}
+			env, _ := container["env"].([]interface{})
+			for _, e := range env {
+				envVar, _ := e.(map[string]interface{})
+				envName, _ := envVar["name"].(string)
+				if key, ok := helmPachdEnvValues[envName]; ok {
+					c.param(envVar, "value", key)
+				}
+			}
+		}
+	case "Secret":
+		if name != client.StorageSecretName {
+			return
+		}
+		// Credentials aren't written to values.yaml. Each key has an empty
+		// placeholder that must be set when the chart is installed, unless the
+		// chart uses a secret that already exists.
+		data, _ := object["data"].(map[string]interface{})
+		placeholders := make(map[string]interface{})
+		for key := range data {
+			// Secret keys contain dashes, so they're looked up with 'index'
+			placeholders[key] = ""
+			data[key] = c.expression(fmt.Sprintf(
+				"{{ index .Values.storageSecret.data %q | b64enc | quote }}", key))
+		}
+		c.setValue("storageSecret.create", true)
+		c.setValue("storageSecret.data", placeholders)
+	}
+}
+
+// parameterizeNamespace replaces every namespace in 'v' that is 'namespace'
+// with the release's namespace
+func (c *helmChart) parameterizeNamespace(v interface{}, namespace string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if s, ok := value.(string); ok && key == "namespace" && s == namespace {
+				v[key] = c.expression("{{ .Release.Namespace }}")
+				continue
+			}
+			c.parameterizeNamespace(value, namespace)
+		}
+	case []interface{}:
+		for _, value := range v {
+			c.parameterizeNamespace(value, namespace)
+		}
+	}
+}
+
+// param replaces m[field] with a reference to the value at 'key' (e.g.
+// "pachd.image"), whose default is m[field]'s current value. String fields
+// are quoted, so that e.g. "true" isn't rendered as a boolean.
+func (c *helmChart) param(m map[string]interface{}, field string, key string) {
+	value, ok := m[field]
+	if !ok {
+		return
+	}
+	expression := ".Values." + key
+	if s, ok := value.(string); ok {
+		value = helmValue(s)
+		expression += " | quote"
+	}
+	c.setValue(key, value)
+	m[field] = c.expression("{{ " + expression + " }}")
+}
+
+// expression returns a placeholder for the template expression 'expression'
+func (c *helmChart) expression(expression string) string {
+	c.expressions = append(c.expressions, expression)
+	return fmt.Sprintf("__HELM_EXPRESSION_%d__", len(c.expressions)-1)
+}
+
+// setValue sets the default of the value at 'key', unless it was already set
+// by an earlier object
+func (c *helmChart) setValue(key string, value interface{}) {
+	m := c.values
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := m[part].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			m[part] = next
+		}
+		m = next
+	}
+	if _, ok := m[parts[len(parts)-1]]; !ok {
+		m[parts[len(parts)-1]] = value
+	}
+}
+
+// helmValue converts the string 's' to the type that a user would write in
+// values.yaml
+func helmValue(s string) interface{} {
+	if s == "true" || s == "false" {
+		return s == "true"
+	}
+	if i, err := strconv.Atoi(s); err == nil && strconv.Itoa(i) == s {
+		return i
+	}
+	return s
+}
+
+// podContainers returns the containers in the pod template of 'object'
+func podContainers(object map[string]interface{}) []map[string]interface{} {
+	spec, _ := object["spec"].(map[string]interface{})
+	template, _ := spec["template"].(map[string]interface{})
+	podSpec, _ := template["spec"].(map[string]interface{})
+	containers, _ := podSpec["containers"].([]interface{})
+	var result []map[string]interface{}
+	for _, container := range containers {
+		if container, ok := container.(map[string]interface{}); ok {
+			result = append(result, container)
+		}
+	}
+	return result
+}
@@ -0,0 +1,74 @@
+package assets
+
+import "path/filepath"
+
+const (
+	kustomizeAPIVersion = "kustomize.config.k8s.io/v1beta1"
+	kustomizeOverlay    = "example"
+	kustomizePachdPatch = "pachd-resources.yaml"
+)
+
+// WriteKustomization writes 'objects' (as returned by ParseManifest) to 'dir'
+// as a kustomize base, along with an example overlay that sets the namespace
+// and patches pachd's resource requests, for users to copy and edit.
+func WriteKustomization(dir string, objects []map[string]interface{}, opts *AssetOpts) error {
+	overlayDir := filepath.Join("overlays", kustomizeOverlay)
+	if err := createOutputDir(dir, "base", overlayDir); err != nil {
+		return err
+	}
+	var resources []string
+	var pachdContainer map[string]interface{}
+	for _, object := range objects {
+		name := manifestFileName(object)
+		if err := writeYAMLFile(filepath.Join(dir, "base", name), "", object); err != nil {
+			return err
+		}
+		resources = append(resources, name)
+		if kind, objectName := objectKind(object); kind == "Deployment" && objectName == pachdName {
+			for _, container := range podContainers(object) {
+				if container["name"] == pachdName {
+					pachdContainer = container
+				}
+			}
+		}
+	}
+	if err := writeYAMLFile(filepath.Join(dir, "base", "kustomization.yaml"), "", map[string]interface{}{
+		"apiVersion": kustomizeAPIVersion,
+		"kind":       "Kustomization",
+		"resources":  resources,
+	}); err != nil {
+		return err
+	}
+
+	overlay := map[string]interface{}{
+		"apiVersion": kustomizeAPIVersion,
+		"kind":       "Kustomization",
+		"namespace":  opts.Namespace,
+		"resources":  []string{"../../base"},
+	}
+	if pachdContainer != nil {
+		// Patch pachd's resource requests with the values from the base, as a
+		// starting point for overriding them
+		if err := writeYAMLFile(filepath.Join(dir, overlayDir, kustomizePachdPatch), "", map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": pachdName},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"containers": []interface{}{
+							map[string]interface{}{
+								"name":      pachdName,
+								"resources": pachdContainer["resources"],
+							},
+						},
+					},
+				},
+			},
+		}); err != nil {
+			return err
+		}
+		overlay["patchesStrategicMerge"] = []string{kustomizePachdPatch}
+	}
+	return writeYAMLFile(filepath.Join(dir, overlayDir, "kustomization.yaml"), "", overlay)
+}
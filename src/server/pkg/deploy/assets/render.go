@@ -0,0 +1,84 @@
+package assets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	"github.com/pachyderm/pachyderm/src/server/pkg/serde"
+)
+
+// ParseManifest parses a manifest written by one of the Write*Assets functions
+// with a JSON encoder back into the generic k8s objects it contains, so that
+// they can be rendered as a Helm chart or a kustomize base.
+func ParseManifest(manifest []byte) ([]map[string]interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(manifest))
+	var objects []map[string]interface{}
+	for {
+		var object map[string]interface{}
+		if err := d.Decode(&object); err != nil {
+			if err == io.EOF {
+				return objects, nil
+			}
+			return nil, errors.Wrapf(err, "could not parse manifest")
+		}
+		objects = append(objects, object)
+	}
+}
+
+// objectKind returns the kind and name of a k8s object
+func objectKind(object map[string]interface{}) (string, string) {
+	kind, _ := object["kind"].(string)
+	metadata, _ := object["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	return kind, name
+}
+
+// manifestFileName returns the name of the file that 'object' is written to
+func manifestFileName(object map[string]interface{}) string {
+	kind, name := objectKind(object)
+	return fmt.Sprintf("%s-%s.yaml", strings.ToLower(kind), name)
+}
+
+// createOutputDir creates 'dir' and its subdirectories, refusing to write into
+// an existing directory so that a previous chart or base isn't clobbered
+func createOutputDir(dir string, subdirs ...string) error {
+	if _, err := os.Stat(dir); err == nil {
+		return errors.Errorf("%s already exists", dir)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	for _, subdir := range append([]string{""}, subdirs...) {
+		if err := os.MkdirAll(filepath.Join(dir, subdir), 0755); err != nil {
+			return errors.Wrapf(err, "could not create %s", filepath.Join(dir, subdir))
+		}
+	}
+	return nil
+}
+
+// encodeYAML encodes 'values' as a YAML document
+func encodeYAML(values ...interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	e := serde.NewYAMLEncoder(&buf, serde.WithIndent(2))
+	for _, v := range values {
+		if err := e.Encode(v); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// writeYAMLFile writes 'values' to 'path' as YAML, after 'header'
+func writeYAMLFile(path string, header string, values ...interface{}) error {
+	data, err := encodeYAML(values...)
+	if err != nil {
+		return errors.Wrapf(err, "could not encode %s", path)
+	}
+	return ioutil.WriteFile(path, append([]byte(header), data...), 0644)
+}
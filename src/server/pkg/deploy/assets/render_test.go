@@ -0,0 +1,184 @@
+package assets
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/server/pkg/serde"
+)
+
+func testManifest(t *testing.T, opts *AssetOpts) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, WriteLocalAssets(serde.NewJSONEncoder(&buf), opts, "/var/pachyderm"))
+	return buf.Bytes()
+}
+
+func testObjects(t *testing.T, manifest []byte) []map[string]interface{} {
+	objects, err := ParseManifest(manifest)
+	require.NoError(t, err)
+	return objects
+}
+
+// normalize round-trips 'v' through JSON, so that objects decoded from JSON
+// and YAML can be compared
+func normalize(t *testing.T, v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	var result interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+	return result
+}
+
+// renderHelmChart renders the templates of the chart in 'dir' like helm would,
+// with the chart's default values modified by 'f'
+func renderHelmChart(t *testing.T, dir string, namespace string, f func(values map[string]interface{})) []map[string]interface{} {
+	data, err := ioutil.ReadFile(filepath.Join(dir, "values.yaml"))
+	require.NoError(t, err)
+	values := make(map[string]interface{})
+	require.NoError(t, serde.DecodeYAML(data, &values))
+	f(values)
+	funcs := template.FuncMap{
+		"quote": func(v interface{}) string { return fmt.Sprintf("%q", fmt.Sprint(v)) },
+		"b64enc": func(s string) string {
+			return base64.StdEncoding.EncodeToString([]byte(s))
+		},
+	}
+	files, err := ioutil.ReadDir(filepath.Join(dir, "templates"))
+	require.NoError(t, err)
+	var objects []map[string]interface{}
+	for _, file := range files {
+		tmpl, err := template.New(file.Name()).Funcs(funcs).ParseFiles(filepath.Join(dir, "templates", file.Name()))
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		require.NoError(t, tmpl.Execute(&buf, map[string]interface{}{
+			"Values":  values,
+			"Release": map[string]interface{}{"Namespace": namespace},
+		}))
+		if len(bytes.TrimSpace(buf.Bytes())) == 0 {
+			continue
+		}
+		object := make(map[string]interface{})
+		require.NoError(t, serde.DecodeYAML(buf.Bytes(), &object), buf.String())
+		objects = append(objects, object)
+	}
+	return objects
+}
+
+func findObject(objects []map[string]interface{}, kind, name string) map[string]interface{} {
+	for _, object := range objects {
+		if k, n := objectKind(object); k == kind && n == name {
+			return object
+		}
+	}
+	return nil
+}
+
+func TestHelmChart(t *testing.T) {
+	opts := &AssetOpts{Version: "1.11.0", Namespace: "pachyderm", LogLevel: "info"}
+	manifest := testManifest(t, opts)
+	dir, err := ioutil.TempDir("", "chart")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	chartDir := filepath.Join(dir, "pachyderm")
+	require.NoError(t, WriteHelmChart(chartDir, testObjects(t, manifest), opts))
+	require.YesError(t, WriteHelmChart(chartDir, testObjects(t, manifest), opts))
+
+	// The chart's defaults render the same objects as the manifest
+	expected := testObjects(t, manifest)
+	rendered := renderHelmChart(t, chartDir, opts.Namespace, func(map[string]interface{}) {})
+	require.Equal(t, len(expected), len(rendered))
+	for _, object := range expected {
+		kind, name := objectKind(object)
+		require.Equal(t, normalize(t, object), normalize(t, findObject(rendered, kind, name)))
+	}
+
+	// Values and the release namespace are substituted
+	rendered = renderHelmChart(t, chartDir, "other", func(values map[string]interface{}) {
+		values["pachd"].(map[string]interface{})["logLevel"] = "debug"
+		values["dash"].(map[string]interface{})["enabled"] = false
+	})
+	require.Nil(t, findObject(rendered, "Deployment", dashName))
+	pachd := findObject(rendered, "Deployment", pachdName)
+	require.Equal(t, "other", pachd["metadata"].(map[string]interface{})["namespace"])
+	var logLevel interface{}
+	for _, e := range podContainers(pachd)[0]["env"].([]interface{}) {
+		if e.(map[string]interface{})["name"] == "LOG_LEVEL" {
+			logLevel = e.(map[string]interface{})["value"]
+		}
+	}
+	require.Equal(t, "debug", logLevel)
+}
+
+func TestHelmChartStorageSecret(t *testing.T) {
+	opts := &AssetOpts{Version: "1.11.0", Namespace: "pachyderm", LogLevel: "info"}
+	objects := testObjects(t, testManifest(t, opts))
+	credentials := map[string]interface{}{"amazon-id": "id", "amazon-secret": "hunter2"}
+	data := make(map[string]interface{})
+	for key, value := range credentials {
+		data[key] = base64.StdEncoding.EncodeToString([]byte(value.(string)))
+	}
+	findObject(objects, "Secret", client.StorageSecretName)["data"] = data
+	expected := normalize(t, findObject(objects, "Secret", client.StorageSecretName))
+	dir, err := ioutil.TempDir("", "chart")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	chartDir := filepath.Join(dir, "pachyderm")
+	require.NoError(t, WriteHelmChart(chartDir, objects, opts))
+
+	// Credentials aren't written to values.yaml
+	values, err := ioutil.ReadFile(filepath.Join(chartDir, "values.yaml"))
+	require.NoError(t, err)
+	require.False(t, strings.Contains(string(values), "hunter2"), string(values))
+	require.False(t, strings.Contains(string(values), data["amazon-secret"].(string)), string(values))
+
+	// They're set when the chart is installed...
+	rendered := renderHelmChart(t, chartDir, opts.Namespace, func(values map[string]interface{}) {
+		values["storageSecret"].(map[string]interface{})["data"] = credentials
+	})
+	require.Equal(t, expected, normalize(t, findObject(rendered, "Secret", client.StorageSecretName)))
+
+	// ...or the chart uses an existing secret
+	rendered = renderHelmChart(t, chartDir, opts.Namespace, func(values map[string]interface{}) {
+		values["storageSecret"].(map[string]interface{})["create"] = false
+	})
+	require.Nil(t, findObject(rendered, "Secret", client.StorageSecretName))
+	require.NotNil(t, findObject(rendered, "Deployment", pachdName))
+}
+
+func TestKustomization(t *testing.T) {
+	opts := &AssetOpts{Version: "1.11.0", Namespace: "pachyderm", LogLevel: "info"}
+	objects := testObjects(t, testManifest(t, opts))
+	dir, err := ioutil.TempDir("", "kustomize")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, WriteKustomization(filepath.Join(dir, "pachyderm"), objects, opts))
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "pachyderm", "base", "kustomization.yaml"))
+	require.NoError(t, err)
+	var kustomization struct {
+		Resources []string `json:"resources"`
+	}
+	require.NoError(t, serde.DecodeYAML(data, &kustomization))
+	require.Equal(t, len(objects), len(kustomization.Resources))
+	for i, resource := range kustomization.Resources {
+		data, err := ioutil.ReadFile(filepath.Join(dir, "pachyderm", "base", resource))
+		require.NoError(t, err)
+		object := make(map[string]interface{})
+		require.NoError(t, serde.DecodeYAML(data, &object))
+		require.Equal(t, normalize(t, objects[i]), normalize(t, object))
+	}
+	for _, file := range []string{"kustomization.yaml", kustomizePachdPatch} {
+		_, err := os.Stat(filepath.Join(dir, "pachyderm", "overlays", kustomizeOverlay, file))
+		require.NoError(t, err)
+	}
+}
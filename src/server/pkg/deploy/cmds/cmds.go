@@ -24,16 +24,22 @@ import (
 	"github.com/pachyderm/pachyderm/src/server/pkg/deploy"
 	"github.com/pachyderm/pachyderm/src/server/pkg/deploy/assets"
 	"github.com/pachyderm/pachyderm/src/server/pkg/deploy/images"
+	"github.com/pachyderm/pachyderm/src/server/pkg/deploy/upgrade"
 	_metrics "github.com/pachyderm/pachyderm/src/server/pkg/metrics"
 	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
 	"github.com/pachyderm/pachyderm/src/server/pkg/serde"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	kube "k8s.io/client-go/kubernetes"
 )
 
 var defaultDashImage = "pachyderm/dash:0.5.48"
 
+// upgradeRolloutTimeout is how long 'deploy upgrade' waits for etcd and pachd
+// to roll out
+const upgradeRolloutTimeout = 10 * time.Minute
+
 var awsAccessKeyIDRE = regexp.MustCompile("^[A-Z0-9]{20}$")
 var awsSecretRE = regexp.MustCompile("^[A-Za-z0-9/+=]{40}$")
 var awsRegionRE = regexp.MustCompile("^[a-z]{2}(?:-gov)?-[a-z]+-[0-9]$")
@@ -165,6 +171,50 @@ func containsEmpty(vals []string) bool {
 	return false
 }
 
+// deployAliases returns the invocations of the deploy command for 'backend',
+// which can also be run through 'deploy diff' and 'deploy upgrade'
+func deployAliases(cmd *cobra.Command, backend string) []*cobra.Command {
+	return []*cobra.Command{
+		cmdutil.CreateAlias(cmd, "deploy "+backend),
+		cmdutil.CreateAlias(cmd, "deploy diff "+backend),
+		cmdutil.CreateAlias(cmd, "deploy upgrade "+backend),
+	}
+}
+
+func newKubeClient() (kube.Interface, error) {
+	kubeConfig, err := config.KubeConfig(nil).ClientConfig()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not load kubernetes config")
+	}
+	kubeClient, err := kube.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not create kubernetes client")
+	}
+	return kubeClient, nil
+}
+
+// diffOrUpgrade compares 'objects' (the assets of a deploy command) with the
+// running cluster and prints the differences, and then applies them if
+// 'action' is "upgrade"
+func diffOrUpgrade(action string, objects []map[string]interface{}, opts *assets.AssetOpts) error {
+	kubeClient, err := newKubeClient()
+	if err != nil {
+		return err
+	}
+	diffs, err := upgrade.Diff(kubeClient, opts.Namespace, objects)
+	if err != nil {
+		return err
+	}
+	if err := upgrade.PrintDiff(os.Stdout, diffs); err != nil {
+		return err
+	}
+	if action != "upgrade" {
+		return nil
+	}
+	fmt.Println("")
+	return upgrade.Upgrade(kubeClient, opts.Namespace, diffs, os.Stdout, upgradeRolloutTimeout)
+}
+
 // deployCmds returns the set of cobra.Commands used to deploy pachyderm.
 func deployCmds() []*cobra.Command {
 	var commands []*cobra.Command
@@ -178,6 +228,38 @@ func deployCmds() []*cobra.Command {
 	var namespace string
 	var serverCert string
 	var createContext bool
+	var renderFormat string
+	var renderDir string
+	// upgradeAction is "diff" or "upgrade" when a deploy command is run through
+	// 'deploy diff' or 'deploy upgrade'
+	var upgradeAction string
+
+	// applyManifest either applies 'manifest' with kubectl, renders it as a
+	// helm chart or kustomize base if --output-format is set, or compares it
+	// with the running cluster for the 'deploy diff' and 'deploy upgrade' commands
+	applyManifest := func(manifest []byte) error {
+		if renderFormat == "" && upgradeAction == "" {
+			return kubectlCreate(dryRun, manifest, opts)
+		}
+		objects, err := assets.ParseManifest(manifest)
+		if err != nil {
+			return err
+		}
+		if upgradeAction != "" {
+			return diffOrUpgrade(upgradeAction, objects, opts)
+		}
+		switch renderFormat {
+		case "helm":
+			err = assets.WriteHelmChart(renderDir, objects, opts)
+		case "kustomize":
+			err = assets.WriteKustomization(renderDir, objects, opts)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Wrote %s output to %s\n", renderFormat, renderDir)
+		return nil
+	}
 
 	deployLocal := &cobra.Command{
 		Short: "Deploy a single-node Pachyderm cluster with local metadata storage.",
@@ -213,7 +295,7 @@ func deployCmds() []*cobra.Command {
 			); err != nil {
 				return err
 			}
-			if err := kubectlCreate(dryRun, buf.Bytes(), opts); err != nil {
+			if err := applyManifest(buf.Bytes()); err != nil {
 				return err
 			}
 			if !dryRun || createContext {
@@ -229,7 +311,7 @@ func deployCmds() []*cobra.Command {
 	}
 	deployLocal.Flags().StringVar(&hostPath, "host-path", "/var/pachyderm", "Location on the host machine where PFS metadata will be stored.")
 	deployLocal.Flags().BoolVarP(&dev, "dev", "d", false, "Deploy pachd with local version tags, disable metrics, expose Pachyderm's object/block API, and use an insecure authentication mechanism (do not set on any cluster with sensitive data)")
-	commands = append(commands, cmdutil.CreateAlias(deployLocal, "deploy local"))
+	commands = append(commands, deployAliases(deployLocal, "local")...)
 
 	deployGoogle := &cobra.Command{
 		Use:   "{{alias}} <bucket-name> <disk-size> [<credentials-file>]",
@@ -266,7 +348,7 @@ func deployCmds() []*cobra.Command {
 			); err != nil {
 				return err
 			}
-			if err := kubectlCreate(dryRun, buf.Bytes(), opts); err != nil {
+			if err := applyManifest(buf.Bytes()); err != nil {
 				return err
 			}
 			if !dryRun || createContext {
@@ -280,7 +362,7 @@ func deployCmds() []*cobra.Command {
 			return nil
 		}),
 	}
-	commands = append(commands, cmdutil.CreateAlias(deployGoogle, "deploy google"))
+	commands = append(commands, deployAliases(deployGoogle, "google")...)
 
 	var objectStoreBackend string
 	var persistentDiskBackend string
@@ -327,7 +409,7 @@ If <object store backend> is \"s3\", then the arguments are:
 			); err != nil {
 				return err
 			}
-			if err := kubectlCreate(dryRun, buf.Bytes(), opts); err != nil {
+			if err := applyManifest(buf.Bytes()); err != nil {
 				return err
 			}
 			if !dryRun || createContext {
@@ -358,7 +440,7 @@ If <object store backend> is \"s3\", then the arguments are:
 	deployCustom.Flags().IntVar(&maxUploadParts, "max-upload-parts", obj.DefaultMaxUploadParts, "(rarely set / S3V2 incompatible) Set a custom maximum number of upload parts.")
 	deployCustom.Flags().BoolVar(&disableSSL, "disable-ssl", obj.DefaultDisableSSL, "(rarely set / S3V2 incompatible) Disable SSL.")
 	deployCustom.Flags().BoolVar(&noVerifySSL, "no-verify-ssl", obj.DefaultNoVerifySSL, "(rarely set / S3V2 incompatible) Skip SSL certificate verification (typically used for enabling self-signed certificates).")
-	commands = append(commands, cmdutil.CreateAlias(deployCustom, "deploy custom"))
+	commands = append(commands, deployAliases(deployCustom, "custom")...)
 
 	var cloudfrontDistribution string
 	var creds string
@@ -466,7 +548,7 @@ If <object store backend> is \"s3\", then the arguments are:
 			); err != nil {
 				return err
 			}
-			if err := kubectlCreate(dryRun, buf.Bytes(), opts); err != nil {
+			if err := applyManifest(buf.Bytes()); err != nil {
 				return err
 			}
 			if !dryRun || createContext {
@@ -495,7 +577,7 @@ If <object store backend> is \"s3\", then the arguments are:
 	deployAmazon.Flags().IntVar(&maxUploadParts, "max-upload-parts", obj.DefaultMaxUploadParts, "(rarely set) Set a custom maximum number of upload parts.")
 	deployAmazon.Flags().BoolVar(&disableSSL, "disable-ssl", obj.DefaultDisableSSL, "(rarely set) Disable SSL.")
 	deployAmazon.Flags().BoolVar(&noVerifySSL, "no-verify-ssl", obj.DefaultNoVerifySSL, "(rarely set) Skip SSL certificate verification (typically used for enabling self-signed certificates).")
-	commands = append(commands, cmdutil.CreateAlias(deployAmazon, "deploy amazon"))
+	commands = append(commands, deployAliases(deployAmazon, "amazon")...)
 
 	deployMicrosoft := &cobra.Command{
 		Use:   "{{alias}} <container> <account-name> <account-key> <disk-size>",
@@ -533,7 +615,7 @@ If <object store backend> is \"s3\", then the arguments are:
 			); err != nil {
 				return err
 			}
-			if err := kubectlCreate(dryRun, buf.Bytes(), opts); err != nil {
+			if err := applyManifest(buf.Bytes()); err != nil {
 				return err
 			}
 			if !dryRun || createContext {
@@ -547,7 +629,7 @@ If <object store backend> is \"s3\", then the arguments are:
 			return nil
 		}),
 	}
-	commands = append(commands, cmdutil.CreateAlias(deployMicrosoft, "deploy microsoft"))
+	commands = append(commands, deployAliases(deployMicrosoft, "microsoft")...)
 
 	deployStorageSecrets := func(data map[string][]byte) error {
 		c, err := client.NewOnUserMachine("user")
@@ -563,6 +645,9 @@ If <object store backend> is \"s3\", then the arguments are:
 			}
 		}
 
+		if renderFormat != "" {
+			return errors.Errorf("--output-format is not supported when deploying storage credentials")
+		}
 		var buf bytes.Buffer
 		if err = assets.WriteSecret(encoder(outputFormat, &buf), data, opts); err != nil {
 			return err
@@ -719,83 +804,120 @@ If <object store backend> is \"s3\", then the arguments are:
 	var registry string
 	var tlsCertKey string
 	var uploadConcurrencyLimit int
+	var storageReplicas []string
 	var clusterDeploymentID string
 	var requireCriticalServersOnly bool
-	deploy := &cobra.Command{
-		Short: "Deploy a Pachyderm cluster.",
-		Long:  "Deploy a Pachyderm cluster.",
-		PersistentPreRun: cmdutil.Run(func([]string) error {
-			cfg, err := config.Read(false)
+	// deployPreRun builds the AssetOpts for every deploy command from the deploy
+	// flags. 'deploy diff' and 'deploy upgrade' call it too, after setting
+	// upgradeAction.
+	deployPreRun := func([]string) error {
+		cfg, err := config.Read(false)
+		if err != nil {
+			log.Warningf("could not read config to check whether cluster metrics "+
+				"will be enabled: %v.\n", err)
+		}
+
+		if namespace == "" {
+			kubeConfig := config.KubeConfig(nil)
+			var err error
+			namespace, _, err = kubeConfig.Namespace()
 			if err != nil {
-				log.Warningf("could not read config to check whether cluster metrics "+
-					"will be enabled: %v.\n", err)
+				log.Warningf("using namespace \"default\" (couldn't load namespace "+
+					"from kubernetes config: %v)\n", err)
+				namespace = "default"
 			}
+		}
 
-			if namespace == "" {
-				kubeConfig := config.KubeConfig(nil)
-				var err error
-				namespace, _, err = kubeConfig.Namespace()
+		if upgradeAction != "" {
+			if renderFormat != "" {
+				return errors.Errorf("--output-format can't be used with 'deploy %s'", upgradeAction)
+			}
+			// The cluster is compared with the JSON manifest, and already has a
+			// context
+			dryRun = true
+			outputFormat = "json"
+			if clusterDeploymentID == "" {
+				// Keep the running cluster's deployment ID, rather than generating
+				// a new one
+				kubeClient, err := newKubeClient()
+				if err != nil {
+					return err
+				}
+				clusterDeploymentID, err = upgrade.ClusterDeploymentID(kubeClient, namespace)
 				if err != nil {
-					log.Warningf("using namespace \"default\" (couldn't load namespace "+
-						"from kubernetes config: %v)\n", err)
-					namespace = "default"
+					return err
 				}
 			}
-
-			dashImage = getDefaultOrLatestDashImage(dashImage, dryRun)
-			opts = &assets.AssetOpts{
-				FeatureFlags: assets.FeatureFlags{
-					NewStorageLayer: newStorageLayer,
-				},
-				StorageOpts: assets.StorageOpts{
-					UploadConcurrencyLimit: uploadConcurrencyLimit,
-				},
-				PachdShards:                uint64(pachdShards),
-				Version:                    version.PrettyPrintVersion(version.Version),
-				LogLevel:                   logLevel,
-				Metrics:                    cfg == nil || cfg.V2.Metrics,
-				PachdCPURequest:            pachdCPURequest,
-				PachdNonCacheMemRequest:    pachdNonCacheMemRequest,
-				BlockCacheSize:             blockCacheSize,
-				EtcdCPURequest:             etcdCPURequest,
-				EtcdMemRequest:             etcdMemRequest,
-				EtcdNodes:                  etcdNodes,
-				EtcdVolume:                 etcdVolume,
-				EtcdStorageClassName:       etcdStorageClassName,
-				DashOnly:                   dashOnly,
-				NoDash:                     noDash,
-				DashImage:                  dashImage,
-				Registry:                   registry,
-				ImagePullSecret:            imagePullSecret,
-				NoGuaranteed:               noGuaranteed,
-				NoRBAC:                     noRBAC,
-				LocalRoles:                 localRoles,
-				Namespace:                  namespace,
-				NoExposeDockerSocket:       noExposeDockerSocket,
-				ExposeObjectAPI:            exposeObjectAPI,
-				ClusterDeploymentID:        clusterDeploymentID,
-				RequireCriticalServersOnly: requireCriticalServersOnly,
+		}
+		switch renderFormat {
+		case "":
+		case "helm", "kustomize":
+			// Rendered output is written to --output-dir rather than applied,
+			// and is parsed from the JSON manifest
+			dryRun = true
+			outputFormat = "json"
+		default:
+			return errors.Errorf("unrecognized output format %q; must be one of: helm|kustomize", renderFormat)
+		}
+		dashImage = getDefaultOrLatestDashImage(dashImage, dryRun)
+		opts = &assets.AssetOpts{
+			FeatureFlags: assets.FeatureFlags{
+				NewStorageLayer: newStorageLayer,
+			},
+			StorageOpts: assets.StorageOpts{
+				UploadConcurrencyLimit: uploadConcurrencyLimit,
+				Replicas:               storageReplicas,
+			},
+			PachdShards:                uint64(pachdShards),
+			Version:                    version.PrettyPrintVersion(version.Version),
+			LogLevel:                   logLevel,
+			Metrics:                    cfg == nil || cfg.V2.Metrics,
+			PachdCPURequest:            pachdCPURequest,
+			PachdNonCacheMemRequest:    pachdNonCacheMemRequest,
+			BlockCacheSize:             blockCacheSize,
+			EtcdCPURequest:             etcdCPURequest,
+			EtcdMemRequest:             etcdMemRequest,
+			EtcdNodes:                  etcdNodes,
+			EtcdVolume:                 etcdVolume,
+			EtcdStorageClassName:       etcdStorageClassName,
+			DashOnly:                   dashOnly,
+			NoDash:                     noDash,
+			DashImage:                  dashImage,
+			Registry:                   registry,
+			ImagePullSecret:            imagePullSecret,
+			NoGuaranteed:               noGuaranteed,
+			NoRBAC:                     noRBAC,
+			LocalRoles:                 localRoles,
+			Namespace:                  namespace,
+			NoExposeDockerSocket:       noExposeDockerSocket,
+			ExposeObjectAPI:            exposeObjectAPI,
+			ClusterDeploymentID:        clusterDeploymentID,
+			RequireCriticalServersOnly: requireCriticalServersOnly,
+		}
+		if tlsCertKey != "" {
+			// TODO(msteffen): If either the cert path or the key path contains a
+			// comma, this doesn't work
+			certKey := strings.Split(tlsCertKey, ",")
+			if len(certKey) != 2 {
+				return errors.Errorf("could not split TLS certificate and key correctly; must have two parts but got: %#v", certKey)
+			}
+			opts.TLS = &assets.TLSOpts{
+				ServerCert: certKey[0],
+				ServerKey:  certKey[1],
 			}
-			if tlsCertKey != "" {
-				// TODO(msteffen): If either the cert path or the key path contains a
-				// comma, this doesn't work
-				certKey := strings.Split(tlsCertKey, ",")
-				if len(certKey) != 2 {
-					return errors.Errorf("could not split TLS certificate and key correctly; must have two parts but got: %#v", certKey)
-				}
-				opts.TLS = &assets.TLSOpts{
-					ServerCert: certKey[0],
-					ServerKey:  certKey[1],
-				}
 
-				serverCertBytes, err := ioutil.ReadFile(certKey[0])
-				if err != nil {
-					return errors.Wrapf(err, "could not read server cert at %q", certKey[0])
-				}
-				serverCert = base64.StdEncoding.EncodeToString([]byte(serverCertBytes))
+			serverCertBytes, err := ioutil.ReadFile(certKey[0])
+			if err != nil {
+				return errors.Wrapf(err, "could not read server cert at %q", certKey[0])
 			}
-			return nil
-		}),
+			serverCert = base64.StdEncoding.EncodeToString([]byte(serverCertBytes))
+		}
+		return nil
+	}
+	deploy := &cobra.Command{
+		Short:            "Deploy a Pachyderm cluster.",
+		Long:             "Deploy a Pachyderm cluster.",
+		PersistentPreRun: cmdutil.Run(deployPreRun),
 	}
 	deploy.PersistentFlags().IntVar(&pachdShards, "shards", 16, "(rarely set) The maximum number of pachd nodes allowed in the cluster; increasing this number blindly can result in degraded performance.")
 	deploy.PersistentFlags().IntVar(&etcdNodes, "dynamic-etcd-nodes", 0, "Deploy etcd as a StatefulSet with the given number of pods.  The persistent volumes used by these pods are provisioned dynamically.  Note that StatefulSet is currently a beta kubernetes feature, which might be unavailable in older versions of kubernetes.")
@@ -803,6 +925,8 @@ If <object store backend> is \"s3\", then the arguments are:
 	deploy.PersistentFlags().StringVar(&etcdStorageClassName, "etcd-storage-class", "", "If set, the name of an existing StorageClass to use for etcd storage. Ignored if --static-etcd-volume is set.")
 	deploy.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Don't actually deploy pachyderm to Kubernetes, instead just print the manifest. Note that a pachyderm context will not be created, unless you also use `--create-context`.")
 	deploy.PersistentFlags().StringVarP(&outputFormat, "output", "o", "json", "Output format. One of: json|yaml")
+	deploy.PersistentFlags().StringVar(&renderFormat, "output-format", "", "If set, write the deployment to --output-dir as a helm chart or kustomize base instead of deploying it. One of: helm|kustomize")
+	deploy.PersistentFlags().StringVar(&renderDir, "output-dir", "pachyderm", "The directory that --output-format writes to, which must not already exist.")
 	deploy.PersistentFlags().StringVar(&logLevel, "log-level", "info", "The level of log messages to print options are, from least to most verbose: \"error\", \"info\", \"debug\".")
 	deploy.PersistentFlags().BoolVar(&dashOnly, "dashboard-only", false, "Only deploy the Pachyderm UI (experimental), without the rest of pachyderm. This is for launching the UI adjacent to an existing Pachyderm cluster. After deployment, run \"pachctl port-forward\" to connect")
 	deploy.PersistentFlags().BoolVar(&noDash, "no-dashboard", false, "Don't deploy the Pachyderm UI alongside Pachyderm (experimental).")
@@ -818,6 +942,7 @@ If <object store backend> is \"s3\", then the arguments are:
 	deploy.PersistentFlags().StringVar(&tlsCertKey, "tls", "", "string of the form \"<cert path>,<key path>\" of the signed TLS certificate and private key that Pachd should use for TLS authentication (enables TLS-encrypted communication with Pachd)")
 	deploy.PersistentFlags().BoolVar(&newStorageLayer, "new-storage-layer", false, "(feature flag) Do not set, used for testing.")
 	deploy.PersistentFlags().IntVar(&uploadConcurrencyLimit, "upload-concurrency-limit", assets.DefaultUploadConcurrencyLimit, "The maximum number of concurrent object storage uploads per Pachd instance.")
+	deploy.PersistentFlags().StringSliceVar(&storageReplicas, "storage-replica", nil, "An object store URL (e.g. s3://dr-bucket) that object storage writes are asynchronously replicated to, using the credentials in the storage secret. May be specified multiple times.")
 	deploy.PersistentFlags().StringVar(&clusterDeploymentID, "cluster-deployment-id", "", "Set an ID for the cluster deployment. Defaults to a random value.")
 	deploy.PersistentFlags().StringVarP(&contextName, "context", "c", "", "Name of the context to add to the pachyderm config. If unspecified, a context name will automatically be derived.")
 	deploy.PersistentFlags().BoolVar(&createContext, "create-context", false, "Create a context, even with `--dry-run`.")
@@ -851,6 +976,36 @@ If <object store backend> is \"s3\", then the arguments are:
 
 	commands = append(commands, cmdutil.CreateAlias(deploy, "deploy"))
 
+	deployDiff := &cobra.Command{
+		Short: "Show how a running Pachyderm cluster differs from a new deployment.",
+		Long: "Show how a running Pachyderm cluster differs from a new deployment. " +
+			"'pachctl deploy diff <backend>' takes the same arguments and flags as " +
+			"'pachctl deploy <backend>', and compares the deployments, services, " +
+			"RBAC objects and secrets that it would create with the ones in the cluster.",
+		PersistentPreRun: cmdutil.Run(func(args []string) error {
+			upgradeAction = "diff"
+			return deployPreRun(args)
+		}),
+	}
+	commands = append(commands, cmdutil.CreateAlias(deployDiff, "deploy diff"))
+
+	deployUpgrade := &cobra.Command{
+		Short: "Upgrade a running Pachyderm cluster in place.",
+		Long: "Upgrade a running Pachyderm cluster in place. " +
+			"'pachctl deploy upgrade <backend>' takes the same arguments and flags as " +
+			"'pachctl deploy <backend>', prints the same differences as " +
+			"'pachctl deploy diff', and then applies them: RBAC objects, secrets and " +
+			"services first, then etcd, then pachd, waiting for each to roll out. " +
+			"Changes that would lose data, such as changing etcd's storage or the " +
+			"number of shards, are refused, and objects that are no longer part of " +
+			"the deployment are not removed.",
+		PersistentPreRun: cmdutil.Run(func(args []string) error {
+			upgradeAction = "upgrade"
+			return deployPreRun(args)
+		}),
+	}
+	commands = append(commands, cmdutil.CreateAlias(deployUpgrade, "deploy upgrade"))
+
 	return commands
 }
 
@@ -0,0 +1,333 @@
+// Package upgrade compares a running Pachyderm cluster with the assets that
+// 'pachctl deploy' generates for the current version and options, and
+// upgrades the cluster to those assets in place.
+package upgrade
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	kube "k8s.io/client-go/kubernetes"
+)
+
+// Action is what Upgrade does to a live object
+type Action string
+
+const (
+	// Create means that the object doesn't exist in the cluster
+	Create Action = "create"
+	// Update means that the live object differs from its target
+	Update Action = "update"
+	// Unchanged means that the live object matches its target
+	Unchanged Action = "unchanged"
+	// Extra means that the live object is labelled as part of Pachyderm's
+	// deployment, but isn't in the target assets. Upgrade leaves it alone.
+	Extra Action = "extra"
+	// Skipped means that objects of this kind (e.g. StorageClasses) aren't
+	// compared or upgraded
+	Skipped Action = "skipped"
+)
+
+const (
+	// suiteSelector selects the objects that 'pachctl deploy' created
+	suiteSelector = "suite=pachyderm"
+
+	// redacted replaces the values of secrets in a diff
+	redacted = "<redacted>"
+)
+
+// Change is one field that differs between a live object and its target
+type Change struct {
+	// Path identifies the field. List elements are identified by their name
+	// if they have one, e.g. "spec.template.spec.containers[pachd].image"
+	Path string
+	// Live and Target are the field's values, or nil if it's unset
+	Live   interface{}
+	Target interface{}
+}
+
+// ObjectDiff describes how one live object differs from its target
+type ObjectDiff struct {
+	Kind    string
+	Name    string
+	Action  Action
+	Changes []Change
+
+	target map[string]interface{}
+}
+
+// Diff compares the live objects in 'namespace' with 'objects', the target
+// assets as returned by assets.ParseManifest. Fields that are set in the live
+// objects but not in their targets (e.g. defaults filled in by k8s) are
+// ignored.
+func Diff(c kube.Interface, namespace string, objects []map[string]interface{}) ([]*ObjectDiff, error) {
+	clients := kindClients(c, namespace)
+	var diffs []*ObjectDiff
+	inTarget := make(map[string]bool)
+	for _, object := range objects {
+		target, _ := prune(object).(map[string]interface{})
+		delete(target, "status")
+		kind, name := objectKind(target)
+		if metadata, ok := target["metadata"].(map[string]interface{}); ok && clusterScoped[kind] {
+			delete(metadata, "namespace")
+		}
+		inTarget[kind+"/"+name] = true
+		d := &ObjectDiff{Kind: kind, Name: name, target: target}
+		diffs = append(diffs, d)
+		kc, ok := clients[kind]
+		if !ok {
+			d.Action = Skipped
+			continue
+		}
+		live, err := kc.getObject(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not get %s %s", kind, name)
+		}
+		if live == nil {
+			d.Action = Create
+			continue
+		}
+		compare("", target, live, &d.Changes)
+		if kind == "Secret" {
+			for i := range d.Changes {
+				d.Changes[i].Live = redact(d.Changes[i].Live)
+				d.Changes[i].Target = redact(d.Changes[i].Target)
+			}
+		}
+		d.Action = Unchanged
+		if len(d.Changes) > 0 {
+			d.Action = Update
+		}
+	}
+
+	// Report the objects that a previous deploy created, but that aren't in
+	// the target assets
+	var kinds []string
+	for kind := range clients {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		names, err := clients[kind].listNames(suiteSelector)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not list %ss", kind)
+		}
+		for _, name := range names {
+			if !inTarget[kind+"/"+name] {
+				diffs = append(diffs, &ObjectDiff{Kind: kind, Name: name, Action: Extra})
+			}
+		}
+	}
+	return diffs, nil
+}
+
+// PrintDiff prints 'diffs' to 'w', omitting unchanged objects
+func PrintDiff(w io.Writer, diffs []*ObjectDiff) error {
+	changed := false
+	for _, d := range diffs {
+		var err error
+		switch d.Action {
+		case Create:
+			_, err = fmt.Fprintf(w, "+ %s %s\n", d.Kind, d.Name)
+		case Update:
+			_, err = fmt.Fprintf(w, "~ %s %s\n", d.Kind, d.Name)
+			for _, change := range d.Changes {
+				if err != nil {
+					break
+				}
+				_, err = fmt.Fprintf(w, "    %s: %s -> %s\n", change.Path,
+					formatValue(change.Live), formatValue(change.Target))
+			}
+		case Extra:
+			_, err = fmt.Fprintf(w, "- %s %s (not in the target assets, will not be removed)\n", d.Kind, d.Name)
+		case Skipped:
+			_, err = fmt.Fprintf(w, "? %s %s (not compared)\n", d.Kind, d.Name)
+		default:
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		changed = true
+	}
+	if !changed {
+		_, err := fmt.Fprintln(w, "No changes")
+		return err
+	}
+	return nil
+}
+
+// compare appends the fields of 'target' that differ in 'live' to 'changes'
+func compare(path string, target, live interface{}, changes *[]Change) {
+	switch target := target.(type) {
+	case map[string]interface{}:
+		liveMap, ok := live.(map[string]interface{})
+		if !ok {
+			if len(target) > 0 {
+				*changes = append(*changes, Change{Path: path, Live: live, Target: target})
+			}
+			return
+		}
+		var keys []string
+		for key := range target {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			keyPath := key
+			if path != "" {
+				keyPath = path + "." + key
+			}
+			compare(keyPath, target[key], liveMap[key], changes)
+		}
+	case []interface{}:
+		liveList, ok := live.([]interface{})
+		if !ok {
+			if len(target) > 0 {
+				*changes = append(*changes, Change{Path: path, Live: live, Target: target})
+			}
+			return
+		}
+		if names := elementNames(target); names != nil {
+			// Match named elements (containers, env vars, ports, ...) by name,
+			// since k8s may reorder them
+			liveByName := make(map[string]interface{})
+			if liveNames := elementNames(liveList); liveNames != nil {
+				for i, name := range liveNames {
+					liveByName[name] = liveList[i]
+				}
+			}
+			for i, name := range names {
+				compare(fmt.Sprintf("%s[%s]", path, name), target[i], liveByName[name], changes)
+			}
+			return
+		}
+		if len(target) != len(liveList) {
+			*changes = append(*changes, Change{Path: path, Live: live, Target: target})
+			return
+		}
+		for i := range target {
+			compare(fmt.Sprintf("%s[%d]", path, i), target[i], liveList[i], changes)
+		}
+	default:
+		if !equalValues(target, live) {
+			*changes = append(*changes, Change{Path: path, Live: live, Target: target})
+		}
+	}
+}
+
+// elementNames returns the names of the elements of 'list', or nil if any
+// element doesn't have a name
+func elementNames(list []interface{}) []string {
+	names := make([]string, 0, len(list))
+	for _, e := range list {
+		m, ok := e.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		name, ok := m["name"].(string)
+		if !ok || name == "" {
+			return nil
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return names
+}
+
+// equalValues returns true if the leaf values 'target' and 'live' are
+// semantically equal, i.e. equal or equal resource quantities ("1" and
+// "1000m")
+func equalValues(target, live interface{}) bool {
+	if reflect.DeepEqual(target, live) {
+		return true
+	}
+	targetString, ok := target.(string)
+	if !ok {
+		return false
+	}
+	liveString, ok := live.(string)
+	if !ok {
+		return false
+	}
+	targetQuantity, err := resource.ParseQuantity(targetString)
+	if err != nil {
+		return false
+	}
+	liveQuantity, err := resource.ParseQuantity(liveString)
+	if err != nil {
+		return false
+	}
+	return targetQuantity.Cmp(liveQuantity) == 0
+}
+
+// prune returns a copy of 'v' without null fields, which the assets contain
+// for unset fields like creationTimestamp
+func prune(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{})
+		for key, value := range v {
+			if value != nil {
+				result[key] = prune(value)
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, value := range v {
+			result[i] = prune(value)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// redact hides the secret values in 'v', which is either one value or a map of
+// them
+func redact(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		result := make(map[string]interface{})
+		for key := range v {
+			result[key] = redacted
+		}
+		return result
+	default:
+		return redacted
+	}
+}
+
+func formatValue(v interface{}) string {
+	if v == nil {
+		return "<unset>"
+	}
+	var buf bytes.Buffer
+	e := json.NewEncoder(&buf)
+	e.SetEscapeHTML(false)
+	if err := e.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// objectKind returns the kind and name of a k8s object
+func objectKind(object map[string]interface{}) (string, string) {
+	kind, _ := object["kind"].(string)
+	metadata, _ := object["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	return kind, name
+}
@@ -0,0 +1,268 @@
+package upgrade
+
+import (
+	"encoding/json"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+
+	apps "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kube "k8s.io/client-go/kubernetes"
+)
+
+// clusterScoped are the kinds that 'kindClients' supports that aren't
+// namespaced. The assets set a namespace on them anyway, which k8s ignores.
+var clusterScoped = map[string]bool{
+	"ClusterRole":        true,
+	"ClusterRoleBinding": true,
+}
+
+// kindClient reads and writes the objects of one kind through the k8s API.
+// Objects are passed around as JSON, so that the same diff and upgrade logic
+// works for every kind.
+type kindClient struct {
+	get    func(name string) (interface{}, error)
+	list   func(opts metav1.ListOptions) (interface{}, error)
+	create func(data []byte) error
+	patch  func(name string, data []byte) error
+}
+
+// kindClients returns clients for the kinds of objects that 'Diff' compares,
+// keyed by kind
+func kindClients(c kube.Interface, namespace string) map[string]*kindClient {
+	core, appsV1, rbac := c.CoreV1(), c.AppsV1(), c.RbacV1()
+	return map[string]*kindClient{
+		"Deployment": {
+			get: func(name string) (interface{}, error) {
+				return appsV1.Deployments(namespace).Get(name, metav1.GetOptions{})
+			},
+			list: func(opts metav1.ListOptions) (interface{}, error) {
+				return appsV1.Deployments(namespace).List(opts)
+			},
+			create: func(data []byte) error {
+				o := &apps.Deployment{}
+				return createObject(data, o, func() error {
+					_, err := appsV1.Deployments(namespace).Create(o)
+					return err
+				})
+			},
+			patch: func(name string, data []byte) error {
+				_, err := appsV1.Deployments(namespace).Patch(name, types.StrategicMergePatchType, data)
+				return err
+			},
+		},
+		"StatefulSet": {
+			get: func(name string) (interface{}, error) {
+				return appsV1.StatefulSets(namespace).Get(name, metav1.GetOptions{})
+			},
+			list: func(opts metav1.ListOptions) (interface{}, error) {
+				return appsV1.StatefulSets(namespace).List(opts)
+			},
+			create: func(data []byte) error {
+				o := &apps.StatefulSet{}
+				return createObject(data, o, func() error {
+					_, err := appsV1.StatefulSets(namespace).Create(o)
+					return err
+				})
+			},
+			patch: func(name string, data []byte) error {
+				_, err := appsV1.StatefulSets(namespace).Patch(name, types.StrategicMergePatchType, data)
+				return err
+			},
+		},
+		"Service": {
+			get: func(name string) (interface{}, error) {
+				return core.Services(namespace).Get(name, metav1.GetOptions{})
+			},
+			list: func(opts metav1.ListOptions) (interface{}, error) {
+				return core.Services(namespace).List(opts)
+			},
+			create: func(data []byte) error {
+				o := &v1.Service{}
+				return createObject(data, o, func() error {
+					_, err := core.Services(namespace).Create(o)
+					return err
+				})
+			},
+			patch: func(name string, data []byte) error {
+				_, err := core.Services(namespace).Patch(name, types.StrategicMergePatchType, data)
+				return err
+			},
+		},
+		"Secret": {
+			get: func(name string) (interface{}, error) {
+				return core.Secrets(namespace).Get(name, metav1.GetOptions{})
+			},
+			list: func(opts metav1.ListOptions) (interface{}, error) {
+				return core.Secrets(namespace).List(opts)
+			},
+			create: func(data []byte) error {
+				o := &v1.Secret{}
+				return createObject(data, o, func() error {
+					_, err := core.Secrets(namespace).Create(o)
+					return err
+				})
+			},
+			patch: func(name string, data []byte) error {
+				_, err := core.Secrets(namespace).Patch(name, types.StrategicMergePatchType, data)
+				return err
+			},
+		},
+		"ServiceAccount": {
+			get: func(name string) (interface{}, error) {
+				return core.ServiceAccounts(namespace).Get(name, metav1.GetOptions{})
+			},
+			list: func(opts metav1.ListOptions) (interface{}, error) {
+				return core.ServiceAccounts(namespace).List(opts)
+			},
+			create: func(data []byte) error {
+				o := &v1.ServiceAccount{}
+				return createObject(data, o, func() error {
+					_, err := core.ServiceAccounts(namespace).Create(o)
+					return err
+				})
+			},
+			patch: func(name string, data []byte) error {
+				_, err := core.ServiceAccounts(namespace).Patch(name, types.StrategicMergePatchType, data)
+				return err
+			},
+		},
+		"ClusterRole": {
+			get: func(name string) (interface{}, error) {
+				return rbac.ClusterRoles().Get(name, metav1.GetOptions{})
+			},
+			list: func(opts metav1.ListOptions) (interface{}, error) {
+				return rbac.ClusterRoles().List(opts)
+			},
+			create: func(data []byte) error {
+				o := &rbacv1.ClusterRole{}
+				return createObject(data, o, func() error {
+					_, err := rbac.ClusterRoles().Create(o)
+					return err
+				})
+			},
+			patch: func(name string, data []byte) error {
+				_, err := rbac.ClusterRoles().Patch(name, types.StrategicMergePatchType, data)
+				return err
+			},
+		},
+		"ClusterRoleBinding": {
+			get: func(name string) (interface{}, error) {
+				return rbac.ClusterRoleBindings().Get(name, metav1.GetOptions{})
+			},
+			list: func(opts metav1.ListOptions) (interface{}, error) {
+				return rbac.ClusterRoleBindings().List(opts)
+			},
+			create: func(data []byte) error {
+				o := &rbacv1.ClusterRoleBinding{}
+				return createObject(data, o, func() error {
+					_, err := rbac.ClusterRoleBindings().Create(o)
+					return err
+				})
+			},
+			patch: func(name string, data []byte) error {
+				_, err := rbac.ClusterRoleBindings().Patch(name, types.StrategicMergePatchType, data)
+				return err
+			},
+		},
+		"Role": {
+			get: func(name string) (interface{}, error) {
+				return rbac.Roles(namespace).Get(name, metav1.GetOptions{})
+			},
+			list: func(opts metav1.ListOptions) (interface{}, error) {
+				return rbac.Roles(namespace).List(opts)
+			},
+			create: func(data []byte) error {
+				o := &rbacv1.Role{}
+				return createObject(data, o, func() error {
+					_, err := rbac.Roles(namespace).Create(o)
+					return err
+				})
+			},
+			patch: func(name string, data []byte) error {
+				_, err := rbac.Roles(namespace).Patch(name, types.StrategicMergePatchType, data)
+				return err
+			},
+		},
+		"RoleBinding": {
+			get: func(name string) (interface{}, error) {
+				return rbac.RoleBindings(namespace).Get(name, metav1.GetOptions{})
+			},
+			list: func(opts metav1.ListOptions) (interface{}, error) {
+				return rbac.RoleBindings(namespace).List(opts)
+			},
+			create: func(data []byte) error {
+				o := &rbacv1.RoleBinding{}
+				return createObject(data, o, func() error {
+					_, err := rbac.RoleBindings(namespace).Create(o)
+					return err
+				})
+			},
+			patch: func(name string, data []byte) error {
+				_, err := rbac.RoleBindings(namespace).Patch(name, types.StrategicMergePatchType, data)
+				return err
+			},
+		},
+	}
+}
+
+// createObject decodes 'data' into 'o' and then calls 'create'
+func createObject(data []byte, o interface{}, create func() error) error {
+	if err := json.Unmarshal(data, o); err != nil {
+		return errors.Wrapf(err, "could not decode object")
+	}
+	return create()
+}
+
+// getObject returns the live object 'name' as generic JSON, or nil if it
+// doesn't exist
+func (kc *kindClient) getObject(name string) (map[string]interface{}, error) {
+	o, err := kc.get(name)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	result := make(map[string]interface{})
+	if err := toJSONObject(o, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// listNames returns the names of the live objects that match 'selector'
+func (kc *kindClient) listNames(selector string) ([]string, error) {
+	l, err := kc.list(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	var list struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		} `json:"items"`
+	}
+	if err := toJSONObject(l, &list); err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, item := range list.Items {
+		names = append(names, item.Metadata.Name)
+	}
+	return names, nil
+}
+
+// toJSONObject converts 'o' to 'result' by round-tripping it through JSON
+func toJSONObject(o interface{}, result interface{}) error {
+	data, err := json.Marshal(o)
+	if err != nil {
+		return errors.Wrapf(err, "could not encode object")
+	}
+	return json.Unmarshal(data, result)
+}
@@ -0,0 +1,195 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kube "k8s.io/client-go/kubernetes"
+)
+
+const (
+	etcdName  = "etcd"
+	pachdName = "pachd"
+)
+
+// rolloutPollInterval is how often Upgrade checks whether etcd and pachd have
+// rolled out
+var rolloutPollInterval = 2 * time.Second
+
+// unsafePaths are the fields of etcd and pachd that Upgrade refuses to change
+// in place: etcd's storage and cluster membership, which are fixed when etcd
+// is first deployed, and pachd's shard count, which existing data is hashed
+// by.
+var unsafePaths = map[string][]string{
+	etcdName: {
+		"spec.replicas",
+		"spec.volumeClaimTemplates",
+		"spec.template.spec.volumes",
+	},
+	pachdName: {
+		"spec.template.spec.containers[pachd].env[NUM_SHARDS]",
+	},
+}
+
+// Upgrade applies 'diffs' (as returned by Diff) to the cluster in 'namespace'.
+// Objects are created or patched in a safe order: service accounts, RBAC,
+// secrets and services first, then etcd, then pachd, then everything else
+// (e.g. the dashboard). Upgrade waits up to 'timeout' for etcd to roll out
+// before pachd is updated, and for pachd to roll out before returning.
+// Objects that aren't in the target assets are never removed.
+func Upgrade(c kube.Interface, namespace string, diffs []*ObjectDiff, w io.Writer, timeout time.Duration) error {
+	clients := kindClients(c, namespace)
+	if err := checkUpgrade(clients, diffs); err != nil {
+		return err
+	}
+	ordered := make([]*ObjectDiff, len(diffs))
+	copy(ordered, diffs)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return upgradeStage(ordered[i]) < upgradeStage(ordered[j])
+	})
+	for _, d := range ordered {
+		kc := clients[d.Kind]
+		if d.Action != Create && d.Action != Update {
+			continue
+		}
+		data, err := json.Marshal(d.target)
+		if err != nil {
+			return errors.Wrapf(err, "could not encode %s %s", d.Kind, d.Name)
+		}
+		if d.Action == Create {
+			if err := kc.create(data); err != nil {
+				return errors.Wrapf(err, "could not create %s %s", d.Kind, d.Name)
+			}
+			fmt.Fprintf(w, "created %s %s\n", d.Kind, d.Name)
+		} else {
+			if err := kc.patch(d.Name, data); err != nil {
+				return errors.Wrapf(err, "could not update %s %s", d.Kind, d.Name)
+			}
+			fmt.Fprintf(w, "updated %s %s\n", d.Kind, d.Name)
+		}
+		if stage := upgradeStage(d); stage == 1 || stage == 2 {
+			fmt.Fprintf(w, "waiting for %s %s to roll out\n", d.Kind, d.Name)
+			if err := waitForRollout(kc, d, timeout); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ClusterDeploymentID returns the deployment ID of the pachd running in
+// 'namespace', or "" if pachd isn't deployed there. Diffs and upgrades should
+// keep it, since the assets generate a new one by default.
+func ClusterDeploymentID(c kube.Interface, namespace string) (string, error) {
+	pachd, err := c.AppsV1().Deployments(namespace).Get(pachdName, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Wrapf(err, "could not get Deployment %s", pachdName)
+	}
+	for _, container := range pachd.Spec.Template.Spec.Containers {
+		for _, env := range container.Env {
+			if container.Name == pachdName && env.Name == "CLUSTER_DEPLOYMENT_ID" {
+				return env.Value, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// checkUpgrade returns an error if applying 'diffs' would change etcd or pachd
+// in a way that loses or corrupts data
+func checkUpgrade(clients map[string]*kindClient, diffs []*ObjectDiff) error {
+	for _, d := range diffs {
+		if d.Name == etcdName && d.Action == Create && (d.Kind == "Deployment" || d.Kind == "StatefulSet") {
+			// etcd moving between a Deployment and a StatefulSet would leave its
+			// data behind
+			otherKind := "StatefulSet"
+			if d.Kind == "StatefulSet" {
+				otherKind = "Deployment"
+			}
+			live, err := clients[otherKind].getObject(etcdName)
+			if err != nil {
+				return errors.Wrapf(err, "could not get %s %s", otherKind, etcdName)
+			}
+			if live != nil {
+				return errors.Errorf("etcd is deployed as a %s, but the target assets deploy it as a %s; "+
+					"this can't be changed by an upgrade", otherKind, d.Kind)
+			}
+		}
+		if d.Action != Update {
+			continue
+		}
+		for _, change := range d.Changes {
+			for _, unsafePath := range unsafePaths[d.Name] {
+				if change.Path == unsafePath || strings.HasPrefix(change.Path, unsafePath+".") ||
+					strings.HasPrefix(change.Path, unsafePath+"[") {
+					return errors.Errorf("%s of %s %s can't be changed by an upgrade (%s -> %s)",
+						change.Path, d.Kind, d.Name, formatValue(change.Live), formatValue(change.Target))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// upgradeStage returns the order in which Upgrade applies 'd'
+func upgradeStage(d *ObjectDiff) int {
+	switch {
+	case d.Kind != "Deployment" && d.Kind != "StatefulSet":
+		return 0
+	case d.Name == etcdName:
+		return 1
+	case d.Name == pachdName:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// waitForRollout waits until every replica of the Deployment or StatefulSet
+// 'd' is running its latest spec
+func waitForRollout(kc *kindClient, d *ObjectDiff, timeout time.Duration) error {
+	return backoff.Retry(func() error {
+		live, err := kc.getObject(d.Name)
+		if err != nil {
+			return errors.Wrapf(err, "could not get %s %s", d.Kind, d.Name)
+		}
+		var o struct {
+			Metadata struct {
+				Generation int64 `json:"generation"`
+			} `json:"metadata"`
+			Spec struct {
+				Replicas *int32 `json:"replicas"`
+			} `json:"spec"`
+			Status struct {
+				ObservedGeneration int64 `json:"observedGeneration"`
+				UpdatedReplicas    int32 `json:"updatedReplicas"`
+				ReadyReplicas      int32 `json:"readyReplicas"`
+			} `json:"status"`
+		}
+		if err := toJSONObject(live, &o); err != nil {
+			return err
+		}
+		replicas := int32(1)
+		if o.Spec.Replicas != nil {
+			replicas = *o.Spec.Replicas
+		}
+		if o.Status.ObservedGeneration < o.Metadata.Generation ||
+			o.Status.UpdatedReplicas < replicas || o.Status.ReadyReplicas < replicas {
+			return errors.Errorf("%s %s has not rolled out (%d/%d replicas updated, %d/%d ready)",
+				d.Kind, d.Name, o.Status.UpdatedReplicas, replicas, o.Status.ReadyReplicas, replicas)
+		}
+		return nil
+	}, backoff.RetryEvery(rolloutPollInterval).For(timeout))
+}
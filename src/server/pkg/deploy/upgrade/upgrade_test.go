@@ -0,0 +1,228 @@
+package upgrade
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/server/pkg/deploy/assets"
+	"github.com/pachyderm/pachyderm/src/server/pkg/serde"
+
+	apps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const testNamespace = "pachyderm"
+
+func init() {
+	rolloutPollInterval = 10 * time.Millisecond
+}
+
+func testOpts(version string) *assets.AssetOpts {
+	return &assets.AssetOpts{
+		Version:             version,
+		Namespace:           testNamespace,
+		LogLevel:            "info",
+		PachdShards:         16,
+		ClusterDeploymentID: "test",
+	}
+}
+
+func localAssets(t *testing.T, opts *assets.AssetOpts) []map[string]interface{} {
+	var buf bytes.Buffer
+	require.NoError(t, assets.WriteLocalAssets(serde.NewJSONEncoder(&buf), opts, "/var/pachyderm"))
+	objects, err := assets.ParseManifest(buf.Bytes())
+	require.NoError(t, err)
+	return objects
+}
+
+func googleAssets(t *testing.T, opts *assets.AssetOpts) []map[string]interface{} {
+	var buf bytes.Buffer
+	require.NoError(t, assets.WriteGoogleAssets(serde.NewJSONEncoder(&buf), opts, "bucket", "", 10))
+	objects, err := assets.ParseManifest(buf.Bytes())
+	require.NoError(t, err)
+	return objects
+}
+
+// newFakeClient returns a fake clientset in which Deployments and
+// StatefulSets report that they've rolled out as soon as they're written,
+// unless they're named in 'stuck'
+func newFakeClient(stuck ...string) *fake.Clientset {
+	c := fake.NewSimpleClientset()
+	isStuck := func(name string) bool {
+		for _, s := range stuck {
+			if s == name {
+				return true
+			}
+		}
+		return false
+	}
+	replicas := func(r *int32) int32 {
+		if r == nil {
+			return 1
+		}
+		return *r
+	}
+	c.PrependReactor("get", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		// Get the object from the clientset's object tracker, which is
+		// behind this reactor in the chain
+		for _, r := range c.ReactionChain[1:] {
+			if !r.Handles(action) {
+				continue
+			}
+			handled, o, err := r.React(action)
+			if !handled || err != nil {
+				return handled, o, err
+			}
+			switch o := o.(type) {
+			case *apps.Deployment:
+				o = o.DeepCopy()
+				if !isStuck(o.Name) {
+					o.Status.UpdatedReplicas, o.Status.ReadyReplicas = replicas(o.Spec.Replicas), replicas(o.Spec.Replicas)
+				}
+				return true, o, nil
+			case *apps.StatefulSet:
+				o = o.DeepCopy()
+				if !isStuck(o.Name) {
+					o.Status.UpdatedReplicas, o.Status.ReadyReplicas = replicas(o.Spec.Replicas), replicas(o.Spec.Replicas)
+				}
+				return true, o, nil
+			}
+			return true, o, nil
+		}
+		return false, nil, nil
+	})
+	return c
+}
+
+func diffActions(diffs []*ObjectDiff) map[string]Action {
+	actions := make(map[string]Action)
+	for _, d := range diffs {
+		actions[d.Kind+"/"+d.Name] = d.Action
+	}
+	return actions
+}
+
+func TestUpgrade(t *testing.T) {
+	c := newFakeClient()
+
+	// Everything is created in an empty cluster
+	diffs, err := Diff(c, testNamespace, localAssets(t, testOpts("1.10.0")))
+	require.NoError(t, err)
+	for _, d := range diffs {
+		require.Equal(t, Create, d.Action, "%s %s", d.Kind, d.Name)
+	}
+	var out bytes.Buffer
+	require.NoError(t, Upgrade(c, testNamespace, diffs, &out, time.Second))
+	// etcd is rolled out before pachd, and pachd before the dashboard
+	etcd := strings.Index(out.String(), "created Deployment etcd")
+	pachd := strings.Index(out.String(), "created Deployment pachd")
+	dash := strings.Index(out.String(), "created Deployment dash")
+	require.True(t, etcd >= 0 && etcd < pachd && pachd < dash, out.String())
+	id, err := ClusterDeploymentID(c, testNamespace)
+	require.NoError(t, err)
+	require.Equal(t, "test", id)
+
+	// After which the cluster matches its assets
+	diffs, err = Diff(c, testNamespace, localAssets(t, testOpts("1.10.0")))
+	require.NoError(t, err)
+	for _, d := range diffs {
+		require.Equal(t, Unchanged, d.Action, "%s %s: %v", d.Kind, d.Name, d.Changes)
+	}
+	out.Reset()
+	require.NoError(t, PrintDiff(&out, diffs))
+	require.Equal(t, "No changes\n", out.String())
+
+	// A new version changes pachd's images, and nothing else
+	diffs, err = Diff(c, testNamespace, localAssets(t, testOpts("1.11.0")))
+	require.NoError(t, err)
+	for key, action := range diffActions(diffs) {
+		if key == "Deployment/pachd" {
+			require.Equal(t, Update, action)
+		} else {
+			require.Equal(t, Unchanged, action, key)
+		}
+	}
+	out.Reset()
+	require.NoError(t, PrintDiff(&out, diffs))
+	require.True(t, strings.Contains(out.String(),
+		`spec.template.spec.containers[pachd].image: "pachyderm/pachd:1.10.0" -> "pachyderm/pachd:1.11.0"`), out.String())
+	require.NoError(t, Upgrade(c, testNamespace, diffs, ioutil.Discard, time.Second))
+	diffs, err = Diff(c, testNamespace, localAssets(t, testOpts("1.11.0")))
+	require.NoError(t, err)
+	for _, d := range diffs {
+		require.Equal(t, Unchanged, d.Action, "%s %s: %v", d.Kind, d.Name, d.Changes)
+	}
+
+	// Objects that aren't in the target are reported, but left alone
+	opts := testOpts("1.11.0")
+	opts.NoDash = true
+	diffs, err = Diff(c, testNamespace, localAssets(t, opts))
+	require.NoError(t, err)
+	require.Equal(t, Extra, diffActions(diffs)["Deployment/dash"])
+	require.NoError(t, Upgrade(c, testNamespace, diffs, ioutil.Discard, time.Second))
+	_, err = c.AppsV1().Deployments(testNamespace).Get("dash", metav1.GetOptions{})
+	require.NoError(t, err)
+}
+
+func TestUpgradeSecretsAreRedacted(t *testing.T) {
+	c := newFakeClient()
+	opts := testOpts("1.10.0")
+	diffs, err := Diff(c, testNamespace, localAssets(t, opts))
+	require.NoError(t, err)
+	require.NoError(t, Upgrade(c, testNamespace, diffs, ioutil.Discard, time.Second))
+
+	var buf bytes.Buffer
+	require.NoError(t, assets.WriteSecret(serde.NewJSONEncoder(&buf),
+		map[string][]byte{"google-bucket": []byte("secret-bucket")}, opts))
+	objects, err := assets.ParseManifest(buf.Bytes())
+	require.NoError(t, err)
+	diffs, err = Diff(c, testNamespace, objects)
+	require.NoError(t, err)
+	var out bytes.Buffer
+	require.NoError(t, PrintDiff(&out, diffs))
+	require.True(t, strings.Contains(out.String(), `data: <unset> -> {"google-bucket":"<redacted>"}`), out.String())
+	require.False(t, strings.Contains(out.String(), "c2VjcmV0LWJ1Y2tldA"), out.String())
+}
+
+func TestUpgradeRefusesUnsafeChanges(t *testing.T) {
+	c := newFakeClient()
+	diffs, err := Diff(c, testNamespace, localAssets(t, testOpts("1.10.0")))
+	require.NoError(t, err)
+	require.NoError(t, Upgrade(c, testNamespace, diffs, ioutil.Discard, time.Second))
+
+	// pachd's shard count can't change
+	opts := testOpts("1.10.0")
+	opts.PachdShards = 32
+	diffs, err = Diff(c, testNamespace, localAssets(t, opts))
+	require.NoError(t, err)
+	require.YesError(t, Upgrade(c, testNamespace, diffs, ioutil.Discard, time.Second))
+
+	// etcd can't move from a Deployment to a StatefulSet
+	opts = testOpts("1.10.0")
+	opts.EtcdNodes = 3
+	diffs, err = Diff(c, testNamespace, googleAssets(t, opts))
+	require.NoError(t, err)
+	err = Upgrade(c, testNamespace, diffs, ioutil.Discard, time.Second)
+	require.YesError(t, err)
+	require.True(t, strings.Contains(err.Error(), "etcd is deployed as a Deployment"), err.Error())
+}
+
+func TestUpgradeWaitsForEtcd(t *testing.T) {
+	c := newFakeClient("etcd")
+	diffs, err := Diff(c, testNamespace, localAssets(t, testOpts("1.10.0")))
+	require.NoError(t, err)
+	err = Upgrade(c, testNamespace, diffs, ioutil.Discard, 100*time.Millisecond)
+	require.YesError(t, err)
+	require.True(t, strings.Contains(err.Error(), "Deployment etcd has not rolled out"), err.Error())
+	// pachd isn't created until etcd has rolled out
+	diffs, err = Diff(c, testNamespace, localAssets(t, testOpts("1.10.0")))
+	require.NoError(t, err)
+	require.Equal(t, Create, diffActions(diffs)["Deployment/pachd"])
+}
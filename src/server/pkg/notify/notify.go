@@ -0,0 +1,215 @@
+// Package notify delivers notifications of commit, job and pipeline state
+// changes to the sinks configured by pps.CreateNotificationSink.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// SignatureHeader is the header in which webhook requests are signed (see
+// pps.WebhookSink)
+const SignatureHeader = "X-Pachyderm-Signature"
+
+// RedactedSecret replaces webhook secrets in the sinks that are returned to
+// users
+const RedactedSecret = "<redacted>"
+
+// Redact returns a copy of 'sinkInfo' whose webhook secret, if it has one, is
+// replaced with RedactedSecret
+func Redact(sinkInfo *pps.NotificationSinkInfo) *pps.NotificationSinkInfo {
+	sinkInfo = proto.Clone(sinkInfo).(*pps.NotificationSinkInfo)
+	if sinkInfo.Webhook != nil && sinkInfo.Webhook.Secret != "" {
+		sinkInfo.Webhook.Secret = RedactedSecret
+	}
+	return sinkInfo
+}
+
+// Validate returns an error if 'sinkInfo' isn't a valid sink
+func Validate(sinkInfo *pps.NotificationSinkInfo) error {
+	if sinkInfo.Sink == nil || sinkInfo.Sink.Name == "" {
+		return errors.New("invalid notification sink: name cannot be empty")
+	}
+	set := 0
+	if sinkInfo.Webhook != nil {
+		if sinkInfo.Webhook.URL == "" {
+			return errors.New("invalid notification sink: webhook.url cannot be empty")
+		}
+		set++
+	}
+	if sinkInfo.Slack != nil {
+		if sinkInfo.Slack.URL == "" {
+			return errors.New("invalid notification sink: slack.url cannot be empty")
+		}
+		set++
+	}
+	if sinkInfo.File != nil {
+		set++
+	}
+	if set != 1 {
+		return errors.New("invalid notification sink: exactly one of webhook, slack and file must be set")
+	}
+	if sinkInfo.MaxRetries != nil && sinkInfo.MaxRetries.Value < 0 {
+		return errors.New("invalid notification sink: max_retries cannot be negative")
+	}
+	return nil
+}
+
+// Matches returns true if 'event' passes all of 'sinkInfo's filters
+func Matches(sinkInfo *pps.NotificationSinkInfo, event *pps.NotificationEvent) bool {
+	if len(sinkInfo.Events) > 0 && !containsEventType(sinkInfo.Events, event.Type) {
+		return false
+	}
+	switch event.Type {
+	case pps.NotificationEventType_COMMIT_FINISHED:
+		return len(sinkInfo.Repos) == 0 || containsString(sinkInfo.Repos, event.Commit.Repo.Name)
+	case pps.NotificationEventType_JOB_STATE:
+		if len(sinkInfo.JobStates) > 0 && !containsJobState(sinkInfo.JobStates, event.JobState) {
+			return false
+		}
+	case pps.NotificationEventType_PIPELINE_STATE:
+		if len(sinkInfo.PipelineStates) > 0 && !containsPipelineState(sinkInfo.PipelineStates, event.PipelineState) {
+			return false
+		}
+	}
+	return len(sinkInfo.Pipelines) == 0 || containsString(sinkInfo.Pipelines, event.Pipeline.Name)
+}
+
+func containsEventType(types []pps.NotificationEventType, t pps.NotificationEventType) bool {
+	for _, x := range types {
+		if x == t {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(strs []string, s string) bool {
+	for _, x := range strs {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsJobState(states []pps.JobState, state pps.JobState) bool {
+	for _, x := range states {
+		if x == state {
+			return true
+		}
+	}
+	return false
+}
+
+func containsPipelineState(states []pps.PipelineState, state pps.PipelineState) bool {
+	for _, x := range states {
+		if x == state {
+			return true
+		}
+	}
+	return false
+}
+
+// Summary returns a one-line, human-readable description of 'event'
+func Summary(event *pps.NotificationEvent) string {
+	var summary string
+	switch event.Type {
+	case pps.NotificationEventType_COMMIT_FINISHED:
+		summary = fmt.Sprintf("commit %s@%s finished", event.Commit.Repo.Name, event.Commit.ID)
+	case pps.NotificationEventType_JOB_STATE:
+		summary = fmt.Sprintf("job %s (pipeline %s) is %s", event.Job.ID, event.Pipeline.Name, event.JobState)
+	case pps.NotificationEventType_PIPELINE_STATE:
+		summary = fmt.Sprintf("pipeline %s is %s", event.Pipeline.Name, event.PipelineState)
+	}
+	if event.Reason != "" {
+		summary += ": " + event.Reason
+	}
+	return summary
+}
+
+// Sign returns the signature of 'body' that's sent with webhook requests
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// fileMu serializes writes by FileSinks, so that events written to the same
+// file concurrently aren't interleaved
+var fileMu sync.Mutex
+
+// Send delivers 'event' to 'sinkInfo' once, without retrying
+func Send(ctx context.Context, sinkInfo *pps.NotificationSinkInfo, event *pps.NotificationEvent) error {
+	body, err := (&jsonpb.Marshaler{}).MarshalToString(event)
+	if err != nil {
+		return err
+	}
+	switch {
+	case sinkInfo.Webhook != nil:
+		header := make(http.Header)
+		if sinkInfo.Webhook.Secret != "" {
+			header.Set(SignatureHeader, Sign(sinkInfo.Webhook.Secret, []byte(body)))
+		}
+		return post(ctx, sinkInfo.Webhook.URL, header, []byte(body))
+	case sinkInfo.Slack != nil:
+		message, err := json.Marshal(map[string]string{"text": Summary(event)})
+		if err != nil {
+			return err
+		}
+		return post(ctx, sinkInfo.Slack.URL, make(http.Header), message)
+	case sinkInfo.File != nil:
+		fileMu.Lock()
+		defer fileMu.Unlock()
+		if sinkInfo.File.Path == "" || sinkInfo.File.Path == "-" {
+			_, err := fmt.Fprintln(os.Stdout, body)
+			return err
+		}
+		f, err := os.OpenFile(sinkInfo.File.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(f, body); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+	return errors.Errorf("notification sink %q has no destination", sinkInfo.Sink.Name)
+}
+
+func post(ctx context.Context, url string, header http.Header, body []byte) error {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header = header
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf("%s returned %s: %s", url, resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
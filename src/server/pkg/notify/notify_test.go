@@ -0,0 +1,165 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/types"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func jobEvent(pipeline string, state pps.JobState) *pps.NotificationEvent {
+	return &pps.NotificationEvent{
+		Type:     pps.NotificationEventType_JOB_STATE,
+		Job:      client.NewJob("abc"),
+		Pipeline: client.NewPipeline(pipeline),
+		JobState: state,
+	}
+}
+
+func TestValidate(t *testing.T) {
+	require.YesError(t, Validate(&pps.NotificationSinkInfo{
+		File: &pps.FileSink{},
+	}))
+	require.YesError(t, Validate(&pps.NotificationSinkInfo{
+		Sink: &pps.NotificationSink{Name: "sink"},
+	}))
+	require.YesError(t, Validate(&pps.NotificationSinkInfo{
+		Sink:  &pps.NotificationSink{Name: "sink"},
+		File:  &pps.FileSink{},
+		Slack: &pps.SlackSink{URL: "http://example.com"},
+	}))
+	require.YesError(t, Validate(&pps.NotificationSinkInfo{
+		Sink:    &pps.NotificationSink{Name: "sink"},
+		Webhook: &pps.WebhookSink{},
+	}))
+	require.NoError(t, Validate(&pps.NotificationSinkInfo{
+		Sink:    &pps.NotificationSink{Name: "sink"},
+		Webhook: &pps.WebhookSink{URL: "http://example.com"},
+	}))
+	// max_retries may be 0 (no retries), but not negative
+	require.NoError(t, Validate(&pps.NotificationSinkInfo{
+		Sink:       &pps.NotificationSink{Name: "sink"},
+		File:       &pps.FileSink{},
+		MaxRetries: &types.Int64Value{Value: 0},
+	}))
+	require.YesError(t, Validate(&pps.NotificationSinkInfo{
+		Sink:       &pps.NotificationSink{Name: "sink"},
+		File:       &pps.FileSink{},
+		MaxRetries: &types.Int64Value{Value: -1},
+	}))
+}
+
+func TestRedact(t *testing.T) {
+	sinkInfo := &pps.NotificationSinkInfo{
+		Sink:    &pps.NotificationSink{Name: "sink"},
+		Webhook: &pps.WebhookSink{URL: "http://example.com", Secret: "secret"},
+	}
+	require.Equal(t, RedactedSecret, Redact(sinkInfo).Webhook.Secret)
+	require.Equal(t, "secret", sinkInfo.Webhook.Secret)
+	sinkInfo.Webhook.Secret = ""
+	require.Equal(t, "", Redact(sinkInfo).Webhook.Secret)
+}
+
+func TestMatches(t *testing.T) {
+	commitEvent := &pps.NotificationEvent{
+		Type:   pps.NotificationEventType_COMMIT_FINISHED,
+		Commit: client.NewCommit("repo", "abc"),
+	}
+	all := &pps.NotificationSinkInfo{}
+	require.True(t, Matches(all, commitEvent))
+	require.True(t, Matches(all, jobEvent("pipeline", pps.JobState_JOB_SUCCESS)))
+
+	failures := &pps.NotificationSinkInfo{
+		Events:    []pps.NotificationEventType{pps.NotificationEventType_JOB_STATE},
+		Pipelines: []string{"pipeline"},
+		JobStates: []pps.JobState{pps.JobState_JOB_FAILURE},
+	}
+	require.False(t, Matches(failures, commitEvent))
+	require.False(t, Matches(failures, jobEvent("pipeline", pps.JobState_JOB_SUCCESS)))
+	require.False(t, Matches(failures, jobEvent("other", pps.JobState_JOB_FAILURE)))
+	require.True(t, Matches(failures, jobEvent("pipeline", pps.JobState_JOB_FAILURE)))
+
+	repos := &pps.NotificationSinkInfo{Repos: []string{"other"}}
+	require.False(t, Matches(repos, commitEvent))
+}
+
+func TestSendWebhook(t *testing.T) {
+	var body []byte
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		body, err = ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		signature = r.Header.Get(SignatureHeader)
+	}))
+	defer server.Close()
+
+	sinkInfo := &pps.NotificationSinkInfo{
+		Sink:    &pps.NotificationSink{Name: "sink"},
+		Webhook: &pps.WebhookSink{URL: server.URL, Secret: "secret"},
+	}
+	event := jobEvent("pipeline", pps.JobState_JOB_FAILURE)
+	require.NoError(t, Send(context.Background(), sinkInfo, event))
+	require.Equal(t, Sign("secret", body), signature)
+	received := &pps.NotificationEvent{}
+	require.NoError(t, jsonpb.UnmarshalString(string(body), received))
+	require.Equal(t, event, received)
+
+	// Unsuccessful responses are errors
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	sinkInfo.Webhook.URL = failing.URL
+	err := Send(context.Background(), sinkInfo, event)
+	require.YesError(t, err)
+	require.True(t, strings.Contains(err.Error(), "nope"))
+}
+
+func TestSendSlack(t *testing.T) {
+	var message map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+	}))
+	defer server.Close()
+
+	sinkInfo := &pps.NotificationSinkInfo{
+		Sink:  &pps.NotificationSink{Name: "sink"},
+		Slack: &pps.SlackSink{URL: server.URL},
+	}
+	event := jobEvent("pipeline", pps.JobState_JOB_FAILURE)
+	event.Reason = "datum failed"
+	require.NoError(t, Send(context.Background(), sinkInfo, event))
+	require.Equal(t, "job abc (pipeline pipeline) is JOB_FAILURE: datum failed", message["text"])
+}
+
+func TestSendFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "notify")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	sinkInfo := &pps.NotificationSinkInfo{
+		Sink: &pps.NotificationSink{Name: "sink"},
+		File: &pps.FileSink{Path: filepath.Join(dir, "events")},
+	}
+	require.NoError(t, Send(context.Background(), sinkInfo, jobEvent("a", pps.JobState_JOB_RUNNING)))
+	require.NoError(t, Send(context.Background(), sinkInfo, jobEvent("b", pps.JobState_JOB_SUCCESS)))
+	data, err := ioutil.ReadFile(sinkInfo.File.Path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Equal(t, 2, len(lines))
+	event := &pps.NotificationEvent{}
+	require.NoError(t, jsonpb.UnmarshalString(lines[1], event))
+	require.Equal(t, "b", event.Pipeline.Name)
+}
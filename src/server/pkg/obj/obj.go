@@ -195,6 +195,16 @@ func BlockPathFromEnv(block *pfs.Block) (string, error) {
 	return filepath.Join(storageRoot, "block", block.Hash), nil
 }
 
+// ObjectPathFromEnv gets the path to an object's metadata (its BlockRef) in
+// object storage based on environment variables.
+func ObjectPathFromEnv(object *pfs.Object) (string, error) {
+	storageRoot, err := StorageRootFromEnv()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(storageRoot, "object", object.Hash), nil
+}
+
 // Client is an interface to object storage.
 type Client interface {
 	// Writer returns a writer which writes to an object.
@@ -593,7 +603,7 @@ func NewClientFromEnv(storageRoot string) (c Client, err error) {
 	case err != nil:
 		return nil, err
 	case c != nil:
-		return TracingObjClient(storageBackend, c), nil
+		return ReplicateFromEnv(TracingObjClient(storageBackend, c))
 	default:
 		return nil, errors.Errorf("unrecognized storage backend: %s", storageBackend)
 	}
@@ -621,7 +631,7 @@ func NewClientFromSecret(storageRoot string) (c Client, err error) {
 	case err != nil:
 		return nil, err
 	case c != nil:
-		return TracingObjClient(storageBackend, c), nil
+		return ReplicateFromEnv(TracingObjClient(storageBackend, c))
 	default:
 		return nil, errors.Errorf("unrecognized storage backend: %s", storageBackend)
 	}
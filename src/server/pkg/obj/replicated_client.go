@@ -0,0 +1,548 @@
+package obj
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	"github.com/pachyderm/pachyderm/src/server/pkg/uuid"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// StorageReplicasEnvVar is a comma-separated list of object store URLs (e.g.
+// "s3://dr-bucket,gs://other-bucket") which all writes to object storage are
+// replicated to. The secondaries use the credentials in the storage secret.
+const StorageReplicasEnvVar = "STORAGE_REPLICAS"
+
+// replicationLogPrefix is where the replay log is stored in the primary.
+const replicationLogPrefix = "replication-log"
+
+// inflightWriteTimeout is how long a write may take after its replay log
+// entry is recorded. Until then, the entry isn't replayed unless the write
+// finishes. After that, the writer is assumed to have crashed, and whatever
+// the primary has is replicated.
+const inflightWriteTimeout = time.Hour
+
+// maxConcurrentReplications bounds the number of objects each
+// ReplicatedClient copies to its secondaries at once.
+const maxConcurrentReplications = 16
+
+// Replica is a secondary object store that a ReplicatedClient writes to.
+type Replica struct {
+	// Name identifies the replica in the replay log and in ReplicaStatus, so
+	// it must not change while there are pending replications.
+	Name   string
+	Client Client
+}
+
+// ReplicaStatus describes how far a replica is behind the primary.
+type ReplicaStatus struct {
+	Name string
+	// Pending is the number of writes and deletes that haven't been applied
+	// to the replica yet.
+	Pending int64
+	// OldestPending is when the oldest of them was made (zero if there are
+	// none).
+	OldestPending time.Time
+	// Copied is the number of missing objects copied to the replica by
+	// Reconcile.
+	Copied int64
+}
+
+const (
+	replicationPut    = "put"
+	replicationDelete = "delete"
+)
+
+// replicationEntry is the content of an entry in the replay log.
+type replicationEntry struct {
+	Op   string    `json:"op"`
+	Time time.Time `json:"time"`
+	// Writing is set for a put whose write to the primary hasn't finished
+	Writing bool `json:"writing,omitempty"`
+}
+
+// ReplicatedClient is a Client that writes synchronously to a primary
+// object store and asynchronously to one or more replicas. Each write and
+// delete is recorded in a replay log in the primary before it's made, and
+// its log entry is only removed once it's been applied to the replica, so
+// replications that fail (or are interrupted by a crash or restart) are
+// retried by Replay. Each write and delete gets its own log entry, and
+// replaying any entry for an object makes the replica's copy match the
+// primary's, so entries can be replayed (and removed) independently. Reads fall back to the replicas when the primary fails,
+// but not when the object doesn't exist in the primary.
+type ReplicatedClient struct {
+	primary  Client
+	replicas []Replica
+	limiter  chan struct{}
+}
+
+// NewReplicatedClient returns a ReplicatedClient that writes to 'primary'
+// and replicates to 'replicas'.
+func NewReplicatedClient(primary Client, replicas []Replica) *ReplicatedClient {
+	return &ReplicatedClient{
+		primary:  primary,
+		replicas: replicas,
+		limiter:  make(chan struct{}, maxConcurrentReplications),
+	}
+}
+
+// ReplicateFromEnv wraps 'c' in a ReplicatedClient if StorageReplicasEnvVar
+// is set, and otherwise returns it unchanged.
+func ReplicateFromEnv(c Client) (Client, error) {
+	urls, ok := os.LookupEnv(StorageReplicasEnvVar)
+	if !ok || urls == "" {
+		return c, nil
+	}
+	var replicas []Replica
+	for _, urlStr := range strings.Split(urls, ",") {
+		urlStr = strings.TrimSpace(urlStr)
+		if urlStr == "" {
+			continue
+		}
+		url, err := ParseURL(urlStr)
+		if err != nil {
+			return nil, err
+		}
+		replica, err := NewClientFromURLAndSecret(url)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not create client for replica %s", urlStr)
+		}
+		replicas = append(replicas, Replica{Name: urlStr, Client: replica})
+	}
+	return NewReplicatedClient(c, replicas), nil
+}
+
+func (c *ReplicatedClient) logDir(replica Replica) string {
+	return path.Join(replicationLogPrefix, url.PathEscape(replica.Name))
+}
+
+// logPath returns the path of the replay log entry 'id' for the object 'name'
+func (c *ReplicatedClient) logPath(replica Replica, name, id string) string {
+	return path.Join(c.logDir(replica), url.PathEscape(name), id)
+}
+
+// Primary returns the primary object store, for callers that must not see
+// the replicas (e.g. to check that an object exists in the primary).
+func (c *ReplicatedClient) Primary() Client {
+	return c.primary
+}
+
+// record adds 'op' on 'name' to the replay log of each replica, as the entry
+// 'id'. It's called before 'op' is applied to the primary, so that the
+// replication isn't lost if pachd crashes in between. If 'op' then fails,
+// replaying its entry copies (or deletes) whatever the primary has, which is
+// harmless. 'writing' marks a put whose write is in progress, which isn't
+// replayed until it's recorded again when the write finishes (or
+// inflightWriteTimeout passes).
+func (c *ReplicatedClient) record(ctx context.Context, op, name, id string, writing bool) error {
+	data, err := json.Marshal(&replicationEntry{Op: op, Time: time.Now(), Writing: writing})
+	if err != nil {
+		return err
+	}
+	for _, replica := range c.replicas {
+		if err := c.writeLog(ctx, c.logPath(replica, name, id), data); err != nil {
+			return errors.Wrapf(err, "could not record replication of %s to %s", name, replica.Name)
+		}
+	}
+	return nil
+}
+
+// replicate starts applying the replay log entry 'id' for 'name' in the
+// background, once the primary has been updated. It blocks while
+// maxConcurrentReplications replications are already running.
+func (c *ReplicatedClient) replicate(name, id string) {
+	for _, replica := range c.replicas {
+		replica := replica
+		c.limiter <- struct{}{}
+		go func() {
+			defer func() { <-c.limiter }()
+			// The request's context may be done by now, and a failure here is
+			// retried by Replay, so don't use it
+			if err := c.replay(context.Background(), replica, c.logPath(replica, name, id)); err != nil {
+				log.Errorf("error replicating %s to %s: %v", name, replica.Name, err)
+			}
+		}()
+	}
+}
+
+func (c *ReplicatedClient) writeLog(ctx context.Context, logPath string, data []byte) (retErr error) {
+	w, err := c.primary.Writer(ctx, logPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := w.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	_, err = w.Write(data)
+	return err
+}
+
+func (c *ReplicatedClient) readLog(ctx context.Context, logPath string) ([]byte, error) {
+	r, err := c.primary.Reader(ctx, logPath, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+// replay applies the replay log entry at 'logPath' to 'replica' by making its
+// copy of the entry's object match the primary's, and then removes the entry.
+// Later writes and deletes of the object have their own entries, so removing
+// this one can't lose them.
+func (c *ReplicatedClient) replay(ctx context.Context, replica Replica, logPath string) error {
+	data, err := c.readLog(ctx, logPath)
+	if err != nil {
+		if c.primary.IsNotExist(err) {
+			return nil // already replayed
+		}
+		return err
+	}
+	var entry replicationEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return errors.Wrapf(err, "could not parse replication log entry %s", logPath)
+	}
+	name, err := url.PathUnescape(path.Base(path.Dir(logPath)))
+	if err != nil {
+		return err
+	}
+	if entry.Writing && time.Since(entry.Time) < inflightWriteTimeout {
+		return nil // the write hasn't finished
+	}
+	if entry.Op != replicationPut && entry.Op != replicationDelete {
+		return errors.Errorf("unrecognized replication op %q in %s", entry.Op, logPath)
+	}
+	// Whatever the op was, the replica is made to match the primary, so that
+	// entries for the same object can be replayed in any order
+	if err := copyObject(ctx, c.primary, replica.Client, name); err != nil {
+		if !c.primary.IsNotExist(err) {
+			return err
+		}
+		if err := replica.Client.Delete(ctx, name); err != nil && !replica.Client.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := c.primary.Delete(ctx, logPath); err != nil && !c.primary.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// copyObject copies the object 'name' from 'src' to 'dst'.
+func copyObject(ctx context.Context, src, dst Client, name string) (retErr error) {
+	r, err := src.Reader(ctx, name, 0, 0)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := r.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	w, err := dst.Writer(ctx, name)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := w.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+	_, err = io.Copy(w, r)
+	return err
+}
+
+// pending calls 'f' with the path of each entry in 'replica's replay log.
+func (c *ReplicatedClient) pending(ctx context.Context, replica Replica, f func(logPath string) error) error {
+	return c.primary.Walk(ctx, c.logDir(replica)+"/", f)
+}
+
+// Replay applies the pending writes and deletes in each replica's replay
+// log. It's run periodically by pachd, to retry the replications that failed
+// or were interrupted. Replications that fail again are left in the log, and
+// the first of their errors is returned.
+func (c *ReplicatedClient) Replay(ctx context.Context) error {
+	var firstErr error
+	for _, replica := range c.replicas {
+		failed := 0
+		var replayErr error
+		if err := c.pending(ctx, replica, func(logPath string) error {
+			if err := c.replay(ctx, replica, logPath); err != nil {
+				failed++
+				if replayErr == nil {
+					replayErr = err
+				}
+			}
+			return nil
+		}); err != nil {
+			replayErr = err
+		}
+		if replayErr != nil && firstErr == nil {
+			firstErr = errors.Wrapf(replayErr, "could not replay %d replications to %s", failed, replica.Name)
+		}
+	}
+	return firstErr
+}
+
+// Status returns how far each replica is behind the primary.
+func (c *ReplicatedClient) Status(ctx context.Context) ([]*ReplicaStatus, error) {
+	var result []*ReplicaStatus
+	for _, replica := range c.replicas {
+		status := &ReplicaStatus{Name: replica.Name}
+		if err := c.pending(ctx, replica, func(logPath string) error {
+			data, err := c.readLog(ctx, logPath)
+			if err != nil {
+				if c.primary.IsNotExist(err) {
+					return nil // replayed since it was listed
+				}
+				return err
+			}
+			var entry replicationEntry
+			if err := json.Unmarshal(data, &entry); err != nil {
+				return errors.Wrapf(err, "could not parse replication log entry %s", logPath)
+			}
+			status.Pending++
+			if status.OldestPending.IsZero() || entry.Time.Before(status.OldestPending) {
+				status.OldestPending = entry.Time
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+		result = append(result, status)
+	}
+	return result, nil
+}
+
+// Reconcile copies each object under 'prefix' in the primary that's missing
+// from a replica, then replays the replay logs. It's used to catch a replica
+// up with writes that were made before replication was enabled. Objects that
+// exist only in a replica are left alone.
+func (c *ReplicatedClient) Reconcile(ctx context.Context, prefix string) ([]*ReplicaStatus, error) {
+	copied := make(map[string]int64)
+	for _, replica := range c.replicas {
+		if err := c.primary.Walk(ctx, prefix, func(name string) error {
+			if strings.HasPrefix(strings.TrimPrefix(name, "/"), replicationLogPrefix+"/") {
+				return nil
+			}
+			if replica.Client.Exists(ctx, name) {
+				return nil
+			}
+			if err := copyObject(ctx, c.primary, replica.Client, name); err != nil {
+				return errors.Wrapf(err, "could not copy %s to %s", name, replica.Name)
+			}
+			copied[replica.Name]++
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.Replay(ctx); err != nil {
+		return nil, err
+	}
+	statuses, err := c.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range statuses {
+		status.Copied = copied[status.Name]
+	}
+	return statuses, nil
+}
+
+// Writer implements the corresponding method in the Client interface. The
+// write is recorded in the replay log before it's started, and recorded
+// again and replicated once the writer is closed.
+func (c *ReplicatedClient) Writer(ctx context.Context, name string) (io.WriteCloser, error) {
+	id := uuid.NewWithoutDashes()
+	if err := c.record(ctx, replicationPut, name, id, true); err != nil {
+		return nil, err
+	}
+	w, err := c.primary.Writer(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return &replicatedWriter{WriteCloser: w, ctx: ctx, c: c, name: name, id: id}, nil
+}
+
+type replicatedWriter struct {
+	io.WriteCloser
+	ctx  context.Context
+	c    *ReplicatedClient
+	name string
+	// id is the ID of the write's replay log entry
+	id string
+}
+
+func (w *replicatedWriter) Close() error {
+	if err := w.WriteCloser.Close(); err != nil {
+		return err
+	}
+	if err := w.c.record(w.ctx, replicationPut, w.name, w.id, false); err != nil {
+		return err
+	}
+	w.c.replicate(w.name, w.id)
+	return nil
+}
+
+// Reader implements the corresponding method in the Client interface. If the
+// primary fails, the object is read from the replicas instead, in order.
+func (c *ReplicatedClient) Reader(ctx context.Context, name string, offset uint64, size uint64) (io.ReadCloser, error) {
+	r := &replicatedReader{ctx: ctx, c: c, name: name, offset: offset, size: size}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// replicatedReader reads from the first of the primary and the replicas
+// that works, moving on to the next one if reading fails part way through.
+type replicatedReader struct {
+	ctx    context.Context
+	c      *ReplicatedClient
+	name   string
+	offset uint64
+	size   uint64
+	read   uint64
+	// next is the index of the next client to try, where 0 is the primary
+	// and i > 0 is c.replicas[i-1]
+	next int
+	r    io.ReadCloser
+	// err is the primary's error, which is returned if nothing works
+	err error
+}
+
+func (r *replicatedReader) client(i int) Client {
+	if i == 0 {
+		return r.c.primary
+	}
+	return r.c.replicas[i-1].Client
+}
+
+func (r *replicatedReader) open() error {
+	for ; r.next <= len(r.c.replicas); r.next++ {
+		size := r.size
+		if size > 0 {
+			size -= r.read
+		}
+		rc, err := r.client(r.next).Reader(r.ctx, r.name, r.offset+r.read, size)
+		if err != nil {
+			if r.next == 0 {
+				// Replicas may still have objects that were deleted from the
+				// primary, so only fall back to them if the primary failed
+				if r.c.primary.IsNotExist(err) {
+					return err
+				}
+				r.err = err
+			}
+			continue
+		}
+		if r.next > 0 {
+			log.Infof("reading %s from replica %s", r.name, r.c.replicas[r.next-1].Name)
+		}
+		r.r = rc
+		r.next++
+		return nil
+	}
+	return r.err
+}
+
+func (r *replicatedReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.read += uint64(n)
+	if err == nil || err == io.EOF || r.next > len(r.c.replicas) || r.ctx.Err() != nil {
+		return n, err
+	}
+	if r.err == nil {
+		r.err = err
+	}
+	r.r.Close()
+	if openErr := r.open(); openErr != nil {
+		return n, err
+	}
+	return n, nil
+}
+
+func (r *replicatedReader) Close() error {
+	return r.r.Close()
+}
+
+// Delete implements the corresponding method in the Client interface.
+func (c *ReplicatedClient) Delete(ctx context.Context, name string) error {
+	id := uuid.NewWithoutDashes()
+	if err := c.record(ctx, replicationDelete, name, id, false); err != nil {
+		return err
+	}
+	err := c.primary.Delete(ctx, name)
+	if err != nil && !c.primary.IsNotExist(err) {
+		return err
+	}
+	c.replicate(name, id)
+	return err
+}
+
+// Walk implements the corresponding method in the Client interface. If the
+// primary fails before returning any objects, the replicas are walked
+// instead, in order.
+func (c *ReplicatedClient) Walk(ctx context.Context, prefix string, fn func(name string) error) error {
+	var primaryErr error
+	for i := 0; i <= len(c.replicas); i++ {
+		client := c.primary
+		if i > 0 {
+			client = c.replicas[i-1].Client
+			log.Infof("walking %s in replica %s", prefix, c.replicas[i-1].Name)
+		}
+		called := false
+		err := client.Walk(ctx, prefix, func(name string) error {
+			called = true
+			return fn(name)
+		})
+		if err == nil || called {
+			return err
+		}
+		if i == 0 {
+			primaryErr = err
+		}
+	}
+	return primaryErr
+}
+
+// Exists implements the corresponding method in the Client interface. Only
+// the primary is checked, as replicas may still have deleted objects.
+func (c *ReplicatedClient) Exists(ctx context.Context, name string) bool {
+	return c.primary.Exists(ctx, name)
+}
+
+// IsRetryable implements the corresponding method in the Client interface.
+func (c *ReplicatedClient) IsRetryable(err error) bool {
+	return c.primary.IsRetryable(err)
+}
+
+// IsNotExist implements the corresponding method in the Client interface.
+func (c *ReplicatedClient) IsNotExist(err error) bool {
+	if c.primary.IsNotExist(err) {
+		return true
+	}
+	for _, replica := range c.replicas {
+		if replica.Client.IsNotExist(err) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsIgnorable implements the corresponding method in the Client interface.
+func (c *ReplicatedClient) IsIgnorable(err error) bool {
+	return c.primary.IsIgnorable(err)
+}
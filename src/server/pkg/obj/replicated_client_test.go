@@ -0,0 +1,252 @@
+package obj
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+// flakyClient fails writes and reads while it's broken
+type flakyClient struct {
+	Client
+	broken int32
+}
+
+func (c *flakyClient) setBroken(broken bool) {
+	var value int32
+	if broken {
+		value = 1
+	}
+	atomic.StoreInt32(&c.broken, value)
+}
+
+func (c *flakyClient) isBroken() bool {
+	return atomic.LoadInt32(&c.broken) == 1
+}
+
+func (c *flakyClient) Writer(ctx context.Context, name string) (io.WriteCloser, error) {
+	if c.isBroken() {
+		return nil, errors.New("broken")
+	}
+	return c.Client.Writer(ctx, name)
+}
+
+func (c *flakyClient) Reader(ctx context.Context, name string, offset uint64, size uint64) (io.ReadCloser, error) {
+	if c.isBroken() {
+		return nil, errors.New("broken")
+	}
+	return c.Client.Reader(ctx, name, offset, size)
+}
+
+func newTestLocalClient(t *testing.T) (Client, func()) {
+	root, err := ioutil.TempDir("", "replicated")
+	require.NoError(t, err)
+	c, err := NewLocalClient(root)
+	require.NoError(t, err)
+	return c, func() { os.RemoveAll(root) }
+}
+
+func writeObject(t *testing.T, c Client, name, content string) {
+	w, err := c.Writer(context.Background(), name)
+	require.NoError(t, err)
+	_, err = w.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+}
+
+func readObject(c Client, name string) (string, error) {
+	r, err := c.Reader(context.Background(), name, 0, 0)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	return string(data), err
+}
+
+func TestReplicatedClient(t *testing.T) {
+	ctx := context.Background()
+	primary, cleanup := newTestLocalClient(t)
+	defer cleanup()
+	secondary, cleanup := newTestLocalClient(t)
+	defer cleanup()
+	replica := &flakyClient{Client: secondary}
+	c := NewReplicatedClient(primary, []Replica{{Name: "local://replica", Client: replica}})
+
+	// Writes are replicated in the background
+	writeObject(t, c, "block/foo", "foo")
+	require.NoErrorWithinTRetry(t, 10*time.Second, func() error {
+		content, err := readObject(secondary, "block/foo")
+		if err != nil {
+			return err
+		}
+		if content != "foo" {
+			return errors.Errorf("expected foo, got %q", content)
+		}
+		statuses, err := c.Status(ctx)
+		if err != nil {
+			return err
+		}
+		if statuses[0].Pending != 0 {
+			return errors.Errorf("%d replications still pending", statuses[0].Pending)
+		}
+		return nil
+	})
+
+	// Writes that fail to replicate stay in the replay log until replayed
+	replica.setBroken(true)
+	writeObject(t, c, "block/bar", "bar")
+	require.YesError(t, c.Replay(ctx))
+	statuses, err := c.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(statuses))
+	require.Equal(t, "local://replica", statuses[0].Name)
+	require.Equal(t, int64(1), statuses[0].Pending)
+	require.False(t, statuses[0].OldestPending.IsZero())
+	replica.setBroken(false)
+	require.NoError(t, c.Replay(ctx))
+	statuses, err = c.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), statuses[0].Pending)
+	content, err := readObject(secondary, "block/bar")
+	require.NoError(t, err)
+	require.Equal(t, "bar", content)
+
+	// Writes are recorded in the replay log before they're made, and kept
+	// there while they're in progress
+	replica.setBroken(true)
+	w, err := c.Writer(ctx, "block/baz")
+	require.NoError(t, err)
+	statuses, err = c.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), statuses[0].Pending)
+	require.NoError(t, c.Replay(ctx))
+	statuses, err = c.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), statuses[0].Pending)
+	_, err = w.Write([]byte("baz"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	replica.setBroken(false)
+	require.NoError(t, c.Replay(ctx))
+	content, err = readObject(secondary, "block/baz")
+	require.NoError(t, err)
+	require.Equal(t, "baz", content)
+
+	// Deletes are replicated too
+	require.NoError(t, c.Delete(ctx, "block/bar"))
+	require.NoError(t, c.Replay(ctx))
+	require.False(t, secondary.Exists(ctx, "block/bar"))
+
+	// Objects written before replication was enabled are copied by Reconcile,
+	// which skips the replay log
+	writeObject(t, primary, "block/buzz", "buzz")
+	statuses, err = c.Reconcile(ctx, "block")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), statuses[0].Copied)
+	statuses, err = c.Reconcile(ctx, "")
+	require.NoError(t, err)
+	require.Equal(t, int64(0), statuses[0].Copied)
+	require.True(t, secondary.Exists(ctx, "block/buzz"))
+	require.NoError(t, secondary.Walk(ctx, "", func(name string) error {
+		require.False(t, strings.HasPrefix(name, replicationLogPrefix), name)
+		return nil
+	}))
+}
+
+func TestReplicatedClientSameObject(t *testing.T) {
+	ctx := context.Background()
+	primary, cleanup := newTestLocalClient(t)
+	defer cleanup()
+	secondary, cleanup := newTestLocalClient(t)
+	defer cleanup()
+	replica := &flakyClient{Client: secondary}
+	c := NewReplicatedClient(primary, []Replica{{Name: "local://replica", Client: replica}})
+	replicaInfo := c.replicas[0]
+
+	// Each write and delete of an object gets its own replay log entry
+	replica.setBroken(true)
+	writeObject(t, c, "block/foo", "1")
+	require.NoError(t, c.Delete(ctx, "block/foo"))
+	writeObject(t, c, "block/foo", "2")
+	waitForReplications := func() {
+		require.NoErrorWithinTRetry(t, 10*time.Second, func() error {
+			if n := len(c.limiter); n > 0 {
+				return errors.Errorf("%d replications still running", n)
+			}
+			return nil
+		})
+	}
+	waitForReplications()
+	var logPaths []string
+	require.NoError(t, c.pending(ctx, replicaInfo, func(logPath string) error {
+		logPaths = append(logPaths, logPath)
+		return nil
+	}))
+	require.Equal(t, 3, len(logPaths))
+
+	// Replaying one entry leaves the others, and whichever entry is replayed
+	// copies the primary's current contents
+	replica.setBroken(false)
+	require.NoError(t, c.replay(ctx, replicaInfo, logPaths[0]))
+	content, err := readObject(secondary, "block/foo")
+	require.NoError(t, err)
+	require.Equal(t, "2", content)
+	statuses, err := c.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), statuses[0].Pending)
+
+	// Deletes are replayed the same way
+	replica.setBroken(true)
+	require.NoError(t, c.Delete(ctx, "block/foo"))
+	waitForReplications()
+	replica.setBroken(false)
+	require.NoError(t, c.Replay(ctx))
+	require.False(t, secondary.Exists(ctx, "block/foo"))
+	statuses, err = c.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), statuses[0].Pending)
+}
+
+func TestReplicatedClientReadFallback(t *testing.T) {
+	primary, cleanup := newTestLocalClient(t)
+	defer cleanup()
+	secondary, cleanup := newTestLocalClient(t)
+	defer cleanup()
+	flakyPrimary := &flakyClient{Client: primary}
+	c := NewReplicatedClient(flakyPrimary, []Replica{{Name: "local://replica", Client: secondary}})
+
+	writeObject(t, primary, "block/foo", "foo")
+	writeObject(t, secondary, "block/foo", "foo")
+	flakyPrimary.setBroken(true)
+	content, err := readObject(c, "block/foo")
+	require.NoError(t, err)
+	require.Equal(t, "foo", content)
+	r, err := c.Reader(context.Background(), "block/foo", 1, 1)
+	require.NoError(t, err)
+	data, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.Equal(t, "o", string(data))
+
+	// If nothing has the object, the primary's error is returned
+	_, err = readObject(c, "block/bar")
+	require.YesError(t, err)
+	require.Equal(t, "broken", err.Error())
+
+	// Objects that don't exist in the primary aren't read from the replicas,
+	// which may not have applied deletes yet
+	flakyPrimary.setBroken(false)
+	writeObject(t, secondary, "block/deleted", "deleted")
+	_, err = readObject(c, "block/deleted")
+	require.YesError(t, err)
+	require.True(t, c.IsNotExist(err))
+}
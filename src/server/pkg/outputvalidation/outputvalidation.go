@@ -0,0 +1,239 @@
+// Package outputvalidation checks the output of a job against its pipeline's
+// pps.Validation, before the job's output commit is finished.
+package outputvalidation
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	globlib "github.com/pachyderm/ohmyglob"
+	"github.com/xeipuuv/gojsonschema"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// File is one file in a job's output
+type File struct {
+	Path      string
+	SizeBytes uint64
+}
+
+// Validate returns an error if 'v' isn't a valid validation spec
+func Validate(v *pps.Validation) error {
+	if v.MinFiles < 0 || v.MaxFiles < 0 {
+		return errors.New("invalid validation: min_files and max_files can't be negative")
+	}
+	if v.MaxFiles != 0 && v.MinFiles > v.MaxFiles {
+		return errors.Errorf("invalid validation: min_files (%d) is greater than max_files (%d)", v.MinFiles, v.MaxFiles)
+	}
+	for _, check := range v.JSONSchema {
+		if _, err := compileGlob(check.Glob); err != nil {
+			return errors.Wrapf(err, "invalid validation: bad glob %q", check.Glob)
+		}
+		if _, err := compileSchema(check.Schema); err != nil {
+			return errors.Wrapf(err, "invalid validation: bad JSON schema for %q", check.Glob)
+		}
+	}
+	for _, check := range v.CSVColumns {
+		if _, err := compileGlob(check.Glob); err != nil {
+			return errors.Wrapf(err, "invalid validation: bad glob %q", check.Glob)
+		}
+		if len(check.Columns) == 0 {
+			return errors.Errorf("invalid validation: no columns for %q", check.Glob)
+		}
+	}
+	return nil
+}
+
+// ContentMatcher returns a function that reports whether Check will read the
+// contents of the file at a given path, i.e. whether the path matches one of
+// v's JSON schema or CSV checks
+func ContentMatcher(v *pps.Validation) (func(path string) bool, error) {
+	var globs []*globlib.Glob
+	for _, check := range v.JSONSchema {
+		g, err := compileGlob(check.Glob)
+		if err != nil {
+			return nil, errors.Wrapf(err, "bad glob %q", check.Glob)
+		}
+		globs = append(globs, g)
+	}
+	for _, check := range v.CSVColumns {
+		g, err := compileGlob(check.Glob)
+		if err != nil {
+			return nil, errors.Wrapf(err, "bad glob %q", check.Glob)
+		}
+		globs = append(globs, g)
+	}
+	return func(path string) bool {
+		for _, g := range globs {
+			if g.Match(path) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// Check evaluates 'v' against 'files', the files in a job's output. The
+// contents of the files matched by its JSON schema and CSV checks are read
+// with 'open'. Check returns the reason that each failed check failed, or nil
+// if they all passed. Only one invalid file is described per check.
+func Check(v *pps.Validation, files []File, open func(path string) (io.ReadCloser, error)) ([]string, error) {
+	var reasons []string
+	var size uint64
+	for _, file := range files {
+		size += file.SizeBytes
+	}
+	numFiles := int64(len(files))
+	if v.MinFiles != 0 && numFiles < v.MinFiles {
+		reasons = append(reasons, fmt.Sprintf("output has %d files, fewer than min_files (%d)", numFiles, v.MinFiles))
+	}
+	if v.MaxFiles != 0 && numFiles > v.MaxFiles {
+		reasons = append(reasons, fmt.Sprintf("output has %d files, more than max_files (%d)", numFiles, v.MaxFiles))
+	}
+	if v.MinBytes != 0 && size < v.MinBytes {
+		reasons = append(reasons, fmt.Sprintf("output is %d bytes, smaller than min_bytes (%d)", size, v.MinBytes))
+	}
+	for _, check := range v.JSONSchema {
+		schema, err := compileSchema(check.Schema)
+		if err != nil {
+			return nil, errors.Wrapf(err, "bad JSON schema for %q", check.Glob)
+		}
+		reason, err := checkFiles(check.Glob, files, open, func(r io.Reader) (string, error) {
+			return checkJSONSchema(schema, r)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			reasons = append(reasons, reason)
+		}
+	}
+	for _, check := range v.CSVColumns {
+		reason, err := checkFiles(check.Glob, files, open, func(r io.Reader) (string, error) {
+			return checkCSVColumns(check.Columns, r)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			reasons = append(reasons, reason)
+		}
+	}
+	return reasons, nil
+}
+
+// checkFiles runs 'check' on the contents of each file in 'files' that
+// matches 'glob'. It returns a description of the first file that 'check'
+// found invalid, along with the number of other invalid files, or "" if
+// every matching file is valid.
+func checkFiles(glob string, files []File, open func(path string) (io.ReadCloser, error), check func(io.Reader) (string, error)) (string, error) {
+	g, err := compileGlob(glob)
+	if err != nil {
+		return "", errors.Wrapf(err, "bad glob %q", glob)
+	}
+	var firstReason string
+	var invalid int
+	for _, file := range files {
+		if !g.Match(file.Path) {
+			continue
+		}
+		r, err := open(file.Path)
+		if err != nil {
+			return "", errors.Wrapf(err, "could not read %s", file.Path)
+		}
+		reason, err := check(r)
+		if closeErr := r.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return "", errors.Wrapf(err, "could not read %s", file.Path)
+		}
+		if reason == "" {
+			continue
+		}
+		if invalid == 0 {
+			firstReason = fmt.Sprintf("%s (matched by %q): %s", file.Path, glob, reason)
+		}
+		invalid++
+	}
+	if invalid > 1 {
+		return fmt.Sprintf("%s (and %d more invalid files)", firstReason, invalid-1), nil
+	}
+	return firstReason, nil
+}
+
+// checkJSONSchema returns the reason that the JSON values in 'r' aren't valid
+// against 'schema', or "" if they are
+func checkJSONSchema(schema *gojsonschema.Schema, r io.Reader) (string, error) {
+	d := json.NewDecoder(r)
+	d.UseNumber()
+	for i := 0; ; i++ {
+		var value interface{}
+		if err := d.Decode(&value); err != nil {
+			if err == io.EOF {
+				if i == 0 {
+					return "no JSON values", nil
+				}
+				return "", nil
+			}
+			if _, ok := err.(*json.SyntaxError); ok || err == io.ErrUnexpectedEOF {
+				return fmt.Sprintf("invalid JSON: %v", err), nil
+			}
+			return "", err
+		}
+		result, err := schema.Validate(gojsonschema.NewGoLoader(value))
+		if err != nil {
+			return "", err
+		}
+		if !result.Valid() {
+			var errs []string
+			for _, e := range result.Errors() {
+				errs = append(errs, e.String())
+			}
+			return fmt.Sprintf("value %d does not match the JSON schema: %s", i+1, strings.Join(errs, "; ")), nil
+		}
+	}
+}
+
+// checkCSVColumns returns the reason that the header row of the CSV in 'r'
+// doesn't include 'columns', or "" if it does
+func checkCSVColumns(columns []string, r io.Reader) (string, error) {
+	header, err := csv.NewReader(r).Read()
+	if err != nil {
+		if err == io.EOF {
+			return "no CSV header row", nil
+		}
+		if _, ok := err.(*csv.ParseError); ok {
+			return fmt.Sprintf("invalid CSV: %v", err), nil
+		}
+		return "", err
+	}
+	present := make(map[string]bool)
+	for _, column := range header {
+		present[column] = true
+	}
+	var missing []string
+	for _, column := range columns {
+		if !present[column] {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Sprintf("missing CSV columns %s", strings.Join(missing, ", ")), nil
+	}
+	return "", nil
+}
+
+func compileGlob(glob string) (*globlib.Glob, error) {
+	return globlib.Compile(path.Join("/", glob), '/')
+}
+
+func compileSchema(schema string) (*gojsonschema.Schema, error) {
+	return gojsonschema.NewSchema(gojsonschema.NewStringLoader(schema))
+}
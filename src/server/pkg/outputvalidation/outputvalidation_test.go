@@ -0,0 +1,112 @@
+package outputvalidation
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+const personSchema = `{
+  "type": "object",
+  "properties": {"name": {"type": "string"}, "age": {"type": "integer"}},
+  "required": ["name"]
+}`
+
+// check runs Check against files with the given contents
+func check(t *testing.T, v *pps.Validation, contents map[string]string) []string {
+	var files []File
+	for path, content := range contents {
+		files = append(files, File{Path: path, SizeBytes: uint64(len(content))})
+	}
+	reasons, err := Check(v, files, func(path string) (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader(contents[path])), nil
+	})
+	require.NoError(t, err)
+	return reasons
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Validate(&pps.Validation{
+		MinFiles:   1,
+		MaxFiles:   10,
+		JSONSchema: []*pps.JSONSchemaCheck{{Glob: "/*.json", Schema: personSchema}},
+		CSVColumns: []*pps.CSVColumnsCheck{{Glob: "/*.csv", Columns: []string{"id"}}},
+	}))
+	require.YesError(t, Validate(&pps.Validation{MinFiles: 2, MaxFiles: 1}))
+	require.YesError(t, Validate(&pps.Validation{
+		JSONSchema: []*pps.JSONSchemaCheck{{Glob: "/*.json", Schema: "{"}},
+	}))
+	require.YesError(t, Validate(&pps.Validation{
+		CSVColumns: []*pps.CSVColumnsCheck{{Glob: "/*.csv"}},
+	}))
+}
+
+func TestCheckCounts(t *testing.T) {
+	contents := map[string]string{"/a": "foo", "/b": "bar"}
+	require.Equal(t, 0, len(check(t, &pps.Validation{MinFiles: 2, MaxFiles: 2, MinBytes: 6}, contents)))
+	reasons := check(t, &pps.Validation{MinFiles: 3, MinBytes: 7}, contents)
+	require.Equal(t, []string{
+		"output has 2 files, fewer than min_files (3)",
+		"output is 6 bytes, smaller than min_bytes (7)",
+	}, reasons)
+	reasons = check(t, &pps.Validation{MaxFiles: 1}, contents)
+	require.Equal(t, []string{"output has 2 files, more than max_files (1)"}, reasons)
+}
+
+func TestCheckJSONSchema(t *testing.T) {
+	v := &pps.Validation{
+		JSONSchema: []*pps.JSONSchemaCheck{{Glob: "/people/*.json", Schema: personSchema}},
+	}
+	require.Equal(t, 0, len(check(t, v, map[string]string{
+		"/people/a.json": `{"name": "alice", "age": 30}`,
+		"/people/b.json": "{\"name\": \"bob\"}\n{\"name\": \"carol\"}\n",
+		"/other.json":    `{"age": "unchecked"}`,
+	})))
+
+	reasons := check(t, v, map[string]string{
+		"/people/a.json": "{\"name\": \"alice\"}\n{\"age\": 1.5}\n",
+	})
+	require.Equal(t, 1, len(reasons))
+	require.True(t, strings.HasPrefix(reasons[0], `/people/a.json (matched by "/people/*.json"): value 2 does not match the JSON schema`), reasons[0])
+
+	reasons = check(t, v, map[string]string{
+		"/people/a.json": `{"name": `,
+		"/people/b.json": ``,
+	})
+	require.Equal(t, 1, len(reasons))
+	require.True(t, strings.HasSuffix(reasons[0], "(and 1 more invalid files)"), reasons[0])
+}
+
+func TestCheckCSVColumns(t *testing.T) {
+	v := &pps.Validation{
+		CSVColumns: []*pps.CSVColumnsCheck{{Glob: "*.csv", Columns: []string{"id", "name"}}},
+	}
+	require.Equal(t, 0, len(check(t, v, map[string]string{
+		"/a.csv": "name,id,age\n1,foo,2\n",
+	})))
+	require.Equal(t, []string{`/a.csv (matched by "*.csv"): missing CSV columns name`}, check(t, v, map[string]string{
+		"/a.csv": "id,age\n1,2\n",
+	}))
+	require.Equal(t, []string{`/a.csv (matched by "*.csv"): no CSV header row`}, check(t, v, map[string]string{
+		"/a.csv": "",
+	}))
+}
+
+func TestContentMatcher(t *testing.T) {
+	readsContent, err := ContentMatcher(&pps.Validation{
+		JSONSchema: []*pps.JSONSchemaCheck{{Glob: "/*.json", Schema: personSchema}},
+		CSVColumns: []*pps.CSVColumnsCheck{{Glob: "/csv/*", Columns: []string{"name"}}},
+	})
+	require.NoError(t, err)
+	require.True(t, readsContent("/a.json"))
+	require.True(t, readsContent("/csv/a"))
+	require.False(t, readsContent("/a.txt"))
+
+	readsContent, err = ContentMatcher(&pps.Validation{MinFiles: 1})
+	require.NoError(t, err)
+	require.False(t, readsContent("/a.json"))
+}
@@ -19,6 +19,7 @@ const (
 	openCommitsPrefix    = "/openCommits"
 	mergesPrefix         = "/merges"
 	shardsPrefix         = "/shards"
+	snapshotsPrefix      = "/snapshots"
 )
 
 var (
@@ -90,3 +91,15 @@ func OpenCommits(etcdClient *etcd.Client, etcdPrefix string) col.Collection {
 		nil,
 	)
 }
+
+// Snapshots returns a collection of snapshots
+func Snapshots(etcdClient *etcd.Client, etcdPrefix string) col.Collection {
+	return col.NewCollection(
+		etcdClient,
+		path.Join(etcdPrefix, snapshotsPrefix),
+		nil,
+		&pfs.SnapshotInfo{},
+		nil,
+		nil,
+	)
+}
@@ -21,4 +21,10 @@ const (
 
 	// SpoutMarkerBranch is the branch that spouts use for keeping track of spout marker files
 	SpoutMarkerBranch = "marker"
+
+	// SecretOwnerAnnotation is the annotation on secrets created with 'pachctl
+	// create secret' that holds the user that created them, if auth was
+	// active. PFS only lets that user (or an admin) fetch put file URLs with
+	// the secret's credentials.
+	SecretOwnerAnnotation = "createdBy"
 )
@@ -11,8 +11,11 @@ import (
 )
 
 const (
-	pipelinesPrefix = "/pipelines"
-	jobsPrefix      = "/jobs"
+	pipelinesPrefix  = "/pipelines"
+	jobsPrefix       = "/jobs"
+	templatesPrefix  = "/pipeline_templates"
+	sinksPrefix      = "/notification_sinks"
+	deadLetterPrefix = "/dead_letters"
 )
 
 var (
@@ -25,6 +28,10 @@ var (
 
 	// JobsOutputIndex maps job outputs to the job that create them.
 	JobsOutputIndex = &col.Index{Field: "OutputCommit", Multi: false}
+
+	// DeadLettersSinkIndex maps notification sinks to the events that couldn't
+	// be delivered to them
+	DeadLettersSinkIndex = &col.Index{Field: "Sink", Multi: false}
 )
 
 // Pipelines returns a Collection of pipelines
@@ -50,3 +57,40 @@ func Jobs(etcdClient *etcd.Client, etcdPrefix string) col.Collection {
 		nil,
 	)
 }
+
+// Templates returns a Collection of pipeline templates
+func Templates(etcdClient *etcd.Client, etcdPrefix string) col.Collection {
+	return col.NewCollection(
+		etcdClient,
+		path.Join(etcdPrefix, templatesPrefix),
+		nil,
+		&pps.PipelineTemplateInfo{},
+		nil,
+		nil,
+	)
+}
+
+// NotificationSinks returns a Collection of notification sinks
+func NotificationSinks(etcdClient *etcd.Client, etcdPrefix string) col.Collection {
+	return col.NewCollection(
+		etcdClient,
+		path.Join(etcdPrefix, sinksPrefix),
+		nil,
+		&pps.NotificationSinkInfo{},
+		nil,
+		nil,
+	)
+}
+
+// DeadLetters returns a Collection of notification events that couldn't be
+// delivered
+func DeadLetters(etcdClient *etcd.Client, etcdPrefix string) col.Collection {
+	return col.NewCollection(
+		etcdClient,
+		path.Join(etcdPrefix, deadLetterPrefix),
+		[]*col.Index{DeadLettersSinkIndex},
+		&pps.DeadLetter{},
+		nil,
+		nil,
+	)
+}
@@ -2,14 +2,17 @@ package ppsutil
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 	"unicode"
 
+	"github.com/google/go-jsonnet"
 	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
 	ppsclient "github.com/pachyderm/pachyderm/src/client/pps"
 	"github.com/pachyderm/pachyderm/src/server/pkg/serde"
@@ -22,15 +25,56 @@ type PipelineManifestReader struct {
 }
 
 // NewPipelineManifestReader creates a new manifest reader from a path.
-func NewPipelineManifestReader(path string) (result *PipelineManifestReader, retErr error) {
-	var pipelineBytes []byte
+func NewPipelineManifestReader(path string) (*PipelineManifestReader, error) {
+	pipelineBytes, err := readPipelineBytes(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewPipelineManifestReaderFromBytes(pipelineBytes), nil
+}
+
+// NewPipelineManifestReaderFromJsonnet creates a new manifest reader from the
+// jsonnet pipeline template at 'path', which is evaluated with the top-level
+// arguments 'args'. Unlike templates stored in pachd, local templates may
+// import other files relative to 'path'.
+func NewPipelineManifestReaderFromJsonnet(path string, args map[string]string) (*PipelineManifestReader, error) {
+	templateBytes, err := readPipelineBytes(path)
+	if err != nil {
+		return nil, err
+	}
+	vm := jsonnet.MakeVM()
+	vm.Importer(&jsonnet.FileImporter{})
+	pipelineBytes, err := renderTemplate(vm, path, string(templateBytes), args)
+	if err != nil {
+		return nil, err
+	}
+	return NewPipelineManifestReaderFromBytes(pipelineBytes), nil
+}
+
+// NewPipelineManifestReaderFromBytes creates a new manifest reader from the
+// JSON or YAML pipeline specs in 'pipelineBytes'.
+func NewPipelineManifestReaderFromBytes(pipelineBytes []byte) *PipelineManifestReader {
+	// TODO(msteffen): if we can get the yaml decoder to handle leading tabs, as
+	// in pps/cmds/cmds_test.go, then we can get rid of this
+	idx := bytes.IndexFunc(pipelineBytes, func(r rune) bool {
+		return !unicode.IsSpace(r)
+	})
+	if idx >= 0 && pipelineBytes[idx] == '{' {
+		return &PipelineManifestReader{
+			decoder: serde.NewJSONDecoder(bytes.NewReader(pipelineBytes)),
+		}
+	}
+	return &PipelineManifestReader{
+		decoder: serde.NewYAMLDecoder(bytes.NewReader(pipelineBytes)),
+	}
+}
+
+// readPipelineBytes reads the file at 'path', which may also be a URL or "-"
+// for stdin
+func readPipelineBytes(path string) (result []byte, retErr error) {
 	if path == "-" {
 		fmt.Print("Reading from stdin.\n")
-		var err error
-		pipelineBytes, err = ioutil.ReadAll(os.Stdin)
-		if err != nil {
-			return nil, err
-		}
+		return ioutil.ReadAll(os.Stdin)
 	} else if url, err := url.Parse(path); err == nil && url.Scheme != "" {
 		resp, err := http.Get(url.String())
 		if err != nil {
@@ -41,30 +85,56 @@ func NewPipelineManifestReader(path string) (result *PipelineManifestReader, ret
 				retErr = err
 			}
 		}()
-		pipelineBytes, err = ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return nil, err
+		return ioutil.ReadAll(resp.Body)
+	}
+	return ioutil.ReadFile(path)
+}
+
+// RenderTemplate evaluates the jsonnet pipeline template 'template' with the
+// top-level arguments 'args', and returns the resulting pipeline specs as a
+// stream of JSON objects (which NewPipelineManifestReaderFromBytes can read).
+// A template may evaluate to a single pipeline spec or a list of them. Imports
+// are disallowed, as this is used to render templates stored in pachd.
+func RenderTemplate(template string, args map[string]string) ([]byte, error) {
+	vm := jsonnet.MakeVM()
+	vm.Importer(&jsonnet.MemoryImporter{})
+	return renderTemplate(vm, "<template>", template, args)
+}
+
+// ValidateTemplate returns an error if 'template' isn't syntactically valid
+// jsonnet
+func ValidateTemplate(template string) error {
+	if _, err := jsonnet.SnippetToAST("<template>", template); err != nil {
+		return errors.Wrapf(err, "invalid pipeline template")
+	}
+	return nil
+}
+
+func renderTemplate(vm *jsonnet.VM, filename string, template string, args map[string]string) ([]byte, error) {
+	for k, v := range args {
+		vm.TLAVar(k, v)
+	}
+	output, err := vm.EvaluateSnippet(filename, template)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not evaluate pipeline template")
+	}
+	// Convert a list of specs into a stream of specs
+	var specs []json.RawMessage
+	if strings.HasPrefix(strings.TrimSpace(output), "[") {
+		if err := json.Unmarshal([]byte(output), &specs); err != nil {
+			return nil, errors.Wrapf(err, "could not parse pipeline template output")
 		}
 	} else {
-		var err error
-		pipelineBytes, err = ioutil.ReadFile(path)
-		if err != nil {
-			return nil, err
-		}
+		specs = append(specs, json.RawMessage(output))
 	}
-	// TODO(msteffen): if we can get the yaml decoder to handle leading tabs, as
-	// in pps/cmds/cmds_test.go, then we can get rid of this
-	idx := bytes.IndexFunc(pipelineBytes, func(r rune) bool {
-		return !unicode.IsSpace(r)
-	})
-	if idx >= 0 && pipelineBytes[idx] == '{' {
-		return &PipelineManifestReader{
-			decoder: serde.NewJSONDecoder(bytes.NewReader(pipelineBytes)),
-		}, nil
+	var buf bytes.Buffer
+	for _, spec := range specs {
+		if err := json.Compact(&buf, spec); err != nil {
+			return nil, errors.Wrapf(err, "could not parse pipeline template output")
+		}
+		buf.WriteString("\n")
 	}
-	return &PipelineManifestReader{
-		decoder: serde.NewYAMLDecoder(bytes.NewReader(pipelineBytes)),
-	}, nil
+	return buf.Bytes(), nil
 }
 
 // NextCreatePipelineRequest gets the next request from the manifest reader.
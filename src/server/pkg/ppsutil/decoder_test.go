@@ -0,0 +1,40 @@
+package ppsutil
+
+import (
+	"io"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	template := `
+function(env, regions="us") [
+  {
+    pipeline: { name: "ingest-" + env + "-" + region },
+    transform: { cmd: ["sh"], stdin: ["echo " + region] },
+    input: { pfs: { repo: "data", glob: "/*" } },
+  }
+  for region in std.split(regions, ",")
+]`
+	specs, err := RenderTemplate(template, map[string]string{"env": "prod", "regions": "us,eu"})
+	require.NoError(t, err)
+	r := NewPipelineManifestReaderFromBytes(specs)
+	var names []string
+	for {
+		request, err := r.NextCreatePipelineRequest()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, request.Pipeline.Name)
+	}
+	require.Equal(t, []string{"ingest-prod-us", "ingest-prod-eu"}, names)
+
+	// Missing arguments and imports are errors
+	_, err = RenderTemplate(template, nil)
+	require.YesError(t, err)
+	_, err = RenderTemplate(`import "/etc/passwd"`, nil)
+	require.YesError(t, err)
+	require.YesError(t, ValidateTemplate(`{ pipeline: `))
+}
@@ -44,6 +44,18 @@ func PipelineRepo(pipeline *pps.Pipeline) *pfs.Repo {
 	return &pfs.Repo{Name: pipeline.Name}
 }
 
+// ShadowPipelineName returns the name of the shadow of the pipeline 'name'
+// (see CreatePipelineRequest.Shadow)
+func ShadowPipelineName(name string) string {
+	return name + "-shadow"
+}
+
+// PinnedBranchName returns the name of the branch that pipeline inputs pinned
+// to 'commitID' read from (see PFSInput.commit)
+func PinnedBranchName(commitID string) string {
+	return "pinned-" + commitID
+}
+
 // PipelineRcName generates the name of the k8s replication controller that
 // manages a pipeline's workers
 func PipelineRcName(name string, version uint64) string {
@@ -215,6 +227,11 @@ func JobInput(pipelineInfo *pps.PipelineInfo, outputCommitInfo *pfs.CommitInfo)
 				input.Git.Commit = commit.ID
 			}
 		}
+		if input.Kafka != nil {
+			if commit, ok := branchToCommit[key(input.Kafka.Repo, "master")]; ok {
+				input.Kafka.Commit = commit.ID
+			}
+		}
 	})
 	return jobInput
 }
@@ -248,6 +265,8 @@ func PipelineReqFromInfo(pipelineInfo *pps.PipelineInfo) *pps.CreatePipelineRequ
 		Standby:          pipelineInfo.Standby,
 		S3Out:            pipelineInfo.S3Out,
 		Metadata:         pipelineInfo.Metadata,
+		Validation:       pipelineInfo.Validation,
+		JobHooks:         pipelineInfo.JobHooks,
 	}
 }
 
@@ -62,6 +62,14 @@ type PachdSpecificConfiguration struct {
 	WorkerUsesRoot             bool   `env:"WORKER_USES_ROOT,default=true"`
 	DeploymentID               string `env:"CLUSTER_DEPLOYMENT_ID,default="`
 	RequireCriticalServersOnly bool   `env:"REQUIRE_CRITICAL_SERVERS_ONLY",default=false"`
+	// AuditLogPath is where mutating API calls are recorded. If unset, they're
+	// recorded in $PACH_ROOT/audit/audit.log
+	AuditLogPath     string `env:"AUDIT_LOG_PATH,default="`
+	AuditLogMaxBytes int64  `env:"AUDIT_LOG_MAX_BYTES,default=104857600"`
+	AuditLogBackups  int    `env:"AUDIT_LOG_BACKUPS,default=5"`
+	// AuditLogKey is the secret that audit events' hashes are keyed with. If
+	// unset, a random key is generated and stored in etcd.
+	AuditLogKey string `env:"AUDIT_LOG_KEY,default="`
 }
 
 // StorageConfiguration contains the storage configuration.
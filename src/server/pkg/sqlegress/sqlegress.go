@@ -0,0 +1,405 @@
+// Package sqlegress loads the output files of a job into a Postgres or MySQL
+// table, as configured by a pipeline's pps.SQLEgress.
+package sqlegress
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	// Register the supported database drivers
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	pgdump "github.com/pachyderm/pachyderm/src/server/pkg/sql"
+)
+
+const (
+	// Postgres is the driver name for Postgres databases
+	Postgres = "postgres"
+	// MySQL is the driver name for MySQL databases
+	MySQL = "mysql"
+
+	// maxBatchRows is the most rows that Load inserts in one statement
+	maxBatchRows = 500
+	// maxBatchValues is the most values that Load inserts in one statement,
+	// as Postgres and MySQL both allow at most 65535 placeholders
+	maxBatchValues = 65535
+)
+
+// Validate returns an error if 'spec' isn't a valid SQL egress
+func Validate(spec *pps.SQLEgress) error {
+	if spec.Driver != Postgres && spec.Driver != MySQL {
+		return errors.Errorf("invalid sql egress: driver must be %q or %q, not %q", Postgres, MySQL, spec.Driver)
+	}
+	if spec.Secret == "" || spec.SecretKey == "" {
+		return errors.New("invalid sql egress: secret and secret_key must be set")
+	}
+	if spec.Table == "" {
+		return errors.New("invalid sql egress: table must be set")
+	}
+	if spec.Mode != pps.SQLEgressMode_REPLACE && spec.Mode != pps.SQLEgressMode_UPSERT {
+		return errors.New("invalid sql egress: mode must be REPLACE or UPSERT")
+	}
+	if spec.Mode == pps.SQLEgressMode_UPSERT && len(spec.KeyColumns) == 0 {
+		return errors.New("invalid sql egress: key_columns must be set in UPSERT mode")
+	}
+	return nil
+}
+
+// Load loads the rows in the files passed by 'files' to its callback into
+// 'spec's table, in a single transaction. If any file can't be loaded, none
+// of them are. Consecutive rows with the same columns are inserted in batches.
+// It returns the number of rows loaded.
+func Load(ctx context.Context, db *sql.DB, spec *pps.SQLEgress, files func(func(io.Reader) error) error) (_ int64, retErr error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if retErr != nil {
+			tx.Rollback()
+		}
+	}()
+	if spec.Mode == pps.SQLEgressMode_REPLACE {
+		// DELETE rather than TRUNCATE, as MySQL commits TRUNCATE immediately
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+quoteTable(spec.Driver, spec.Table)); err != nil {
+			return 0, errors.Wrapf(err, "could not clear table %s", spec.Table)
+		}
+	}
+	var rows int64
+	var b *batch
+	flush := func() error {
+		if b == nil {
+			return nil
+		}
+		query, err := insertStatement(spec, b.columns, b.rows)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, query, b.values...); err != nil {
+			return errors.Wrapf(err, "could not insert rows %d-%d", rows+1, rows+int64(b.rows))
+		}
+		rows += int64(b.rows)
+		b = nil
+		return nil
+	}
+	if err := files(func(r io.Reader) error {
+		return readRows(spec.Format, r, func(columns []string, values []interface{}) error {
+			key := rowKey(spec, columns, values)
+			if b != nil && !b.fits(columns, key) {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+			if b == nil {
+				b = &batch{columns: columns, keys: make(map[string]bool)}
+			}
+			b.values = append(b.values, values...)
+			b.rows++
+			if key != "" {
+				b.keys[key] = true
+			}
+			return nil
+		})
+	}); err != nil {
+		return 0, err
+	}
+	if err := flush(); err != nil {
+		return 0, err
+	}
+	return rows, tx.Commit()
+}
+
+// batch is a set of rows with the same columns, which Load inserts in one
+// statement
+type batch struct {
+	columns []string
+	values  []interface{}
+	rows    int
+	// keys are the rowKeys of the batch's rows. Postgres can't upsert the
+	// same row twice in one statement, so a row whose key is already in the
+	// batch starts a new batch.
+	keys map[string]bool
+}
+
+// fits returns true if a row with 'columns' and the rowKey 'key' can be added
+// to b
+func (b *batch) fits(columns []string, key string) bool {
+	if b.rows >= maxBatchRows || len(b.values)+len(columns) > maxBatchValues || b.keys[key] {
+		return false
+	}
+	if len(b.columns) != len(columns) {
+		return false
+	}
+	for i := range columns {
+		if b.columns[i] != columns[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// rowKey returns the values of a row's key columns in UPSERT mode, or "" if
+// rows aren't upserted
+func rowKey(spec *pps.SQLEgress, columns []string, values []interface{}) string {
+	if spec.Mode != pps.SQLEgressMode_UPSERT {
+		return ""
+	}
+	isKey := make(map[string]bool)
+	for _, key := range spec.KeyColumns {
+		isKey[key] = true
+	}
+	var key []string
+	for i, column := range columns {
+		if isKey[column] {
+			key = append(key, fmt.Sprintf("%v", values[i]))
+		}
+	}
+	return strings.Join(key, "\x00")
+}
+
+// readRows calls 'f' with the columns and values of each row in 'r'
+func readRows(format pps.SQLEgressFormat, r io.Reader, f func(columns []string, values []interface{}) error) error {
+	switch format {
+	case pps.SQLEgressFormat_CSV:
+		return readCSV(r, f)
+	case pps.SQLEgressFormat_JSON_LINES:
+		return readJSONLines(r, f)
+	case pps.SQLEgressFormat_PGDUMP:
+		return readPGDump(r, f)
+	}
+	return errors.Errorf("unrecognized sql egress format %v", format)
+}
+
+func readCSV(r io.Reader, f func([]string, []interface{}) error) error {
+	cr := csv.NewReader(r)
+	columns, err := cr.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil // empty file
+		}
+		return err
+	}
+	for {
+		record, err := cr.Read()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		values := make([]interface{}, len(record))
+		for i, field := range record {
+			values[i] = field
+		}
+		if err := f(columns, values); err != nil {
+			return err
+		}
+	}
+}
+
+func readJSONLines(r io.Reader, f func([]string, []interface{}) error) error {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	for {
+		var object map[string]interface{}
+		if err := decoder.Decode(&object); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		columns := make([]string, 0, len(object))
+		for column := range object {
+			columns = append(columns, column)
+		}
+		sort.Strings(columns)
+		values := make([]interface{}, len(columns))
+		for i, column := range columns {
+			switch value := object[column].(type) {
+			case json.Number:
+				values[i] = value.String()
+			case map[string]interface{}, []interface{}:
+				// Nested values are loaded as JSON text
+				data, err := json.Marshal(value)
+				if err != nil {
+					return err
+				}
+				values[i] = string(data)
+			default:
+				values[i] = value
+			}
+		}
+		if err := f(columns, values); err != nil {
+			return err
+		}
+	}
+}
+
+func readPGDump(r io.Reader, f func([]string, []interface{}) error) error {
+	pgr := pgdump.NewPGDumpReader(bufio.NewReader(r))
+	var columns []string
+	for {
+		row, err := pgr.ReadRow()
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if columns == nil && len(pgr.Header) > 0 {
+			if columns, err = copyColumns(pgr.Header); err != nil {
+				return err
+			}
+		}
+		if len(row) > 0 {
+			fields := strings.Split(strings.TrimSuffix(string(row), "\n"), "\t")
+			if len(fields) != len(columns) {
+				return errors.Errorf("pgdump row has %d fields, but the COPY statement has %d columns", len(fields), len(columns))
+			}
+			values := make([]interface{}, len(fields))
+			for i, field := range fields {
+				if field != `\N` {
+					values[i] = unescapeCopyField(field)
+				}
+			}
+			if err := f(columns, values); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// copyColumns returns the columns named by the COPY statement that ends a
+// pgdump header, e.g. "COPY public.t (a, b) FROM stdin;"
+func copyColumns(header []byte) ([]string, error) {
+	lines := strings.Split(strings.TrimSpace(string(header)), "\n")
+	copyStmt := lines[len(lines)-1]
+	start, end := strings.Index(copyStmt, "("), strings.LastIndex(copyStmt, ")")
+	if start < 0 || end < start {
+		return nil, errors.Errorf("pgdump COPY statement doesn't name its columns: %q", copyStmt)
+	}
+	var columns []string
+	for _, column := range strings.Split(copyStmt[start+1:end], ",") {
+		columns = append(columns, strings.Trim(strings.TrimSpace(column), `"`))
+	}
+	return columns, nil
+}
+
+// unescapeCopyField reverses the backslash escaping of a field in Postgres's
+// COPY text format
+func unescapeCopyField(field string) string {
+	if !strings.Contains(field, `\`) {
+		return field
+	}
+	var b strings.Builder
+	for i := 0; i < len(field); i++ {
+		if field[i] != '\\' || i == len(field)-1 {
+			b.WriteByte(field[i])
+			continue
+		}
+		i++
+		switch field[i] {
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'v':
+			b.WriteByte('\v')
+		default:
+			b.WriteByte(field[i])
+		}
+	}
+	return b.String()
+}
+
+// insertStatement returns the statement that inserts (or, in UPSERT mode,
+// upserts) 'rows' rows with 'columns' into 'spec's table
+func insertStatement(spec *pps.SQLEgress, columns []string, rows int) (string, error) {
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = quoteIdentifier(spec.Driver, column)
+	}
+	tuples := make([]string, rows)
+	for r := range tuples {
+		placeholders := make([]string, len(columns))
+		for i := range columns {
+			placeholders[i] = "?"
+			if spec.Driver == Postgres {
+				placeholders[i] = fmt.Sprintf("$%d", r*len(columns)+i+1)
+			}
+		}
+		tuples[r] = "(" + strings.Join(placeholders, ", ") + ")"
+	}
+	stmt := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
+		quoteTable(spec.Driver, spec.Table), strings.Join(quoted, ", "), strings.Join(tuples, ", "))
+	if spec.Mode != pps.SQLEgressMode_UPSERT {
+		return stmt, nil
+	}
+
+	isKey := make(map[string]bool)
+	var keys []string
+	for _, key := range spec.KeyColumns {
+		isKey[key] = true
+		keys = append(keys, quoteIdentifier(spec.Driver, key))
+	}
+	var updates []string
+	found := 0
+	for i, column := range columns {
+		if isKey[column] {
+			found++
+			continue
+		}
+		if spec.Driver == Postgres {
+			updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", quoted[i], quoted[i]))
+		} else {
+			updates = append(updates, fmt.Sprintf("%s = VALUES(%s)", quoted[i], quoted[i]))
+		}
+	}
+	if found != len(spec.KeyColumns) {
+		return "", errors.Errorf("row is missing some of the key columns %v", spec.KeyColumns)
+	}
+	if spec.Driver == Postgres {
+		if len(updates) == 0 {
+			return fmt.Sprintf("%s ON CONFLICT (%s) DO NOTHING", stmt, strings.Join(keys, ", ")), nil
+		}
+		return fmt.Sprintf("%s ON CONFLICT (%s) DO UPDATE SET %s", stmt, strings.Join(keys, ", "), strings.Join(updates, ", ")), nil
+	}
+	if len(updates) == 0 {
+		// MySQL has no DO NOTHING, so set a key to itself instead
+		updates = append(updates, fmt.Sprintf("%s = %s", keys[0], keys[0]))
+	}
+	return fmt.Sprintf("%s ON DUPLICATE KEY UPDATE %s", stmt, strings.Join(updates, ", ")), nil
+}
+
+// quoteTable quotes a table name, which may be qualified by a schema (e.g.
+// "public.t")
+func quoteTable(driver, table string) string {
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		parts[i] = quoteIdentifier(driver, part)
+	}
+	return strings.Join(parts, ".")
+}
+
+func quoteIdentifier(driver, identifier string) string {
+	if driver == MySQL {
+		return "`" + strings.Replace(identifier, "`", "``", -1) + "`"
+	}
+	return `"` + strings.Replace(identifier, `"`, `""`, -1) + `"`
+}
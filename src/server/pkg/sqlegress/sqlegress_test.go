@@ -0,0 +1,185 @@
+package sqlegress
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+type row struct {
+	columns []string
+	values  []interface{}
+}
+
+func read(t *testing.T, format pps.SQLEgressFormat, data string) []row {
+	var rows []row
+	require.NoError(t, readRows(format, strings.NewReader(data), func(columns []string, values []interface{}) error {
+		rows = append(rows, row{columns, values})
+		return nil
+	}))
+	return rows
+}
+
+func TestReadCSV(t *testing.T) {
+	rows := read(t, pps.SQLEgressFormat_CSV, "id,name\n1,foo\n2,\"bar, baz\"\n")
+	require.Equal(t, 2, len(rows))
+	require.Equal(t, []string{"id", "name"}, rows[1].columns)
+	require.Equal(t, []interface{}{"2", "bar, baz"}, rows[1].values)
+	require.Equal(t, 0, len(read(t, pps.SQLEgressFormat_CSV, "")))
+}
+
+func TestReadJSONLines(t *testing.T) {
+	rows := read(t, pps.SQLEgressFormat_JSON_LINES, `{"name": "foo", "id": 1, "tags": ["a"]}
+{"id": 2.5, "ok": true, "name": null}
+`)
+	require.Equal(t, 2, len(rows))
+	require.Equal(t, []string{"id", "name", "tags"}, rows[0].columns)
+	require.Equal(t, []interface{}{"1", "foo", `["a"]`}, rows[0].values)
+	require.Equal(t, []string{"id", "name", "ok"}, rows[1].columns)
+	require.Equal(t, []interface{}{"2.5", nil, true}, rows[1].values)
+}
+
+func TestReadPGDump(t *testing.T) {
+	rows := read(t, pps.SQLEgressFormat_PGDUMP, `SET statement_timeout = 0;
+COPY public.users (id, "name", note) FROM stdin;
+1	alice	\N
+2	bob	tab\there
+\.
+
+-- done
+`)
+	require.Equal(t, 2, len(rows))
+	require.Equal(t, []string{"id", "name", "note"}, rows[0].columns)
+	require.Equal(t, []interface{}{"1", "alice", nil}, rows[0].values)
+	require.Equal(t, []interface{}{"2", "bob", "tab\there"}, rows[1].values)
+}
+
+func TestInsertStatement(t *testing.T) {
+	spec := &pps.SQLEgress{Driver: Postgres, Table: "public.users", Mode: pps.SQLEgressMode_REPLACE}
+	stmt, err := insertStatement(spec, []string{"id", "name"}, 1)
+	require.NoError(t, err)
+	require.Equal(t, `INSERT INTO "public"."users" ("id", "name") VALUES ($1, $2)`, stmt)
+	stmt, err = insertStatement(spec, []string{"id", "name"}, 2)
+	require.NoError(t, err)
+	require.Equal(t, `INSERT INTO "public"."users" ("id", "name") VALUES ($1, $2), ($3, $4)`, stmt)
+
+	spec.Mode = pps.SQLEgressMode_UPSERT
+	spec.KeyColumns = []string{"id"}
+	stmt, err = insertStatement(spec, []string{"id", "name"}, 1)
+	require.NoError(t, err)
+	require.Equal(t, `INSERT INTO "public"."users" ("id", "name") VALUES ($1, $2) ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name"`, stmt)
+	stmt, err = insertStatement(spec, []string{"id"}, 1)
+	require.NoError(t, err)
+	require.Equal(t, `INSERT INTO "public"."users" ("id") VALUES ($1) ON CONFLICT ("id") DO NOTHING`, stmt)
+	_, err = insertStatement(spec, []string{"name"}, 1)
+	require.YesError(t, err)
+
+	spec.Driver = MySQL
+	stmt, err = insertStatement(spec, []string{"id", "name"}, 2)
+	require.NoError(t, err)
+	require.Equal(t, "INSERT INTO `public`.`users` (`id`, `name`) VALUES (?, ?), (?, ?) ON DUPLICATE KEY UPDATE `name` = VALUES(`name`)", stmt)
+}
+
+func TestValidate(t *testing.T) {
+	spec := &pps.SQLEgress{Driver: Postgres, Secret: "db", SecretKey: "dsn", Table: "t"}
+	// The mode must be set explicitly
+	require.YesError(t, Validate(spec))
+	spec.Mode = pps.SQLEgressMode_REPLACE
+	require.NoError(t, Validate(spec))
+	spec.Mode = pps.SQLEgressMode_UPSERT
+	require.YesError(t, Validate(spec))
+	spec.KeyColumns = []string{"id"}
+	require.NoError(t, Validate(spec))
+	spec.Driver = "sqlite"
+	require.YesError(t, Validate(spec))
+}
+
+// TestLoadPostgres loads rows into the Postgres database in
+// $POSTGRES_EGRESS_DSN (e.g. "postgres://postgres@localhost/postgres?sslmode=disable")
+func TestLoadPostgres(t *testing.T) {
+	dsn := os.Getenv("POSTGRES_EGRESS_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_EGRESS_DSN not set")
+	}
+	db, err := sql.Open(Postgres, dsn)
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("DROP TABLE IF EXISTS egress_test")
+	require.NoError(t, err)
+	_, err = db.Exec("CREATE TABLE egress_test (id INT PRIMARY KEY, name TEXT)")
+	require.NoError(t, err)
+	files := func(data ...string) func(func(io.Reader) error) error {
+		return func(f func(io.Reader) error) error {
+			for _, d := range data {
+				if err := f(strings.NewReader(d)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	names := func() map[int]string {
+		rows, err := db.Query("SELECT id, name FROM egress_test")
+		require.NoError(t, err)
+		defer rows.Close()
+		result := make(map[int]string)
+		for rows.Next() {
+			var id int
+			var name string
+			require.NoError(t, rows.Scan(&id, &name))
+			result[id] = name
+		}
+		require.NoError(t, rows.Err())
+		return result
+	}
+	ctx := context.Background()
+
+	spec := &pps.SQLEgress{Driver: Postgres, Table: "egress_test", Mode: pps.SQLEgressMode_REPLACE}
+	n, err := Load(ctx, db, spec, files("id,name\n1,a\n2,b\n", "id,name\n3,c\n"))
+	require.NoError(t, err)
+	require.Equal(t, int64(3), n)
+	require.Equal(t, map[int]string{1: "a", 2: "b", 3: "c"}, names())
+
+	spec.Mode = pps.SQLEgressMode_UPSERT
+	spec.KeyColumns = []string{"id"}
+	spec.Format = pps.SQLEgressFormat_JSON_LINES
+	_, err = Load(ctx, db, spec, files(`{"id": 2, "name": "B"}`+"\n"+`{"id": 4, "name": "d"}`))
+	require.NoError(t, err)
+	require.Equal(t, map[int]string{1: "a", 2: "B", 3: "c", 4: "d"}, names())
+
+	// A row that's upserted twice in one load has its last value
+	_, err = Load(ctx, db, spec, files(`{"id": 4, "name": "x"}`+"\n"+`{"id": 4, "name": "D"}`))
+	require.NoError(t, err)
+	require.Equal(t, map[int]string{1: "a", 2: "B", 3: "c", 4: "D"}, names())
+
+	// A failure in any file rolls back the whole load
+	spec.Mode = pps.SQLEgressMode_REPLACE
+	spec.Format = pps.SQLEgressFormat_CSV
+	_, err = Load(ctx, db, spec, files("id,name\n5,e\n", "id,name\nnot a number,f\n"))
+	require.YesError(t, err)
+	require.Equal(t, map[int]string{1: "a", 2: "B", 3: "c", 4: "d"}, names())
+
+	_, err = Load(ctx, db, spec, files("id,name\n5,e\n"))
+	require.NoError(t, err)
+	require.Equal(t, map[int]string{5: "e"}, names())
+
+	// Loads with more rows than fit in one batch
+	var data strings.Builder
+	data.WriteString("id,name\n")
+	expected := make(map[int]string)
+	for i := 0; i < 2*maxBatchRows+1; i++ {
+		fmt.Fprintf(&data, "%d,n%d\n", i, i)
+		expected[i] = fmt.Sprintf("n%d", i)
+	}
+	n, err = Load(ctx, db, spec, files(data.String()))
+	require.NoError(t, err)
+	require.Equal(t, int64(2*maxBatchRows+1), n)
+	require.Equal(t, expected, names())
+}
@@ -0,0 +1,282 @@
+package sync
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	pachclient "github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/limit"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	pfsserver "github.com/pachyderm/pachyderm/src/server/pfs"
+	"github.com/pachyderm/pachyderm/src/server/pkg/hashtree"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ChangeType describes how Upload or Download changed a file
+type ChangeType int
+
+const (
+	// Added files didn't exist in the destination
+	Added ChangeType = iota
+	// Modified files existed in the destination with different content
+	Modified
+	// Deleted files existed in the destination but not the source
+	Deleted
+)
+
+func (t ChangeType) String() string {
+	switch t {
+	case Added:
+		return "added"
+	case Modified:
+		return "modified"
+	case Deleted:
+		return "deleted"
+	default:
+		return "unknown"
+	}
+}
+
+// Change is a file that Upload or Download transferred or deleted (or would
+// have, in a dry run). Path is relative to the directories being synced.
+type Change struct {
+	Path string
+	Type ChangeType
+}
+
+// FileHash returns the hash (see pfs.FileInfo.Hash) that PFS gives a file
+// with the content in 'r', if the file is written by a single PutFile. Files
+// that were appended to have a different hash even if their content is the
+// same.
+func FileHash(r io.Reader) ([]byte, error) {
+	// PutFile stores a file as one object per pfs.ChunkSize bytes, the same way
+	// objBlockAPIServer.PutObjectSplit splits its input: chunks are read until
+	// one ends early, so files whose size is a multiple of pfs.ChunkSize
+	// (including empty files) end with an empty object.
+	fileNode := &hashtree.FileNodeProto{}
+	for {
+		hash := pfs.NewHash()
+		_, err := io.CopyN(hash, r, pfs.ChunkSize)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		fileNode.Objects = append(fileNode.Objects, &pfs.Object{Hash: pfs.EncodeHash(hash.Sum(nil))})
+		if err == io.EOF {
+			break
+		}
+	}
+	return hashtree.HashFileNode(fileNode), nil
+}
+
+// localFiles returns the hashes of the files under 'root', by their slash-
+// separated paths relative to 'root'. 'root' not existing is the same as it
+// being empty.
+func localFiles(root string, concurrency int) (map[string]string, error) {
+	var mu sync.Mutex
+	files := make(map[string]string)
+	limiter := limit.New(concurrency)
+	var eg errgroup.Group
+	if err := filepath.Walk(root, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			if filePath == root && os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		relPath, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+		eg.Go(func() (retErr error) {
+			limiter.Acquire()
+			defer limiter.Release()
+			f, err := os.Open(filePath)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if err := f.Close(); err != nil && retErr == nil {
+					retErr = err
+				}
+			}()
+			hash, err := FileHash(f)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			files[filepath.ToSlash(relPath)] = pfs.EncodeHash(hash)
+			return nil
+		})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// remoteFiles returns the hashes of the files under 'dir' in 'commit', by
+// their paths relative to 'dir'. A branch with no commits, or a 'dir' that
+// doesn't exist, is the same as an empty directory.
+func remoteFiles(client *pachclient.APIClient, repo, commit, dir string) (map[string]string, error) {
+	files := make(map[string]string)
+	root := path.Clean("/" + dir)
+	if err := client.Walk(repo, commit, dir, func(fileInfo *pfs.FileInfo) error {
+		if fileInfo.FileType != pfs.FileType_FILE {
+			return nil
+		}
+		relPath := strings.TrimPrefix(strings.TrimPrefix(path.Clean("/"+fileInfo.File.Path), root), "/")
+		if relPath != "" {
+			files[relPath] = pfs.EncodeHash(fileInfo.Hash)
+		}
+		return nil
+	}); err != nil && !isNotExist(err) && !pfsserver.IsNoHeadErr(err) {
+		return nil, err
+	}
+	return files, nil
+}
+
+// diffFiles returns the changes that make the files in 'dst' match the files
+// in 'src'. Files that are only in 'dst' are deleted only if 'del' is set.
+func diffFiles(src, dst map[string]string, del bool) []*Change {
+	var changes []*Change
+	for p, srcHash := range src {
+		if dstHash, ok := dst[p]; !ok {
+			changes = append(changes, &Change{Path: p, Type: Added})
+		} else if dstHash != srcHash {
+			changes = append(changes, &Change{Path: p, Type: Modified})
+		}
+	}
+	if del {
+		for p := range dst {
+			if _, ok := src[p]; !ok {
+				changes = append(changes, &Change{Path: p, Type: Deleted})
+			}
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// Upload makes the directory 'dir' on 'branch' match the local directory
+// 'root', by uploading only the files whose content differs, in a single new
+// commit. Files under 'dir' that aren't under 'root' are deleted if 'del' is
+// set. If 'dryRun' is set, Upload only returns the changes it would make. No
+// commit is created if there are no changes.
+func Upload(client *pachclient.APIClient, root, repo, branch, dir string, del, dryRun bool, concurrency int) (_ []*Change, retErr error) {
+	local, err := localFiles(root, concurrency)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := remoteFiles(client, repo, branch, dir)
+	if err != nil {
+		return nil, err
+	}
+	changes := diffFiles(local, remote, del)
+	if dryRun || len(changes) == 0 {
+		return changes, nil
+	}
+
+	commit, err := client.StartCommit(repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if retErr != nil {
+			// Don't leave a partial upload in the branch
+			client.DeleteCommit(repo, commit.ID)
+			return
+		}
+		retErr = client.FinishCommit(repo, commit.ID)
+	}()
+	limiter := limit.New(concurrency)
+	var eg errgroup.Group
+	for _, change := range changes {
+		change := change
+		pfsPath := path.Join(dir, change.Path)
+		eg.Go(func() (retErr error) {
+			limiter.Acquire()
+			defer limiter.Release()
+			if change.Type == Deleted {
+				return client.DeleteFile(repo, commit.ID, pfsPath)
+			}
+			f, err := os.Open(filepath.Join(root, filepath.FromSlash(change.Path)))
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if err := f.Close(); err != nil && retErr == nil {
+					retErr = err
+				}
+			}()
+			_, err = client.PutFileOverwrite(repo, commit.ID, pfsPath, f, 0)
+			return err
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// Download makes the local directory 'root' match the directory 'dir' in
+// 'commit', by downloading only the files whose content differs. Files under
+// 'root' that aren't under 'dir' are deleted if 'del' is set. If 'dryRun' is
+// set, Download only returns the changes it would make.
+func Download(client *pachclient.APIClient, root, repo, commit, dir string, del, dryRun bool, concurrency int) ([]*Change, error) {
+	// Resolve 'commit', in case it's a branch that moves during the download.
+	// A branch with no commits is left for remoteFiles to treat as empty.
+	commitInfo, err := client.InspectCommit(repo, commit)
+	if err != nil && !pfsserver.IsNoHeadErr(err) {
+		return nil, err
+	}
+	if commitInfo != nil {
+		commit = commitInfo.Commit.ID
+	}
+	local, err := localFiles(root, concurrency)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := remoteFiles(client, repo, commit, dir)
+	if err != nil {
+		return nil, err
+	}
+	changes := diffFiles(remote, local, del)
+	if dryRun {
+		return changes, nil
+	}
+
+	puller := NewPuller()
+	limiter := limit.New(concurrency)
+	var eg errgroup.Group
+	for _, change := range changes {
+		change := change
+		localPath := filepath.Join(root, filepath.FromSlash(change.Path))
+		eg.Go(func() error {
+			limiter.Acquire()
+			defer limiter.Release()
+			if change.Type == Deleted {
+				return os.Remove(localPath)
+			}
+			return puller.makeFile(localPath, func(w io.Writer) error {
+				return client.GetFile(repo, commit, path.Join(dir, change.Path), 0, 0, w)
+			})
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
@@ -0,0 +1,165 @@
+package sync
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/server/pkg/testpachd"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	for file, content := range files {
+		p := filepath.Join(root, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, ioutil.WriteFile(p, []byte(content), 0644))
+	}
+}
+
+func changeList(changes []*Change) []string {
+	var result []string
+	for _, change := range changes {
+		result = append(result, change.Type.String()+" "+change.Path)
+	}
+	return result
+}
+
+func TestFileHash(t *testing.T) {
+	require.NoError(t, testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		require.NoError(t, env.PachClient.CreateRepo("repo"))
+		for _, content := range []string{"", "foo\n"} {
+			_, err := env.PachClient.PutFile("repo", "master", "file", strings.NewReader(content))
+			require.NoError(t, err)
+			fileInfo, err := env.PachClient.InspectFile("repo", "master", "file")
+			require.NoError(t, err)
+			hash, err := FileHash(strings.NewReader(content))
+			require.NoError(t, err)
+			require.Equal(t, fileInfo.Hash, hash)
+			require.NoError(t, env.PachClient.DeleteFile("repo", "master", "file"))
+		}
+		return nil
+	}))
+}
+
+// patternReader returns 'n' bytes of a repeating pattern, so that large files
+// don't have to be held in memory
+func patternReader(n int64) io.Reader {
+	return io.LimitReader(&repeatReader{pattern: []byte("0123456789abcdef\n")}, n)
+}
+
+type repeatReader struct {
+	pattern []byte
+	off     int
+}
+
+func (r *repeatReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = r.pattern[r.off]
+		r.off = (r.off + 1) % len(r.pattern)
+	}
+	return len(p), nil
+}
+
+func TestFileHashMultipleChunks(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+	require.NoError(t, testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		require.NoError(t, env.PachClient.CreateRepo("repo"))
+		// PutFile splits files into pfs.ChunkSize objects, so these are stored as
+		// 2 objects, the second of which is empty for the first file
+		for _, size := range []int64{pfs.ChunkSize, pfs.ChunkSize + 1} {
+			_, err := env.PachClient.PutFileOverwrite("repo", "master", "file", patternReader(size), 0)
+			require.NoError(t, err)
+			fileInfo, err := env.PachClient.InspectFile("repo", "master", "file")
+			require.NoError(t, err)
+			require.Equal(t, uint64(size), fileInfo.SizeBytes)
+			require.Equal(t, 2, len(fileInfo.Objects))
+			hash, err := FileHash(patternReader(size))
+			require.NoError(t, err)
+			require.Equal(t, fileInfo.Hash, hash)
+		}
+		return nil
+	}))
+}
+
+func TestUploadDownload(t *testing.T) {
+	require.NoError(t, testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		c := env.PachClient
+		require.NoError(t, c.CreateRepo("repo"))
+		src := filepath.Join(env.Directory, "src")
+		writeFiles(t, src, map[string]string{
+			"a":     "a\n",
+			"dir/b": "b\n",
+			"dir/c": "c\n",
+		})
+
+		// The first upload adds everything, under 'dir'
+		changes, err := Upload(c, src, "repo", "master", "data", false, false, 10)
+		require.NoError(t, err)
+		require.Equal(t, []string{"added a", "added dir/b", "added dir/c"}, changeList(changes))
+		commitInfos, err := c.ListCommit("repo", "master", "", 0)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(commitInfos))
+
+		// Nothing changed, so no commit is made
+		changes, err = Upload(c, src, "repo", "master", "data", true, false, 10)
+		require.NoError(t, err)
+		require.Equal(t, 0, len(changes))
+		commitInfos, err = c.ListCommit("repo", "master", "", 0)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(commitInfos))
+
+		// Only changed files are uploaded, and removals are only propagated with
+		// 'del'
+		writeFiles(t, src, map[string]string{"dir/b": "B\n", "d": "d\n"})
+		require.NoError(t, os.Remove(filepath.Join(src, "dir/c")))
+		changes, err = Upload(c, src, "repo", "master", "data", false, true, 10)
+		require.NoError(t, err)
+		require.Equal(t, []string{"added d", "modified dir/b"}, changeList(changes))
+		changes, err = Upload(c, src, "repo", "master", "data", true, false, 10)
+		require.NoError(t, err)
+		require.Equal(t, []string{"added d", "modified dir/b", "deleted dir/c"}, changeList(changes))
+		var buf strings.Builder
+		require.NoError(t, c.GetFile("repo", "master", "data/dir/b", 0, 0, &buf))
+		require.Equal(t, "B\n", buf.String())
+		_, err = c.InspectFile("repo", "master", "data/dir/c")
+		require.YesError(t, err)
+
+		// Downloading into a stale copy only transfers what's changed
+		dst := filepath.Join(env.Directory, "dst")
+		writeFiles(t, dst, map[string]string{
+			"a":     "a\n",
+			"dir/b": "b\n",
+			"extra": "extra\n",
+		})
+		changes, err = Download(c, dst, "repo", "master", "data", true, true, 10)
+		require.NoError(t, err)
+		require.Equal(t, []string{"added d", "modified dir/b", "deleted extra"}, changeList(changes))
+		changes, err = Download(c, dst, "repo", "master", "data", true, false, 10)
+		require.NoError(t, err)
+		require.Equal(t, 3, len(changes))
+		data, err := ioutil.ReadFile(filepath.Join(dst, "dir/b"))
+		require.NoError(t, err)
+		require.Equal(t, "B\n", string(data))
+		_, err = os.Stat(filepath.Join(dst, "extra"))
+		require.True(t, os.IsNotExist(err))
+		changes, err = Download(c, dst, "repo", "master", "data", true, false, 10)
+		require.NoError(t, err)
+		require.Equal(t, 0, len(changes))
+
+		// A branch with no commits is empty, but other errors are returned
+		require.NoError(t, c.CreateBranch("repo", "empty", "", nil))
+		changes, err = Download(c, dst, "repo", "empty", "data", true, true, 10)
+		require.NoError(t, err)
+		require.Equal(t, []string{"deleted a", "deleted d", "deleted dir/b"}, changeList(changes))
+		_, err = Download(c, dst, "repo", "master~5", "data", true, true, 10)
+		require.YesError(t, err)
+		return nil
+	}))
+}
@@ -119,6 +119,7 @@ type modifyMembersFunc func(context.Context, *auth.ModifyMembersRequest) (*auth.
 type getGroupsFunc func(context.Context, *auth.GetGroupsRequest) (*auth.GetGroupsResponse, error)
 type getUsersFunc func(context.Context, *auth.GetUsersRequest) (*auth.GetUsersResponse, error)
 type getOneTimePasswordFunc func(context.Context, *auth.GetOneTimePasswordRequest) (*auth.GetOneTimePasswordResponse, error)
+type listAuditEventsFunc func(context.Context, *auth.ListAuditEventsRequest) (*auth.ListAuditEventsResponse, error)
 
 type mockActivateAuth struct{ handler activateAuthFunc }
 type mockDeactivateAuth struct{ handler deactivateAuthFunc }
@@ -141,6 +142,7 @@ type mockModifyMembers struct{ handler modifyMembersFunc }
 type mockGetGroups struct{ handler getGroupsFunc }
 type mockGetUsers struct{ handler getUsersFunc }
 type mockGetOneTimePassword struct{ handler getOneTimePasswordFunc }
+type mockListAuditEvents struct{ handler listAuditEventsFunc }
 
 func (mock *mockActivateAuth) Use(cb activateAuthFunc)             { mock.handler = cb }
 func (mock *mockDeactivateAuth) Use(cb deactivateAuthFunc)         { mock.handler = cb }
@@ -163,6 +165,7 @@ func (mock *mockModifyMembers) Use(cb modifyMembersFunc)           { mock.handle
 func (mock *mockGetGroups) Use(cb getGroupsFunc)                   { mock.handler = cb }
 func (mock *mockGetUsers) Use(cb getUsersFunc)                     { mock.handler = cb }
 func (mock *mockGetOneTimePassword) Use(cb getOneTimePasswordFunc) { mock.handler = cb }
+func (mock *mockListAuditEvents) Use(cb listAuditEventsFunc)       { mock.handler = cb }
 
 type authServerAPI struct {
 	mock *mockAuthServer
@@ -191,6 +194,7 @@ type mockAuthServer struct {
 	GetGroups          mockGetGroups
 	GetUsers           mockGetUsers
 	GetOneTimePassword mockGetOneTimePassword
+	ListAuditEvents    mockListAuditEvents
 }
 
 func (api *authServerAPI) Activate(ctx context.Context, req *auth.ActivateRequest) (*auth.ActivateResponse, error) {
@@ -319,6 +323,12 @@ func (api *authServerAPI) GetOneTimePassword(ctx context.Context, req *auth.GetO
 	}
 	return nil, errors.Errorf("unhandled pachd mock auth.GetOneTimePassword")
 }
+func (api *authServerAPI) ListAuditEvents(ctx context.Context, req *auth.ListAuditEventsRequest) (*auth.ListAuditEventsResponse, error) {
+	if api.mock.ListAuditEvents.handler != nil {
+		return api.mock.ListAuditEvents.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock auth.ListAuditEvents")
+}
 
 /* Enterprise Server Mocks */
 
@@ -370,6 +380,7 @@ type createRepoFunc func(context.Context, *pfs.CreateRepoRequest) (*types.Empty,
 type inspectRepoFunc func(context.Context, *pfs.InspectRepoRequest) (*pfs.RepoInfo, error)
 type listRepoFunc func(context.Context, *pfs.ListRepoRequest) (*pfs.ListRepoResponse, error)
 type deleteRepoFunc func(context.Context, *pfs.DeleteRepoRequest) (*types.Empty, error)
+type labelRepoFunc func(context.Context, *pfs.LabelRepoRequest) (*types.Empty, error)
 type startCommitFunc func(context.Context, *pfs.StartCommitRequest) (*pfs.Commit, error)
 type finishCommitFunc func(context.Context, *pfs.FinishCommitRequest) (*types.Empty, error)
 type inspectCommitFunc func(context.Context, *pfs.InspectCommitRequest) (*pfs.CommitInfo, error)
@@ -379,10 +390,15 @@ type deleteCommitFunc func(context.Context, *pfs.DeleteCommitRequest) (*types.Em
 type flushCommitFunc func(*pfs.FlushCommitRequest, pfs.API_FlushCommitServer) error
 type subscribeCommitFunc func(*pfs.SubscribeCommitRequest, pfs.API_SubscribeCommitServer) error
 type buildCommitFunc func(context.Context, *pfs.BuildCommitRequest) (*pfs.Commit, error)
+type labelCommitFunc func(context.Context, *pfs.LabelCommitRequest) (*types.Empty, error)
 type createBranchFunc func(context.Context, *pfs.CreateBranchRequest) (*types.Empty, error)
 type inspectBranchFunc func(context.Context, *pfs.InspectBranchRequest) (*pfs.BranchInfo, error)
 type listBranchFunc func(context.Context, *pfs.ListBranchRequest) (*pfs.BranchInfos, error)
 type deleteBranchFunc func(context.Context, *pfs.DeleteBranchRequest) (*types.Empty, error)
+type mergeBranchFunc func(context.Context, *pfs.MergeBranchRequest) (*pfs.MergeBranchResponse, error)
+type createSnapshotFunc func(context.Context, *pfs.CreateSnapshotRequest) (*pfs.SnapshotInfo, error)
+type inspectSnapshotFunc func(context.Context, *pfs.InspectSnapshotRequest) (*pfs.SnapshotInfo, error)
+type restoreBranchesFromSnapshotFunc func(context.Context, *pfs.RestoreBranchesFromSnapshotRequest) (*types.Empty, error)
 type putFileFunc func(pfs.API_PutFileServer) error
 type copyFileFunc func(context.Context, *pfs.CopyFileRequest) (*types.Empty, error)
 type getFileFunc func(*pfs.GetFileRequest, pfs.API_GetFileServer) error
@@ -393,9 +409,12 @@ type walkFileFunc func(*pfs.WalkFileRequest, pfs.API_WalkFileServer) error
 type globFileFunc func(context.Context, *pfs.GlobFileRequest) (*pfs.FileInfos, error)
 type globFileStreamFunc func(*pfs.GlobFileRequest, pfs.API_GlobFileStreamServer) error
 type diffFileFunc func(context.Context, *pfs.DiffFileRequest) (*pfs.DiffFileResponse, error)
+type diffFileContentFunc func(*pfs.DiffFileRequest, pfs.API_DiffFileContentServer) error
 type deleteFileFunc func(context.Context, *pfs.DeleteFileRequest) (*types.Empty, error)
 type deleteAllPFSFunc func(context.Context, *types.Empty) (*types.Empty, error)
 type fsckFunc func(*pfs.FsckRequest, pfs.API_FsckServer) error
+type inspectReplicationFunc func(context.Context, *types.Empty) (*pfs.ReplicationInfo, error)
+type reconcileReplicationFunc func(context.Context, *types.Empty) (*pfs.ReplicationInfo, error)
 type putTarFunc func(pfs.API_PutTarServer) error
 type getTarFunc func(*pfs.GetTarRequest, pfs.API_GetTarServer) error
 type getTarConditionalFunc func(pfs.API_GetTarConditionalServer) error
@@ -404,6 +423,7 @@ type mockCreateRepo struct{ handler createRepoFunc }
 type mockInspectRepo struct{ handler inspectRepoFunc }
 type mockListRepo struct{ handler listRepoFunc }
 type mockDeleteRepo struct{ handler deleteRepoFunc }
+type mockLabelRepo struct{ handler labelRepoFunc }
 type mockStartCommit struct{ handler startCommitFunc }
 type mockFinishCommit struct{ handler finishCommitFunc }
 type mockInspectCommit struct{ handler inspectCommitFunc }
@@ -413,10 +433,17 @@ type mockDeleteCommit struct{ handler deleteCommitFunc }
 type mockFlushCommit struct{ handler flushCommitFunc }
 type mockSubscribeCommit struct{ handler subscribeCommitFunc }
 type mockBuildCommit struct{ handler buildCommitFunc }
+type mockLabelCommit struct{ handler labelCommitFunc }
 type mockCreateBranch struct{ handler createBranchFunc }
 type mockInspectBranch struct{ handler inspectBranchFunc }
 type mockListBranch struct{ handler listBranchFunc }
 type mockDeleteBranch struct{ handler deleteBranchFunc }
+type mockMergeBranch struct{ handler mergeBranchFunc }
+type mockCreateSnapshot struct{ handler createSnapshotFunc }
+type mockInspectSnapshot struct{ handler inspectSnapshotFunc }
+type mockRestoreBranchesFromSnapshot struct {
+	handler restoreBranchesFromSnapshotFunc
+}
 type mockPutFile struct{ handler putFileFunc }
 type mockCopyFile struct{ handler copyFileFunc }
 type mockGetFile struct{ handler getFileFunc }
@@ -427,86 +454,109 @@ type mockWalkFile struct{ handler walkFileFunc }
 type mockGlobFile struct{ handler globFileFunc }
 type mockGlobFileStream struct{ handler globFileStreamFunc }
 type mockDiffFile struct{ handler diffFileFunc }
+type mockDiffFileContent struct{ handler diffFileContentFunc }
 type mockDeleteFile struct{ handler deleteFileFunc }
 type mockDeleteAllPFS struct{ handler deleteAllPFSFunc }
 type mockFsck struct{ handler fsckFunc }
+type mockInspectReplication struct{ handler inspectReplicationFunc }
+type mockReconcileReplication struct{ handler reconcileReplicationFunc }
 type mockPutTar struct{ handler putTarFunc }
 type mockGetTar struct{ handler getTarFunc }
 type mockGetTarConditional struct{ handler getTarConditionalFunc }
 
-func (mock *mockCreateRepo) Use(cb createRepoFunc)               { mock.handler = cb }
-func (mock *mockInspectRepo) Use(cb inspectRepoFunc)             { mock.handler = cb }
-func (mock *mockListRepo) Use(cb listRepoFunc)                   { mock.handler = cb }
-func (mock *mockDeleteRepo) Use(cb deleteRepoFunc)               { mock.handler = cb }
-func (mock *mockStartCommit) Use(cb startCommitFunc)             { mock.handler = cb }
-func (mock *mockFinishCommit) Use(cb finishCommitFunc)           { mock.handler = cb }
-func (mock *mockInspectCommit) Use(cb inspectCommitFunc)         { mock.handler = cb }
-func (mock *mockListCommit) Use(cb listCommitFunc)               { mock.handler = cb }
-func (mock *mockListCommitStream) Use(cb listCommitStreamFunc)   { mock.handler = cb }
-func (mock *mockDeleteCommit) Use(cb deleteCommitFunc)           { mock.handler = cb }
-func (mock *mockFlushCommit) Use(cb flushCommitFunc)             { mock.handler = cb }
-func (mock *mockSubscribeCommit) Use(cb subscribeCommitFunc)     { mock.handler = cb }
-func (mock *mockBuildCommit) Use(cb buildCommitFunc)             { mock.handler = cb }
-func (mock *mockCreateBranch) Use(cb createBranchFunc)           { mock.handler = cb }
-func (mock *mockInspectBranch) Use(cb inspectBranchFunc)         { mock.handler = cb }
-func (mock *mockListBranch) Use(cb listBranchFunc)               { mock.handler = cb }
-func (mock *mockDeleteBranch) Use(cb deleteBranchFunc)           { mock.handler = cb }
-func (mock *mockPutFile) Use(cb putFileFunc)                     { mock.handler = cb }
-func (mock *mockCopyFile) Use(cb copyFileFunc)                   { mock.handler = cb }
-func (mock *mockGetFile) Use(cb getFileFunc)                     { mock.handler = cb }
-func (mock *mockInspectFile) Use(cb inspectFileFunc)             { mock.handler = cb }
-func (mock *mockListFile) Use(cb listFileFunc)                   { mock.handler = cb }
-func (mock *mockListFileStream) Use(cb listFileStreamFunc)       { mock.handler = cb }
-func (mock *mockWalkFile) Use(cb walkFileFunc)                   { mock.handler = cb }
-func (mock *mockGlobFile) Use(cb globFileFunc)                   { mock.handler = cb }
-func (mock *mockGlobFileStream) Use(cb globFileStreamFunc)       { mock.handler = cb }
-func (mock *mockDiffFile) Use(cb diffFileFunc)                   { mock.handler = cb }
-func (mock *mockDeleteFile) Use(cb deleteFileFunc)               { mock.handler = cb }
-func (mock *mockDeleteAllPFS) Use(cb deleteAllPFSFunc)           { mock.handler = cb }
-func (mock *mockFsck) Use(cb fsckFunc)                           { mock.handler = cb }
-func (mock *mockPutTar) Use(cb putTarFunc)                       { mock.handler = cb }
-func (mock *mockGetTar) Use(cb getTarFunc)                       { mock.handler = cb }
-func (mock *mockGetTarConditional) Use(cb getTarConditionalFunc) { mock.handler = cb }
+func (mock *mockCreateRepo) Use(cb createRepoFunc)             { mock.handler = cb }
+func (mock *mockInspectRepo) Use(cb inspectRepoFunc)           { mock.handler = cb }
+func (mock *mockListRepo) Use(cb listRepoFunc)                 { mock.handler = cb }
+func (mock *mockDeleteRepo) Use(cb deleteRepoFunc)             { mock.handler = cb }
+func (mock *mockLabelRepo) Use(cb labelRepoFunc)               { mock.handler = cb }
+func (mock *mockStartCommit) Use(cb startCommitFunc)           { mock.handler = cb }
+func (mock *mockFinishCommit) Use(cb finishCommitFunc)         { mock.handler = cb }
+func (mock *mockInspectCommit) Use(cb inspectCommitFunc)       { mock.handler = cb }
+func (mock *mockListCommit) Use(cb listCommitFunc)             { mock.handler = cb }
+func (mock *mockListCommitStream) Use(cb listCommitStreamFunc) { mock.handler = cb }
+func (mock *mockDeleteCommit) Use(cb deleteCommitFunc)         { mock.handler = cb }
+func (mock *mockFlushCommit) Use(cb flushCommitFunc)           { mock.handler = cb }
+func (mock *mockSubscribeCommit) Use(cb subscribeCommitFunc)   { mock.handler = cb }
+func (mock *mockBuildCommit) Use(cb buildCommitFunc)           { mock.handler = cb }
+func (mock *mockLabelCommit) Use(cb labelCommitFunc)           { mock.handler = cb }
+func (mock *mockCreateBranch) Use(cb createBranchFunc)         { mock.handler = cb }
+func (mock *mockInspectBranch) Use(cb inspectBranchFunc)       { mock.handler = cb }
+func (mock *mockListBranch) Use(cb listBranchFunc)             { mock.handler = cb }
+func (mock *mockDeleteBranch) Use(cb deleteBranchFunc)         { mock.handler = cb }
+func (mock *mockMergeBranch) Use(cb mergeBranchFunc)           { mock.handler = cb }
+func (mock *mockCreateSnapshot) Use(cb createSnapshotFunc)     { mock.handler = cb }
+func (mock *mockInspectSnapshot) Use(cb inspectSnapshotFunc)   { mock.handler = cb }
+func (mock *mockRestoreBranchesFromSnapshot) Use(cb restoreBranchesFromSnapshotFunc) {
+	mock.handler = cb
+}
+func (mock *mockPutFile) Use(cb putFileFunc)                           { mock.handler = cb }
+func (mock *mockCopyFile) Use(cb copyFileFunc)                         { mock.handler = cb }
+func (mock *mockGetFile) Use(cb getFileFunc)                           { mock.handler = cb }
+func (mock *mockInspectFile) Use(cb inspectFileFunc)                   { mock.handler = cb }
+func (mock *mockListFile) Use(cb listFileFunc)                         { mock.handler = cb }
+func (mock *mockListFileStream) Use(cb listFileStreamFunc)             { mock.handler = cb }
+func (mock *mockWalkFile) Use(cb walkFileFunc)                         { mock.handler = cb }
+func (mock *mockGlobFile) Use(cb globFileFunc)                         { mock.handler = cb }
+func (mock *mockGlobFileStream) Use(cb globFileStreamFunc)             { mock.handler = cb }
+func (mock *mockDiffFile) Use(cb diffFileFunc)                         { mock.handler = cb }
+func (mock *mockDiffFileContent) Use(cb diffFileContentFunc)           { mock.handler = cb }
+func (mock *mockDeleteFile) Use(cb deleteFileFunc)                     { mock.handler = cb }
+func (mock *mockDeleteAllPFS) Use(cb deleteAllPFSFunc)                 { mock.handler = cb }
+func (mock *mockFsck) Use(cb fsckFunc)                                 { mock.handler = cb }
+func (mock *mockInspectReplication) Use(cb inspectReplicationFunc)     { mock.handler = cb }
+func (mock *mockReconcileReplication) Use(cb reconcileReplicationFunc) { mock.handler = cb }
+func (mock *mockPutTar) Use(cb putTarFunc)                             { mock.handler = cb }
+func (mock *mockGetTar) Use(cb getTarFunc)                             { mock.handler = cb }
+func (mock *mockGetTarConditional) Use(cb getTarConditionalFunc)       { mock.handler = cb }
 
 type pfsServerAPI struct {
 	mock *mockPFSServer
 }
 
 type mockPFSServer struct {
-	api               pfsServerAPI
-	CreateRepo        mockCreateRepo
-	InspectRepo       mockInspectRepo
-	ListRepo          mockListRepo
-	DeleteRepo        mockDeleteRepo
-	StartCommit       mockStartCommit
-	FinishCommit      mockFinishCommit
-	InspectCommit     mockInspectCommit
-	ListCommit        mockListCommit
-	ListCommitStream  mockListCommitStream
-	DeleteCommit      mockDeleteCommit
-	FlushCommit       mockFlushCommit
-	SubscribeCommit   mockSubscribeCommit
-	BuildCommit       mockBuildCommit
-	CreateBranch      mockCreateBranch
-	InspectBranch     mockInspectBranch
-	ListBranch        mockListBranch
-	DeleteBranch      mockDeleteBranch
-	PutFile           mockPutFile
-	CopyFile          mockCopyFile
-	GetFile           mockGetFile
-	InspectFile       mockInspectFile
-	ListFile          mockListFile
-	ListFileStream    mockListFileStream
-	WalkFile          mockWalkFile
-	GlobFile          mockGlobFile
-	GlobFileStream    mockGlobFileStream
-	DiffFile          mockDiffFile
-	DeleteFile        mockDeleteFile
-	DeleteAll         mockDeleteAllPFS
-	Fsck              mockFsck
-	PutTar            mockPutTar
-	GetTar            mockGetTar
-	GetTarConditional mockGetTarConditional
+	api                         pfsServerAPI
+	CreateRepo                  mockCreateRepo
+	InspectRepo                 mockInspectRepo
+	ListRepo                    mockListRepo
+	DeleteRepo                  mockDeleteRepo
+	LabelRepo                   mockLabelRepo
+	StartCommit                 mockStartCommit
+	FinishCommit                mockFinishCommit
+	InspectCommit               mockInspectCommit
+	ListCommit                  mockListCommit
+	ListCommitStream            mockListCommitStream
+	DeleteCommit                mockDeleteCommit
+	FlushCommit                 mockFlushCommit
+	SubscribeCommit             mockSubscribeCommit
+	BuildCommit                 mockBuildCommit
+	LabelCommit                 mockLabelCommit
+	CreateBranch                mockCreateBranch
+	InspectBranch               mockInspectBranch
+	ListBranch                  mockListBranch
+	DeleteBranch                mockDeleteBranch
+	MergeBranch                 mockMergeBranch
+	CreateSnapshot              mockCreateSnapshot
+	InspectSnapshot             mockInspectSnapshot
+	RestoreBranchesFromSnapshot mockRestoreBranchesFromSnapshot
+	PutFile                     mockPutFile
+	CopyFile                    mockCopyFile
+	GetFile                     mockGetFile
+	InspectFile                 mockInspectFile
+	ListFile                    mockListFile
+	ListFileStream              mockListFileStream
+	WalkFile                    mockWalkFile
+	GlobFile                    mockGlobFile
+	GlobFileStream              mockGlobFileStream
+	DiffFile                    mockDiffFile
+	DiffFileContent             mockDiffFileContent
+	DeleteFile                  mockDeleteFile
+	DeleteAll                   mockDeleteAllPFS
+	Fsck                        mockFsck
+	InspectReplication          mockInspectReplication
+	ReconcileReplication        mockReconcileReplication
+	PutTar                      mockPutTar
+	GetTar                      mockGetTar
+	GetTarConditional           mockGetTarConditional
 }
 
 func (api *pfsServerAPI) CreateRepo(ctx context.Context, req *pfs.CreateRepoRequest) (*types.Empty, error) {
@@ -533,6 +583,12 @@ func (api *pfsServerAPI) DeleteRepo(ctx context.Context, req *pfs.DeleteRepoRequ
 	}
 	return nil, errors.Errorf("unhandled pachd mock pfs.DeleteRepo")
 }
+func (api *pfsServerAPI) LabelRepo(ctx context.Context, req *pfs.LabelRepoRequest) (*types.Empty, error) {
+	if api.mock.LabelRepo.handler != nil {
+		return api.mock.LabelRepo.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.LabelRepo")
+}
 func (api *pfsServerAPI) StartCommit(ctx context.Context, req *pfs.StartCommitRequest) (*pfs.Commit, error) {
 	if api.mock.StartCommit.handler != nil {
 		return api.mock.StartCommit.handler(ctx, req)
@@ -587,6 +643,12 @@ func (api *pfsServerAPI) BuildCommit(ctx context.Context, req *pfs.BuildCommitRe
 	}
 	return nil, errors.Errorf("unhandled pachd mock pfs.BuildCommit")
 }
+func (api *pfsServerAPI) LabelCommit(ctx context.Context, req *pfs.LabelCommitRequest) (*types.Empty, error) {
+	if api.mock.LabelCommit.handler != nil {
+		return api.mock.LabelCommit.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.LabelCommit")
+}
 func (api *pfsServerAPI) CreateBranch(ctx context.Context, req *pfs.CreateBranchRequest) (*types.Empty, error) {
 	if api.mock.CreateBranch.handler != nil {
 		return api.mock.CreateBranch.handler(ctx, req)
@@ -611,6 +673,30 @@ func (api *pfsServerAPI) DeleteBranch(ctx context.Context, req *pfs.DeleteBranch
 	}
 	return nil, errors.Errorf("unhandled pachd mock pfs.DeleteBranch")
 }
+func (api *pfsServerAPI) MergeBranch(ctx context.Context, req *pfs.MergeBranchRequest) (*pfs.MergeBranchResponse, error) {
+	if api.mock.MergeBranch.handler != nil {
+		return api.mock.MergeBranch.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.MergeBranch")
+}
+func (api *pfsServerAPI) CreateSnapshot(ctx context.Context, req *pfs.CreateSnapshotRequest) (*pfs.SnapshotInfo, error) {
+	if api.mock.CreateSnapshot.handler != nil {
+		return api.mock.CreateSnapshot.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.CreateSnapshot")
+}
+func (api *pfsServerAPI) InspectSnapshot(ctx context.Context, req *pfs.InspectSnapshotRequest) (*pfs.SnapshotInfo, error) {
+	if api.mock.InspectSnapshot.handler != nil {
+		return api.mock.InspectSnapshot.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.InspectSnapshot")
+}
+func (api *pfsServerAPI) RestoreBranchesFromSnapshot(ctx context.Context, req *pfs.RestoreBranchesFromSnapshotRequest) (*types.Empty, error) {
+	if api.mock.RestoreBranchesFromSnapshot.handler != nil {
+		return api.mock.RestoreBranchesFromSnapshot.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.RestoreBranchesFromSnapshot")
+}
 func (api *pfsServerAPI) PutFile(serv pfs.API_PutFileServer) error {
 	if api.mock.PutFile.handler != nil {
 		return api.mock.PutFile.handler(serv)
@@ -671,6 +757,12 @@ func (api *pfsServerAPI) DiffFile(ctx context.Context, req *pfs.DiffFileRequest)
 	}
 	return nil, errors.Errorf("unhandled pachd mock pfs.DiffFile")
 }
+func (api *pfsServerAPI) DiffFileContent(req *pfs.DiffFileRequest, serv pfs.API_DiffFileContentServer) error {
+	if api.mock.DiffFileContent.handler != nil {
+		return api.mock.DiffFileContent.handler(req, serv)
+	}
+	return errors.Errorf("unhandled pachd mock pfs.DiffFileContent")
+}
 func (api *pfsServerAPI) DeleteFile(ctx context.Context, req *pfs.DeleteFileRequest) (*types.Empty, error) {
 	if api.mock.DeleteFile.handler != nil {
 		return api.mock.DeleteFile.handler(ctx, req)
@@ -689,6 +781,18 @@ func (api *pfsServerAPI) Fsck(req *pfs.FsckRequest, serv pfs.API_FsckServer) err
 	}
 	return errors.Errorf("unhandled pachd mock pfs.Fsck")
 }
+func (api *pfsServerAPI) InspectReplication(ctx context.Context, req *types.Empty) (*pfs.ReplicationInfo, error) {
+	if api.mock.InspectReplication.handler != nil {
+		return api.mock.InspectReplication.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.InspectReplication")
+}
+func (api *pfsServerAPI) ReconcileReplication(ctx context.Context, req *types.Empty) (*pfs.ReplicationInfo, error) {
+	if api.mock.ReconcileReplication.handler != nil {
+		return api.mock.ReconcileReplication.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pfs.ReconcileReplication")
+}
 func (api *pfsServerAPI) PutTar(serv pfs.API_PutTarServer) error {
 	if api.mock.PutTar.handler != nil {
 		return api.mock.PutTar.handler(serv)
@@ -723,16 +827,30 @@ type listDatumFunc func(context.Context, *pps.ListDatumRequest) (*pps.ListDatumR
 type listDatumStreamFunc func(*pps.ListDatumRequest, pps.API_ListDatumStreamServer) error
 type restartDatumFunc func(context.Context, *pps.RestartDatumRequest) (*types.Empty, error)
 type createPipelineFunc func(context.Context, *pps.CreatePipelineRequest) (*types.Empty, error)
+type dryRunPipelineFunc func(context.Context, *pps.CreatePipelineRequest) (*pps.DryRunPipelineResponse, error)
 type inspectPipelineFunc func(context.Context, *pps.InspectPipelineRequest) (*pps.PipelineInfo, error)
 type listPipelineFunc func(context.Context, *pps.ListPipelineRequest) (*pps.PipelineInfos, error)
 type deletePipelineFunc func(context.Context, *pps.DeletePipelineRequest) (*types.Empty, error)
 type startPipelineFunc func(context.Context, *pps.StartPipelineRequest) (*types.Empty, error)
 type stopPipelineFunc func(context.Context, *pps.StopPipelineRequest) (*types.Empty, error)
+type rollbackPipelineFunc func(context.Context, *pps.RollbackPipelineRequest) (*types.Empty, error)
+type inspectShadowFunc func(context.Context, *pps.InspectShadowRequest) (*pps.ShadowReport, error)
+type promoteShadowFunc func(context.Context, *pps.PromoteShadowRequest) (*types.Empty, error)
+type getUsageFunc func(context.Context, *pps.GetUsageRequest) (*pps.UsageInfos, error)
+type listDeadLetterFunc func(context.Context, *pps.ListDeadLetterRequest) (*pps.DeadLetters, error)
+type deleteNotificationSinkFunc func(context.Context, *pps.DeleteNotificationSinkRequest) (*types.Empty, error)
+type listNotificationSinkFunc func(context.Context, *types.Empty) (*pps.NotificationSinkInfos, error)
+type inspectNotificationSinkFunc func(context.Context, *pps.InspectNotificationSinkRequest) (*pps.NotificationSinkInfo, error)
+type createNotificationSinkFunc func(context.Context, *pps.CreateNotificationSinkRequest) (*types.Empty, error)
 type runPipelineFunc func(context.Context, *pps.RunPipelineRequest) (*types.Empty, error)
 type runCronFunc func(context.Context, *pps.RunCronRequest) (*types.Empty, error)
 type createSecretFunc func(context.Context, *pps.CreateSecretRequest) (*types.Empty, error)
 type deleteSecretFunc func(context.Context, *pps.DeleteSecretRequest) (*types.Empty, error)
 type inspectSecretFunc func(context.Context, *pps.InspectSecretRequest) (*pps.SecretInfo, error)
+type deletePipelineTemplateFunc func(context.Context, *pps.DeletePipelineTemplateRequest) (*types.Empty, error)
+type listPipelineTemplateFunc func(context.Context, *types.Empty) (*pps.PipelineTemplateInfos, error)
+type inspectPipelineTemplateFunc func(context.Context, *pps.InspectPipelineTemplateRequest) (*pps.PipelineTemplateInfo, error)
+type createPipelineTemplateFunc func(context.Context, *pps.CreatePipelineTemplateRequest) (*types.Empty, error)
 type listSecretFunc func(context.Context, *types.Empty) (*pps.SecretInfos, error)
 type deleteAllPPSFunc func(context.Context, *types.Empty) (*types.Empty, error)
 type getLogsFunc func(*pps.GetLogsRequest, pps.API_GetLogsServer) error
@@ -752,85 +870,127 @@ type mockListDatum struct{ handler listDatumFunc }
 type mockListDatumStream struct{ handler listDatumStreamFunc }
 type mockRestartDatum struct{ handler restartDatumFunc }
 type mockCreatePipeline struct{ handler createPipelineFunc }
+type mockDryRunPipeline struct{ handler dryRunPipelineFunc }
 type mockInspectPipeline struct{ handler inspectPipelineFunc }
 type mockListPipeline struct{ handler listPipelineFunc }
 type mockDeletePipeline struct{ handler deletePipelineFunc }
 type mockStartPipeline struct{ handler startPipelineFunc }
 type mockStopPipeline struct{ handler stopPipelineFunc }
+type mockRollbackPipeline struct{ handler rollbackPipelineFunc }
+type mockInspectShadow struct{ handler inspectShadowFunc }
+type mockPromoteShadow struct{ handler promoteShadowFunc }
+type mockGetUsage struct{ handler getUsageFunc }
+type mockListDeadLetter struct{ handler listDeadLetterFunc }
+type mockDeleteNotificationSink struct{ handler deleteNotificationSinkFunc }
+type mockListNotificationSink struct{ handler listNotificationSinkFunc }
+type mockInspectNotificationSink struct{ handler inspectNotificationSinkFunc }
+type mockCreateNotificationSink struct{ handler createNotificationSinkFunc }
 type mockRunPipeline struct{ handler runPipelineFunc }
 type mockRunCron struct{ handler runCronFunc }
 type mockCreateSecret struct{ handler createSecretFunc }
 type mockDeleteSecret struct{ handler deleteSecretFunc }
 type mockInspectSecret struct{ handler inspectSecretFunc }
+type mockDeletePipelineTemplate struct{ handler deletePipelineTemplateFunc }
+type mockListPipelineTemplate struct{ handler listPipelineTemplateFunc }
+type mockInspectPipelineTemplate struct{ handler inspectPipelineTemplateFunc }
+type mockCreatePipelineTemplate struct{ handler createPipelineTemplateFunc }
 type mockListSecret struct{ handler listSecretFunc }
 type mockDeleteAllPPS struct{ handler deleteAllPPSFunc }
 type mockGetLogs struct{ handler getLogsFunc }
 type mockGarbageCollect struct{ handler garbageCollectFunc }
 type mockActivateAuthPPS struct{ handler activateAuthPPSFunc }
 
-func (mock *mockCreateJob) Use(cb createJobFunc)             { mock.handler = cb }
-func (mock *mockInspectJob) Use(cb inspectJobFunc)           { mock.handler = cb }
-func (mock *mockListJob) Use(cb listJobFunc)                 { mock.handler = cb }
-func (mock *mockListJobStream) Use(cb listJobStreamFunc)     { mock.handler = cb }
-func (mock *mockFlushJob) Use(cb flushJobFunc)               { mock.handler = cb }
-func (mock *mockDeleteJob) Use(cb deleteJobFunc)             { mock.handler = cb }
-func (mock *mockStopJob) Use(cb stopJobFunc)                 { mock.handler = cb }
-func (mock *mockUpdateJobState) Use(cb updateJobStateFunc)   { mock.handler = cb }
-func (mock *mockInspectDatum) Use(cb inspectDatumFunc)       { mock.handler = cb }
-func (mock *mockListDatum) Use(cb listDatumFunc)             { mock.handler = cb }
-func (mock *mockListDatumStream) Use(cb listDatumStreamFunc) { mock.handler = cb }
-func (mock *mockRestartDatum) Use(cb restartDatumFunc)       { mock.handler = cb }
-func (mock *mockCreatePipeline) Use(cb createPipelineFunc)   { mock.handler = cb }
-func (mock *mockInspectPipeline) Use(cb inspectPipelineFunc) { mock.handler = cb }
-func (mock *mockListPipeline) Use(cb listPipelineFunc)       { mock.handler = cb }
-func (mock *mockDeletePipeline) Use(cb deletePipelineFunc)   { mock.handler = cb }
-func (mock *mockStartPipeline) Use(cb startPipelineFunc)     { mock.handler = cb }
-func (mock *mockStopPipeline) Use(cb stopPipelineFunc)       { mock.handler = cb }
-func (mock *mockRunPipeline) Use(cb runPipelineFunc)         { mock.handler = cb }
-func (mock *mockRunCron) Use(cb runCronFunc)                 { mock.handler = cb }
-func (mock *mockCreateSecret) Use(cb createSecretFunc)       { mock.handler = cb }
-func (mock *mockDeleteSecret) Use(cb deleteSecretFunc)       { mock.handler = cb }
-func (mock *mockInspectSecret) Use(cb inspectSecretFunc)     { mock.handler = cb }
-func (mock *mockListSecret) Use(cb listSecretFunc)           { mock.handler = cb }
-func (mock *mockDeleteAllPPS) Use(cb deleteAllPPSFunc)       { mock.handler = cb }
-func (mock *mockGetLogs) Use(cb getLogsFunc)                 { mock.handler = cb }
-func (mock *mockGarbageCollect) Use(cb garbageCollectFunc)   { mock.handler = cb }
-func (mock *mockActivateAuthPPS) Use(cb activateAuthPPSFunc) { mock.handler = cb }
+func (mock *mockCreateJob) Use(cb createJobFunc)                             { mock.handler = cb }
+func (mock *mockInspectJob) Use(cb inspectJobFunc)                           { mock.handler = cb }
+func (mock *mockListJob) Use(cb listJobFunc)                                 { mock.handler = cb }
+func (mock *mockListJobStream) Use(cb listJobStreamFunc)                     { mock.handler = cb }
+func (mock *mockFlushJob) Use(cb flushJobFunc)                               { mock.handler = cb }
+func (mock *mockDeleteJob) Use(cb deleteJobFunc)                             { mock.handler = cb }
+func (mock *mockStopJob) Use(cb stopJobFunc)                                 { mock.handler = cb }
+func (mock *mockUpdateJobState) Use(cb updateJobStateFunc)                   { mock.handler = cb }
+func (mock *mockInspectDatum) Use(cb inspectDatumFunc)                       { mock.handler = cb }
+func (mock *mockListDatum) Use(cb listDatumFunc)                             { mock.handler = cb }
+func (mock *mockListDatumStream) Use(cb listDatumStreamFunc)                 { mock.handler = cb }
+func (mock *mockRestartDatum) Use(cb restartDatumFunc)                       { mock.handler = cb }
+func (mock *mockCreatePipeline) Use(cb createPipelineFunc)                   { mock.handler = cb }
+func (mock *mockDryRunPipeline) Use(cb dryRunPipelineFunc)                   { mock.handler = cb }
+func (mock *mockInspectPipeline) Use(cb inspectPipelineFunc)                 { mock.handler = cb }
+func (mock *mockListPipeline) Use(cb listPipelineFunc)                       { mock.handler = cb }
+func (mock *mockDeletePipeline) Use(cb deletePipelineFunc)                   { mock.handler = cb }
+func (mock *mockStartPipeline) Use(cb startPipelineFunc)                     { mock.handler = cb }
+func (mock *mockStopPipeline) Use(cb stopPipelineFunc)                       { mock.handler = cb }
+func (mock *mockRollbackPipeline) Use(cb rollbackPipelineFunc)               { mock.handler = cb }
+func (mock *mockInspectShadow) Use(cb inspectShadowFunc)                     { mock.handler = cb }
+func (mock *mockPromoteShadow) Use(cb promoteShadowFunc)                     { mock.handler = cb }
+func (mock *mockGetUsage) Use(cb getUsageFunc)                               { mock.handler = cb }
+func (mock *mockListDeadLetter) Use(cb listDeadLetterFunc)                   { mock.handler = cb }
+func (mock *mockDeleteNotificationSink) Use(cb deleteNotificationSinkFunc)   { mock.handler = cb }
+func (mock *mockListNotificationSink) Use(cb listNotificationSinkFunc)       { mock.handler = cb }
+func (mock *mockInspectNotificationSink) Use(cb inspectNotificationSinkFunc) { mock.handler = cb }
+func (mock *mockCreateNotificationSink) Use(cb createNotificationSinkFunc)   { mock.handler = cb }
+func (mock *mockRunPipeline) Use(cb runPipelineFunc)                         { mock.handler = cb }
+func (mock *mockRunCron) Use(cb runCronFunc)                                 { mock.handler = cb }
+func (mock *mockCreateSecret) Use(cb createSecretFunc)                       { mock.handler = cb }
+func (mock *mockDeleteSecret) Use(cb deleteSecretFunc)                       { mock.handler = cb }
+func (mock *mockInspectSecret) Use(cb inspectSecretFunc)                     { mock.handler = cb }
+func (mock *mockDeletePipelineTemplate) Use(cb deletePipelineTemplateFunc)   { mock.handler = cb }
+func (mock *mockListPipelineTemplate) Use(cb listPipelineTemplateFunc)       { mock.handler = cb }
+func (mock *mockInspectPipelineTemplate) Use(cb inspectPipelineTemplateFunc) { mock.handler = cb }
+func (mock *mockCreatePipelineTemplate) Use(cb createPipelineTemplateFunc)   { mock.handler = cb }
+func (mock *mockListSecret) Use(cb listSecretFunc)                           { mock.handler = cb }
+func (mock *mockDeleteAllPPS) Use(cb deleteAllPPSFunc)                       { mock.handler = cb }
+func (mock *mockGetLogs) Use(cb getLogsFunc)                                 { mock.handler = cb }
+func (mock *mockGarbageCollect) Use(cb garbageCollectFunc)                   { mock.handler = cb }
+func (mock *mockActivateAuthPPS) Use(cb activateAuthPPSFunc)                 { mock.handler = cb }
 
 type ppsServerAPI struct {
 	mock *mockPPSServer
 }
 
 type mockPPSServer struct {
-	api             ppsServerAPI
-	CreateJob       mockCreateJob
-	InspectJob      mockInspectJob
-	ListJob         mockListJob
-	ListJobStream   mockListJobStream
-	FlushJob        mockFlushJob
-	DeleteJob       mockDeleteJob
-	StopJob         mockStopJob
-	UpdateJobState  mockUpdateJobState
-	InspectDatum    mockInspectDatum
-	ListDatum       mockListDatum
-	ListDatumStream mockListDatumStream
-	RestartDatum    mockRestartDatum
-	CreatePipeline  mockCreatePipeline
-	InspectPipeline mockInspectPipeline
-	ListPipeline    mockListPipeline
-	DeletePipeline  mockDeletePipeline
-	StartPipeline   mockStartPipeline
-	StopPipeline    mockStopPipeline
-	RunPipeline     mockRunPipeline
-	RunCron         mockRunCron
-	CreateSecret    mockCreateSecret
-	DeleteSecret    mockDeleteSecret
-	InspectSecret   mockInspectSecret
-	ListSecret      mockListSecret
-	DeleteAll       mockDeleteAllPPS
-	GetLogs         mockGetLogs
-	GarbageCollect  mockGarbageCollect
-	ActivateAuth    mockActivateAuthPPS
+	api                     ppsServerAPI
+	CreateJob               mockCreateJob
+	InspectJob              mockInspectJob
+	ListJob                 mockListJob
+	ListJobStream           mockListJobStream
+	FlushJob                mockFlushJob
+	DeleteJob               mockDeleteJob
+	StopJob                 mockStopJob
+	UpdateJobState          mockUpdateJobState
+	InspectDatum            mockInspectDatum
+	ListDatum               mockListDatum
+	ListDatumStream         mockListDatumStream
+	RestartDatum            mockRestartDatum
+	CreatePipeline          mockCreatePipeline
+	DryRunPipeline          mockDryRunPipeline
+	InspectPipeline         mockInspectPipeline
+	ListPipeline            mockListPipeline
+	DeletePipeline          mockDeletePipeline
+	StartPipeline           mockStartPipeline
+	StopPipeline            mockStopPipeline
+	RollbackPipeline        mockRollbackPipeline
+	InspectShadow           mockInspectShadow
+	PromoteShadow           mockPromoteShadow
+	GetUsage                mockGetUsage
+	ListDeadLetter          mockListDeadLetter
+	DeleteNotificationSink  mockDeleteNotificationSink
+	ListNotificationSink    mockListNotificationSink
+	InspectNotificationSink mockInspectNotificationSink
+	CreateNotificationSink  mockCreateNotificationSink
+	RunPipeline             mockRunPipeline
+	RunCron                 mockRunCron
+	CreateSecret            mockCreateSecret
+	DeleteSecret            mockDeleteSecret
+	InspectSecret           mockInspectSecret
+	DeletePipelineTemplate  mockDeletePipelineTemplate
+	ListPipelineTemplate    mockListPipelineTemplate
+	InspectPipelineTemplate mockInspectPipelineTemplate
+	CreatePipelineTemplate  mockCreatePipelineTemplate
+	ListSecret              mockListSecret
+	DeleteAll               mockDeleteAllPPS
+	GetLogs                 mockGetLogs
+	GarbageCollect          mockGarbageCollect
+	ActivateAuth            mockActivateAuthPPS
 }
 
 func (api *ppsServerAPI) CreateJob(ctx context.Context, req *pps.CreateJobRequest) (*pps.Job, error) {
@@ -911,6 +1071,12 @@ func (api *ppsServerAPI) CreatePipeline(ctx context.Context, req *pps.CreatePipe
 	}
 	return nil, errors.Errorf("unhandled pachd mock pps.CreatePipeline")
 }
+func (api *ppsServerAPI) DryRunPipeline(ctx context.Context, req *pps.CreatePipelineRequest) (*pps.DryRunPipelineResponse, error) {
+	if api.mock.DryRunPipeline.handler != nil {
+		return api.mock.DryRunPipeline.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pps.DryRunPipeline")
+}
 func (api *ppsServerAPI) InspectPipeline(ctx context.Context, req *pps.InspectPipelineRequest) (*pps.PipelineInfo, error) {
 	if api.mock.InspectPipeline.handler != nil {
 		return api.mock.InspectPipeline.handler(ctx, req)
@@ -941,6 +1107,60 @@ func (api *ppsServerAPI) StopPipeline(ctx context.Context, req *pps.StopPipeline
 	}
 	return nil, errors.Errorf("unhandled pachd mock pps.StopPipeline")
 }
+func (api *ppsServerAPI) RollbackPipeline(ctx context.Context, req *pps.RollbackPipelineRequest) (*types.Empty, error) {
+	if api.mock.RollbackPipeline.handler != nil {
+		return api.mock.RollbackPipeline.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pps.RollbackPipeline")
+}
+func (api *ppsServerAPI) InspectShadow(ctx context.Context, req *pps.InspectShadowRequest) (*pps.ShadowReport, error) {
+	if api.mock.InspectShadow.handler != nil {
+		return api.mock.InspectShadow.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pps.InspectShadow")
+}
+func (api *ppsServerAPI) PromoteShadow(ctx context.Context, req *pps.PromoteShadowRequest) (*types.Empty, error) {
+	if api.mock.PromoteShadow.handler != nil {
+		return api.mock.PromoteShadow.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pps.PromoteShadow")
+}
+func (api *ppsServerAPI) GetUsage(ctx context.Context, req *pps.GetUsageRequest) (*pps.UsageInfos, error) {
+	if api.mock.GetUsage.handler != nil {
+		return api.mock.GetUsage.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pps.GetUsage")
+}
+func (api *ppsServerAPI) ListDeadLetter(ctx context.Context, req *pps.ListDeadLetterRequest) (*pps.DeadLetters, error) {
+	if api.mock.ListDeadLetter.handler != nil {
+		return api.mock.ListDeadLetter.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pps.ListDeadLetter")
+}
+func (api *ppsServerAPI) DeleteNotificationSink(ctx context.Context, req *pps.DeleteNotificationSinkRequest) (*types.Empty, error) {
+	if api.mock.DeleteNotificationSink.handler != nil {
+		return api.mock.DeleteNotificationSink.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pps.DeleteNotificationSink")
+}
+func (api *ppsServerAPI) ListNotificationSink(ctx context.Context, req *types.Empty) (*pps.NotificationSinkInfos, error) {
+	if api.mock.ListNotificationSink.handler != nil {
+		return api.mock.ListNotificationSink.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pps.ListNotificationSink")
+}
+func (api *ppsServerAPI) InspectNotificationSink(ctx context.Context, req *pps.InspectNotificationSinkRequest) (*pps.NotificationSinkInfo, error) {
+	if api.mock.InspectNotificationSink.handler != nil {
+		return api.mock.InspectNotificationSink.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pps.InspectNotificationSink")
+}
+func (api *ppsServerAPI) CreateNotificationSink(ctx context.Context, req *pps.CreateNotificationSinkRequest) (*types.Empty, error) {
+	if api.mock.CreateNotificationSink.handler != nil {
+		return api.mock.CreateNotificationSink.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pps.CreateNotificationSink")
+}
 func (api *ppsServerAPI) RunPipeline(ctx context.Context, req *pps.RunPipelineRequest) (*types.Empty, error) {
 	if api.mock.RunPipeline.handler != nil {
 		return api.mock.RunPipeline.handler(ctx, req)
@@ -971,6 +1191,30 @@ func (api *ppsServerAPI) InspectSecret(ctx context.Context, req *pps.InspectSecr
 	}
 	return nil, errors.Errorf("unhandled pachd mock pps.InspectSecret")
 }
+func (api *ppsServerAPI) DeletePipelineTemplate(ctx context.Context, req *pps.DeletePipelineTemplateRequest) (*types.Empty, error) {
+	if api.mock.DeletePipelineTemplate.handler != nil {
+		return api.mock.DeletePipelineTemplate.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pps.DeletePipelineTemplate")
+}
+func (api *ppsServerAPI) ListPipelineTemplate(ctx context.Context, req *types.Empty) (*pps.PipelineTemplateInfos, error) {
+	if api.mock.ListPipelineTemplate.handler != nil {
+		return api.mock.ListPipelineTemplate.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pps.ListPipelineTemplate")
+}
+func (api *ppsServerAPI) InspectPipelineTemplate(ctx context.Context, req *pps.InspectPipelineTemplateRequest) (*pps.PipelineTemplateInfo, error) {
+	if api.mock.InspectPipelineTemplate.handler != nil {
+		return api.mock.InspectPipelineTemplate.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pps.InspectPipelineTemplate")
+}
+func (api *ppsServerAPI) CreatePipelineTemplate(ctx context.Context, req *pps.CreatePipelineTemplateRequest) (*types.Empty, error) {
+	if api.mock.CreatePipelineTemplate.handler != nil {
+		return api.mock.CreatePipelineTemplate.handler(ctx, req)
+	}
+	return nil, errors.Errorf("unhandled pachd mock pps.CreatePipelineTemplate")
+}
 func (api *ppsServerAPI) ListSecret(ctx context.Context, in *types.Empty) (*pps.SecretInfos, error) {
 	if api.mock.ListSecret.handler != nil {
 		return api.mock.ListSecret.handler(ctx, in)
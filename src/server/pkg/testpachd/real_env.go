@@ -23,6 +23,10 @@ type RealEnv struct {
 
 	treeCache *hashtree.Cache
 
+	// StorageRoot is the directory holding the local object store. Like in
+	// pachd, it's shared by PFS and the block server.
+	StorageRoot string
+
 	AuthServer        authserver.APIServer
 	PFSBlockServer    pfsserver.BlockAPIServer
 	PFSServer         pfsserver.APIServer
@@ -62,8 +66,9 @@ func WithRealEnv(cb func(*RealEnv) error, customConfig ...*serviceenv.PachdFullC
 		config.PeerPort = uint16(realEnv.MockPachd.Addr.(*net.TCPAddr).Port)
 		servEnv := serviceenv.InitServiceEnv(config)
 
+		realEnv.StorageRoot = path.Join(realEnv.Directory, "objects")
 		realEnv.PFSBlockServer, err = pfsserver.NewBlockAPIServer(
-			path.Join(realEnv.Directory, "objects"),
+			realEnv.StorageRoot,
 			localBlockServerCacheBytes,
 			pfsserver.LocalBackendEnvVar,
 			net.JoinHostPort(config.EtcdHost, config.EtcdPort),
@@ -86,7 +91,7 @@ func WithRealEnv(cb func(*RealEnv) error, customConfig ...*serviceenv.PachdFullC
 			txnEnv,
 			etcdPrefix,
 			realEnv.treeCache,
-			path.Join(realEnv.Directory, "pfs"),
+			realEnv.StorageRoot,
 			64*1024*1024,
 		)
 		if err != nil {
@@ -0,0 +1,194 @@
+// Package textdiff computes line-level diffs of file contents: unified diffs
+// of text files, and row-level diffs of CSV files.
+package textdiff
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// binarySniffLen is how much of a file IsBinary examines (as in git)
+const binarySniffLen = 8000
+
+// IsBinary returns true if 'data' looks like binary data rather than text,
+// i.e. if its beginning contains a NUL byte or isn't valid UTF-8
+func IsBinary(data []byte) bool {
+	if len(data) > binarySniffLen {
+		data = data[:binarySniffLen]
+		// Don't count a multi-byte character split by the cutoff as invalid
+		for i := 1; i < utf8.UTFMax && i <= len(data); i++ {
+			if utf8.RuneStart(data[len(data)-i]) {
+				if !utf8.FullRune(data[len(data)-i:]) {
+					data = data[:len(data)-i]
+				}
+				break
+			}
+		}
+	}
+	return bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data)
+}
+
+// line is a line of a diff: ' ' for a line in both texts, '-' for a line only
+// in the old text, and '+' for a line only in the new text
+type line struct {
+	op   byte
+	text string
+}
+
+// diffLines returns the lines of oldText and newText, in order, marked by
+// which of them each line appears in
+func diffLines(oldText, newText string) []line {
+	dmp := diffmatchpatch.New()
+	a, b, lineArray := dmp.DiffLinesToChars(oldText, newText)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(a, b, false), lineArray)
+	var lines []line
+	for _, d := range diffs {
+		op := byte(' ')
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			op = '-'
+		case diffmatchpatch.DiffInsert:
+			op = '+'
+		}
+		for _, text := range strings.SplitAfter(d.Text, "\n") {
+			if text != "" {
+				lines = append(lines, line{op: op, text: text})
+			}
+		}
+	}
+	return lines
+}
+
+// Unified returns a unified diff (as produced by 'diff -u') of oldText and
+// newText, labeled with oldName and newName and with 'context' lines of
+// context around each change. It returns "" if the texts are the same.
+func Unified(oldName, newName, oldText, newText string, context int) string {
+	lines := diffLines(oldText, newText)
+	// oldNum[i] and newNum[i] are the number of lines of each text before
+	// lines[i]
+	oldNum, newNum := make([]int, len(lines)+1), make([]int, len(lines)+1)
+	var changes []int
+	for i, l := range lines {
+		oldNum[i+1], newNum[i+1] = oldNum[i], newNum[i]
+		if l.op != '+' {
+			oldNum[i+1]++
+		}
+		if l.op != '-' {
+			newNum[i+1]++
+		}
+		if l.op != ' ' {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "--- %s\n+++ %s\n", oldName, newName)
+	for i := 0; i < len(changes); {
+		// Group changes that are within 2*context lines of each other into one
+		// hunk
+		j := i
+		for j+1 < len(changes) && changes[j+1]-changes[j] <= 2*context+1 {
+			j++
+		}
+		start, end := changes[i]-context, changes[j]+context+1
+		if start < 0 {
+			start = 0
+		}
+		if end > len(lines) {
+			end = len(lines)
+		}
+		fmt.Fprintf(&buf, "@@ -%s +%s @@\n",
+			hunkRange(oldNum[start], oldNum[end]-oldNum[start]),
+			hunkRange(newNum[start], newNum[end]-newNum[start]))
+		for _, l := range lines[start:end] {
+			buf.WriteByte(l.op)
+			buf.WriteString(l.text)
+			if !strings.HasSuffix(l.text, "\n") {
+				buf.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = j + 1
+	}
+	return buf.String()
+}
+
+// hunkRange formats the range of lines in one text covered by a hunk, which
+// starts after line 'start' and is 'length' lines long
+func hunkRange(start, length int) string {
+	if length == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, length)
+}
+
+// Rows returns the rows of newData that aren't in oldData ('added') and the
+// rows of oldData that aren't in newData ('deleted'), where both are CSV data
+// with fields separated by 'comma'. Rows are compared regardless of order
+// (but a row repeated n times in one and m < n times in the other differs
+// n-m times), and each row is returned re-encoded as a single line of CSV.
+func Rows(oldData, newData []byte, comma rune) (added, deleted []string, retErr error) {
+	oldRows, err := readRows(oldData, comma)
+	if err != nil {
+		return nil, nil, err
+	}
+	newRows, err := readRows(newData, comma)
+	if err != nil {
+		return nil, nil, err
+	}
+	unmatched := make(map[string]int)
+	for _, row := range oldRows {
+		unmatched[row]++
+	}
+	for _, row := range newRows {
+		if unmatched[row] > 0 {
+			unmatched[row]--
+		} else {
+			added = append(added, row)
+		}
+	}
+	for _, row := range oldRows {
+		if unmatched[row] > 0 {
+			unmatched[row]--
+			deleted = append(deleted, row)
+		}
+	}
+	return added, deleted, nil
+}
+
+// readRows parses 'data' as CSV and re-encodes each of its rows as a single
+// line, so that rows can be compared regardless of quoting
+func readRows(data []byte, comma rune) ([]string, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	var rows []string
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return rows, nil
+		} else if err != nil {
+			return nil, err
+		}
+		var buf strings.Builder
+		w := csv.NewWriter(&buf)
+		w.Comma = comma
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+		w.Flush()
+		rows = append(rows, strings.TrimSuffix(buf.String(), "\n"))
+	}
+}
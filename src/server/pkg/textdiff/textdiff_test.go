@@ -0,0 +1,83 @@
+package textdiff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+)
+
+func TestIsBinary(t *testing.T) {
+	require.False(t, IsBinary([]byte("hello\nworld\n")))
+	require.False(t, IsBinary([]byte("héllo")))
+	require.True(t, IsBinary([]byte("hello\x00world")))
+	require.True(t, IsBinary([]byte{0xff, 0xfe, 'a'}))
+	// A multi-byte character split by the cutoff doesn't make text binary
+	require.False(t, IsBinary([]byte(strings.Repeat("a", binarySniffLen-1)+"é")))
+}
+
+func TestUnified(t *testing.T) {
+	require.Equal(t, "", Unified("a/f", "b/f", "x\ny\n", "x\ny\n", 3))
+
+	oldText := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	newText := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n"
+	require.Equal(t, `--- a/f
++++ b/f
+@@ -2,3 +2,3 @@
+ 2
+-3
++three
+ 4
+@@ -10 +10,2 @@
+ 10
++11
+`, Unified("a/f", "b/f", oldText, newText, 1))
+
+	// Nearby changes share a hunk
+	require.Equal(t, `--- a/f
++++ b/f
+@@ -1,10 +1,11 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+ 7
+ 8
+ 9
+ 10
++11
+`, Unified("a/f", "b/f", oldText, newText, 4))
+
+	// Files without a trailing newline, and new files
+	require.Equal(t, `--- a/f
++++ b/f
+@@ -1 +1 @@
+-x
+\ No newline at end of file
++y
+\ No newline at end of file
+`, Unified("a/f", "b/f", "x", "y", 3))
+	require.Equal(t, `--- /dev/null
++++ b/f
+@@ -0,0 +1,2 @@
++x
++y
+`, Unified("/dev/null", "b/f", "", "x\ny\n", 3))
+}
+
+func TestRows(t *testing.T) {
+	oldData := []byte("id,name\n1,a\n2,b\n2,b\n3,\"c\"\n")
+	newData := []byte("id,name\n3,c\n2,b\n4,\"d, e\"\n")
+	added, deleted, err := Rows(oldData, newData, ',')
+	require.NoError(t, err)
+	require.Equal(t, []string{`4,"d, e"`}, added)
+	require.Equal(t, []string{"1,a", "2,b"}, deleted)
+
+	added, deleted, err = Rows([]byte("a\tb\n"), []byte("a\tc\n"), '\t')
+	require.NoError(t, err)
+	require.Equal(t, []string{"a\tc"}, added)
+	require.Equal(t, []string{"a\tb"}, deleted)
+}
@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
 	pachdclient "github.com/pachyderm/pachyderm/src/client"
 	"github.com/pachyderm/pachyderm/src/client/pfs"
@@ -26,6 +27,7 @@ import (
 	"github.com/pachyderm/pachyderm/src/server/pkg/serde"
 	"github.com/pachyderm/pachyderm/src/server/pkg/tabwriter"
 	"github.com/pachyderm/pachyderm/src/server/pkg/uuid"
+	"github.com/pachyderm/pachyderm/src/server/pps/local"
 	"github.com/pachyderm/pachyderm/src/server/pps/pretty"
 
 	prompt "github.com/c-bata/go-prompt"
@@ -537,34 +539,48 @@ All jobs created by a pipeline will create commits in the pipeline's output repo
 	var registry string
 	var username string
 	var pipelinePath string
+	var jsonnetPath string
+	var templateName string
+	var templateArgs []string
+	var dryRun bool
 	createPipeline := &cobra.Command{
 		Short: "Create a new pipeline.",
 		Long:  "Create a new pipeline from a pipeline specification. For details on the format, see http://docs.pachyderm.io/en/latest/reference/pipeline_spec.html.",
 		Run: cmdutil.RunFixedArgs(0, func(args []string) (retErr error) {
-			return pipelineHelper(false, build, pushImages, registry, username, pipelinePath, false)
+			return pipelineHelper(false, build, pushImages, registry, username, pipelinePath, jsonnetPath, templateName, templateArgs, false, false, dryRun)
 		}),
 	}
 	createPipeline.Flags().StringVarP(&pipelinePath, "file", "f", "-", "The JSON file containing the pipeline, it can be a url or local file. - reads from stdin.")
+	createPipeline.Flags().StringVar(&jsonnetPath, "jsonnet", "", "A jsonnet template file (a url or local file) that evaluates to one or more pipelines, to use instead of --file.")
+	createPipeline.Flags().StringVar(&templateName, "template", "", "The name of a pipeline template stored in pachd (see 'pachctl create template') to use instead of --file.")
+	createPipeline.Flags().StringArrayVar(&templateArgs, "arg", nil, "A top-level argument of the form 'key=value' to pass to --jsonnet or --template. Can be repeated.")
 	createPipeline.Flags().BoolVarP(&build, "build", "b", false, "If true, build and push local docker images into the docker registry.")
 	createPipeline.Flags().BoolVarP(&pushImages, "push-images", "p", false, "If true, push local docker images into the docker registry.")
 	createPipeline.Flags().StringVarP(&registry, "registry", "r", "index.docker.io", "The registry to push images to.")
 	createPipeline.Flags().StringVarP(&username, "username", "u", "", "The username to push images as.")
+	createPipeline.Flags().BoolVar(&dryRun, "dry-run", false, "If true, validate the pipeline and report the datums it would process, without creating it.")
 	commands = append(commands, cmdutil.CreateAlias(createPipeline, "create pipeline"))
 
 	var reprocess bool
+	var shadow bool
 	updatePipeline := &cobra.Command{
 		Short: "Update an existing Pachyderm pipeline.",
 		Long:  "Update a Pachyderm pipeline with a new pipeline specification. For details on the format, see http://docs.pachyderm.io/en/latest/reference/pipeline_spec.html.",
 		Run: cmdutil.RunFixedArgs(0, func(args []string) (retErr error) {
-			return pipelineHelper(reprocess, build, pushImages, registry, username, pipelinePath, true)
+			return pipelineHelper(reprocess, build, pushImages, registry, username, pipelinePath, jsonnetPath, templateName, templateArgs, true, shadow, dryRun)
 		}),
 	}
 	updatePipeline.Flags().StringVarP(&pipelinePath, "file", "f", "-", "The JSON file containing the pipeline, it can be a url or local file. - reads from stdin.")
+	updatePipeline.Flags().StringVar(&jsonnetPath, "jsonnet", "", "A jsonnet template file (a url or local file) that evaluates to one or more pipelines, to use instead of --file.")
+	updatePipeline.Flags().StringVar(&templateName, "template", "", "The name of a pipeline template stored in pachd (see 'pachctl create template') to use instead of --file.")
+	updatePipeline.Flags().StringArrayVar(&templateArgs, "arg", nil, "A top-level argument of the form 'key=value' to pass to --jsonnet or --template. Can be repeated.")
 	updatePipeline.Flags().BoolVarP(&build, "build", "b", false, "If true, build and push local docker images into the docker registry.")
 	updatePipeline.Flags().BoolVarP(&pushImages, "push-images", "p", false, "If true, push local docker images into the docker registry.")
 	updatePipeline.Flags().StringVarP(&registry, "registry", "r", "index.docker.io", "The registry to push images to.")
 	updatePipeline.Flags().StringVarP(&username, "username", "u", "", "The username to push images as.")
 	updatePipeline.Flags().BoolVar(&reprocess, "reprocess", false, "If true, reprocess datums that were already processed by previous version of the pipeline.")
+	updatePipeline.Flags().BoolVar(&dryRun, "dry-run", false, "If true, validate the pipeline and report the datums it would process, without updating it.")
+	updatePipeline.Flags().BoolVar(&shadow, "shadow", false, "If true, run the new spec as a shadow of the pipeline, alongside it on the same inputs, rather than replacing it (see 'pachctl inspect shadow').")
 	commands = append(commands, cmdutil.CreateAlias(updatePipeline, "update pipeline"))
 
 	runPipeline := &cobra.Command{
@@ -622,6 +638,87 @@ All jobs created by a pipeline will create commits in the pipeline's output repo
 	}
 	commands = append(commands, cmdutil.CreateAlias(runCron, "run cron"))
 
+	var localInputs []string
+	var localOutputDir string
+	var localContainer bool
+	runPipelineLocal := &cobra.Command{
+		Use:   "{{alias}} <spec-file>",
+		Short: "Run a pipeline on local data, without a cluster.",
+		Long: `Run the pipelines in a spec file on local data, without a cluster, to test
+their transforms. Each input repo is loaded from a local directory (--input),
+and each datum's transform runs as a local subprocess rather than in a
+container, so the commands it runs must be installed locally. Inputs are laid
+out as in /pfs, under a temporary directory that the input environment variables
+(e.g. $images) point to. Each pipeline's output is written to
+<output>/<pipeline>, and later pipelines in the file may use the output of
+earlier ones as input.
+
+With --container, transforms run in a Docker container of the pipeline's image
+instead, with their inputs and output mounted at /pfs as in a worker.`,
+		Example: `
+# Run the pipelines in edges.json on the data in ./images
+$ {{alias}} edges.json --input images=./images
+
+# Write the output to ./out/edges, and print the job report as JSON
+$ {{alias}} edges.json --input images=./images --output-dir ./out --raw
+
+# Run the transforms in Docker containers, for transforms that read /pfs
+$ {{alias}} edges.json --input images=./images --container`,
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			inputs, err := parseKeyValueArgs("--input", localInputs)
+			if err != nil {
+				return err
+			}
+			pipelineReader, err := ppsutil.NewPipelineManifestReader(args[0])
+			if err != nil {
+				return err
+			}
+			var requests []*ppsclient.CreatePipelineRequest
+			for {
+				request, err := pipelineReader.NextCreatePipelineRequest()
+				if err == io.EOF {
+					break
+				} else if err != nil {
+					return err
+				}
+				requests = append(requests, request)
+			}
+			jobInfos, err := local.Run(requests, &local.Options{
+				Inputs:    inputs,
+				OutputDir: localOutputDir,
+				Logs:      os.Stderr,
+				Container: localContainer,
+			})
+			if err != nil {
+				return err
+			}
+			failed := false
+			for _, jobInfo := range jobInfos {
+				if jobInfo.State != ppsclient.JobState_JOB_SUCCESS {
+					failed = true
+				}
+				if raw {
+					if err := encoder(output).EncodeProto(jobInfo); err != nil {
+						return err
+					}
+					continue
+				}
+				if err := pretty.PrintDetailedJobInfo(pretty.NewPrintableJobInfo(jobInfo)); err != nil {
+					return err
+				}
+			}
+			if failed {
+				return errors.New("some datums failed")
+			}
+			return nil
+		}),
+	}
+	runPipelineLocal.Flags().StringArrayVarP(&localInputs, "input", "i", nil, "An input repo and the local directory to load it from, of the form 'repo=dir'. Can be repeated.")
+	runPipelineLocal.Flags().StringVar(&localOutputDir, "output-dir", ".", "The directory to write each pipeline's output to, in a subdirectory named after the pipeline.")
+	runPipelineLocal.Flags().BoolVar(&localContainer, "container", false, "Run transforms in Docker containers of their pipelines' images, with their inputs mounted at /pfs, instead of as local subprocesses.")
+	runPipelineLocal.Flags().AddFlagSet(outputFlags)
+	commands = append(commands, cmdutil.CreateAlias(runPipelineLocal, "run pipeline-local"))
+
 	inspectPipeline := &cobra.Command{
 		Use:   "{{alias}} <pipeline>",
 		Short: "Return info about a pipeline.",
@@ -883,6 +980,178 @@ All jobs created by a pipeline will create commits in the pipeline's output repo
 	}
 	commands = append(commands, cmdutil.CreateAlias(stopPipeline, "stop pipeline"))
 
+	var version uint64
+	rollbackPipeline := &cobra.Command{
+		Use:   "{{alias}} <pipeline>",
+		Short: "Restore a pipeline's spec to an earlier version.",
+		Long: `Restore a pipeline's spec to an earlier version. The pipeline is updated to
+the spec it had at that version (see 'pachctl inspect pipeline' for the
+current version and 'pachctl list pipeline --history' for earlier ones).`,
+		Example: `
+# roll back pipeline "foo" to version 2
+$ {{alias}} foo --to 2
+
+# roll back pipeline "foo" to version 2, and reprocess all of its datums
+$ {{alias}} foo --to 2 --reprocess`,
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			if version == 0 {
+				return errors.New("--to must be set to the version to roll back to")
+			}
+			client, err := pachdclient.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+			return client.RollbackPipeline(args[0], version, reprocess)
+		}),
+	}
+	rollbackPipeline.Flags().Uint64Var(&version, "to", 0, "The version of the pipeline's spec to restore.")
+	rollbackPipeline.Flags().BoolVar(&reprocess, "reprocess", false, "If true, reprocess datums that were already processed by previous versions of the pipeline.")
+	shell.RegisterCompletionFunc(rollbackPipeline, shell.PipelineCompletion)
+	commands = append(commands, cmdutil.CreateAlias(rollbackPipeline, "rollback pipeline"))
+
+	shadowDocs := &cobra.Command{
+		Short: "Docs for shadow pipelines.",
+		Long: `A shadow runs a candidate spec for a pipeline alongside the live pipeline,
+on the same input commits, so that its output can be checked before it
+replaces the live pipeline's.
+
+Shadows are created with 'pachctl update pipeline --shadow', and write to
+their own output repo, named '<pipeline>-shadow', rather than to a branch of
+the live pipeline's output repo. Once the shadow's output has
+been compared with the live pipeline's ('pachctl inspect shadow'), it can
+either replace the live pipeline's spec ('pachctl promote shadow') or be
+discarded ('pachctl delete shadow').`,
+	}
+	commands = append(commands, cmdutil.CreateDocsAlias(shadowDocs, "shadow", " shadow$"))
+
+	inspectShadow := &cobra.Command{
+		Use:   "{{alias}} <pipeline>",
+		Short: "Compare a pipeline's output with its shadow's.",
+		Long: `Compare a pipeline's output with its shadow's. For each set of input
+commits that the shadow has processed, this shows the job that each pipeline
+ran and, if both succeeded, how many files the shadow's output added, deleted,
+or changed relative to the live pipeline's.`,
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			client, err := pachdclient.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+			report, err := client.InspectShadow(args[0])
+			if err != nil {
+				return err
+			}
+			if raw {
+				return encoder(output).EncodeProto(report)
+			} else if output != "" {
+				cmdutil.ErrorAndExit("cannot set --output (-o) without --raw")
+			}
+			writer := tabwriter.NewWriter(os.Stdout, pretty.ShadowCommitHeader)
+			for _, commitReport := range report.Commits {
+				pretty.PrintShadowCommitReport(writer, commitReport)
+			}
+			return writer.Flush()
+		}),
+	}
+	inspectShadow.Flags().AddFlagSet(outputFlags)
+	shell.RegisterCompletionFunc(inspectShadow, shell.PipelineCompletion)
+	commands = append(commands, cmdutil.CreateAlias(inspectShadow, "inspect shadow"))
+
+	promoteShadow := &cobra.Command{
+		Use:   "{{alias}} <pipeline>",
+		Short: "Replace a pipeline's spec with its shadow's.",
+		Long:  "Replace a pipeline's spec with its shadow's, and delete the shadow and its output repo.",
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			client, err := pachdclient.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+			return client.PromoteShadow(args[0], reprocess)
+		}),
+	}
+	promoteShadow.Flags().BoolVar(&reprocess, "reprocess", false, "If true, reprocess datums that were already processed by previous versions of the pipeline.")
+	shell.RegisterCompletionFunc(promoteShadow, shell.PipelineCompletion)
+	commands = append(commands, cmdutil.CreateAlias(promoteShadow, "promote shadow"))
+
+	deleteShadow := &cobra.Command{
+		Use:   "{{alias}} <pipeline>",
+		Short: "Discard a pipeline's shadow.",
+		Long:  "Discard a pipeline's shadow, deleting it and its output repo. The live pipeline is unaffected.",
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			client, err := pachdclient.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+			report, err := client.InspectShadow(args[0])
+			if err != nil {
+				return err
+			}
+			return client.DeletePipeline(report.Shadow.Name, false)
+		}),
+	}
+	shell.RegisterCompletionFunc(deleteShadow, shell.PipelineCompletion)
+	commands = append(commands, cmdutil.CreateAlias(deleteShadow, "delete shadow"))
+
+	var usagePipeline, usageLabel, usageFrom, usageTo string
+	usage := &cobra.Command{
+		Use:   "{{alias}}",
+		Short: "Report the resources used by jobs.",
+		Long: `Report the resources used by jobs, rolled up by pipeline or, with --label,
+by the value of a label in each job's pipeline metadata (as of when the job was
+created). --from and --to take
+either a time (RFC 3339, or a date like 2020-01-31) or a duration before now.`,
+		Example: `
+# report the resources used by each pipeline's jobs in the last 30 days
+$ {{alias}} --from 720h
+
+# report the resources used by each team's pipelines in January
+$ {{alias}} --label team --from 2020-01-01 --to 2020-02-01`,
+		Run: cmdutil.RunFixedArgs(0, func(args []string) error {
+			from, err := parseUsageTime(usageFrom)
+			if err != nil {
+				return err
+			}
+			to, err := parseUsageTime(usageTo)
+			if err != nil {
+				return err
+			}
+			client, err := pachdclient.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+			usageInfos, err := client.GetUsage(usagePipeline, usageLabel, from, to)
+			if err != nil {
+				return err
+			}
+			if raw {
+				e := encoder(output)
+				for _, usageInfo := range usageInfos {
+					if err := e.EncodeProto(usageInfo); err != nil {
+						return err
+					}
+				}
+				return nil
+			} else if output != "" {
+				cmdutil.ErrorAndExit("cannot set --output (-o) without --raw")
+			}
+			writer := tabwriter.NewWriter(os.Stdout, pretty.UsageHeader)
+			for _, usageInfo := range usageInfos {
+				pretty.PrintUsageInfo(writer, usageInfo)
+			}
+			return writer.Flush()
+		}),
+	}
+	usage.Flags().StringVarP(&usagePipeline, "pipeline", "p", "", "Only report the usage of this pipeline's jobs.")
+	usage.Flags().StringVarP(&usageLabel, "label", "l", "", "Roll up usage by the value of this metadata label (e.g. 'team'), as of when each job was created, instead of by pipeline.")
+	usage.Flags().StringVar(&usageFrom, "from", "", "Only count jobs that started at or after this time.")
+	usage.Flags().StringVar(&usageTo, "to", "", "Only count jobs that started before this time.")
+	usage.Flags().AddFlagSet(outputFlags)
+	commands = append(commands, cmdutil.CreateAlias(usage, "usage"))
+
 	var file string
 	createSecret := &cobra.Command{
 		Short: "Create a secret on the cluster.",
@@ -994,6 +1263,242 @@ All jobs created by a pipeline will create commits in the pipeline's output repo
 	}
 	commands = append(commands, cmdutil.CreateAlias(listSecret, "list secret"))
 
+	templateDocs := &cobra.Command{
+		Short: "Docs for pipeline templates.",
+		Long: `Pipeline templates are jsonnet programs stored in pachd that evaluate to one
+or more pipeline specs. A template that's a function takes its arguments from
+'--arg key=value', so one template can create many similar pipelines.
+Templates are rendered by pachctl, and pachd only receives the resulting
+pipeline specs:
+
+$ pachctl create template per-customer -f per-customer.jsonnet
+$ pachctl create pipeline --template per-customer --arg customer=acme`,
+	}
+	commands = append(commands, cmdutil.CreateDocsAlias(templateDocs, "template", " template$"))
+
+	var templatePath string
+	createTemplate := &cobra.Command{
+		Use:   "{{alias}} <template>",
+		Short: "Store a pipeline template in pachd.",
+		Long:  "Store a jsonnet pipeline template in pachd, to be used with 'pachctl create pipeline --template'.",
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			return templateHelper(args[0], templatePath, false)
+		}),
+	}
+	createTemplate.Flags().StringVarP(&templatePath, "file", "f", "-", "The jsonnet file containing the template. - reads from stdin.")
+	commands = append(commands, cmdutil.CreateAlias(createTemplate, "create template"))
+
+	updateTemplate := &cobra.Command{
+		Use:   "{{alias}} <template>",
+		Short: "Replace a pipeline template in pachd.",
+		Long:  "Replace a jsonnet pipeline template in pachd. Pipelines previously created from the template are unaffected until they're updated.",
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			return templateHelper(args[0], templatePath, true)
+		}),
+	}
+	updateTemplate.Flags().StringVarP(&templatePath, "file", "f", "-", "The jsonnet file containing the template. - reads from stdin.")
+	commands = append(commands, cmdutil.CreateAlias(updateTemplate, "update template"))
+
+	inspectTemplate := &cobra.Command{
+		Use:   "{{alias}} <template>",
+		Short: "Print the source of a pipeline template.",
+		Long:  "Print the source of a pipeline template.",
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			client, err := pachdclient.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+			templateInfo, err := client.InspectPipelineTemplate(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Println(templateInfo.Jsonnet)
+			return nil
+		}),
+	}
+	commands = append(commands, cmdutil.CreateAlias(inspectTemplate, "inspect template"))
+
+	listTemplate := &cobra.Command{
+		Short: "List the pipeline templates stored in pachd.",
+		Long:  "List the pipeline templates stored in pachd.",
+		Run: cmdutil.RunFixedArgs(0, func(args []string) error {
+			client, err := pachdclient.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+			templateInfos, err := client.ListPipelineTemplate()
+			if err != nil {
+				return err
+			}
+			writer := tabwriter.NewWriter(os.Stdout, pretty.PipelineTemplateHeader)
+			for _, templateInfo := range templateInfos {
+				pretty.PrintPipelineTemplateInfo(writer, templateInfo)
+			}
+			return writer.Flush()
+		}),
+	}
+	commands = append(commands, cmdutil.CreateAlias(listTemplate, "list template"))
+
+	deleteTemplate := &cobra.Command{
+		Use:   "{{alias}} <template>",
+		Short: "Delete a pipeline template.",
+		Long:  "Delete a pipeline template. Pipelines created from the template are unaffected.",
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			client, err := pachdclient.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+			return client.DeletePipelineTemplate(args[0])
+		}),
+	}
+	commands = append(commands, cmdutil.CreateAlias(deleteTemplate, "delete template"))
+
+	sinkDocs := &cobra.Command{
+		Short: "Docs for notification sinks.",
+		Long: `Notification sinks are destinations that pachd sends events to when commits
+finish and when jobs and pipelines change state. A sink is a webhook (whose
+requests can be signed with HMAC-SHA256), a Slack-compatible incoming webhook,
+or a file on pachd's filesystem (for testing). Sinks can filter events by
+type, repo, pipeline and state. Events that can't be delivered after retrying
+(5 times, or "max_retries" times if it's set) are dead-lettered, and can be
+listed with 'pachctl list dead-letter'. Webhook secrets are shown as
+"<redacted>", and updating a sink with the secret "<redacted>" keeps its
+existing secret.
+
+Sinks are specified as JSON, e.g.:
+
+{
+  "sink": {"name": "job-failures"},
+  "slack": {"url": "https://hooks.slack.com/services/..."},
+  "events": ["JOB_STATE"],
+  "job_states": ["JOB_FAILURE"]
+}`,
+	}
+	commands = append(commands, cmdutil.CreateDocsAlias(sinkDocs, "sink", " sink$"))
+
+	var sinkPath string
+	createSink := &cobra.Command{
+		Short: "Create a notification sink.",
+		Long:  "Create a notification sink from a JSON spec.",
+		Run: cmdutil.RunFixedArgs(0, func(args []string) error {
+			return sinkHelper(sinkPath, false)
+		}),
+	}
+	createSink.Flags().StringVarP(&sinkPath, "file", "f", "-", "The JSON file containing the sink spec. - reads from stdin.")
+	commands = append(commands, cmdutil.CreateAlias(createSink, "create sink"))
+
+	updateSink := &cobra.Command{
+		Short: "Replace a notification sink.",
+		Long:  "Replace a notification sink with a new JSON spec.",
+		Run: cmdutil.RunFixedArgs(0, func(args []string) error {
+			return sinkHelper(sinkPath, true)
+		}),
+	}
+	updateSink.Flags().StringVarP(&sinkPath, "file", "f", "-", "The JSON file containing the sink spec. - reads from stdin.")
+	commands = append(commands, cmdutil.CreateAlias(updateSink, "update sink"))
+
+	inspectSink := &cobra.Command{
+		Use:   "{{alias}} <sink>",
+		Short: "Print the spec of a notification sink.",
+		Long:  "Print the spec of a notification sink.",
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			client, err := pachdclient.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+			sinkInfo, err := client.InspectNotificationSink(args[0])
+			if err != nil {
+				return err
+			}
+			return encoder(output).EncodeProto(sinkInfo)
+		}),
+	}
+	inspectSink.Flags().StringVarP(&output, "output", "o", "", "Output format: \"json\" or \"yaml\" (default \"json\")")
+	commands = append(commands, cmdutil.CreateAlias(inspectSink, "inspect sink"))
+
+	listSink := &cobra.Command{
+		Short: "List the notification sinks.",
+		Long:  "List the notification sinks.",
+		Run: cmdutil.RunFixedArgs(0, func(args []string) error {
+			client, err := pachdclient.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+			sinkInfos, err := client.ListNotificationSink()
+			if err != nil {
+				return err
+			}
+			writer := tabwriter.NewWriter(os.Stdout, pretty.NotificationSinkHeader)
+			for _, sinkInfo := range sinkInfos {
+				pretty.PrintNotificationSinkInfo(writer, sinkInfo)
+			}
+			return writer.Flush()
+		}),
+	}
+	commands = append(commands, cmdutil.CreateAlias(listSink, "list sink"))
+
+	deleteSink := &cobra.Command{
+		Use:   "{{alias}} <sink>",
+		Short: "Delete a notification sink.",
+		Long:  "Delete a notification sink, along with the events that couldn't be delivered to it.",
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			client, err := pachdclient.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+			return client.DeleteNotificationSink(args[0])
+		}),
+	}
+	commands = append(commands, cmdutil.CreateAlias(deleteSink, "delete sink"))
+
+	deadLetterDocs := &cobra.Command{
+		Short: "Docs for dead letters.",
+		Long: `Dead letters are notification events that couldn't be delivered to a sink
+after retrying (see 'pachctl sink'). They're kept until the sink is deleted.`,
+	}
+	commands = append(commands, cmdutil.CreateDocsAlias(deadLetterDocs, "dead-letter", " dead-letter$"))
+
+	listDeadLetter := &cobra.Command{
+		Use:   "{{alias}} <sink>",
+		Short: "List the events that couldn't be delivered to a notification sink.",
+		Long:  "List the events that couldn't be delivered to a notification sink, oldest first.",
+		Run: cmdutil.RunFixedArgs(1, func(args []string) error {
+			client, err := pachdclient.NewOnUserMachine("user")
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+			deadLetters, err := client.ListDeadLetter(args[0])
+			if err != nil {
+				return err
+			}
+			if raw {
+				e := encoder(output)
+				for _, deadLetter := range deadLetters {
+					if err := e.EncodeProto(deadLetter); err != nil {
+						return err
+					}
+				}
+				return nil
+			} else if output != "" {
+				cmdutil.ErrorAndExit("cannot set --output (-o) without --raw")
+			}
+			writer := tabwriter.NewWriter(os.Stdout, pretty.DeadLetterHeader)
+			for _, deadLetter := range deadLetters {
+				pretty.PrintDeadLetter(writer, deadLetter)
+			}
+			return writer.Flush()
+		}),
+	}
+	listDeadLetter.Flags().AddFlagSet(outputFlags)
+	commands = append(commands, cmdutil.CreateAlias(listDeadLetter, "list dead-letter"))
+
 	var memory string
 	garbageCollect := &cobra.Command{
 		Short: "Garbage collect unused data.",
@@ -1039,8 +1544,73 @@ you can increase the amount of memory used for the bloom filters with the
 	return commands
 }
 
-func pipelineHelper(reprocess bool, build bool, pushImages bool, registry string, username string, pipelinePath string, update bool) error {
-	pipelineReader, err := ppsutil.NewPipelineManifestReader(pipelinePath)
+// parseKeyValueArgs parses the values of a repeated flag (e.g. --arg) of the
+// form "key=value"
+func parseKeyValueArgs(flag string, args []string) (map[string]string, error) {
+	result := make(map[string]string)
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, errors.Errorf("invalid %s %q (must be of the form key=value)", flag, arg)
+		}
+		result[parts[0]] = parts[1]
+	}
+	return result, nil
+}
+
+func templateHelper(name string, templatePath string, update bool) error {
+	var templateBytes []byte
+	var err error
+	if templatePath == "-" {
+		fmt.Print("Reading from stdin.\n")
+		templateBytes, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		templateBytes, err = ioutil.ReadFile(templatePath)
+	}
+	if err != nil {
+		return err
+	}
+	client, err := pachdclient.NewOnUserMachine("user")
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return client.CreatePipelineTemplate(name, string(templateBytes), update)
+}
+
+func sinkHelper(sinkPath string, update bool) error {
+	var r io.Reader
+	if sinkPath == "-" {
+		fmt.Print("Reading from stdin.\n")
+		r = os.Stdin
+	} else {
+		f, err := os.Open(sinkPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	sinkInfo := &ppsclient.NotificationSinkInfo{}
+	if err := serde.NewJSONDecoder(r).DecodeProto(sinkInfo); err != nil {
+		return errors.Wrapf(err, "malformed sink spec")
+	}
+	client, err := pachdclient.NewOnUserMachine("user")
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return client.CreateNotificationSink(sinkInfo, update)
+}
+
+func pipelineHelper(reprocess bool, build bool, pushImages bool, registry string, username string, pipelinePath string, jsonnetPath string, templateName string, templateArgs []string, update bool, shadow bool, dryRun bool) error {
+	if jsonnetPath != "" && templateName != "" {
+		return errors.New("only one of --jsonnet and --template may be set")
+	}
+	if len(templateArgs) > 0 && jsonnetPath == "" && templateName == "" {
+		return errors.New("--arg can only be used with --jsonnet or --template")
+	}
+	args, err := parseKeyValueArgs("--arg", templateArgs)
 	if err != nil {
 		return err
 	}
@@ -1049,6 +1619,28 @@ func pipelineHelper(reprocess bool, build bool, pushImages bool, registry string
 		return errors.Wrapf(err, "error connecting to pachd")
 	}
 	defer client.Close()
+	var pipelineReader *ppsutil.PipelineManifestReader
+	switch {
+	case jsonnetPath != "":
+		// --build requires the template to be a local file, as for a spec file
+		pipelinePath = jsonnetPath
+		pipelineReader, err = ppsutil.NewPipelineManifestReaderFromJsonnet(jsonnetPath, args)
+	case templateName != "":
+		// Stored templates are rendered here rather than in pachd, so that an
+		// expensive template can't tie up pachd
+		var templateInfo *ppsclient.PipelineTemplateInfo
+		if templateInfo, err = client.InspectPipelineTemplate(templateName); err != nil {
+			return err
+		}
+		var specs []byte
+		specs, err = ppsutil.RenderTemplate(templateInfo.Jsonnet, args)
+		pipelineReader = ppsutil.NewPipelineManifestReaderFromBytes(specs)
+	default:
+		pipelineReader, err = ppsutil.NewPipelineManifestReader(pipelinePath)
+	}
+	if err != nil {
+		return err
+	}
 	for {
 		request, err := pipelineReader.NextCreatePipelineRequest()
 		if err == io.EOF {
@@ -1064,6 +1656,16 @@ func pipelineHelper(reprocess bool, build bool, pushImages bool, registry string
 		if update {
 			request.Update = true
 			request.Reprocess = reprocess
+			request.Shadow = shadow
+		}
+		if dryRun {
+			// Images aren't built or pushed for a dry run, as nothing is run
+			response, err := client.DryRunPipeline(request)
+			if err != nil {
+				return err
+			}
+			pretty.PrintDryRunPipelineResponse(os.Stdout, response)
+			continue
 		}
 		if build || pushImages {
 			if build && pushImages {
@@ -1227,3 +1829,20 @@ func pushImage(client *docker.Client, authConfig docker.AuthConfiguration, repo
 
 	return destImage, nil
 }
+
+// parseUsageTime parses a time passed to 'pachctl usage', which is either a
+// timestamp or a duration before now. "" is the zero time.
+func parseUsageTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.Errorf("could not parse %q as a time or a duration", s)
+}
@@ -0,0 +1,350 @@
+// Package local runs pipelines on the local machine, without a cluster, so
+// that transforms can be tested quickly. Pipelines run against an in-process
+// PFS whose input repos are loaded from local directories, and each datum's
+// user code runs as a local subprocess (or, optionally, in a Docker container
+// of the pipeline's image, with its inputs and output mounted at /pfs).
+package local
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	filesync "github.com/pachyderm/pachyderm/src/server/pkg/sync"
+	"github.com/pachyderm/pachyderm/src/server/pkg/testpachd"
+	"github.com/pachyderm/pachyderm/src/server/pkg/uuid"
+	"github.com/pachyderm/pachyderm/src/server/worker"
+)
+
+// concurrency is the number of files that are downloaded at once
+const concurrency = 100
+
+// pfsMount is where a datum's inputs and output are mounted in its container
+const pfsMount = "/pfs"
+
+// Options configures a local run
+type Options struct {
+	// Inputs maps input repos to the local directories holding their data
+	Inputs map[string]string
+	// OutputDir is the directory that each pipeline's output is written to, in
+	// a subdirectory named after the pipeline
+	OutputDir string
+	// Logs receives the output of the pipelines' user code
+	Logs io.Writer
+	// Container runs user code in a Docker container of the pipeline's image,
+	// with its inputs and output mounted at /pfs, instead of as a local
+	// subprocess. Otherwise, inputs and output are laid out under a temporary
+	// directory rather than /pfs, so user code must find them through its
+	// input environment variables.
+	Container bool
+}
+
+// Run runs the pipelines in 'requests' in order, and returns a report of the
+// job that each one ran. Later pipelines may take the output of earlier ones
+// as input. Unlike in a cluster, the output of a job with failed datums still
+// includes the output of the datums that succeeded, to help with debugging.
+func Run(requests []*pps.CreatePipelineRequest, opts *Options) ([]*pps.JobInfo, error) {
+	var jobInfos []*pps.JobInfo
+	if err := testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		for repo, dir := range opts.Inputs {
+			if err := loadInput(env.PachClient, repo, dir); err != nil {
+				return err
+			}
+		}
+		for _, request := range requests {
+			jobInfo, err := runPipeline(env.PachClient, filepath.Join(env.Directory, "pfs-local"), request, opts)
+			if err != nil {
+				return errors.Wrapf(err, "could not run pipeline %q", request.Pipeline.Name)
+			}
+			jobInfos = append(jobInfos, jobInfo)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return jobInfos, nil
+}
+
+// loadInput creates 'repo' with a single commit on master holding the
+// contents of 'dir'
+func loadInput(pachClient *client.APIClient, repo string, dir string) error {
+	if err := pachClient.CreateRepo(repo); err != nil {
+		return err
+	}
+	commit, err := pachClient.StartCommit(repo, "master")
+	if err != nil {
+		return err
+	}
+	if err := filesync.Push(pachClient, dir, commit, false); err != nil {
+		return errors.Wrapf(err, "could not load %q into input repo %q", dir, repo)
+	}
+	return pachClient.FinishCommit(repo, commit.ID)
+}
+
+// jobInput sets defaults for the inputs of a pipeline, and points them at the
+// heads of their branches
+func jobInput(pachClient *client.APIClient, pipelineInput *pps.Input) (*pps.Input, error) {
+	if pipelineInput == nil {
+		return nil, errors.New("pipelines without inputs can't be run locally")
+	}
+	input := proto.Clone(pipelineInput).(*pps.Input)
+	var visitErr error
+	pps.VisitInput(input, func(input *pps.Input) {
+		switch {
+		case visitErr != nil:
+			return
+		case input.Pfs != nil:
+			if input.Pfs.Branch == "" {
+				input.Pfs.Branch = "master"
+			}
+			if input.Pfs.Name == "" {
+				input.Pfs.Name = input.Pfs.Repo
+			}
+			if input.Pfs.S3 {
+				visitErr = errors.Errorf("input %q: s3 inputs can't be run locally", input.Pfs.Name)
+				return
+			}
+			commitInfo, err := pachClient.InspectCommit(input.Pfs.Repo, input.Pfs.Branch)
+			if err != nil {
+				visitErr = errors.Wrapf(err, "no data for input repo %q (set it with --input %s=<dir>)", input.Pfs.Repo, input.Pfs.Repo)
+				return
+			}
+			input.Pfs.Commit = commitInfo.Commit.ID
+		case input.Cron != nil, input.Git != nil, input.Kafka != nil:
+			visitErr = errors.New("cron, git, and kafka inputs can't be run locally")
+		}
+	})
+	if visitErr != nil {
+		return nil, visitErr
+	}
+	return input, nil
+}
+
+func runPipeline(pachClient *client.APIClient, pfsRoot string, request *pps.CreatePipelineRequest, opts *Options) (*pps.JobInfo, error) {
+	switch {
+	case request.Transform == nil || len(request.Transform.Cmd) == 0:
+		return nil, errors.New("pipeline must have a transform with a cmd")
+	case request.Spout != nil || request.Service != nil:
+		return nil, errors.New("spouts and services can't be run locally")
+	case request.S3Out:
+		return nil, errors.New("s3_out pipelines can't be run locally")
+	}
+	input, err := jobInput(pachClient, request.Input)
+	if err != nil {
+		return nil, err
+	}
+	outputBranch := request.OutputBranch
+	if outputBranch == "" {
+		outputBranch = "master"
+	}
+	pipelineName := request.Pipeline.Name
+	if err := pachClient.CreateRepo(pipelineName); err != nil {
+		return nil, err
+	}
+	outputCommit, err := pachClient.StartCommit(pipelineName, outputBranch)
+	if err != nil {
+		return nil, err
+	}
+	jobInfo := &pps.JobInfo{
+		Job:          client.NewJob(uuid.NewWithoutDashes()),
+		Pipeline:     request.Pipeline,
+		Transform:    request.Transform,
+		Input:        input,
+		OutputCommit: outputCommit,
+		DatumTimeout: request.DatumTimeout,
+		JobTimeout:   request.JobTimeout,
+		Started:      types.TimestampNow(),
+		Stats:        &pps.ProcessStats{},
+	}
+	dit, err := worker.NewDatumIterator(pachClient, input)
+	if err != nil {
+		return nil, err
+	}
+	jobInfo.DataTotal = int64(dit.Len())
+	for dit.Next() {
+		if err := processDatum(pachClient, jobInfo, dit.Datum(), pfsRoot, opts); err != nil {
+			fmt.Fprintf(opts.Logs, "datum %s failed: %v\n", datumPaths(dit.Datum()), err)
+			jobInfo.DataFailed++
+			continue
+		}
+		jobInfo.DataProcessed++
+	}
+	if err := pachClient.FinishCommit(pipelineName, outputCommit.ID); err != nil {
+		return nil, err
+	}
+	jobInfo.Finished = types.TimestampNow()
+	jobInfo.State = pps.JobState_JOB_SUCCESS
+	if jobInfo.DataFailed > 0 {
+		jobInfo.State = pps.JobState_JOB_FAILURE
+		jobInfo.Reason = fmt.Sprintf("%d datums failed", jobInfo.DataFailed)
+	}
+
+	// Copy the output commit out of the in-process PFS
+	outputDir := filepath.Join(opts.OutputDir, pipelineName)
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, err
+	}
+	puller := filesync.NewPuller()
+	if err := puller.Pull(pachClient, outputDir, pipelineName, outputCommit.ID, "/", false, false, concurrency, nil, ""); err != nil {
+		return nil, errors.Wrapf(err, "could not write output to %q", outputDir)
+	}
+	return jobInfo, nil
+}
+
+// processDatum downloads a datum's inputs under 'pfsRoot', runs the job's user
+// code on it, and adds what it writes to 'pfsRoot'/out to the job's output
+// commit
+func processDatum(pachClient *client.APIClient, jobInfo *pps.JobInfo, data []*worker.Input, pfsRoot string, opts *Options) error {
+	stats := jobInfo.Stats
+	if err := os.RemoveAll(pfsRoot); err != nil {
+		return err
+	}
+	outDir := filepath.Join(pfsRoot, "out")
+	if err := os.MkdirAll(outDir, 0777); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	puller := filesync.NewPuller()
+	for _, input := range data {
+		file := input.FileInfo.File
+		root := filepath.Join(pfsRoot, input.Name, file.Path)
+		if err := puller.Pull(pachClient, root, file.Commit.Repo.Name, file.Commit.ID, file.Path, false, input.EmptyFiles, concurrency, nil, ""); err != nil {
+			return err
+		}
+	}
+	downSize, err := puller.CleanUp()
+	if err != nil {
+		return err
+	}
+	stats.DownloadBytes += uint64(downSize)
+	stats.DownloadTime = addDuration(stats.DownloadTime, time.Since(start))
+
+	start = time.Now()
+	err = runUserCode(jobInfo, data, pfsRoot, opts)
+	stats.ProcessTime = addDuration(stats.ProcessTime, time.Since(start))
+	if err != nil {
+		return err
+	}
+
+	start = time.Now()
+	if err := filepath.Walk(outDir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			stats.UploadBytes += uint64(info.Size())
+		}
+		return err
+	}); err != nil {
+		return err
+	}
+	if err := filesync.Push(pachClient, outDir, jobInfo.OutputCommit, false); err != nil {
+		return err
+	}
+	stats.UploadTime = addDuration(stats.UploadTime, time.Since(start))
+	return nil
+}
+
+// runUserCode runs the job's transform as a local subprocess or, if
+// opts.Container is set, in a container with 'pfsRoot' mounted at /pfs
+func runUserCode(jobInfo *pps.JobInfo, data []*worker.Input, pfsRoot string, opts *Options) error {
+	ctx := context.Background()
+	if jobInfo.DatumTimeout != nil {
+		datumTimeout, err := types.DurationFromProto(jobInfo.DatumTimeout)
+		if err != nil {
+			return err
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, datumTimeout)
+		defer cancel()
+	}
+	transform := jobInfo.Transform
+	var cmd *exec.Cmd
+	var container string
+	if opts.Container {
+		if transform.Image == "" {
+			return errors.New("transforms run in a container must set an image")
+		}
+		container = "pachyderm-local-" + uuid.NewWithoutDashes()
+		cmd = exec.CommandContext(ctx, "docker", dockerRunArgs(container, transform, data, jobInfo, pfsRoot)...)
+	} else {
+		cmd = exec.CommandContext(ctx, transform.Cmd[0], transform.Cmd[1:]...)
+		cmd.Env = append(os.Environ(), worker.UserCodeEnv(pfsRoot, jobInfo.Job.ID, jobInfo.OutputCommit.ID, data)...)
+		for k, v := range transform.Env {
+			cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+		}
+	}
+	if transform.Stdin != nil {
+		cmd.Stdin = strings.NewReader(strings.Join(transform.Stdin, "\n") + "\n")
+	}
+	cmd.Stdout = opts.Logs
+	cmd.Stderr = opts.Logs
+	err := cmd.Run()
+	// Killing the docker client doesn't stop its container
+	if container != "" && ctx.Err() != nil {
+		exec.Command("docker", "rm", "--force", container).Run()
+	}
+	if exiterr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exiterr.Sys().(syscall.WaitStatus); ok {
+			for _, returnCode := range transform.AcceptReturnCode {
+				if int(returnCode) == status.ExitStatus() {
+					return nil
+				}
+			}
+		}
+	}
+	return err
+}
+
+// dockerRunArgs returns the arguments to 'docker run' that run the job's
+// transform in a container named 'container', with 'pfsRoot' mounted at /pfs.
+// Unless the transform sets a user, it runs as the local user, so that its
+// output can be read and cleaned up.
+func dockerRunArgs(container string, transform *pps.Transform, data []*worker.Input, jobInfo *pps.JobInfo, pfsRoot string) []string {
+	user := transform.User
+	if user == "" {
+		user = fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())
+	}
+	args := []string{"run", "--rm", "--interactive", "--name", container,
+		"--volume", pfsRoot + ":" + pfsMount, "--user", user}
+	if transform.WorkingDir != "" {
+		args = append(args, "--workdir", transform.WorkingDir)
+	}
+	for _, env := range worker.UserCodeEnv(pfsMount, jobInfo.Job.ID, jobInfo.OutputCommit.ID, data) {
+		args = append(args, "--env", env)
+	}
+	for k, v := range transform.Env {
+		args = append(args, "--env", fmt.Sprintf("%s=%s", k, v))
+	}
+	args = append(args, "--entrypoint", transform.Cmd[0], transform.Image)
+	return append(args, transform.Cmd[1:]...)
+}
+
+func addDuration(d *types.Duration, elapsed time.Duration) *types.Duration {
+	if d != nil {
+		prev, err := types.DurationFromProto(d)
+		if err == nil {
+			elapsed += prev
+		}
+	}
+	return types.DurationProto(elapsed)
+}
+
+// datumPaths describes a datum by the paths of its inputs
+func datumPaths(data []*worker.Input) string {
+	var paths []string
+	for _, input := range data {
+		paths = append(paths, fmt.Sprintf("%s@%s", input.Name, input.FileInfo.File.Path))
+	}
+	return strings.Join(paths, ", ")
+}
@@ -0,0 +1,106 @@
+package local
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+	dir, err := ioutil.TempDir("", "local")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	inputDir := filepath.Join(dir, "in")
+	require.NoError(t, os.MkdirAll(inputDir, 0755))
+	for name, data := range map[string]string{"a": "foo\n", "b": "bar\n", "fail": ""} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(inputDir, name), []byte(data), 0644))
+	}
+
+	var logs bytes.Buffer
+	jobInfos, err := Run([]*pps.CreatePipelineRequest{
+		{
+			Pipeline: client.NewPipeline("copy"),
+			Transform: &pps.Transform{
+				Cmd: []string{"sh"},
+				Stdin: []string{
+					`test "$(basename $data)" != fail || exit 1`,
+					`cp $data $(dirname $(dirname $data))/out/`,
+				},
+			},
+			Input: client.NewPFSInputOpts("data", "data", "", "/*", "", false),
+		},
+		{
+			Pipeline: client.NewPipeline("cat"),
+			Transform: &pps.Transform{
+				Cmd:   []string{"sh"},
+				Stdin: []string{`cat $copy/* > $(dirname $copy)/out/all`},
+			},
+			Input: client.NewPFSInput("copy", "/"),
+		},
+	}, &Options{
+		Inputs:    map[string]string{"data": inputDir},
+		OutputDir: filepath.Join(dir, "out"),
+		Logs:      &logs,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(jobInfos))
+	require.Equal(t, pps.JobState_JOB_FAILURE, jobInfos[0].State)
+	require.Equal(t, int64(2), jobInfos[0].DataProcessed)
+	require.Equal(t, int64(1), jobInfos[0].DataFailed)
+	require.Equal(t, pps.JobState_JOB_SUCCESS, jobInfos[1].State)
+
+	all, err := ioutil.ReadFile(filepath.Join(dir, "out", "cat", "all"))
+	require.NoError(t, err)
+	require.Equal(t, "foo\nbar\n", string(all))
+}
+
+func TestRunContainer(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration tests in short mode")
+	}
+	if _, err := exec.LookPath("docker"); err != nil {
+		t.Skip("Skipping container tests without docker")
+	}
+	dir, err := ioutil.TempDir("", "local")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	inputDir := filepath.Join(dir, "in")
+	require.NoError(t, os.MkdirAll(inputDir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(inputDir, "a"), []byte("foo\n"), 0644))
+
+	// User code finds its inputs and output at /pfs, as in a worker
+	var logs bytes.Buffer
+	jobInfos, err := Run([]*pps.CreatePipelineRequest{
+		{
+			Pipeline: client.NewPipeline("copy"),
+			Transform: &pps.Transform{
+				Image: "alpine:3.11",
+				Cmd:   []string{"sh"},
+				Stdin: []string{"cp /pfs/data/* /pfs/out/"},
+			},
+			Input: client.NewPFSInputOpts("data", "data", "", "/*", "", false),
+		},
+	}, &Options{
+		Inputs:    map[string]string{"data": inputDir},
+		OutputDir: filepath.Join(dir, "out"),
+		Logs:      &logs,
+		Container: true,
+	})
+	require.NoError(t, err, logs.String())
+	require.Equal(t, 1, len(jobInfos))
+	require.Equal(t, pps.JobState_JOB_SUCCESS, jobInfos[0].State, logs.String())
+
+	a, err := ioutil.ReadFile(filepath.Join(dir, "out", "copy", "a"))
+	require.NoError(t, err)
+	require.Equal(t, "foo\n", string(a))
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/docker/go-units"
 	"github.com/fatih/color"
@@ -17,6 +18,7 @@ import (
 	pfsclient "github.com/pachyderm/pachyderm/src/client/pfs"
 	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
 	ppsclient "github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/notify"
 	"github.com/pachyderm/pachyderm/src/server/pkg/pretty"
 )
 
@@ -29,6 +31,16 @@ const (
 	DatumHeader = "ID\tSTATUS\tTIME\t\n"
 	// SecretHeader is the header for secrets
 	SecretHeader = "NAME\tTYPE\tCREATED\t\n"
+	// PipelineTemplateHeader is the header for pipeline templates
+	PipelineTemplateHeader = "NAME\tCREATED\t\n"
+	// ShadowCommitHeader is the header for the commits in a shadow report
+	ShadowCommitHeader = "INPUT\tLIVE JOB\tSHADOW JOB\tDURATION (LIVE / SHADOW)\tADDED\tDELETED\tCHANGED\t\n"
+	// NotificationSinkHeader is the header for notification sinks
+	NotificationSinkHeader = "NAME\tTYPE\tEVENTS\tCREATED\t\n"
+	// DeadLetterHeader is the header for dead-lettered notification events
+	DeadLetterHeader = "TIME\tEVENT\tERROR\t\n"
+	// UsageHeader is the header for usage reports
+	UsageHeader = "NAME\tJOBS\tDATUMS\tPROCESS TIME\tCPU TIME\tPEAK MEMORY\tDL\tUL\t\n"
 	// jobReasonLen is the amount of the job reason that we print
 	jobReasonLen = 25
 )
@@ -138,6 +150,8 @@ Data Uploaded: {{prettySize .Stats.UploadBytes}}
 Download Time: {{prettyDuration .Stats.DownloadTime}}
 Process Time: {{prettyDuration .Stats.ProcessTime}}
 Upload Time: {{prettyDuration .Stats.UploadTime}}
+CPU Time: {{cpuTime .Stats.CpuSeconds}}
+Peak Memory: {{prettySize .Stats.MemoryPeakBytes}}
 Datum Timeout: {{.DatumTimeout}}
 Job Timeout: {{.JobTimeout}}
 Worker Status:
@@ -160,7 +174,7 @@ Transform:
 {{prettyTransform .Transform}} {{if .OutputCommit}}
 Output Commit: {{.OutputCommit.ID}} {{end}} {{ if .StatsCommit }}
 Stats Commit: {{.StatsCommit.ID}} {{end}} {{ if .Egress }}
-Egress: {{.Egress.URL}} {{end}}
+Egress: {{prettyEgress .Egress}} {{end}}
 `)
 	if err != nil {
 		return err
@@ -214,7 +228,7 @@ Input:
 Output Branch: {{.OutputBranch}}
 Transform:
 {{prettyTransform .Transform}}
-{{ if .Egress }}Egress: {{.Egress.URL}} {{end}}
+{{ if .Egress }}Egress: {{prettyEgress .Egress}} {{end}}
 {{if .RecentError}} Recent Error: {{.RecentError}} {{end}}
 Job Counts:
 {{jobCounts .JobCounts}}
@@ -278,6 +292,8 @@ func PrintDetailedDatumInfo(w io.Writer, datumInfo *ppsclient.DatumInfo) {
 		uploadTime = ul.String()
 	}
 	fmt.Fprintf(w, "Upload Time\t%s\n", uploadTime)
+	fmt.Fprintf(w, "CPU Time\t%s\n", cpuTime(datumInfo.Stats.CpuSeconds))
+	fmt.Fprintf(w, "Peak Memory\t%s\n", pretty.Size(datumInfo.Stats.MemoryPeakBytes))
 
 	fmt.Fprintf(w, "PFS State:\n")
 	tw := ansiterm.NewTabWriter(w, 10, 1, 3, ' ', 0)
@@ -293,11 +309,117 @@ func PrintDetailedDatumInfo(w io.Writer, datumInfo *ppsclient.DatumInfo) {
 	tw.Flush()
 }
 
+// PrintDryRunPipelineResponse pretty-prints the result of a pipeline dry run
+func PrintDryRunPipelineResponse(w io.Writer, response *ppsclient.DryRunPipelineResponse) {
+	tw := ansiterm.NewTabWriter(w, 10, 1, 3, ' ', 0)
+	fmt.Fprintf(tw, "Pipeline\t%s\n", response.Pipeline.Name)
+	fmt.Fprintf(tw, "Datums\t%d\n", response.Datums)
+	fmt.Fprintf(tw, "Datums Skipped\t%d\n", response.DatumsSkipped)
+	fmt.Fprintf(tw, "Input Size\t%s\n", pretty.Size(response.InputBytes))
+	if len(response.EmptyInputs) > 0 {
+		fmt.Fprintf(tw, "Empty Inputs\t%s\n", strings.Join(response.EmptyInputs, ", "))
+	}
+	tw.Flush()
+}
+
 // PrintSecretInfo pretty-prints secret info.
 func PrintSecretInfo(w io.Writer, secretInfo *ppsclient.SecretInfo) {
 	fmt.Fprintf(w, "%s\t%s\t%s\t\n", secretInfo.Secret.Name, secretInfo.Type, pretty.Ago(secretInfo.CreationTimestamp))
 }
 
+// PrintPipelineTemplateInfo pretty-prints pipeline template info.
+func PrintPipelineTemplateInfo(w io.Writer, templateInfo *ppsclient.PipelineTemplateInfo) {
+	fmt.Fprintf(w, "%s\t%s\t\n", templateInfo.Template.Name, pretty.Ago(templateInfo.Created))
+}
+
+// PrintShadowCommitReport pretty-prints a comparison of the jobs run by a
+// pipeline and its shadow on one set of input commits.
+func PrintShadowCommitReport(w io.Writer, report *ppsclient.ShadowCommitReport) {
+	var inputs []string
+	for _, commit := range report.InputCommits {
+		inputs = append(inputs, fmt.Sprintf("%s@%s", commit.Repo.Name, commit.ID))
+	}
+	fmt.Fprintf(w, "%s\t", strings.Join(inputs, ", "))
+	fmt.Fprintf(w, "%s\t", shadowJob(report.LiveJob))
+	fmt.Fprintf(w, "%s\t", shadowJob(report.ShadowJob))
+	fmt.Fprintf(w, "%s / %s\t", jobDuration(report.LiveJob), jobDuration(report.ShadowJob))
+	if report.LiveJob == nil ||
+		report.LiveJob.State != ppsclient.JobState_JOB_SUCCESS ||
+		report.ShadowJob.State != ppsclient.JobState_JOB_SUCCESS {
+		fmt.Fprintf(w, "-\t-\t-\t\n")
+		return
+	}
+	fmt.Fprintf(w, "%d\t%d\t%d\t\n", len(report.Added), len(report.Deleted), len(report.Changed))
+}
+
+// PrintNotificationSinkInfo pretty-prints notification sink info.
+func PrintNotificationSinkInfo(w io.Writer, sinkInfo *ppsclient.NotificationSinkInfo) {
+	fmt.Fprintf(w, "%s\t", sinkInfo.Sink.Name)
+	switch {
+	case sinkInfo.Webhook != nil:
+		fmt.Fprintf(w, "webhook\t")
+	case sinkInfo.Slack != nil:
+		fmt.Fprintf(w, "slack\t")
+	case sinkInfo.File != nil:
+		fmt.Fprintf(w, "file\t")
+	default:
+		fmt.Fprintf(w, "-\t")
+	}
+	if len(sinkInfo.Events) == 0 {
+		fmt.Fprintf(w, "all\t")
+	} else {
+		var events []string
+		for _, event := range sinkInfo.Events {
+			events = append(events, event.String())
+		}
+		fmt.Fprintf(w, "%s\t", strings.Join(events, ", "))
+	}
+	fmt.Fprintf(w, "%s\t\n", pretty.Ago(sinkInfo.Created))
+}
+
+// PrintDeadLetter pretty-prints a notification event that couldn't be
+// delivered.
+func PrintDeadLetter(w io.Writer, deadLetter *ppsclient.DeadLetter) {
+	fmt.Fprintf(w, "%s\t", pretty.Ago(deadLetter.Time))
+	fmt.Fprintf(w, "%s\t", notify.Summary(deadLetter.Event))
+	fmt.Fprintf(w, "%s\t\n", safeTrim(deadLetter.Error, 80))
+}
+
+// PrintUsageInfo pretty-prints the resources used by a pipeline's (or label
+// value's) jobs.
+func PrintUsageInfo(w io.Writer, usageInfo *ppsclient.UsageInfo) {
+	name := usageInfo.Name
+	if name == "" {
+		name = "<none>"
+	}
+	fmt.Fprintf(w, "%s\t", name)
+	fmt.Fprintf(w, "%d\t", usageInfo.Jobs)
+	fmt.Fprintf(w, "%d\t", usageInfo.DataProcessed)
+	fmt.Fprintf(w, "%s\t", pretty.Duration(usageInfo.ProcessTime))
+	fmt.Fprintf(w, "%s\t", cpuTime(usageInfo.CpuSeconds))
+	fmt.Fprintf(w, "%s\t", pretty.Size(usageInfo.MemoryPeakBytes))
+	fmt.Fprintf(w, "%s\t", pretty.Size(usageInfo.DownloadBytes))
+	fmt.Fprintf(w, "%s\t\n", pretty.Size(usageInfo.UploadBytes))
+}
+
+func cpuTime(seconds float64) string {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Millisecond).String()
+}
+
+func shadowJob(jobInfo *ppsclient.JobInfo) string {
+	if jobInfo == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%s (%s)", jobInfo.Job.ID, JobState(jobInfo.State))
+}
+
+func jobDuration(jobInfo *ppsclient.JobInfo) string {
+	if jobInfo == nil || jobInfo.Finished == nil {
+		return "-"
+	}
+	return pretty.TimeDifference(jobInfo.Started, jobInfo.Finished)
+}
+
 // PrintFileHeader prints the header for a pfs file.
 func PrintFileHeader(w io.Writer) {
 	fmt.Fprintf(w, "  REPO\tCOMMIT\tPATH\t\n")
@@ -409,6 +531,13 @@ func jobCounts(counts map[int32]int32) string {
 	return buffer.String()
 }
 
+func prettyEgress(egress *ppsclient.Egress) string {
+	if egress.SQL != nil {
+		return fmt.Sprintf("%s table %s (%s, %s)", egress.SQL.Driver, egress.SQL.Table, egress.SQL.Format, egress.SQL.Mode)
+	}
+	return egress.URL
+}
+
 func prettyTransform(transform *ppsclient.Transform) (string, error) {
 	result, err := json.MarshalIndent(transform, "", "  ")
 	if err != nil {
@@ -444,6 +573,8 @@ func ShorthandInput(input *ppsclient.Input) string {
 		return "(" + strings.Join(subInput, " ∪ ") + ")"
 	case input.Cron != nil:
 		return fmt.Sprintf("%s:%s", input.Cron.Name, input.Cron.Spec)
+	case input.Kafka != nil:
+		return fmt.Sprintf("%s:%s/%d", input.Kafka.Name, input.Kafka.Topic, input.Kafka.Partition)
 	}
 	return ""
 }
@@ -461,4 +592,6 @@ var funcMap = template.FuncMap{
 	"prettySize":           pretty.Size,
 	"jobCounts":            jobCounts,
 	"prettyTransform":      prettyTransform,
+	"cpuTime":              cpuTime,
+	"prettyEgress":         prettyEgress,
 }
@@ -29,13 +29,17 @@ import (
 	"github.com/pachyderm/pachyderm/src/server/pkg/ancestry"
 	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
 	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	"github.com/pachyderm/pachyderm/src/server/pkg/errutil"
 	"github.com/pachyderm/pachyderm/src/server/pkg/hashtree"
 	"github.com/pachyderm/pachyderm/src/server/pkg/log"
 	"github.com/pachyderm/pachyderm/src/server/pkg/metrics"
+	"github.com/pachyderm/pachyderm/src/server/pkg/notify"
+	"github.com/pachyderm/pachyderm/src/server/pkg/outputvalidation"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ppsconsts"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ppsdb"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
 	"github.com/pachyderm/pachyderm/src/server/pkg/serviceenv"
+	"github.com/pachyderm/pachyderm/src/server/pkg/sqlegress"
 	txnenv "github.com/pachyderm/pachyderm/src/server/pkg/transactionenv"
 	"github.com/pachyderm/pachyderm/src/server/pkg/uuid"
 	"github.com/pachyderm/pachyderm/src/server/pkg/watch"
@@ -43,6 +47,7 @@ import (
 	workerpkg "github.com/pachyderm/pachyderm/src/server/worker"
 
 	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
 	"github.com/gogo/protobuf/types"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/robfig/cron"
@@ -111,8 +116,11 @@ type apiServer struct {
 	httpPort              uint16
 	peerPort              uint16
 	// collections
-	pipelines col.Collection
-	jobs      col.Collection
+	pipelines   col.Collection
+	jobs        col.Collection
+	templates   col.Collection
+	sinks       col.Collection
+	deadLetters col.Collection
 }
 
 func merge(from, to map[string]bool) {
@@ -165,6 +173,11 @@ func validateNames(names map[string]bool, input *pps.Input) error {
 			return errors.Errorf(`name "%s" was used more than once`, input.Git.Name)
 		}
 		names[input.Git.Name] = true
+	case input.Kafka != nil:
+		if names[input.Kafka.Name] {
+			return errors.Errorf(`name "%s" was used more than once`, input.Kafka.Name)
+		}
+		names[input.Kafka.Name] = true
 	}
 	return nil
 }
@@ -209,9 +222,11 @@ func (a *apiServer) validateInput(pachClient *client.APIClient, pipelineName str
 						"Pachyderm's S3 gateway rather than the file system")
 				}
 				// Note that input.Pfs.Commit is empty if a) this is a job b) one of
-				// the job pipeline's input branches has no commits yet
-				if job && input.Pfs.Commit != "" {
-					// for jobs we check that the input commit exists
+				// the job pipeline's input branches has no commits yet, or c) this
+				// is a pipeline whose input isn't pinned to a commit
+				if input.Pfs.Commit != "" {
+					// for jobs and pinned pipeline inputs we check that the input
+					// commit exists
 					if _, err := pachClient.InspectCommit(input.Pfs.Repo, input.Pfs.Commit); err != nil {
 						return err
 					}
@@ -269,6 +284,15 @@ func (a *apiServer) validateInput(pachClient *client.APIClient, pipelineName str
 					return err
 				}
 			}
+			if input.Kafka != nil {
+				if set {
+					return errors.Errorf("multiple input types set")
+				}
+				set = true
+				if err := validateKafkaInput(input.Kafka); err != nil {
+					return err
+				}
+			}
 			if !set {
 				return errors.Errorf("no input set")
 			}
@@ -290,6 +314,61 @@ func validateTransform(transform *pps.Transform) error {
 	return nil
 }
 
+func validateJobHooks(pipelineName string, hooks []*pps.JobHook) error {
+	for i, hook := range hooks {
+		if (len(hook.Cmd) == 0) == (hook.Pipeline == "") {
+			return errors.Errorf("invalid pipeline spec: job hook %d must set exactly one of cmd and pipeline", i)
+		}
+		if hook.Pipeline == pipelineName {
+			return errors.Errorf("invalid pipeline spec: job hook %d can't run its own pipeline", i)
+		}
+		if len(hook.States) == 0 {
+			return errors.Errorf("invalid pipeline spec: job hook %d must set states", i)
+		}
+		for _, state := range hook.States {
+			switch state {
+			case pps.JobState_JOB_SUCCESS, pps.JobState_JOB_FAILURE, pps.JobState_JOB_KILLED:
+			default:
+				return errors.Errorf("invalid pipeline spec: job hook %d can't run on %s, which isn't a terminal state", i, state)
+			}
+		}
+	}
+	return nil
+}
+
+// checkJobHookCycles returns an error if the job hooks 'hooks' of the pipeline
+// 'pipelineName' would form a cycle, e.g. if pipeline A hooks pipeline B and B
+// hooks A. 'getHooks' returns the job hooks of an existing pipeline (or nil, if
+// it doesn't exist).
+func checkJobHookCycles(pipelineName string, hooks []*pps.JobHook, getHooks func(pipeline string) ([]*pps.JobHook, error)) error {
+	visited := make(map[string]bool)
+	var visit func(path []string, hooks []*pps.JobHook) error
+	visit = func(path []string, hooks []*pps.JobHook) error {
+		for _, hook := range hooks {
+			if hook.Pipeline == "" {
+				continue
+			}
+			if hook.Pipeline == pipelineName {
+				return errors.Errorf("invalid pipeline spec: job hooks form a cycle (%s)",
+					strings.Join(append(path, pipelineName), " -> "))
+			}
+			if visited[hook.Pipeline] {
+				continue
+			}
+			visited[hook.Pipeline] = true
+			next, err := getHooks(hook.Pipeline)
+			if err != nil {
+				return err
+			}
+			if err := visit(append(path[:len(path):len(path)], hook.Pipeline), next); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return visit([]string{pipelineName}, hooks)
+}
+
 func (a *apiServer) validateKube() {
 	errors := false
 	kubeClient := a.env.GetKubeClient()
@@ -394,6 +473,8 @@ const (
 	pipelineOpUpdate
 	// pipelineOpUpdate is required for DeletePipeline
 	pipelineOpDelete
+	// pipelineOpDryRun is required for DryRunPipeline
+	pipelineOpDryRun
 )
 
 // authorizePipelineOp checks if the user indicated by 'ctx' is authorized
@@ -465,6 +546,8 @@ func (a *apiServer) authorizePipelineOp(pachClient *client.APIClient, operation
 		}
 	case pipelineOpListDatum, pipelineOpGetLogs:
 		required = auth.Scope_READER
+	case pipelineOpDryRun:
+		// A dry run only reads the pipeline's inputs, which were checked above
 	case pipelineOpUpdate:
 		required = auth.Scope_WRITER
 	case pipelineOpDelete:
@@ -533,6 +616,16 @@ func (a *apiServer) CreateJob(ctx context.Context, request *pps.CreateJobRequest
 	if request.Stats == nil {
 		request.Stats = &pps.ProcessStats{}
 	}
+	if request.Metadata == nil {
+		// Stamp the job with the metadata of the pipeline version that created
+		// it, so that later updates to the pipeline don't change how the job is
+		// labelled (e.g. in GetUsage)
+		pipelineInfo, err := a.jobPipelineInfo(pachClient, request.Pipeline, request.OutputCommit)
+		if err != nil {
+			return nil, err
+		}
+		request.Metadata = pipelineInfo.Metadata
+	}
 	_, err = col.NewSTM(ctx, a.env.GetEtcdClient(), func(stm col.STM) error {
 		jobPtr := &pps.EtcdJobInfo{
 			Job:           job,
@@ -548,6 +641,7 @@ func (a *apiServer) CreateJob(ctx context.Context, request *pps.CreateJobRequest
 			StatsCommit:   request.StatsCommit,
 			Started:       request.Started,
 			Finished:      request.Finished,
+			Metadata:      request.Metadata,
 		}
 		return ppsutil.UpdateJobState(a.pipelines.ReadWrite(stm), a.jobs.ReadWrite(stm), jobPtr, request.State, request.Reason)
 	})
@@ -557,6 +651,26 @@ func (a *apiServer) CreateJob(ctx context.Context, request *pps.CreateJobRequest
 	return job, nil
 }
 
+// jobPipelineInfo returns the version of 'pipeline' that created
+// 'outputCommit', i.e. the spec commit in the output commit's provenance
+func (a *apiServer) jobPipelineInfo(pachClient *client.APIClient, pipeline *pps.Pipeline, outputCommit *pfs.Commit) (*pps.PipelineInfo, error) {
+	commitInfo, err := pachClient.InspectCommit(outputCommit.Repo.Name, outputCommit.ID)
+	if err != nil {
+		return nil, err
+	}
+	pipelinePtr := &pps.EtcdPipelineInfo{}
+	if err := a.pipelines.ReadOnly(pachClient.Ctx()).Get(pipeline.Name, pipelinePtr); err != nil {
+		return nil, err
+	}
+	for _, prov := range commitInfo.Provenance {
+		if prov.Commit.Repo.Name == ppsconsts.SpecRepo && prov.Branch.Name == pipeline.Name {
+			pipelinePtr.SpecCommit = prov.Commit
+			break
+		}
+	}
+	return ppsutil.GetPipelineInfo(pachClient, pipelinePtr)
+}
+
 // InspectJob implements the protobuf pps.InspectJob RPC
 func (a *apiServer) InspectJob(ctx context.Context, request *pps.InspectJobRequest) (response *pps.JobInfo, retErr error) {
 	func() { a.Log(request, nil, nil, 0) }()
@@ -776,6 +890,7 @@ func (a *apiServer) jobInfoFromPtr(pachClient *client.APIClient, jobPtr *pps.Etc
 		Reason:        jobPtr.Reason,
 		Started:       jobPtr.Started,
 		Finished:      jobPtr.Finished,
+		Metadata:      jobPtr.Metadata,
 	}
 	commitInfo, err := pachClient.InspectCommit(jobPtr.OutputCommit.Repo.Name, jobPtr.OutputCommit.ID)
 	if err != nil {
@@ -1618,6 +1733,30 @@ func (a *apiServer) validatePipeline(pachClient *client.APIClient, pipelineInfo
 			return errors.New("invalid pipeline spec: HashtreeSpec.Constant must be > 0")
 		}
 	}
+	if pipelineInfo.Egress != nil && pipelineInfo.Egress.SQL != nil {
+		if pipelineInfo.Egress.URL != "" {
+			return errors.New("invalid pipeline spec: only one of Egress.URL and Egress.SQL may be set")
+		}
+		if err := sqlegress.Validate(pipelineInfo.Egress.SQL); err != nil {
+			return err
+		}
+	}
+	if err := validateJobHooks(pipelineInfo.Pipeline.Name, pipelineInfo.JobHooks); err != nil {
+		return err
+	}
+	if err := checkJobHookCycles(pipelineInfo.Pipeline.Name, pipelineInfo.JobHooks, func(pipeline string) ([]*pps.JobHook, error) {
+		return a.jobHooks(pachClient, pipeline)
+	}); err != nil {
+		return err
+	}
+	if pipelineInfo.Validation != nil {
+		if pipelineInfo.S3Out {
+			return errors.New("invalid pipeline spec: validation can't be used with s3_out")
+		}
+		if err := outputvalidation.Validate(pipelineInfo.Validation); err != nil {
+			return err
+		}
+	}
 	if pipelineInfo.OutputBranch == "" {
 		return errors.New("pipeline needs to specify an output branch")
 	}
@@ -1682,6 +1821,9 @@ func branchProvenance(input *pps.Input) []*pfs.Branch {
 		if input.Git != nil {
 			result = append(result, client.NewBranch(input.Git.Name, input.Git.Branch))
 		}
+		if input.Kafka != nil {
+			result = append(result, client.NewBranch(input.Kafka.Repo, "master"))
+		}
 	})
 	return result
 }
@@ -1819,6 +1961,8 @@ func (a *apiServer) fixPipelineInputRepoACLs(pachClient *client.APIClient, pipel
 				repo = input.Cron.Repo
 			case input.Git != nil:
 				repo = input.Git.Name
+			case input.Kafka != nil:
+				repo = input.Kafka.Repo
 			default:
 				return // no scope to set: input is not a repo
 			}
@@ -1848,6 +1992,8 @@ func (a *apiServer) fixPipelineInputRepoACLs(pachClient *client.APIClient, pipel
 				repo = input.Cron.Repo
 			case input.Git != nil:
 				repo = input.Git.Name
+			case input.Kafka != nil:
+				repo = input.Kafka.Repo
 			default:
 				return // no scope to set: input is not a repo
 			}
@@ -1941,6 +2087,20 @@ func (a *apiServer) CreatePipeline(ctx context.Context, request *pps.CreatePipel
 	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
 	metricsFn := metrics.ReportUserAction(ctx, a.reporter, "CreatePipeline")
 	defer func(start time.Time) { metricsFn(start, retErr) }(time.Now())
+	return a.createPipeline(ctx, request, 0)
+}
+
+// createPipeline creates or updates the pipeline described by 'request'. If
+// 'restoredFromVersion' is set, the new pipeline version records that it was
+// restored from that earlier version by RollbackPipeline.
+func (a *apiServer) createPipeline(ctx context.Context, request *pps.CreatePipelineRequest, restoredFromVersion uint64) (response *types.Empty, retErr error) {
+	// A shadow is a separate pipeline, named after the one that it shadows
+	var shadowOf string
+	if request.Shadow && request.Pipeline != nil {
+		shadowOf = request.Pipeline.Name
+		request.Pipeline = client.NewPipeline(ppsutil.ShadowPipelineName(shadowOf))
+		request.Update = true
+	}
 
 	// Validate request
 	if err := a.validatePipelineRequest(request); err != nil {
@@ -1959,41 +2119,18 @@ func (a *apiServer) CreatePipeline(ctx context.Context, request *pps.CreatePipel
 	pachClient := a.env.GetPachClient(ctx)
 	ctx = pachClient.Ctx() // GetPachClient propagates auth info to inner ctx
 	pfsClient := pachClient.PfsAPIClient
+	if shadowOf != "" {
+		if err := a.validateShadow(pachClient, shadowOf); err != nil {
+			return nil, err
+		}
+	}
 	// Reprocess overrides the salt in the request
 	if request.Salt == "" || request.Reprocess {
 		request.Salt = uuid.NewWithoutDashes()
 	}
-	pipelineInfo := &pps.PipelineInfo{
-		Pipeline:         request.Pipeline,
-		Version:          1,
-		Transform:        request.Transform,
-		TFJob:            request.TFJob,
-		ParallelismSpec:  request.ParallelismSpec,
-		HashtreeSpec:     request.HashtreeSpec,
-		Input:            request.Input,
-		OutputBranch:     request.OutputBranch,
-		Egress:           request.Egress,
-		CreatedAt:        now(),
-		ResourceRequests: request.ResourceRequests,
-		ResourceLimits:   request.ResourceLimits,
-		Description:      request.Description,
-		CacheSize:        request.CacheSize,
-		EnableStats:      request.EnableStats,
-		Salt:             request.Salt,
-		MaxQueueSize:     request.MaxQueueSize,
-		Service:          request.Service,
-		Spout:            request.Spout,
-		ChunkSpec:        request.ChunkSpec,
-		DatumTimeout:     request.DatumTimeout,
-		JobTimeout:       request.JobTimeout,
-		Standby:          request.Standby,
-		DatumTries:       request.DatumTries,
-		SchedulingSpec:   request.SchedulingSpec,
-		PodSpec:          request.PodSpec,
-		PodPatch:         request.PodPatch,
-		S3Out:            request.S3Out,
-		Metadata:         request.Metadata,
-	}
+	pipelineInfo := pipelineInfoFromRequest(request)
+	pipelineInfo.RestoredFromVersion = restoredFromVersion
+	pipelineInfo.ShadowOf = shadowOf
 	if err := setPipelineDefaults(pipelineInfo); err != nil {
 		return nil, err
 	}
@@ -2022,6 +2159,15 @@ func (a *apiServer) CreatePipeline(ctx context.Context, request *pps.CreatePipel
 				visitErr = err
 			}
 		}
+		if input.Kafka != nil {
+			if _, err := pachClient.PfsAPIClient.CreateRepo(pachClient.Ctx(),
+				&pfs.CreateRepoRequest{
+					Repo:        client.NewRepo(input.Kafka.Repo),
+					Description: fmt.Sprintf("Kafka input repo for pipeline %s.", request.Pipeline.Name),
+				}); err != nil && !isAlreadyExistsErr(err) {
+				visitErr = err
+			}
+		}
 	})
 	if visitErr != nil {
 		return nil, visitErr
@@ -2035,6 +2181,9 @@ func (a *apiServer) CreatePipeline(ctx context.Context, request *pps.CreatePipel
 	if err := a.authorizePipelineOp(pachClient, operation, pipelineInfo.Input, pipelineInfo.Pipeline.Name); err != nil {
 		return nil, err
 	}
+	if err := a.pinInputs(pachClient, pipelineInfo.Input); err != nil {
+		return nil, err
+	}
 	pipelineName := pipelineInfo.Pipeline.Name
 	pps.SortInput(pipelineInfo.Input) // Makes datum hashes comparable
 	update := false
@@ -2267,6 +2416,246 @@ func (a *apiServer) CreatePipeline(ctx context.Context, request *pps.CreatePipel
 	return &types.Empty{}, nil
 }
 
+// pipelineInfoFromRequest creates the initial PipelineInfo (before defaults are
+// set) for the pipeline described by 'request'
+func pipelineInfoFromRequest(request *pps.CreatePipelineRequest) *pps.PipelineInfo {
+	return &pps.PipelineInfo{
+		Pipeline:         request.Pipeline,
+		Version:          1,
+		Transform:        request.Transform,
+		TFJob:            request.TFJob,
+		ParallelismSpec:  request.ParallelismSpec,
+		HashtreeSpec:     request.HashtreeSpec,
+		Input:            request.Input,
+		OutputBranch:     request.OutputBranch,
+		Egress:           request.Egress,
+		CreatedAt:        now(),
+		ResourceRequests: request.ResourceRequests,
+		ResourceLimits:   request.ResourceLimits,
+		Description:      request.Description,
+		CacheSize:        request.CacheSize,
+		EnableStats:      request.EnableStats,
+		Salt:             request.Salt,
+		MaxQueueSize:     request.MaxQueueSize,
+		Service:          request.Service,
+		Spout:            request.Spout,
+		ChunkSpec:        request.ChunkSpec,
+		DatumTimeout:     request.DatumTimeout,
+		JobTimeout:       request.JobTimeout,
+		Standby:          request.Standby,
+		DatumTries:       request.DatumTries,
+		SchedulingSpec:   request.SchedulingSpec,
+		PodSpec:          request.PodSpec,
+		PodPatch:         request.PodPatch,
+		S3Out:            request.S3Out,
+		Metadata:         request.Metadata,
+		Validation:       request.Validation,
+		JobHooks:         request.JobHooks,
+	}
+}
+
+// DryRunPipeline implements the protobuf pps.DryRunPipeline RPC
+func (a *apiServer) DryRunPipeline(ctx context.Context, request *pps.CreatePipelineRequest) (response *pps.DryRunPipelineResponse, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	if err := a.validatePipelineRequest(request); err != nil {
+		return nil, err
+	}
+	pachClient := a.env.GetPachClient(ctx)
+	return a.dryRunPipeline(pachClient, request)
+}
+
+// dryRunPipeline contains the functional implementation of DryRunPipeline. It
+// validates 'request' the same way CreatePipeline does, and then computes the
+// datums the pipeline would see at the current heads of its inputs. Nothing is
+// created or modified.
+func (a *apiServer) dryRunPipeline(pachClient *client.APIClient, request *pps.CreatePipelineRequest) (*pps.DryRunPipelineResponse, error) {
+	// Work on a copy, so that setting defaults and resolving input commits
+	// doesn't modify the caller's request
+	request = proto.Clone(request).(*pps.CreatePipelineRequest)
+	pipelineInfo := pipelineInfoFromRequest(request)
+	if err := setPipelineDefaults(pipelineInfo); err != nil {
+		return nil, err
+	}
+	if err := a.validatePipeline(pachClient, pipelineInfo); err != nil {
+		return nil, err
+	}
+	if err := a.authorizePipelineOp(pachClient, pipelineOpDryRun, pipelineInfo.Input, pipelineInfo.Pipeline.Name); err != nil {
+		return nil, err
+	}
+	response := &pps.DryRunPipelineResponse{Pipeline: pipelineInfo.Pipeline}
+	if pipelineInfo.Input == nil {
+		return response, nil // spouts have no datums
+	}
+	pps.SortInput(pipelineInfo.Input) // Makes datum hashes comparable
+	if err := resolveInputHeads(pachClient, pipelineInfo.Input); err != nil {
+		return nil, err
+	}
+
+	// If this is an update that doesn't reprocess, the new pipeline keeps the
+	// old pipeline's salt, and skips the datums that it already processed
+	var processed map[string]uint64
+	if request.Update && !request.Reprocess {
+		oldPipelineInfo, err := a.inspectPipeline(pachClient, pipelineInfo.Pipeline.Name)
+		if err != nil && !isNotFoundErr(err) {
+			return nil, err
+		}
+		if oldPipelineInfo != nil {
+			pipelineInfo.Salt = oldPipelineInfo.Salt
+			processed, err = processedDatums(pachClient,
+				client.NewCommit(pipelineInfo.Pipeline.Name, oldPipelineInfo.OutputBranch))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	df, err := workerpkg.NewDatumIterator(pachClient, pipelineInfo.Input)
+	if err != nil {
+		return nil, err
+	}
+	response.Datums = int64(df.Len())
+	for i := 0; i < df.Len(); i++ {
+		datum := df.DatumN(i)
+		for _, input := range datum {
+			response.InputBytes += input.FileInfo.SizeBytes
+		}
+		if processed[workerpkg.HashDatum(pipelineInfo.Pipeline.Name, pipelineInfo.Salt, datum)] > 0 {
+			response.DatumsSkipped++
+		}
+	}
+
+	// Report the individual inputs that match nothing, as these are usually
+	// the cause of a pipeline with no datums
+	var visitErr error
+	pps.VisitInput(pipelineInfo.Input, func(input *pps.Input) {
+		var name string
+		switch {
+		case input.Pfs != nil:
+			name = input.Pfs.Name
+		case input.Cron != nil:
+			name = input.Cron.Name
+		case input.Git != nil:
+			name = input.Git.Name
+		case input.Kafka != nil:
+			name = input.Kafka.Name
+		default:
+			return
+		}
+		if visitErr != nil {
+			return
+		}
+		df, err := workerpkg.NewDatumIterator(pachClient, input)
+		if err != nil {
+			visitErr = err
+			return
+		}
+		if df.Len() == 0 {
+			response.EmptyInputs = append(response.EmptyInputs, name)
+		}
+	})
+	if visitErr != nil {
+		return nil, visitErr
+	}
+	return response, nil
+}
+
+// pinInputs points each PFS input in 'input' that is pinned to a commit (e.g.
+// "master~2" or "master@{2020-05-01}") at a branch whose head is the commit
+// that it currently resolves to, creating the branch if needed. The pipeline's
+// output is then provenant on that branch, which never moves, so every job of
+// the pipeline reads the same data.
+func (a *apiServer) pinInputs(pachClient *client.APIClient, input *pps.Input) error {
+	var result error
+	pps.VisitInput(input, func(input *pps.Input) {
+		if result != nil || input.Pfs == nil || input.Pfs.Commit == "" {
+			return
+		}
+		commitInfo, err := pachClient.InspectCommit(input.Pfs.Repo, input.Pfs.Commit)
+		if err != nil {
+			result = err
+			return
+		}
+		input.Pfs.Commit = commitInfo.Commit.ID
+		input.Pfs.Branch = ppsutil.PinnedBranchName(input.Pfs.Commit)
+		// The caller may only be able to read the input repo, and pinned
+		// branches are shared by every pipeline pinned to the same commit, so
+		// they're created by the superuser. Existing branches aren't touched, as
+		// resetting their heads would start new jobs downstream.
+		result = a.sudo(pachClient, func(superUserClient *client.APIClient) error {
+			if _, err := superUserClient.InspectBranch(input.Pfs.Repo, input.Pfs.Branch); err == nil {
+				return nil
+			} else if !isNotFoundErr(err) {
+				return err
+			}
+			return superUserClient.CreateBranch(input.Pfs.Repo, input.Pfs.Branch, input.Pfs.Commit, nil)
+		})
+	})
+	return result
+}
+
+// resolveInputHeads sets the commit of each leaf input in 'input' to the
+// current head of the branch that it reads from. Inputs whose branch doesn't
+// exist or has no commits are left without a commit, and so have no datums.
+func resolveInputHeads(pachClient *client.APIClient, input *pps.Input) error {
+	var result error
+	pps.VisitInput(input, func(input *pps.Input) {
+		var repo, branch string
+		var commit *string
+		switch {
+		case input.Pfs != nil:
+			repo, branch, commit = input.Pfs.Repo, input.Pfs.Branch, &input.Pfs.Commit
+		case input.Cron != nil:
+			repo, branch, commit = input.Cron.Repo, "master", &input.Cron.Commit
+		case input.Git != nil:
+			repo, branch, commit = input.Git.Name, input.Git.Branch, &input.Git.Commit
+		case input.Kafka != nil:
+			repo, branch, commit = input.Kafka.Repo, "master", &input.Kafka.Commit
+		default:
+			return
+		}
+		if result != nil || *commit != "" {
+			return
+		}
+		branchInfo, err := pachClient.InspectBranch(repo, branch)
+		if err != nil {
+			if !isNotFoundErr(err) {
+				result = err
+			}
+			return
+		}
+		if branchInfo.Head != nil {
+			*commit = branchInfo.Head.ID
+		}
+	})
+	return result
+}
+
+// processedDatums returns the hashes of the datums processed in the most
+// recent commit on or before 'commit' that has output, or nil if there's no
+// such commit
+func processedDatums(pachClient *client.APIClient, commit *pfs.Commit) (map[string]uint64, error) {
+	commitInfo, err := pachClient.InspectCommit(commit.Repo.Name, commit.ID)
+	if err != nil {
+		if isNotFoundErr(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	for {
+		if commitInfo.Finished != nil && commitInfo.Trees != nil {
+			return workerpkg.GetDatumMap(pachClient, commitInfo.Datums)
+		}
+		if commitInfo.ParentCommit == nil {
+			return nil, nil
+		}
+		commitInfo, err = pachClient.InspectCommit(commitInfo.ParentCommit.Repo.Name, commitInfo.ParentCommit.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
 // setPipelineDefaults sets the default values for a pipeline info
 func setPipelineDefaults(pipelineInfo *pps.PipelineInfo) error {
 	now := time.Now()
@@ -2302,6 +2691,11 @@ func setPipelineDefaults(pipelineInfo *pps.PipelineInfo) error {
 				input.Git.Name = tokens[0]
 			}
 		}
+		if input.Kafka != nil {
+			if input.Kafka.Repo == "" {
+				input.Kafka.Repo = fmt.Sprintf("%s_%s", pipelineInfo.Pipeline.Name, input.Kafka.Name)
+			}
+		}
 	})
 	if pipelineInfo.OutputBranch == "" {
 		// Output branches default to master
@@ -2651,7 +3045,7 @@ func (a *apiServer) deletePipeline(pachClient *client.APIClient, request *pps.De
 		}
 		return nil
 	})
-	// Delete cron input repos
+	// Delete cron and kafka input repos
 	if !request.KeepRepo {
 		pps.VisitInput(pipelineInfo.Input, func(input *pps.Input) {
 			if input.Cron != nil {
@@ -2659,6 +3053,11 @@ func (a *apiServer) deletePipeline(pachClient *client.APIClient, request *pps.De
 					return pachClient.DeleteRepo(input.Cron.Repo, request.Force)
 				})
 			}
+			if input.Kafka != nil {
+				eg.Go(func() error {
+					return pachClient.DeleteRepo(input.Kafka.Repo, request.Force)
+				})
+			}
 		})
 	}
 	if err := eg.Wait(); err != nil {
@@ -2748,27 +3147,326 @@ func (a *apiServer) StopPipeline(ctx context.Context, request *pps.StopPipelineR
 	return &types.Empty{}, nil
 }
 
-func (a *apiServer) RunPipeline(ctx context.Context, request *pps.RunPipelineRequest) (response *types.Empty, retErr error) {
+// RollbackPipeline implements the protobuf pps.RollbackPipeline RPC
+func (a *apiServer) RollbackPipeline(ctx context.Context, request *pps.RollbackPipelineRequest) (response *types.Empty, retErr error) {
 	func() { a.Log(request, nil, nil, 0) }()
 	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
-
 	pachClient := a.env.GetPachClient(ctx)
-	ctx = pachClient.Ctx() // pachClient will propagate auth info
-	pfsClient := pachClient.PfsAPIClient
-	ppsClient := pachClient.PpsAPIClient
+	if request.Pipeline == nil {
+		return nil, errors.New("request.Pipeline cannot be nil")
+	}
+	if request.Version == 0 {
+		return nil, errors.New("must specify the version to roll back to")
+	}
 
-	pipelineInfo, err := a.inspectPipeline(pachClient, request.Pipeline.Name)
-	if err != nil {
+	// Find the most recent spec commit with the requested version (stopping and
+	// starting a pipeline writes new spec commits without changing its version)
+	var current, target *pps.PipelineInfo
+	if err := a.listPipelinePtr(pachClient, request.Pipeline, -1, func(ptr *pps.EtcdPipelineInfo) error {
+		pipelineInfo, err := ppsutil.GetPipelineInfo(pachClient, ptr)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			current = pipelineInfo
+		}
+		if pipelineInfo.Version == request.Version {
+			target = pipelineInfo
+			return errutil.ErrBreak
+		}
+		return nil
+	}); err != nil && err != errutil.ErrBreak {
 		return nil, err
 	}
-	// make sure the user isn't trying to run pipeline on an empty branch
-	branch, err := pfsClient.InspectBranch(ctx, &pfs.InspectBranchRequest{
-		Branch: client.NewBranch(request.Pipeline.Name, pipelineInfo.OutputBranch),
-	})
-	if err != nil {
-		return nil, err
+	if target == nil {
+		return nil, errors.Errorf("pipeline %q has no version %d", request.Pipeline.Name, request.Version)
 	}
-	if branch.Head == nil {
+	if target.Version == current.Version {
+		return nil, errors.Errorf("pipeline %q is already at version %d", request.Pipeline.Name, request.Version)
+	}
+
+	// Re-apply the old spec as an update
+	createRequest := ppsutil.PipelineReqFromInfo(target)
+	createRequest.TFJob = target.TFJob
+	createRequest.Update = true
+	createRequest.Reprocess = request.Reprocess
+	return a.createPipeline(ctx, createRequest, target.Version)
+}
+
+// validateShadow checks that a shadow of the pipeline 'shadowOf' can be
+// created: 'shadowOf' must exist and not be a shadow itself, and the shadow's
+// name mustn't be taken by an unrelated pipeline
+func (a *apiServer) validateShadow(pachClient *client.APIClient, shadowOf string) error {
+	liveInfo, err := a.inspectPipeline(pachClient, shadowOf)
+	if err != nil {
+		return errors.Wrapf(err, "cannot shadow pipeline %q", shadowOf)
+	}
+	if liveInfo.ShadowOf != "" {
+		return errors.Errorf("cannot shadow pipeline %q, as it's a shadow of %q", shadowOf, liveInfo.ShadowOf)
+	}
+	shadowInfo, err := a.inspectPipeline(pachClient, ppsutil.ShadowPipelineName(shadowOf))
+	if err != nil {
+		if isNotFoundErr(err) {
+			return nil
+		}
+		return err
+	}
+	if shadowInfo.ShadowOf != shadowOf {
+		return errors.Errorf("cannot shadow pipeline %q, as pipeline %q already exists and isn't its shadow", shadowOf, shadowInfo.Pipeline.Name)
+	}
+	return nil
+}
+
+// inspectShadow returns the PipelineInfo of the shadow of 'pipeline'
+func (a *apiServer) inspectShadow(pachClient *client.APIClient, pipeline *pps.Pipeline) (*pps.PipelineInfo, error) {
+	if pipeline == nil {
+		return nil, errors.New("request.Pipeline cannot be nil")
+	}
+	shadowInfo, err := a.inspectPipeline(pachClient, ppsutil.ShadowPipelineName(pipeline.Name))
+	if err != nil {
+		if isNotFoundErr(err) {
+			return nil, errors.Errorf("pipeline %q has no shadow", pipeline.Name)
+		}
+		return nil, err
+	}
+	if shadowInfo.ShadowOf != pipeline.Name {
+		return nil, errors.Errorf("pipeline %q has no shadow (%q isn't a shadow of it)", pipeline.Name, shadowInfo.Pipeline.Name)
+	}
+	return shadowInfo, nil
+}
+
+// inputCommitsKey identifies the set of input commits that a job ran on, so
+// that jobs run by a pipeline and its shadow can be matched up
+func inputCommitsKey(commits []*pfs.Commit) string {
+	var keys []string
+	for _, commit := range commits {
+		keys = append(keys, commit.Repo.Name+"@"+commit.ID)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+// InspectShadow implements the protobuf pps.InspectShadow RPC
+func (a *apiServer) InspectShadow(ctx context.Context, request *pps.InspectShadowRequest) (response *pps.ShadowReport, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	pachClient := a.env.GetPachClient(ctx)
+	shadowInfo, err := a.inspectShadow(pachClient, request.Pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	// Index the live pipeline's jobs by their input commits, keeping the most
+	// recent job for each (a job may be rerun, e.g. by RunPipeline)
+	liveJobs := make(map[string]*pps.JobInfo)
+	if err := a.listJob(pachClient, request.Pipeline, nil, nil, -1, true, func(jobInfo *pps.JobInfo) error {
+		key := inputCommitsKey(pps.InputCommits(jobInfo.Input))
+		if prev, ok := liveJobs[key]; !ok || prev.Started.Compare(jobInfo.Started) < 0 {
+			liveJobs[key] = jobInfo
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	response = &pps.ShadowReport{
+		Pipeline: request.Pipeline,
+		Shadow:   shadowInfo.Pipeline,
+	}
+	if err := a.listJob(pachClient, shadowInfo.Pipeline, nil, nil, -1, true, func(jobInfo *pps.JobInfo) error {
+		inputCommits := pps.InputCommits(jobInfo.Input)
+		report := &pps.ShadowCommitReport{
+			InputCommits: inputCommits,
+			LiveJob:      liveJobs[inputCommitsKey(inputCommits)],
+			ShadowJob:    jobInfo,
+		}
+		if report.LiveJob != nil &&
+			report.LiveJob.State == pps.JobState_JOB_SUCCESS &&
+			report.ShadowJob.State == pps.JobState_JOB_SUCCESS {
+			if err := diffShadowOutput(pachClient, report); err != nil {
+				return err
+			}
+		}
+		response.Commits = append(response.Commits, report)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(response.Commits, func(i, j int) bool {
+		return response.Commits[i].ShadowJob.Started.Compare(response.Commits[j].ShadowJob.Started) > 0
+	})
+	return response, nil
+}
+
+// diffShadowOutput fills in the files that differ between the output commits
+// of 'report's live and shadow jobs
+func diffShadowOutput(pachClient *client.APIClient, report *pps.ShadowCommitReport) error {
+	live, shadow := report.LiveJob.OutputCommit, report.ShadowJob.OutputCommit
+	newFiles, oldFiles, err := pachClient.DiffFile(
+		shadow.Repo.Name, shadow.ID, "/", live.Repo.Name, live.ID, "/", false)
+	if err != nil {
+		return errors.Wrapf(err, "could not diff output of job %s against job %s",
+			report.ShadowJob.Job.ID, report.LiveJob.Job.ID)
+	}
+	// A changed file appears in both lists
+	inLive := make(map[string]bool)
+	for _, fileInfo := range oldFiles {
+		if fileInfo.FileType == pfs.FileType_FILE {
+			inLive[fileInfo.File.Path] = true
+		}
+	}
+	for _, fileInfo := range newFiles {
+		if fileInfo.FileType != pfs.FileType_FILE {
+			continue
+		}
+		if inLive[fileInfo.File.Path] {
+			report.Changed = append(report.Changed, fileInfo.File.Path)
+			delete(inLive, fileInfo.File.Path)
+		} else {
+			report.Added = append(report.Added, fileInfo.File.Path)
+		}
+	}
+	for path := range inLive {
+		report.Deleted = append(report.Deleted, path)
+	}
+	sort.Strings(report.Added)
+	sort.Strings(report.Deleted)
+	sort.Strings(report.Changed)
+	return nil
+}
+
+// PromoteShadow implements the protobuf pps.PromoteShadow RPC
+func (a *apiServer) PromoteShadow(ctx context.Context, request *pps.PromoteShadowRequest) (response *types.Empty, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	pachClient := a.env.GetPachClient(ctx)
+	shadowInfo, err := a.inspectShadow(pachClient, request.Pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply the shadow's spec to the live pipeline as an update
+	createRequest := ppsutil.PipelineReqFromInfo(shadowInfo)
+	createRequest.TFJob = shadowInfo.TFJob
+	createRequest.Pipeline = request.Pipeline
+	createRequest.Update = true
+	createRequest.Reprocess = request.Reprocess
+	if _, err := a.createPipeline(ctx, createRequest, 0); err != nil {
+		return nil, err
+	}
+	if _, err := a.deletePipeline(pachClient, &pps.DeletePipelineRequest{
+		Pipeline: shadowInfo.Pipeline,
+	}); err != nil {
+		return nil, errors.Wrapf(err, "promoted shadow, but could not delete it")
+	}
+	// deletePipeline skips the output repo if the shadow's pipeline was
+	// already partially deleted, so make sure that it's gone
+	if err := pachClient.DeleteRepo(shadowInfo.Pipeline.Name, false); err != nil && !isNotFoundErr(err) {
+		return nil, errors.Wrapf(err, "promoted shadow, but could not delete its output repo")
+	}
+	return &types.Empty{}, nil
+}
+
+// GetUsage implements the protobuf pps.GetUsage RPC
+func (a *apiServer) GetUsage(ctx context.Context, request *pps.GetUsageRequest) (response *pps.UsageInfos, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	pachClient := a.env.GetPachClient(ctx)
+	var from, to time.Time
+	if request.From != nil {
+		var err error
+		if from, err = types.TimestampFromProto(request.From); err != nil {
+			return nil, err
+		}
+	}
+	if request.To != nil {
+		var err error
+		if to, err = types.TimestampFromProto(request.To); err != nil {
+			return nil, err
+		}
+	}
+
+	usage := make(map[string]*pps.UsageInfo)
+	processTime := make(map[string]time.Duration)
+	if err := a.listJob(pachClient, request.Pipeline, nil, nil, -1, false, func(jobInfo *pps.JobInfo) error {
+		if jobInfo.Started == nil {
+			return nil
+		}
+		started, err := types.TimestampFromProto(jobInfo.Started)
+		if err != nil {
+			return err
+		}
+		if (!from.IsZero() && started.Before(from)) || (!to.IsZero() && !started.Before(to)) {
+			return nil
+		}
+		// Jobs are rolled up by the value of request.Label in the pipeline's
+		// metadata when the job was created. Jobs without the label are rolled
+		// up under "".
+		name := jobInfo.Pipeline.Name
+		if request.Label != "" {
+			name = ""
+			if jobInfo.Metadata != nil {
+				name = jobInfo.Metadata.Labels[request.Label]
+			}
+		}
+		usageInfo, ok := usage[name]
+		if !ok {
+			usageInfo = &pps.UsageInfo{Name: name}
+			usage[name] = usageInfo
+		}
+		usageInfo.Jobs++
+		usageInfo.DataProcessed += jobInfo.DataProcessed
+		if stats := jobInfo.Stats; stats != nil {
+			if stats.ProcessTime != nil {
+				d, err := types.DurationFromProto(stats.ProcessTime)
+				if err != nil {
+					return err
+				}
+				processTime[name] += d
+			}
+			usageInfo.CpuSeconds += stats.CpuSeconds
+			if stats.MemoryPeakBytes > usageInfo.MemoryPeakBytes {
+				usageInfo.MemoryPeakBytes = stats.MemoryPeakBytes
+			}
+			usageInfo.DownloadBytes += stats.DownloadBytes
+			usageInfo.UploadBytes += stats.UploadBytes
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	response = &pps.UsageInfos{}
+	for name, usageInfo := range usage {
+		usageInfo.ProcessTime = types.DurationProto(processTime[name])
+		response.UsageInfo = append(response.UsageInfo, usageInfo)
+	}
+	sort.Slice(response.UsageInfo, func(i, j int) bool {
+		return response.UsageInfo[i].Name < response.UsageInfo[j].Name
+	})
+	return response, nil
+}
+
+func (a *apiServer) RunPipeline(ctx context.Context, request *pps.RunPipelineRequest) (response *types.Empty, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+
+	pachClient := a.env.GetPachClient(ctx)
+	ctx = pachClient.Ctx() // pachClient will propagate auth info
+	pfsClient := pachClient.PfsAPIClient
+	ppsClient := pachClient.PpsAPIClient
+
+	pipelineInfo, err := a.inspectPipeline(pachClient, request.Pipeline.Name)
+	if err != nil {
+		return nil, err
+	}
+	// make sure the user isn't trying to run pipeline on an empty branch
+	branch, err := pfsClient.InspectBranch(ctx, &pfs.InspectBranchRequest{
+		Branch: client.NewBranch(request.Pipeline.Name, pipelineInfo.OutputBranch),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if branch.Head == nil {
 		return nil, errors.Errorf("run pipeline needs a pipeline with existing data to run\nnew commits will trigger the pipeline automatically, so this only needs to be used if you need to run the pipeline on an old version of the data, or to rerun an job")
 	}
 
@@ -2888,7 +3586,8 @@ func (a *apiServer) RunPipeline(ctx context.Context, request *pps.RunPipelineReq
 					Name: request.Pipeline.Name,
 				},
 			},
-			Provenance: provenance,
+			Provenance:  provenance,
+			Description: request.Description,
 		})
 		if err != nil {
 			return err
@@ -2990,6 +3689,21 @@ func (a *apiServer) CreateSecret(ctx context.Context, request *pps.CreateSecretR
 	labels["secret-source"] = "pachyderm-user"
 	s.SetLabels(labels)
 
+	// Record who created the secret, so that other users can't use it
+	annotations := s.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	delete(annotations, ppsconsts.SecretOwnerAnnotation)
+	pachClient := a.env.GetPachClient(ctx)
+	me, err := pachClient.WhoAmI(pachClient.Ctx(), &auth.WhoAmIRequest{})
+	if err != nil && !auth.IsErrNotActivated(err) {
+		return nil, err
+	} else if err == nil {
+		annotations[ppsconsts.SecretOwnerAnnotation] = me.Username
+	}
+	s.SetAnnotations(annotations)
+
 	if _, err = a.env.GetKubeClient().CoreV1().Secrets(a.namespace).Create(&s); err != nil {
 		return nil, errors.Wrapf(err, "failed to create secret")
 	}
@@ -3072,6 +3786,342 @@ func (a *apiServer) ListSecret(ctx context.Context, in *types.Empty) (response *
 	}, nil
 }
 
+// CreatePipelineTemplate implements the protobuf pps.CreatePipelineTemplate RPC
+func (a *apiServer) CreatePipelineTemplate(ctx context.Context, request *pps.CreatePipelineTemplateRequest) (response *types.Empty, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	metricsFn := metrics.ReportUserAction(ctx, a.reporter, "CreatePipelineTemplate")
+	defer func(start time.Time) { metricsFn(start, retErr) }(time.Now())
+	pachClient := a.env.GetPachClient(ctx)
+	ctx = pachClient.Ctx() // pachClient will propagate auth info
+	username, superuser, err := templateCaller(pachClient)
+	if err != nil {
+		return nil, err
+	}
+	if request.Template == nil || request.Template.Name == "" {
+		return nil, errors.New("invalid pipeline template: name cannot be empty")
+	}
+	if err := ancestry.ValidateName(request.Template.Name); err != nil {
+		return nil, errors.Wrapf(err, "invalid pipeline template")
+	}
+	if err := ppsutil.ValidateTemplate(request.Jsonnet); err != nil {
+		return nil, err
+	}
+	templateInfo := &pps.PipelineTemplateInfo{
+		Template: request.Template,
+		Jsonnet:  request.Jsonnet,
+		Created:  now(),
+		Owner:    username,
+	}
+	if _, err := col.NewSTM(ctx, a.env.GetEtcdClient(), func(stm col.STM) error {
+		templates := a.templates.ReadWrite(stm)
+		if !request.Update {
+			return templates.Create(request.Template.Name, templateInfo)
+		}
+		existing := &pps.PipelineTemplateInfo{}
+		if err := templates.Get(request.Template.Name, existing); err != nil {
+			if col.IsErrNotFound(err) {
+				return templates.Create(request.Template.Name, templateInfo)
+			}
+			return err
+		}
+		if err := checkTemplateOwner(username, superuser, existing); err != nil {
+			return err
+		}
+		templateInfo.Owner = existing.Owner
+		return templates.Put(request.Template.Name, templateInfo)
+	}); err != nil {
+		if col.IsErrExists(err) {
+			return nil, errors.Errorf("pipeline template %q already exists", request.Template.Name)
+		}
+		return nil, err
+	}
+	return &types.Empty{}, nil
+}
+
+// InspectPipelineTemplate implements the protobuf pps.InspectPipelineTemplate RPC
+func (a *apiServer) InspectPipelineTemplate(ctx context.Context, request *pps.InspectPipelineTemplateRequest) (response *pps.PipelineTemplateInfo, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	return a.inspectPipelineTemplate(ctx, request.Template)
+}
+
+func (a *apiServer) inspectPipelineTemplate(ctx context.Context, template *pps.PipelineTemplate) (*pps.PipelineTemplateInfo, error) {
+	if template == nil {
+		return nil, errors.New("request.Template cannot be nil")
+	}
+	templateInfo := &pps.PipelineTemplateInfo{}
+	if err := a.templates.ReadOnly(ctx).Get(template.Name, templateInfo); err != nil {
+		if col.IsErrNotFound(err) {
+			return nil, errors.Errorf("pipeline template %q not found", template.Name)
+		}
+		return nil, err
+	}
+	return templateInfo, nil
+}
+
+// ListPipelineTemplate implements the protobuf pps.ListPipelineTemplate RPC
+func (a *apiServer) ListPipelineTemplate(ctx context.Context, request *types.Empty) (response *pps.PipelineTemplateInfos, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, nil, retErr, time.Since(start)) }(time.Now())
+	response = &pps.PipelineTemplateInfos{}
+	templateInfo := &pps.PipelineTemplateInfo{}
+	if err := a.templates.ReadOnly(ctx).List(templateInfo, col.DefaultOptions, func(string) error {
+		response.TemplateInfo = append(response.TemplateInfo, proto.Clone(templateInfo).(*pps.PipelineTemplateInfo))
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// DeletePipelineTemplate implements the protobuf pps.DeletePipelineTemplate RPC
+func (a *apiServer) DeletePipelineTemplate(ctx context.Context, request *pps.DeletePipelineTemplateRequest) (response *types.Empty, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	pachClient := a.env.GetPachClient(ctx)
+	ctx = pachClient.Ctx() // pachClient will propagate auth info
+	username, superuser, err := templateCaller(pachClient)
+	if err != nil {
+		return nil, err
+	}
+	if request.Template == nil {
+		return nil, errors.New("request.Template cannot be nil")
+	}
+	if _, err := col.NewSTM(ctx, a.env.GetEtcdClient(), func(stm col.STM) error {
+		templates := a.templates.ReadWrite(stm)
+		existing := &pps.PipelineTemplateInfo{}
+		if err := templates.Get(request.Template.Name, existing); err != nil {
+			return err
+		}
+		if err := checkTemplateOwner(username, superuser, existing); err != nil {
+			return err
+		}
+		return templates.Delete(request.Template.Name)
+	}); err != nil {
+		if col.IsErrNotFound(err) {
+			return nil, errors.Errorf("pipeline template %q not found", request.Template.Name)
+		}
+		return nil, err
+	}
+	return &types.Empty{}, nil
+}
+
+// templateCaller returns the username of the caller, and whether they can
+// modify pipeline templates owned by other users (i.e. they're an admin, or
+// auth isn't active)
+func templateCaller(pachClient *client.APIClient) (string, bool, error) {
+	me, err := pachClient.WhoAmI(pachClient.Ctx(), &auth.WhoAmIRequest{})
+	if auth.IsErrNotActivated(err) {
+		return "", true, nil
+	} else if err != nil {
+		return "", false, err
+	}
+	return me.Username, me.IsAdmin, nil
+}
+
+// checkTemplateOwner returns an error if 'username' can't modify the pipeline
+// template in 'templateInfo'
+func checkTemplateOwner(username string, superuser bool, templateInfo *pps.PipelineTemplateInfo) error {
+	if superuser || templateInfo.Owner == username {
+		return nil
+	}
+	return errors.Errorf("%s is not authorized to perform this operation; pipeline template %q can only be modified by its owner (%s) or an admin",
+		username, templateInfo.Template.Name, templateInfo.Owner)
+}
+
+// jobHooks returns the job hooks of the pipeline 'name', or nil if it doesn't
+// exist. It reads the pipeline's spec as the superuser, as the caller may not
+// have access to every pipeline that their hooks reach.
+func (a *apiServer) jobHooks(pachClient *client.APIClient, name string) ([]*pps.JobHook, error) {
+	pipelinePtr := &pps.EtcdPipelineInfo{}
+	if err := a.pipelines.ReadOnly(pachClient.Ctx()).Get(name, pipelinePtr); err != nil {
+		if col.IsErrNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var hooks []*pps.JobHook
+	if err := a.sudo(pachClient, func(superUserClient *client.APIClient) error {
+		pipelineInfo, err := ppsutil.GetPipelineInfo(superUserClient, pipelinePtr)
+		if err != nil {
+			return err
+		}
+		hooks = pipelineInfo.JobHooks
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+// checkAdmin returns an error if auth is active and the caller isn't an admin
+func checkAdmin(pachClient *client.APIClient, op string) error {
+	me, err := pachClient.WhoAmI(pachClient.Ctx(), &auth.WhoAmIRequest{})
+	if auth.IsErrNotActivated(err) {
+		return nil
+	} else if err != nil {
+		return errors.Wrapf(err, "error during authorization check")
+	}
+	if !me.IsAdmin {
+		return &auth.ErrNotAuthorized{
+			Subject: me.Username,
+			AdminOp: op,
+		}
+	}
+	return nil
+}
+
+// CreateNotificationSink implements the protobuf pps.CreateNotificationSink RPC
+func (a *apiServer) CreateNotificationSink(ctx context.Context, request *pps.CreateNotificationSinkRequest) (response *types.Empty, retErr error) {
+	// Don't log the request, as it may contain a webhook secret
+	func() { a.Log(nil, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(nil, response, retErr, time.Since(start)) }(time.Now())
+	pachClient := a.env.GetPachClient(ctx)
+	// Sinks receive events for every repo and pipeline, so only admins may
+	// manage them
+	if err := checkAdmin(pachClient, "CreateNotificationSink"); err != nil {
+		return nil, err
+	}
+	if request.SinkInfo == nil {
+		return nil, errors.New("request.SinkInfo cannot be nil")
+	}
+	if err := notify.Validate(request.SinkInfo); err != nil {
+		return nil, err
+	}
+	sinkInfo := proto.Clone(request.SinkInfo).(*pps.NotificationSinkInfo)
+	sinkInfo.Created = now()
+	name := sinkInfo.Sink.Name
+	if _, err := col.NewSTM(ctx, a.env.GetEtcdClient(), func(stm col.STM) error {
+		sinks := a.sinks.ReadWrite(stm)
+		// Sinks are returned with their webhook secrets redacted, so a sink that
+		// was inspected and then updated keeps its secret
+		if sinkInfo.Webhook != nil && sinkInfo.Webhook.Secret == notify.RedactedSecret {
+			existing := &pps.NotificationSinkInfo{}
+			if err := sinks.Get(name, existing); err != nil && !col.IsErrNotFound(err) {
+				return err
+			}
+			if !request.Update || existing.Webhook == nil || existing.Webhook.Secret == "" {
+				return errors.Errorf("webhook secret %q can only be used to keep the secret of an existing sink", notify.RedactedSecret)
+			}
+			sinkInfo.Webhook.Secret = existing.Webhook.Secret
+		}
+		if request.Update {
+			return sinks.Put(name, sinkInfo)
+		}
+		return sinks.Create(name, sinkInfo)
+	}); err != nil {
+		if col.IsErrExists(err) {
+			return nil, errors.Errorf("notification sink %q already exists", name)
+		}
+		return nil, err
+	}
+	return &types.Empty{}, nil
+}
+
+// InspectNotificationSink implements the protobuf pps.InspectNotificationSink RPC
+func (a *apiServer) InspectNotificationSink(ctx context.Context, request *pps.InspectNotificationSinkRequest) (response *pps.NotificationSinkInfo, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, nil, retErr, time.Since(start)) }(time.Now())
+	pachClient := a.env.GetPachClient(ctx)
+	if err := checkAdmin(pachClient, "InspectNotificationSink"); err != nil {
+		return nil, err
+	}
+	if request.Sink == nil {
+		return nil, errors.New("request.Sink cannot be nil")
+	}
+	sinkInfo := &pps.NotificationSinkInfo{}
+	if err := a.sinks.ReadOnly(ctx).Get(request.Sink.Name, sinkInfo); err != nil {
+		if col.IsErrNotFound(err) {
+			return nil, errors.Errorf("notification sink %q not found", request.Sink.Name)
+		}
+		return nil, err
+	}
+	return notify.Redact(sinkInfo), nil
+}
+
+// ListNotificationSink implements the protobuf pps.ListNotificationSink RPC
+func (a *apiServer) ListNotificationSink(ctx context.Context, request *types.Empty) (response *pps.NotificationSinkInfos, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, nil, retErr, time.Since(start)) }(time.Now())
+	pachClient := a.env.GetPachClient(ctx)
+	if err := checkAdmin(pachClient, "ListNotificationSink"); err != nil {
+		return nil, err
+	}
+	response = &pps.NotificationSinkInfos{}
+	sinkInfo := &pps.NotificationSinkInfo{}
+	if err := a.sinks.ReadOnly(ctx).List(sinkInfo, col.DefaultOptions, func(string) error {
+		response.SinkInfo = append(response.SinkInfo, notify.Redact(sinkInfo))
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// DeleteNotificationSink implements the protobuf pps.DeleteNotificationSink RPC
+func (a *apiServer) DeleteNotificationSink(ctx context.Context, request *pps.DeleteNotificationSinkRequest) (response *types.Empty, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, response, retErr, time.Since(start)) }(time.Now())
+	pachClient := a.env.GetPachClient(ctx)
+	if err := checkAdmin(pachClient, "DeleteNotificationSink"); err != nil {
+		return nil, err
+	}
+	if request.Sink == nil {
+		return nil, errors.New("request.Sink cannot be nil")
+	}
+	// Delete the sink's dead letters along with it
+	var deadLetterKeys []string
+	deadLetter := &pps.DeadLetter{}
+	if err := a.deadLetters.ReadOnly(ctx).GetByIndex(ppsdb.DeadLettersSinkIndex, request.Sink, deadLetter, col.DefaultOptions, func(key string) error {
+		deadLetterKeys = append(deadLetterKeys, key)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if _, err := col.NewSTM(ctx, a.env.GetEtcdClient(), func(stm col.STM) error {
+		deadLetters := a.deadLetters.ReadWrite(stm)
+		for _, key := range deadLetterKeys {
+			if err := deadLetters.Delete(key); err != nil && !col.IsErrNotFound(err) {
+				return err
+			}
+		}
+		return a.sinks.ReadWrite(stm).Delete(request.Sink.Name)
+	}); err != nil {
+		if col.IsErrNotFound(err) {
+			return nil, errors.Errorf("notification sink %q not found", request.Sink.Name)
+		}
+		return nil, err
+	}
+	return &types.Empty{}, nil
+}
+
+// ListDeadLetter implements the protobuf pps.ListDeadLetter RPC
+func (a *apiServer) ListDeadLetter(ctx context.Context, request *pps.ListDeadLetterRequest) (response *pps.DeadLetters, retErr error) {
+	func() { a.Log(request, nil, nil, 0) }()
+	defer func(start time.Time) { a.Log(request, nil, retErr, time.Since(start)) }(time.Now())
+	pachClient := a.env.GetPachClient(ctx)
+	if err := checkAdmin(pachClient, "ListDeadLetter"); err != nil {
+		return nil, err
+	}
+	if request.Sink == nil {
+		return nil, errors.New("request.Sink cannot be nil")
+	}
+	response = &pps.DeadLetters{}
+	deadLetter := &pps.DeadLetter{}
+	if err := a.deadLetters.ReadOnly(ctx).GetByIndex(ppsdb.DeadLettersSinkIndex, request.Sink, deadLetter, col.DefaultOptions, func(string) error {
+		response.DeadLetter = append(response.DeadLetter, proto.Clone(deadLetter).(*pps.DeadLetter))
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.Slice(response.DeadLetter, func(i, j int) bool {
+		ti, tj := response.DeadLetter[i].Time, response.DeadLetter[j].Time
+		return ti.Seconds < tj.Seconds || (ti.Seconds == tj.Seconds && ti.Nanos < tj.Nanos)
+	})
+	return response, nil
+}
+
 // DeleteAll implements the protobuf pps.DeleteAll RPC
 func (a *apiServer) DeleteAll(ctx context.Context, request *types.Empty) (response *types.Empty, retErr error) {
 	func() { a.Log(request, nil, nil, 0) }()
@@ -3101,6 +4151,15 @@ func (a *apiServer) DeleteAll(ctx context.Context, request *types.Empty) (respon
 		return nil, err
 	}
 
+	if _, err := col.NewSTM(ctx, a.env.GetEtcdClient(), func(stm col.STM) error {
+		a.templates.ReadWrite(stm).DeleteAll()
+		a.sinks.ReadWrite(stm).DeleteAll()
+		a.deadLetters.ReadWrite(stm).DeleteAll()
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
 	// PFS doesn't delete the spec repo, so do it here
 	if err := pachClient.DeleteRepo(ppsconsts.SpecRepo, true); err != nil && !isNotFoundErr(err) {
 		return nil, err
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func TestValidateJobHooks(t *testing.T) {
+	failed := []pps.JobState{pps.JobState_JOB_FAILURE, pps.JobState_JOB_KILLED}
+	require.NoError(t, validateJobHooks("p", nil))
+	require.NoError(t, validateJobHooks("p", []*pps.JobHook{
+		{States: failed, Cmd: []string{"sh", "alert.sh"}},
+		{States: []pps.JobState{pps.JobState_JOB_SUCCESS}, Pipeline: "publish"},
+	}))
+	// Exactly one of cmd and pipeline
+	require.YesError(t, validateJobHooks("p", []*pps.JobHook{{States: failed}}))
+	require.YesError(t, validateJobHooks("p", []*pps.JobHook{
+		{States: failed, Cmd: []string{"true"}, Pipeline: "cleanup"},
+	}))
+	// A pipeline can't hook itself
+	require.YesError(t, validateJobHooks("p", []*pps.JobHook{{States: failed, Pipeline: "p"}}))
+	// Hooks only run on terminal states
+	require.YesError(t, validateJobHooks("p", []*pps.JobHook{{Pipeline: "cleanup"}}))
+	require.YesError(t, validateJobHooks("p", []*pps.JobHook{
+		{States: []pps.JobState{pps.JobState_JOB_RUNNING}, Pipeline: "cleanup"},
+	}))
+}
+
+func TestCheckJobHookCycles(t *testing.T) {
+	failed := []pps.JobState{pps.JobState_JOB_FAILURE}
+	existing := map[string][]*pps.JobHook{
+		"a": {{States: failed, Pipeline: "b"}},
+		"b": {{States: failed, Pipeline: "c"}, {States: failed, Cmd: []string{"true"}}},
+		"c": nil,
+	}
+	getHooks := func(pipeline string) ([]*pps.JobHook, error) {
+		return existing[pipeline], nil
+	}
+	// Hooking a pipeline that doesn't hook back is fine, as is hooking a
+	// pipeline that doesn't exist yet
+	require.NoError(t, checkJobHookCycles("d", []*pps.JobHook{{States: failed, Pipeline: "a"}}, getHooks))
+	require.NoError(t, checkJobHookCycles("d", []*pps.JobHook{{States: failed, Pipeline: "e"}}, getHooks))
+	// c -> a -> b -> c is a cycle, as is b -> a -> b
+	err := checkJobHookCycles("c", []*pps.JobHook{{States: failed, Pipeline: "a"}}, getHooks)
+	require.YesError(t, err)
+	require.Matches(t, "c -> a -> b -> c", err.Error())
+	require.YesError(t, checkJobHookCycles("b", []*pps.JobHook{{States: failed, Pipeline: "a"}}, getHooks))
+}
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	kafka "github.com/segmentio/kafka-go"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	pfsServer "github.com/pachyderm/pachyderm/src/server/pfs"
+)
+
+// kafkaReader is the part of *kafka.Reader that makeKafkaCommits uses, which
+// lets tests stand in for a Kafka broker
+type kafkaReader interface {
+	ReadMessage(ctx context.Context) (kafka.Message, error)
+	Close() error
+}
+
+// openKafkaReader returns a reader of 'in's topic that starts at 'offset'
+func openKafkaReader(in *pps.KafkaInput, offset int64) (kafkaReader, error) {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   in.Brokers,
+		Topic:     in.Topic,
+		Partition: int(in.Partition),
+		MinBytes:  1,
+		MaxBytes:  10e6,
+	})
+	if err := reader.SetOffset(offset); err != nil {
+		reader.Close()
+		return nil, err
+	}
+	return reader, nil
+}
+
+// kafkaCursor is stored (as JSON) in the description of each commit in a
+// kafka input's repo, and records where the next window starts
+type kafkaCursor struct {
+	Topic      string `json:"topic"`
+	Partition  int32  `json:"partition"`
+	NextOffset int64  `json:"next_offset"`
+}
+
+func validateKafkaInput(in *pps.KafkaInput) error {
+	switch {
+	case in.Name == "":
+		return errors.Errorf("input must specify a name")
+	case in.Name == "out":
+		return errors.Errorf("input cannot be named \"out\", as pachyderm " +
+			"already creates /pfs/out to collect job output")
+	case len(in.Brokers) == 0:
+		return errors.Errorf("kafka input must specify at least one broker")
+	case in.Topic == "":
+		return errors.Errorf("kafka input must specify a topic")
+	case in.Partition < 0:
+		return errors.Errorf("kafka input partition cannot be negative")
+	case in.MaxMessages < 0:
+		return errors.Errorf("kafka input max_messages cannot be negative")
+	}
+	window, err := kafkaWindow(in)
+	if err != nil {
+		return errors.Wrapf(err, "error parsing kafka window")
+	}
+	if in.MaxMessages == 0 && window == 0 {
+		return errors.Errorf("kafka input must set 'max_messages', 'window', or both")
+	}
+	return nil
+}
+
+// kafkaWindow returns the duration of 'in's windows, or 0 if windows aren't
+// limited by time
+func kafkaWindow(in *pps.KafkaInput) (time.Duration, error) {
+	if in.Window == nil {
+		return 0, nil
+	}
+	window, err := types.DurationFromProto(in.Window)
+	if err != nil {
+		return 0, err
+	}
+	if window < 0 {
+		return 0, errors.Errorf("window cannot be negative")
+	}
+	return window, nil
+}
+
+// kafkaStartOffset returns the offset that consumption of 'in' should resume
+// from, given the head commit of its repo (which may be nil)
+func kafkaStartOffset(commitInfo *pfs.CommitInfo, in *pps.KafkaInput) int64 {
+	if commitInfo == nil {
+		return kafka.FirstOffset
+	}
+	var cursor kafkaCursor
+	if err := json.Unmarshal([]byte(commitInfo.Description), &cursor); err != nil {
+		return kafka.FirstOffset
+	}
+	if cursor.Topic != in.Topic || cursor.Partition != in.Partition {
+		// The input was updated to read from somewhere else
+		return kafka.FirstOffset
+	}
+	return cursor.NextOffset
+}
+
+// kafkaMessagePath returns the path of the file that the message at 'offset'
+// is written to. Offsets are zero-padded so that files sort in offset order.
+func kafkaMessagePath(offset int64) string {
+	return fmt.Sprintf("%020d", offset)
+}
+
+// readKafkaWindow reads the next window of messages from 'r'. It blocks until
+// the first message arrives, and then reads until the window holds
+// 'in.MaxMessages' messages or 'in.Window' has passed.
+func readKafkaWindow(ctx context.Context, r kafkaReader, in *pps.KafkaInput) ([]kafka.Message, error) {
+	window, err := kafkaWindow(in)
+	if err != nil {
+		return nil, err // Shouldn't happen, as the input is validated in CreatePipeline
+	}
+	m, err := r.ReadMessage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	messages := []kafka.Message{m}
+	windowCtx := ctx
+	if window > 0 {
+		var cancel context.CancelFunc
+		windowCtx, cancel = context.WithTimeout(ctx, window)
+		defer cancel()
+	}
+	for in.MaxMessages == 0 || int64(len(messages)) < in.MaxMessages {
+		m, err := r.ReadMessage(windowCtx)
+		if err != nil {
+			if ctx.Err() == nil && windowCtx.Err() == context.DeadlineExceeded {
+				break // the window has closed
+			}
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+	return messages, nil
+}
+
+// makeKafkaCommits consumes a single kafka input's topic, read with the reader
+// returned by 'openReader', into its repo. It's a helper function called by
+// monitorPipeline.
+func (a *apiServer) makeKafkaCommits(pachClient *client.APIClient, in *pps.Input, openReader func(*pps.KafkaInput, int64) (kafkaReader, error)) error {
+	// make sure there isn't an unfinished commit on the branch
+	commitInfo, err := pachClient.InspectCommit(in.Kafka.Repo, "master")
+	if err != nil && !pfsServer.IsNoHeadErr(err) {
+		return err
+	} else if commitInfo != nil && commitInfo.Finished == nil {
+		// and if there is, delete it (its messages will be read again)
+		if err = pachClient.DeleteCommit(in.Kafka.Repo, "master"); err != nil {
+			return err
+		}
+		commitInfo, err = pachClient.InspectCommit(in.Kafka.Repo, "master")
+		if err != nil && !pfsServer.IsNoHeadErr(err) {
+			return err
+		}
+	}
+
+	reader, err := openReader(in.Kafka, kafkaStartOffset(commitInfo, in.Kafka))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	for {
+		messages, err := readKafkaWindow(pachClient.Ctx(), reader, in.Kafka)
+		if err != nil {
+			return err
+		}
+		if err := a.writeKafkaCommit(pachClient, in.Kafka, messages); err != nil {
+			return err
+		}
+	}
+}
+
+// writeKafkaCommit writes a window of messages into a new commit in 'in's
+// repo, recording the offset that the next window starts from
+func (a *apiServer) writeKafkaCommit(pachClient *client.APIClient, in *pps.KafkaInput, messages []kafka.Message) error {
+	if _, err := pachClient.StartCommit(in.Repo, "master"); err != nil {
+		return err
+	}
+	if in.Overwrite {
+		// get rid of any messages, so the new window "overwrites" previous ones
+		err := pachClient.DeleteFile(in.Repo, "master", "")
+		if err != nil && !isNotFoundErr(err) && !pfsServer.IsNoHeadErr(err) {
+			return errors.Wrapf(err, "delete error")
+		}
+	}
+	if err := func() (retErr error) {
+		pfc, err := pachClient.NewPutFileClient()
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := pfc.Close(); err != nil && retErr == nil {
+				retErr = err
+			}
+		}()
+		for _, m := range messages {
+			if _, err := pfc.PutFile(in.Repo, "master", kafkaMessagePath(m.Offset), bytes.NewReader(m.Value)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}(); err != nil {
+		return errors.Wrapf(err, "put error")
+	}
+	description, err := json.Marshal(&kafkaCursor{
+		Topic:      in.Topic,
+		Partition:  in.Partition,
+		NextOffset: messages[len(messages)-1].Offset + 1,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = pachClient.PfsAPIClient.FinishCommit(pachClient.Ctx(), &pfs.FinishCommitRequest{
+		Commit:      client.NewCommit(in.Repo, "master"),
+		Description: string(description),
+	})
+	return grpcutil.ScrubGRPC(err)
+}
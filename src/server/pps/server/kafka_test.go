@@ -0,0 +1,239 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	kafka "github.com/segmentio/kafka-go"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/testpachd"
+)
+
+// testKafkaReader stands in for a Kafka broker, serving whatever messages are
+// sent on 'messages'
+type testKafkaReader struct {
+	messages chan kafka.Message
+}
+
+func newTestKafkaReader(n int) *testKafkaReader {
+	r := &testKafkaReader{messages: make(chan kafka.Message, n)}
+	for i := 0; i < n; i++ {
+		r.messages <- kafka.Message{Offset: int64(i), Value: []byte("msg")}
+	}
+	return r
+}
+
+func (r *testKafkaReader) ReadMessage(ctx context.Context) (kafka.Message, error) {
+	select {
+	case m := <-r.messages:
+		return m, nil
+	case <-ctx.Done():
+		return kafka.Message{}, ctx.Err()
+	}
+}
+
+func (r *testKafkaReader) Close() error {
+	return nil
+}
+
+// errEndOfTopic is returned by testKafkaTopic's readers once they've read
+// every message, which stops makeKafkaCommits
+var errEndOfTopic = errors.New("end of topic")
+
+// testKafkaTopic stands in for a Kafka topic. It records the offset that each
+// of its readers starts from.
+type testKafkaTopic struct {
+	messages []kafka.Message
+	opened   []int64
+}
+
+func (topic *testKafkaTopic) append(values ...string) {
+	for _, value := range values {
+		topic.messages = append(topic.messages, kafka.Message{
+			Offset: int64(len(topic.messages)),
+			Value:  []byte(value),
+		})
+	}
+}
+
+func (topic *testKafkaTopic) open(in *pps.KafkaInput, offset int64) (kafkaReader, error) {
+	topic.opened = append(topic.opened, offset)
+	if offset == kafka.FirstOffset {
+		offset = 0
+	}
+	r := &testKafkaReader{messages: make(chan kafka.Message, len(topic.messages))}
+	for _, m := range topic.messages[offset:] {
+		r.messages <- m
+	}
+	return &endingKafkaReader{r}, nil
+}
+
+// endingKafkaReader returns errEndOfTopic instead of blocking when there are
+// no more messages
+type endingKafkaReader struct {
+	*testKafkaReader
+}
+
+func (r *endingKafkaReader) ReadMessage(ctx context.Context) (kafka.Message, error) {
+	select {
+	case m := <-r.messages:
+		return m, nil
+	default:
+		return kafka.Message{}, errEndOfTopic
+	}
+}
+
+func TestReadKafkaWindowMaxMessages(t *testing.T) {
+	r := newTestKafkaReader(5)
+	in := &pps.KafkaInput{MaxMessages: 2}
+	messages, err := readKafkaWindow(context.Background(), r, in)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(messages))
+	require.Equal(t, int64(0), messages[0].Offset)
+	messages, err = readKafkaWindow(context.Background(), r, in)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(messages))
+	require.Equal(t, int64(2), messages[0].Offset)
+}
+
+func TestReadKafkaWindowTime(t *testing.T) {
+	r := newTestKafkaReader(3)
+	in := &pps.KafkaInput{Window: types.DurationProto(100 * time.Millisecond)}
+	messages, err := readKafkaWindow(context.Background(), r, in)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(messages))
+
+	// A window doesn't start until its first message arrives
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = readKafkaWindow(ctx, r, in)
+	require.YesError(t, err)
+}
+
+func TestKafkaStartOffset(t *testing.T) {
+	in := &pps.KafkaInput{Topic: "topic", Partition: 1}
+	require.Equal(t, int64(kafka.FirstOffset), kafkaStartOffset(nil, in))
+	require.Equal(t, int64(kafka.FirstOffset), kafkaStartOffset(&pfs.CommitInfo{}, in))
+	commitInfo := &pfs.CommitInfo{
+		Description: `{"topic":"topic","partition":1,"next_offset":7}`,
+	}
+	require.Equal(t, int64(7), kafkaStartOffset(commitInfo, in))
+	in.Partition = 2
+	require.Equal(t, int64(kafka.FirstOffset), kafkaStartOffset(commitInfo, in))
+}
+
+func TestValidateKafkaInput(t *testing.T) {
+	in := &pps.KafkaInput{
+		Name:        "in",
+		Brokers:     []string{"localhost:9092"},
+		Topic:       "topic",
+		MaxMessages: 10,
+	}
+	require.NoError(t, validateKafkaInput(in))
+	in.MaxMessages = 0
+	require.YesError(t, validateKafkaInput(in))
+	in.Window = types.DurationProto(time.Minute)
+	require.NoError(t, validateKafkaInput(in))
+	in.Brokers = nil
+	require.YesError(t, validateKafkaInput(in))
+}
+
+// kafkaFiles returns the contents of each file in the head of 'repo', by path
+func kafkaFiles(t *testing.T, c *client.APIClient, repo string) map[string]string {
+	fileInfos, err := c.ListFile(repo, "master", "")
+	require.NoError(t, err)
+	files := make(map[string]string)
+	for _, fileInfo := range fileInfos {
+		var buf bytes.Buffer
+		require.NoError(t, c.GetFile(repo, "master", fileInfo.File.Path, 0, 0, &buf))
+		files[strings.TrimPrefix(fileInfo.File.Path, "/")] = buf.String()
+	}
+	return files
+}
+
+func TestMakeKafkaCommits(t *testing.T) {
+	require.NoError(t, testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		c := env.PachClient
+		// The kafka input's branch exists, as it's in the pipeline's provenance
+		require.NoError(t, c.CreateRepo("in"))
+		require.NoError(t, c.CreateBranch("in", "master", "", nil))
+		in := &pps.Input{Kafka: &pps.KafkaInput{
+			Name:        "in",
+			Repo:        "in",
+			Topic:       "topic",
+			MaxMessages: 2,
+		}}
+		a := &apiServer{}
+		topic := &testKafkaTopic{}
+		topic.append("a", "b", "c", "d", "e")
+
+		// Each full window is written to its own commit. The last message is
+		// in an incomplete window, so it isn't written.
+		require.Equal(t, errEndOfTopic, a.makeKafkaCommits(c, in, topic.open))
+		commitInfos, err := c.ListCommitByRepo("in")
+		require.NoError(t, err)
+		require.Equal(t, 2, len(commitInfos))
+		require.Equal(t, `{"topic":"topic","partition":0,"next_offset":4}`, commitInfos[0].Description)
+		require.Equal(t, `{"topic":"topic","partition":0,"next_offset":2}`, commitInfos[1].Description)
+		require.Equal(t, map[string]string{
+			kafkaMessagePath(0): "a",
+			kafkaMessagePath(1): "b",
+			kafkaMessagePath(2): "c",
+			kafkaMessagePath(3): "d",
+		}, kafkaFiles(t, c, "in"))
+
+		// After a restart, consumption resumes from the offset after the last
+		// committed message, and an unfinished commit is discarded
+		_, err = c.StartCommit("in", "master")
+		require.NoError(t, err)
+		_, err = c.PutFile("in", "master", "partial", strings.NewReader("x"))
+		require.NoError(t, err)
+		topic.append("f")
+		require.Equal(t, errEndOfTopic, a.makeKafkaCommits(c, in, topic.open))
+		require.Equal(t, []int64{kafka.FirstOffset, 4}, topic.opened)
+		files := kafkaFiles(t, c, "in")
+		require.Equal(t, 6, len(files))
+		require.Equal(t, "e", files[kafkaMessagePath(4)])
+		require.Equal(t, "f", files[kafkaMessagePath(5)])
+		commitInfo, err := c.InspectCommit("in", "master")
+		require.NoError(t, err)
+		require.Equal(t, int64(6), kafkaStartOffset(commitInfo, in.Kafka))
+
+		// A different partition is read from its start
+		in.Kafka.Partition = 1
+		require.Equal(t, errEndOfTopic, a.makeKafkaCommits(c, in, topic.open))
+		require.Equal(t, []int64{kafka.FirstOffset, 4, kafka.FirstOffset}, topic.opened)
+		return nil
+	}))
+}
+
+func TestWriteKafkaCommitOverwrite(t *testing.T) {
+	require.NoError(t, testpachd.WithRealEnv(func(env *testpachd.RealEnv) error {
+		c := env.PachClient
+		require.NoError(t, c.CreateRepo("in"))
+		in := &pps.KafkaInput{Repo: "in", Topic: "topic", Overwrite: true}
+		a := &apiServer{}
+		require.NoError(t, a.writeKafkaCommit(c, in, []kafka.Message{
+			{Offset: 3, Value: []byte("a")},
+			{Offset: 4, Value: []byte("b")},
+		}))
+		require.NoError(t, a.writeKafkaCommit(c, in, []kafka.Message{
+			{Offset: 5, Value: []byte("c")},
+		}))
+		// Only the last window's messages are in the head commit
+		require.Equal(t, map[string]string{kafkaMessagePath(5): "c"}, kafkaFiles(t, c, "in"))
+		commitInfo, err := c.InspectCommit("in", "master")
+		require.NoError(t, err)
+		require.Equal(t, int64(6), kafkaStartOffset(commitInfo, in))
+		return nil
+	}))
+}
@@ -269,10 +269,10 @@ func (a *apiServer) setPipelineState(pachClient *client.APIClient, pipelineInfo
 
 func (a *apiServer) monitorPipeline(pachClient *client.APIClient, pipelineInfo *pps.PipelineInfo) {
 	log.Printf("PPS master: monitoring pipeline %q", pipelineInfo.Pipeline.Name)
-	// If this exits (e.g. b/c Standby is false, and pipeline has no cron inputs),
-	// remove this fn's cancel() call from a.monitorCancels (if it hasn't already
-	// been removed, e.g. by deletePipelineResources cancelling this call), so
-	// that it can be called again
+	// If this exits (e.g. b/c Standby is false, and pipeline has no cron or
+	// kafka inputs), remove this fn's cancel() call from a.monitorCancels (if it
+	// hasn't already been removed, e.g. by deletePipelineResources cancelling
+	// this call), so that it can be called again
 	defer a.cancelMonitor(pipelineInfo.Pipeline.Name)
 	var eg errgroup.Group
 	pps.VisitInput(pipelineInfo.Input, func(in *pps.Input) {
@@ -283,6 +283,13 @@ func (a *apiServer) monitorPipeline(pachClient *client.APIClient, pipelineInfo *
 				}, backoff.NewInfiniteBackOff(), notifyCtx(pachClient.Ctx(), "cron for "+in.Cron.Name))
 			})
 		}
+		if in.Kafka != nil {
+			eg.Go(func() error {
+				return backoff.RetryNotify(func() error {
+					return a.makeKafkaCommits(pachClient, in, openKafkaReader)
+				}, backoff.NewInfiniteBackOff(), notifyCtx(pachClient.Ctx(), "kafka for "+in.Kafka.Name))
+			})
+		}
 	})
 	if pipelineInfo.Standby {
 		// Capacity 1 gives us a bit of buffer so we don't needlessly go into
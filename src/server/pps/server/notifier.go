@@ -0,0 +1,441 @@
+package server
+
+import (
+	"context"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/pachyderm/pachyderm/src/client"
+	"github.com/pachyderm/pachyderm/src/client/pfs"
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/backoff"
+	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
+	"github.com/pachyderm/pachyderm/src/server/pkg/dlock"
+	"github.com/pachyderm/pachyderm/src/server/pkg/notify"
+	"github.com/pachyderm/pachyderm/src/server/pkg/pfsdb"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsconsts"
+	"github.com/pachyderm/pachyderm/src/server/pkg/ppsdb"
+	"github.com/pachyderm/pachyderm/src/server/pkg/uuid"
+	"github.com/pachyderm/pachyderm/src/server/pkg/watch"
+)
+
+const (
+	notifierLockPath = "_notifier_lock"
+
+	// defaultNotificationRetries is how many times delivery of an event is
+	// retried if the sink doesn't set max_retries
+	defaultNotificationRetries = 5
+
+	// notificationQueueSize is how many undelivered events are buffered for
+	// each sink. Events that arrive when a sink's queue is full are
+	// dead-lettered.
+	notificationQueueSize = 1000
+
+	// deadLetterQueueSize is how many events can wait to be dead-lettered.
+	// Events that can't be queued are logged and dropped.
+	deadLetterQueueSize = 1000
+
+	// maxDeadLettersPerSink is how many dead letters are kept for each sink.
+	// When a sink has more, the oldest are deleted.
+	maxDeadLettersPerSink = 1000
+)
+
+// The notifier watches commits, jobs and pipelines, and sends events for
+// their state changes to the configured notification sinks. Like the master,
+// it runs in only one pachd at a time. Events that happen while no notifier
+// is running (e.g. while pachd restarts) aren't sent.
+func (a *apiServer) notifier() {
+	notifierLock := dlock.NewDLock(a.env.GetEtcdClient(), path.Join(a.etcdPrefix, notifierLockPath))
+	backoff.RetryNotify(func() error {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctx, err := notifierLock.Lock(ctx)
+		if err != nil {
+			return err
+		}
+		defer notifierLock.Unlock(ctx)
+		log.Infof("PPS notifier: launching notifier process")
+		return a.runNotifier(ctx)
+	}, backoff.NewInfiniteBackOff(), func(err error, d time.Duration) error {
+		log.Errorf("PPS notifier: error running the notifier process: %v; retrying in %v", err, d)
+		return nil
+	})
+}
+
+type notifier struct {
+	a   *apiServer
+	ctx context.Context
+	// startRev is the etcd revision when the notifier started. Watch events at
+	// or before it describe state that already existed, and aren't sent.
+	startRev int64
+
+	mu     sync.Mutex
+	queues map[string]*sinkQueue
+
+	// deadLetters holds the events waiting to be written as dead letters by
+	// writeDeadLetters
+	deadLetters chan *pps.DeadLetter
+}
+
+// sinkQueue holds the events waiting to be delivered to one sink. Events are
+// delivered to each sink in order, one at a time.
+type sinkQueue struct {
+	mu       sync.Mutex
+	sinkInfo *pps.NotificationSinkInfo
+	events   chan *pps.NotificationEvent
+	cancel   func()
+}
+
+func (q *sinkQueue) getSinkInfo() *pps.NotificationSinkInfo {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.sinkInfo
+}
+
+func (a *apiServer) runNotifier(ctx context.Context) error {
+	resp, err := a.env.GetEtcdClient().Get(ctx, path.Join(a.etcdPrefix, notifierLockPath))
+	if err != nil {
+		return err
+	}
+	n := &notifier{
+		a:           a,
+		startRev:    resp.Header.Revision,
+		queues:      make(map[string]*sinkQueue),
+		deadLetters: make(chan *pps.DeadLetter, deadLetterQueueSize),
+	}
+	defer n.removeSinks()
+	var eg *errgroup.Group
+	eg, n.ctx = errgroup.WithContext(ctx)
+	eg.Go(n.watchSinks)
+	eg.Go(n.writeDeadLetters)
+	eg.Go(n.watchJobs)
+	eg.Go(n.watchPipelines)
+	eg.Go(func() error { return n.watchRepos(eg) })
+	return eg.Wait()
+}
+
+func (n *notifier) watchSinks() error {
+	return n.a.sinks.ReadOnly(n.ctx).WatchF(func(e *watch.Event) error {
+		var name string
+		sinkInfo := &pps.NotificationSinkInfo{}
+		if e.Type == watch.EventDelete {
+			n.removeSink(string(e.Key))
+			return nil
+		}
+		if err := e.Unmarshal(&name, sinkInfo); err != nil {
+			return err
+		}
+		n.setSink(name, sinkInfo)
+		return nil
+	})
+}
+
+func (n *notifier) setSink(name string, sinkInfo *pps.NotificationSinkInfo) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if q, ok := n.queues[name]; ok {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		q.sinkInfo = sinkInfo
+		return
+	}
+	ctx, cancel := context.WithCancel(n.ctx)
+	q := &sinkQueue{
+		sinkInfo: sinkInfo,
+		events:   make(chan *pps.NotificationEvent, notificationQueueSize),
+		cancel:   cancel,
+	}
+	n.queues[name] = q
+	go n.deliver(ctx, q)
+}
+
+func (n *notifier) removeSink(name string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if q, ok := n.queues[name]; ok {
+		q.cancel()
+		delete(n.queues, name)
+	}
+}
+
+func (n *notifier) removeSinks() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for name, q := range n.queues {
+		q.cancel()
+		delete(n.queues, name)
+	}
+}
+
+// deliver sends the events in 'q' to its sink until 'ctx' is cancelled,
+// retrying each one with exponential backoff and dead-lettering the ones that
+// can't be delivered
+func (n *notifier) deliver(ctx context.Context, q *sinkQueue) {
+	for {
+		var event *pps.NotificationEvent
+		select {
+		case event = <-q.events:
+		case <-ctx.Done():
+			return
+		}
+		sinkInfo := q.getSinkInfo()
+		maxRetries := int64(defaultNotificationRetries)
+		if sinkInfo.MaxRetries != nil {
+			maxRetries = sinkInfo.MaxRetries.Value
+		}
+		var retries int64
+		err := backoff.RetryNotify(func() error {
+			return notify.Send(ctx, sinkInfo, event)
+		}, backoff.NewExponentialBackOff(), func(err error, d time.Duration) error {
+			if retries >= maxRetries || ctx.Err() != nil {
+				return err
+			}
+			retries++
+			log.Errorf("PPS notifier: could not send event to sink %q: %v; retrying in %v", sinkInfo.Sink.Name, err, d)
+			return nil
+		})
+		if err != nil && ctx.Err() == nil {
+			n.deadLetter(sinkInfo.Sink, event, err)
+		}
+	}
+}
+
+// deadLetter queues 'event' to be written as a dead letter for 'sink'. It
+// doesn't block: if too many events are already waiting to be dead-lettered,
+// 'event' is dropped.
+func (n *notifier) deadLetter(sink *pps.NotificationSink, event *pps.NotificationEvent, err error) {
+	log.Errorf("PPS notifier: could not send event to sink %q, dead-lettering it: %v", sink.Name, err)
+	select {
+	case n.deadLetters <- &pps.DeadLetter{
+		Sink:  sink,
+		Event: event,
+		Error: err.Error(),
+		Time:  now(),
+	}:
+	default:
+		log.Errorf("PPS notifier: too many events waiting to be dead-lettered; dropping event for sink %q", sink.Name)
+	}
+}
+
+// writeDeadLetters writes queued dead letters to etcd one at a time, and
+// deletes the oldest dead letters of sinks that have more than
+// maxDeadLettersPerSink
+func (n *notifier) writeDeadLetters() error {
+	// counts holds an upper bound on the number of dead letters that each sink
+	// has (dead letters may also be deleted by users)
+	counts := make(map[string]int)
+	for {
+		var deadLetter *pps.DeadLetter
+		select {
+		case deadLetter = <-n.deadLetters:
+		case <-n.ctx.Done():
+			return nil
+		}
+		name := deadLetter.Sink.Name
+		if _, err := col.NewSTM(n.ctx, n.a.env.GetEtcdClient(), func(stm col.STM) error {
+			return n.a.deadLetters.ReadWrite(stm).Put(uuid.NewWithoutDashes(), deadLetter)
+		}); err != nil {
+			log.Errorf("PPS notifier: could not dead-letter event for sink %q: %v", name, err)
+			continue
+		}
+		count, ok := counts[name]
+		if ok && count < maxDeadLettersPerSink {
+			counts[name] = count + 1
+			continue
+		}
+		count, err := n.trimDeadLetters(deadLetter.Sink)
+		if err != nil {
+			log.Errorf("PPS notifier: could not delete old dead letters for sink %q: %v", name, err)
+			delete(counts, name)
+			continue
+		}
+		counts[name] = count
+	}
+}
+
+// trimDeadLetters deletes the oldest dead letters of 'sink' so that it has at
+// most maxDeadLettersPerSink, and returns the number that it has left
+func (n *notifier) trimDeadLetters(sink *pps.NotificationSink) (int, error) {
+	type keyedDeadLetter struct {
+		key  string
+		time *types.Timestamp
+	}
+	var deadLetters []keyedDeadLetter
+	deadLetter := &pps.DeadLetter{}
+	if err := n.a.deadLetters.ReadOnly(n.ctx).GetByIndex(ppsdb.DeadLettersSinkIndex, sink, deadLetter, col.DefaultOptions, func(key string) error {
+		deadLetters = append(deadLetters, keyedDeadLetter{key: key, time: deadLetter.Time})
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	if len(deadLetters) <= maxDeadLettersPerSink {
+		return len(deadLetters), nil
+	}
+	sort.Slice(deadLetters, func(i, j int) bool {
+		ti, tj := deadLetters[i].time, deadLetters[j].time
+		return ti.Seconds < tj.Seconds || (ti.Seconds == tj.Seconds && ti.Nanos < tj.Nanos)
+	})
+	if _, err := col.NewSTM(n.ctx, n.a.env.GetEtcdClient(), func(stm col.STM) error {
+		collection := n.a.deadLetters.ReadWrite(stm)
+		for _, d := range deadLetters[:len(deadLetters)-maxDeadLettersPerSink] {
+			if err := collection.Delete(d.key); err != nil && !col.IsErrNotFound(err) {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	return maxDeadLettersPerSink, nil
+}
+
+// dispatch queues 'event' for delivery to every sink whose filters it matches
+func (n *notifier) dispatch(event *pps.NotificationEvent) {
+	event.Time = now()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, q := range n.queues {
+		sinkInfo := q.getSinkInfo()
+		if !notify.Matches(sinkInfo, event) {
+			continue
+		}
+		select {
+		case q.events <- event:
+		default:
+			n.deadLetter(sinkInfo.Sink, event, errors.New("too many undelivered events"))
+		}
+	}
+}
+
+func (n *notifier) watchJobs() error {
+	// states holds the last state of each job, so that only changes are sent
+	states := make(map[string]pps.JobState)
+	return n.a.jobs.ReadOnly(n.ctx).WatchF(func(e *watch.Event) error {
+		var jobID string
+		jobPtr := &pps.EtcdJobInfo{}
+		if e.Type == watch.EventDelete {
+			delete(states, string(e.Key))
+			return nil
+		}
+		if err := e.Unmarshal(&jobID, jobPtr); err != nil {
+			return err
+		}
+		prevState, ok := states[jobID]
+		states[jobID] = jobPtr.State
+		if e.Rev <= n.startRev || (ok && prevState == jobPtr.State) {
+			return nil
+		}
+		n.dispatch(&pps.NotificationEvent{
+			Type:     pps.NotificationEventType_JOB_STATE,
+			Job:      jobPtr.Job,
+			JobState: jobPtr.State,
+			Pipeline: jobPtr.Pipeline,
+			Reason:   jobPtr.Reason,
+		})
+		return nil
+	})
+}
+
+func (n *notifier) watchPipelines() error {
+	// states holds the last state of each pipeline, so that only changes are
+	// sent
+	states := make(map[string]pps.PipelineState)
+	return n.a.pipelines.ReadOnly(n.ctx).WatchF(func(e *watch.Event) error {
+		var pipelineName string
+		pipelinePtr := &pps.EtcdPipelineInfo{}
+		if e.Type == watch.EventDelete {
+			delete(states, string(e.Key))
+			return nil
+		}
+		if err := e.Unmarshal(&pipelineName, pipelinePtr); err != nil {
+			return err
+		}
+		prevState, ok := states[pipelineName]
+		states[pipelineName] = pipelinePtr.State
+		if e.Rev <= n.startRev || (ok && prevState == pipelinePtr.State) {
+			return nil
+		}
+		n.dispatch(&pps.NotificationEvent{
+			Type:          pps.NotificationEventType_PIPELINE_STATE,
+			Pipeline:      client.NewPipeline(pipelineName),
+			PipelineState: pipelinePtr.State,
+			Reason:        pipelinePtr.Reason,
+		})
+		return nil
+	})
+}
+
+// watchRepos watches the commits of each repo (other than the spec repo) in
+// a goroutine in 'eg', which is stopped when the repo is deleted
+func (n *notifier) watchRepos(eg *errgroup.Group) error {
+	pfsPrefix := path.Join(n.a.env.EtcdPrefix, n.a.env.PFSEtcdPrefix)
+	cancels := make(map[string]func())
+	defer func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}()
+	return pfsdb.Repos(n.a.env.GetEtcdClient(), pfsPrefix).ReadOnly(n.ctx).WatchF(func(e *watch.Event) error {
+		repo := string(e.Key)
+		if repo == ppsconsts.SpecRepo {
+			return nil
+		}
+		if e.Type == watch.EventDelete {
+			if cancel, ok := cancels[repo]; ok {
+				cancel()
+				delete(cancels, repo)
+			}
+			return nil
+		}
+		if _, ok := cancels[repo]; ok {
+			return nil
+		}
+		ctx, cancel := context.WithCancel(n.ctx)
+		cancels[repo] = cancel
+		commits := pfsdb.Commits(n.a.env.GetEtcdClient(), pfsPrefix, repo)
+		eg.Go(func() error {
+			if err := n.watchCommits(ctx, commits); err != nil && ctx.Err() == nil {
+				return err
+			}
+			return nil
+		})
+		return nil
+	})
+}
+
+func (n *notifier) watchCommits(ctx context.Context, commits col.Collection) error {
+	// finished holds the commits that are known to be finished, so that each
+	// commit is only sent once (commits are updated after they're finished,
+	// e.g. when downstream commits are added to their subvenance)
+	finished := make(map[string]bool)
+	return commits.ReadOnly(ctx).WatchF(func(e *watch.Event) error {
+		var commitID string
+		commitInfo := &pfs.CommitInfo{}
+		if e.Type == watch.EventDelete {
+			delete(finished, string(e.Key))
+			return nil
+		}
+		if err := e.Unmarshal(&commitID, commitInfo); err != nil {
+			return err
+		}
+		if commitInfo.Finished == nil || finished[commitID] {
+			return nil
+		}
+		finished[commitID] = true
+		if e.Rev <= n.startRev {
+			return nil
+		}
+		n.dispatch(&pps.NotificationEvent{
+			Type:   pps.NotificationEventType_COMMIT_FINISHED,
+			Commit: commitInfo.Commit,
+		})
+		return nil
+	})
+}
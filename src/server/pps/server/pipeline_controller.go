@@ -381,8 +381,8 @@ func (op *pipelineOp) createPipelineResources() error {
 }
 
 // startPipelineMonitor spawns a monitorPipeline() goro for this pipeline (if
-// one doesn't exist already), which manages standby and cron inputs, and
-// updates the the pipeline state.
+// one doesn't exist already), which manages standby, cron, and kafka inputs,
+// and updates the the pipeline state.
 // Note: this is called by every run through step(), so must be idempotent
 func (op *pipelineOp) startPipelineMonitor() {
 	op.apiServer.monitorCancelsMu.Lock()
@@ -56,6 +56,9 @@ func NewAPIServer(
 		workerUsesRoot:        workerUsesRoot,
 		pipelines:             ppsdb.Pipelines(env.GetEtcdClient(), etcdPrefix),
 		jobs:                  ppsdb.Jobs(env.GetEtcdClient(), etcdPrefix),
+		templates:             ppsdb.Templates(env.GetEtcdClient(), etcdPrefix),
+		sinks:                 ppsdb.NotificationSinks(env.GetEtcdClient(), etcdPrefix),
+		deadLetters:           ppsdb.DeadLetters(env.GetEtcdClient(), etcdPrefix),
 		monitorCancels:        make(map[string]func()),
 		workerGrpcPort:        workerGrpcPort,
 		port:                  port,
@@ -64,6 +67,7 @@ func NewAPIServer(
 	}
 	apiServer.validateKube()
 	go apiServer.master()
+	go apiServer.notifier()
 	return apiServer, nil
 }
 
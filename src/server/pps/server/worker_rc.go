@@ -16,6 +16,7 @@ import (
 	"github.com/pachyderm/pachyderm/src/client/pps"
 	"github.com/pachyderm/pachyderm/src/client/version"
 	"github.com/pachyderm/pachyderm/src/server/pkg/deploy/assets"
+	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
 	"github.com/pachyderm/pachyderm/src/server/worker"
 
@@ -381,9 +382,14 @@ func getStorageEnvVars() ([]v1.EnvVar, error) {
 	if !ok {
 		return nil, errors.Errorf("%s not found", assets.UploadConcurrencyLimitEnvVar)
 	}
-	return []v1.EnvVar{
+	envVars := []v1.EnvVar{
 		{Name: assets.UploadConcurrencyLimitEnvVar, Value: uploadConcurrencyLimit},
-	}, nil
+	}
+	// Workers replicate their writes to the same object stores as pachd
+	if replicas, ok := os.LookupEnv(obj.StorageReplicasEnvVar); ok {
+		envVars = append(envVars, v1.EnvVar{Name: obj.StorageReplicasEnvVar, Value: replicas})
+	}
+	return envVars, nil
 }
 
 // We don't want to expose pipeline auth tokens, so we hash it. This will be
@@ -472,6 +478,20 @@ func (a *apiServer) getWorkerOptions(ptr *pps.EtcdPipelineInfo, pipelineInfo *pp
 		}
 	}
 
+	if egress := pipelineInfo.Egress; egress != nil && egress.SQL != nil {
+		workerEnv = append(workerEnv, v1.EnvVar{
+			Name: client.PPSEgressSQLDSNEnv,
+			ValueFrom: &v1.EnvVarSource{
+				SecretKeyRef: &v1.SecretKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{
+						Name: egress.SQL.Secret,
+					},
+					Key: egress.SQL.SecretKey,
+				},
+			},
+		})
+	}
+
 	volumes = append(volumes, v1.Volume{
 		Name: "pach-bin",
 		VolumeSource: v1.VolumeSource{
@@ -701,6 +701,7 @@ func (a *APIServer) runUserCode(ctx context.Context, logger *taggedLogger, envir
 		cmd.SysProcAttr = makeCmdCredentials(*a.uid, *a.gid)
 	}
 	cmd.Dir = a.pipelineInfo.Transform.WorkingDir
+	defer startCgroupSample(cgroupRoot).finish(stats)
 	err := cmd.Start()
 	if err != nil {
 		return errors.Wrapf(err, "error cmd.Start")
@@ -765,6 +766,7 @@ func (a *APIServer) runUserErrorHandlingCode(ctx context.Context, logger *tagged
 		cmd.SysProcAttr = makeCmdCredentials(*a.uid, *a.gid)
 	}
 	cmd.Dir = a.pipelineInfo.Transform.WorkingDir
+	defer startCgroupSample(cgroupRoot).finish(stats)
 	err := cmd.Start()
 	if err != nil {
 		return errors.Wrapf(err, "error cmd.Start")
@@ -1163,13 +1165,7 @@ func (a *APIServer) datum() []*pps.InputFile {
 }
 
 func (a *APIServer) userCodeEnv(jobID string, outputCommitID string, data []*Input) []string {
-	result := os.Environ()
-	for _, input := range data {
-		result = append(result, fmt.Sprintf("%s=%s", input.Name, filepath.Join(client.PPSInputPrefix, input.Name, input.FileInfo.File.Path)))
-		result = append(result, fmt.Sprintf("%s_COMMIT=%s", input.Name, input.FileInfo.File.Commit.ID))
-	}
-	result = append(result, fmt.Sprintf("%s=%s", client.JobIDEnv, jobID))
-	result = append(result, fmt.Sprintf("%s=%s", client.OutputCommitIDEnv, outputCommitID))
+	result := append(os.Environ(), UserCodeEnv(client.PPSInputPrefix, jobID, outputCommitID, data)...)
 	if ppsutil.ContainsS3Inputs(a.pipelineInfo.Input) || a.pipelineInfo.S3Out {
 		// TODO(msteffen) Instead of reading S3GATEWAY_PORT directly, worker/main.go
 		// should pass its ServiceEnv to worker.NewAPIServer, which should store it
@@ -1186,6 +1182,20 @@ func (a *APIServer) userCodeEnv(jobID string, outputCommitID string, data []*Inp
 	return result
 }
 
+// UserCodeEnv returns the environment variables that tell user code where a
+// datum's inputs are (under 'pfsRoot', which is /pfs in a worker) and which
+// job it's running in
+func UserCodeEnv(pfsRoot string, jobID string, outputCommitID string, data []*Input) []string {
+	var result []string
+	for _, input := range data {
+		result = append(result, fmt.Sprintf("%s=%s", input.Name, filepath.Join(pfsRoot, input.Name, input.FileInfo.File.Path)))
+		result = append(result, fmt.Sprintf("%s_COMMIT=%s", input.Name, input.FileInfo.File.Commit.ID))
+	}
+	result = append(result, fmt.Sprintf("%s=%s", client.JobIDEnv, jobID))
+	result = append(result, fmt.Sprintf("%s=%s", client.OutputCommitIDEnv, outputCommitID))
+	return result
+}
+
 type processResult struct {
 	failedDatumID   string
 	datumsProcessed int64
@@ -1638,7 +1648,9 @@ func (a *APIServer) getHashtrees(ctx context.Context, pachClient *client.APIClie
 	return rs, nil
 }
 
-func (a *APIServer) getDatumMap(ctx context.Context, pachClient *client.APIClient, object *pfs.Object) (_ map[string]uint64, retErr error) {
+// GetDatumMap reads the datum hashes stored in 'object' (e.g. a commit's
+// Datums object) and returns the number of times each hash appears.
+func GetDatumMap(pachClient *client.APIClient, object *pfs.Object) (_ map[string]uint64, retErr error) {
 	if object == nil {
 		return nil, nil
 	}
@@ -1882,7 +1894,7 @@ func (a *APIServer) worker() {
 					}
 					if parentCommitInfo != nil {
 						var err error
-						parentCounts, err := a.getDatumMap(jobCtx, pachClient, parentCommitInfo.Datums)
+						parentCounts, err := GetDatumMap(pachClient, parentCommitInfo.Datums)
 						if err != nil {
 							return err
 						}
@@ -2398,6 +2410,10 @@ func mergeStats(x, y *pps.ProcessStats) error {
 	}
 	x.DownloadBytes += y.DownloadBytes
 	x.UploadBytes += y.UploadBytes
+	x.CpuSeconds += y.CpuSeconds
+	if y.MemoryPeakBytes > x.MemoryPeakBytes {
+		x.MemoryPeakBytes = y.MemoryPeakBytes
+	}
 	return nil
 }
 
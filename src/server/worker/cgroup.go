@@ -0,0 +1,133 @@
+package worker
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+// cgroupRoot is where the worker container's cgroup filesystem is mounted.
+// User code runs in the same container as the worker, so its resource usage
+// is accounted to the same cgroup.
+const cgroupRoot = "/sys/fs/cgroup"
+
+// cgroupSample measures the CPU time and peak memory used by the container
+// between startCgroupSample and finish. Both cgroup v2 and v1 are supported;
+// if neither is readable (e.g. when not running in a container) nothing is
+// recorded.
+type cgroupSample struct {
+	root   string
+	cpu    time.Duration
+	cpuErr error
+	// peak is cgroup v2's memory.peak, opened for reading and writing so that
+	// reads only reflect the peak since the sample started (supported by
+	// kernels >= 6.12). It's nil if the file couldn't be reset.
+	peak *os.File
+}
+
+func startCgroupSample(root string) *cgroupSample {
+	s := &cgroupSample{root: root}
+	if f, err := os.OpenFile(filepath.Join(root, "memory.peak"), os.O_RDWR, 0); err == nil {
+		if _, err := f.WriteString("reset\n"); err != nil {
+			f.Close()
+		} else {
+			s.peak = f
+		}
+	}
+	if s.peak == nil {
+		// cgroup v1's high water mark is reset by writing 0 to it. If this
+		// fails (or we're on cgroup v2 with an older kernel), the peak is the
+		// container's peak so far, which is an upper bound.
+		ioutil.WriteFile(filepath.Join(root, "memory", "memory.max_usage_in_bytes"), []byte("0"), 0)
+	}
+	s.cpu, s.cpuErr = readCgroupCPU(root)
+	return s
+}
+
+// finish adds the CPU time used since the sample started to stats, and raises
+// stats' peak memory to the peak seen during the sample.
+func (s *cgroupSample) finish(stats *pps.ProcessStats) {
+	if s.cpuErr == nil {
+		if cpu, err := readCgroupCPU(s.root); err == nil && cpu > s.cpu {
+			stats.CpuSeconds += (cpu - s.cpu).Seconds()
+		}
+	}
+	var peak uint64
+	var err error
+	if s.peak != nil {
+		defer s.peak.Close()
+		if _, err = s.peak.Seek(0, io.SeekStart); err == nil {
+			peak, err = readUint(s.peak)
+		}
+	} else {
+		peak, err = readCgroupMemoryPeak(s.root)
+	}
+	if err == nil && peak > stats.MemoryPeakBytes {
+		stats.MemoryPeakBytes = peak
+	}
+}
+
+// readCgroupCPU returns the total CPU time used by the cgroup at 'root'
+func readCgroupCPU(root string) (time.Duration, error) {
+	// cgroup v2
+	if f, err := os.Open(filepath.Join(root, "cpu.stat")); err == nil {
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) == 2 && fields[0] == "usage_usec" {
+				usec, err := strconv.ParseInt(fields[1], 10, 64)
+				if err != nil {
+					return 0, errors.Wrapf(err, "could not parse cpu.stat")
+				}
+				return time.Duration(usec) * time.Microsecond, nil
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return 0, err
+		}
+	}
+	// cgroup v1
+	f, err := os.Open(filepath.Join(root, "cpuacct", "cpuacct.usage"))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	nsec, err := readUint(f)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(nsec), nil
+}
+
+// readCgroupMemoryPeak returns the most memory used by the cgroup at 'root'
+// since it was created (or its peak was last reset)
+func readCgroupMemoryPeak(root string) (uint64, error) {
+	f, err := os.Open(filepath.Join(root, "memory.peak"))
+	if os.IsNotExist(err) {
+		f, err = os.Open(filepath.Join(root, "memory", "memory.max_usage_in_bytes"))
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return readUint(f)
+}
+
+// readUint reads a file containing a single integer, such as most cgroup
+// files
+func readUint(r io.Reader) (uint64, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+)
+
+func writeCgroupFile(t *testing.T, root, name, content string) {
+	p := filepath.Join(root, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+	require.NoError(t, ioutil.WriteFile(p, []byte(content), 0644))
+}
+
+func TestCgroupSampleV2(t *testing.T) {
+	root, err := ioutil.TempDir("", "cgroup")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+	writeCgroupFile(t, root, "cpu.stat", "usage_usec 1000000\nuser_usec 600000\nsystem_usec 400000\n")
+	writeCgroupFile(t, root, "memory.peak", "1024\n")
+
+	stats := &pps.ProcessStats{MemoryPeakBytes: 512}
+	s := startCgroupSample(root)
+	writeCgroupFile(t, root, "cpu.stat", "usage_usec 3500000\n")
+	writeCgroupFile(t, root, "memory.peak", "4096\n")
+	s.finish(stats)
+	require.Equal(t, 2.5, stats.CpuSeconds)
+	require.Equal(t, uint64(4096), stats.MemoryPeakBytes)
+}
+
+func TestCgroupSampleV1(t *testing.T) {
+	root, err := ioutil.TempDir("", "cgroup")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+	writeCgroupFile(t, root, "cpuacct/cpuacct.usage", "2000000000\n")
+	writeCgroupFile(t, root, "memory/memory.max_usage_in_bytes", "8192\n")
+
+	stats := &pps.ProcessStats{}
+	s := startCgroupSample(root)
+	// Starting the sample resets the high water mark
+	peak, err := readCgroupMemoryPeak(root)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), peak)
+	writeCgroupFile(t, root, "cpuacct/cpuacct.usage", "2500000000\n")
+	writeCgroupFile(t, root, "memory/memory.max_usage_in_bytes", "2048\n")
+	s.finish(stats)
+	require.Equal(t, 0.5, stats.CpuSeconds)
+	require.Equal(t, uint64(2048), stats.MemoryPeakBytes)
+}
+
+func TestCgroupSampleMissing(t *testing.T) {
+	root, err := ioutil.TempDir("", "cgroup")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+	stats := &pps.ProcessStats{}
+	startCgroupSample(root).finish(stats)
+	require.Equal(t, 0.0, stats.CpuSeconds)
+	require.Equal(t, uint64(0), stats.MemoryPeakBytes)
+}
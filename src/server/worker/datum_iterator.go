@@ -451,6 +451,16 @@ func newCronDatumIterator(pachClient *client.APIClient, input *pps.CronInput) (D
 	})
 }
 
+func newKafkaDatumIterator(pachClient *client.APIClient, input *pps.KafkaInput) (DatumIterator, error) {
+	return newPFSDatumIterator(pachClient, &pps.PFSInput{
+		Name:   input.Name,
+		Repo:   input.Repo,
+		Branch: "master",
+		Commit: input.Commit,
+		Glob:   "/*",
+	})
+}
+
 // NewDatumIterator creates a datumIterator for an input.
 func NewDatumIterator(pachClient *client.APIClient, input *pps.Input) (DatumIterator, error) {
 	switch {
@@ -466,6 +476,8 @@ func NewDatumIterator(pachClient *client.APIClient, input *pps.Input) (DatumIter
 		return newCronDatumIterator(pachClient, input.Cron)
 	case input.Git != nil:
 		return newGitDatumIterator(pachClient, input.Git)
+	case input.Kafka != nil:
+		return newKafkaDatumIterator(pachClient, input.Kafka)
 	}
 	return nil, errors.Errorf("unrecognized input type: %v", input)
 }
@@ -0,0 +1,81 @@
+package worker
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gogo/protobuf/types"
+
+	"github.com/pachyderm/pachyderm/src/client/pkg/require"
+	"github.com/pachyderm/pachyderm/src/client/pps"
+	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
+	"github.com/pachyderm/pachyderm/src/server/pkg/testpachd"
+)
+
+func TestRunJobHooks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "job_hooks")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	// Object storage credentials in the worker's environment must not reach
+	// hooks
+	os.Setenv(obj.GoogleCredEnvVar, "secret")
+	defer os.Unsetenv(obj.GoogleCredEnvVar)
+
+	require.NoError(t, testpachd.WithMockEnv(func(env *testpachd.MockEnv) error {
+		// Each job is named after the state it ended in
+		states := map[string]pps.JobState{
+			"success": pps.JobState_JOB_SUCCESS,
+			"failure": pps.JobState_JOB_FAILURE,
+			"killed":  pps.JobState_JOB_KILLED,
+		}
+		env.MockPachd.PPS.InspectJob.Use(func(_ context.Context, req *pps.InspectJobRequest) (*pps.JobInfo, error) {
+			return &pps.JobInfo{Job: req.Job, State: states[req.Job.ID]}, nil
+		})
+		var mu sync.Mutex
+		var ranPipelines []string
+		env.MockPachd.PPS.RunPipeline.Use(func(_ context.Context, req *pps.RunPipelineRequest) (*types.Empty, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			ranPipelines = append(ranPipelines, req.Pipeline.Name)
+			return &types.Empty{}, nil
+		})
+
+		a := &APIServer{
+			pipelineInfo: &pps.PipelineInfo{
+				Transform: &pps.Transform{},
+				JobHooks: []*pps.JobHook{
+					{
+						States: []pps.JobState{pps.JobState_JOB_SUCCESS, pps.JobState_JOB_FAILURE},
+						Cmd:    []string{"sh", "-c", `env > "` + dir + `/$PACH_JOB_ID"`},
+						Env:    map[string]string{"HOOK": "true"},
+					},
+					{
+						States:   []pps.JobState{pps.JobState_JOB_KILLED},
+						Pipeline: "cleanup",
+					},
+				},
+			},
+		}
+		logger := a.getMasterLogger()
+		for jobID := range states {
+			a.runJobHooks(env.PachClient, logger, jobID)
+		}
+
+		for _, jobID := range []string{"success", "failure"} {
+			data, err := ioutil.ReadFile(filepath.Join(dir, jobID))
+			require.NoError(t, err)
+			require.True(t, strings.Contains(string(data), "PACH_JOB_ID="+jobID))
+			require.True(t, strings.Contains(string(data), "HOOK=true"))
+			require.False(t, strings.Contains(string(data), obj.GoogleCredEnvVar+"="))
+		}
+		_, err := os.Stat(filepath.Join(dir, "killed"))
+		require.True(t, os.IsNotExist(err))
+		require.Equal(t, []string{"cleanup"}, ranPipelines)
+		return nil
+	}))
+}
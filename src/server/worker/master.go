@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"context"
+	"database/sql"
 	"fmt"
 	"io"
 	"log"
@@ -19,6 +20,7 @@ import (
 	"github.com/pachyderm/pachyderm/src/client/auth"
 	"github.com/pachyderm/pachyderm/src/client/pfs"
 	"github.com/pachyderm/pachyderm/src/client/pkg/errors"
+	"github.com/pachyderm/pachyderm/src/client/pkg/grpcutil"
 	"github.com/pachyderm/pachyderm/src/client/pkg/pbutil"
 	"github.com/pachyderm/pachyderm/src/client/pps"
 	pfsserver "github.com/pachyderm/pachyderm/src/server/pfs"
@@ -26,15 +28,22 @@ import (
 	col "github.com/pachyderm/pachyderm/src/server/pkg/collection"
 	"github.com/pachyderm/pachyderm/src/server/pkg/dlock"
 	"github.com/pachyderm/pachyderm/src/server/pkg/errutil"
+	"github.com/pachyderm/pachyderm/src/server/pkg/exec"
+	"github.com/pachyderm/pachyderm/src/server/pkg/hashtree"
 	"github.com/pachyderm/pachyderm/src/server/pkg/obj"
+	"github.com/pachyderm/pachyderm/src/server/pkg/outputvalidation"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ppsconsts"
 	"github.com/pachyderm/pachyderm/src/server/pkg/ppsutil"
+	"github.com/pachyderm/pachyderm/src/server/pkg/sqlegress"
 	filesync "github.com/pachyderm/pachyderm/src/server/pkg/sync"
 	"github.com/pachyderm/pachyderm/src/server/pkg/watch"
 )
 
 const (
 	masterLockPath = "_master_worker_lock"
+
+	// jobHookTimeout is how long a job hook's cmd may run before it's killed
+	jobHookTimeout = 10 * time.Minute
 )
 
 func (a *APIServer) getMasterLogger() *taggedLogger {
@@ -160,6 +169,7 @@ func (a *APIServer) jobSpawner(pachClient *client.APIClient) error {
 						return errors.Wrapf(err, "could not mark job with finished output commit as successful")
 					}
 				}
+				go a.runJobHooks(pachClient, logger, ji.Job.ID)
 			}
 			continue // commit finished after queueing
 		}
@@ -234,6 +244,7 @@ func (a *APIServer) jobSpawner(pachClient *client.APIClient) error {
 				pps.JobState_JOB_KILLED, "pipeline has been updated"); err != nil {
 				return errors.Wrapf(err, "could not kill stale job")
 			}
+			go a.runJobHooks(pachClient, logger, jobInfo.Job.ID)
 			continue
 		case jobInfo.PipelineVersion > a.pipelineInfo.Version:
 			return errors.Errorf("job %s's version (%d) greater than pipeline's "+
@@ -247,6 +258,7 @@ func (a *APIServer) jobSpawner(pachClient *client.APIClient) error {
 		if err := a.waitJob(pachClient, jobInfo, logger); err != nil {
 			return err
 		}
+		go a.runJobHooks(pachClient, logger, jobInfo.Job.ID)
 	}
 }
 
@@ -462,6 +474,9 @@ func (a *APIServer) failedInputs(ctx context.Context, jobInfo *pps.JobInfo) ([]s
 		}
 		if ci.Tree == nil && ci.Trees == nil {
 			failedInputs = append(failedInputs, name)
+		} else if ci.Quarantined != "" {
+			// A deep fsck found problems with the input's data
+			failedInputs = append(failedInputs, name+" (quarantined)")
 		}
 	}
 	pps.VisitInput(jobInfo.Input, func(input *pps.Input) {
@@ -474,6 +489,9 @@ func (a *APIServer) failedInputs(ctx context.Context, jobInfo *pps.JobInfo) ([]s
 		if input.Git != nil && input.Git.Commit != "" {
 			blockCommit(input.Git.Name, client.NewCommit(input.Git.Name, input.Git.Commit))
 		}
+		if input.Kafka != nil && input.Kafka.Commit != "" {
+			blockCommit(input.Kafka.Name, client.NewCommit(input.Kafka.Repo, input.Kafka.Commit))
+		}
 	})
 	return failedInputs, vistErr
 }
@@ -680,7 +698,7 @@ func (a *APIServer) waitJob(pachClient *client.APIClient, jobInfo *pps.JobInfo,
 						failedDatumID = chunkState.DatumID
 					} else if chunkState.State == State_COMPLETE {
 						// if the chunk has been completed, grab the recovered datums from the chunk
-						chunkRecoveredDatums, err := a.getDatumMap(ctx, pachClient, chunkState.RecoveredDatums)
+						chunkRecoveredDatums, err := GetDatumMap(pachClient, chunkState.RecoveredDatums)
 						if err != nil {
 							return err
 						}
@@ -748,11 +766,23 @@ func (a *APIServer) waitJob(pachClient *client.APIClient, jobInfo *pps.JobInfo,
 				}
 			}
 		}
+		var reason string
+		if failedDatumID != "" {
+			reason = fmt.Sprintf("failed to process datum: %v", failedDatumID)
+		} else if a.pipelineInfo.Validation != nil && !a.pipelineInfo.S3Out {
+			logger.Logf("validating output of job %v", jobInfo.Job.ID)
+			reasons, err := a.validateOutput(pachClient, trees)
+			if err != nil {
+				return err
+			}
+			if len(reasons) > 0 {
+				reason = fmt.Sprintf("output failed validation: %s", strings.Join(reasons, "; "))
+			}
+		}
 		// If the job failed we finish the commit with an empty tree but only
 		// after we've set the state, otherwise the job will be considered
 		// killed.
-		if failedDatumID != "" {
-			reason := fmt.Sprintf("failed to process datum: %v", failedDatumID)
+		if reason != "" {
 			if err := a.updateJobState(ctx, jobInfo, pps.JobState_JOB_FAILURE, reason); err != nil {
 				return err
 			}
@@ -877,6 +907,9 @@ func (a *APIServer) egress(pachClient *client.APIClient, logger *taggedLogger, j
 	pachClient.SetMaxConcurrentStreams(100)
 	var egressFailureCount int
 	return backoff.RetryNotify(func() (retErr error) {
+		if jobInfo.Egress != nil && jobInfo.Egress.SQL != nil {
+			return a.egressSQL(pachClient, logger, jobInfo)
+		}
 		if jobInfo.Egress != nil {
 			logger.Logf("Starting egress upload for job (%v)", jobInfo)
 			start := time.Now()
@@ -904,6 +937,213 @@ func (a *APIServer) egress(pachClient *client.APIClient, logger *taggedLogger, j
 	})
 }
 
+// runJobHooks runs the pipeline's job hooks for the state that the job 'jobID'
+// ended in. The job's state is already final, so hooks that fail are logged
+// rather than retried. It's run in its own goroutine, so that slow hooks
+// don't hold up the pipeline's next job. Hooks are best-effort: whether they
+// ran isn't recorded, so they're lost if the master restarts while they run.
+func (a *APIServer) runJobHooks(pachClient *client.APIClient, logger *taggedLogger, jobID string) {
+	if len(a.pipelineInfo.JobHooks) == 0 {
+		return
+	}
+	jobInfo, err := pachClient.InspectJob(jobID, false)
+	if err != nil {
+		logger.Logf("could not inspect job %q to run its hooks: %v", jobID, err)
+		return
+	}
+	if !ppsutil.IsTerminal(jobInfo.State) {
+		return
+	}
+	jobInfoJSON, err := (&jsonpb.Marshaler{}).MarshalToString(jobInfo)
+	if err != nil {
+		logger.Logf("could not marshal job %q to run its hooks: %v", jobID, err)
+		return
+	}
+	for i, hook := range a.pipelineInfo.JobHooks {
+		triggered := false
+		for _, state := range hook.States {
+			if state == jobInfo.State {
+				triggered = true
+			}
+		}
+		if !triggered {
+			continue
+		}
+		logger.Logf("running job hook %d for job %q (%s)", i, jobID, jobInfo.State)
+		if hook.Pipeline != "" {
+			_, err = pachClient.PpsAPIClient.RunPipeline(pachClient.Ctx(), &pps.RunPipelineRequest{
+				Pipeline:    client.NewPipeline(hook.Pipeline),
+				Description: jobInfoJSON,
+			})
+			err = grpcutil.ScrubGRPC(err)
+		} else {
+			err = a.runJobHookCmd(pachClient.Ctx(), logger, hook, jobID, jobInfoJSON)
+		}
+		if err != nil {
+			logger.Logf("job hook %d for job %q failed: %v", i, jobID, err)
+		}
+	}
+}
+
+// runJobHookCmd runs 'hook's cmd in the worker's container, with the JobInfo
+// of the job that triggered it on stdin
+func (a *APIServer) runJobHookCmd(ctx context.Context, logger *taggedLogger, hook *pps.JobHook, jobID string, jobInfoJSON string) error {
+	ctx, cancel := context.WithTimeout(ctx, jobHookTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, hook.Cmd[0], hook.Cmd[1:]...)
+	cmd.Stdin = strings.NewReader(jobInfoJSON)
+	cmd.Stdout = logger.userLogger()
+	cmd.Stderr = logger.userLogger()
+	cmd.Env = append(jobHookEnv(os.Environ()), fmt.Sprintf("%s=%s", client.JobIDEnv, jobID))
+	for k, v := range hook.Env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+	}
+	if a.uid != nil && a.gid != nil {
+		cmd.SysProcAttr = makeCmdCredentials(*a.uid, *a.gid)
+	}
+	cmd.Dir = a.pipelineInfo.Transform.WorkingDir
+	return cmd.Run()
+}
+
+// jobHookEnv returns 'environ' (the user container's environment) without the
+// variables that pachyderm adds to it for the worker's own use, i.e. object
+// storage credentials and the SQL egress DSN, which job hooks mustn't see
+func jobHookEnv(environ []string) []string {
+	workerOnly := map[string]bool{
+		obj.StorageBackendEnvVar:  true,
+		client.PPSEgressSQLDSNEnv: true,
+	}
+	for _, e := range obj.EnvVarToSecretKey {
+		workerOnly[e.Key] = true
+	}
+	var result []string
+	for _, v := range environ {
+		if !workerOnly[strings.SplitN(v, "=", 2)[0]] {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// validateOutput checks 'trees', the merged output hashtrees of a job, against
+// the pipeline's validation spec. It returns the reasons that the output is
+// invalid, or nil if it's valid.
+func (a *APIServer) validateOutput(pachClient *client.APIClient, trees []*pfs.Object) (_ []string, retErr error) {
+	var rs []io.ReadCloser
+	defer func() {
+		for _, r := range rs {
+			if err := r.Close(); err != nil && retErr == nil {
+				retErr = err
+			}
+		}
+	}()
+	for _, tree := range trees {
+		info, err := pachClient.InspectObject(tree.Hash)
+		if err != nil {
+			return nil, err
+		}
+		path, err := obj.BlockPathFromEnv(info.BlockRef.Block)
+		if err != nil {
+			return nil, err
+		}
+		r, err := pachClient.DirectObjReader(path)
+		if err != nil {
+			return nil, err
+		}
+		rs = append(rs, r)
+	}
+	readsContent, err := outputvalidation.ContentMatcher(a.pipelineInfo.Validation)
+	if err != nil {
+		return nil, err
+	}
+	var files []outputvalidation.File
+	// Only the nodes of files whose contents are checked are kept
+	nodes := make(map[string]*hashtree.NodeProto)
+	if err := hashtree.Walk(rs, "/", func(path string, node *hashtree.NodeProto) error {
+		if node.FileNode != nil {
+			files = append(files, outputvalidation.File{Path: path, SizeBytes: uint64(node.SubtreeSize)})
+			if readsContent(path) {
+				nodes[path] = node
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return outputvalidation.Check(a.pipelineInfo.Validation, files, func(path string) (io.ReadCloser, error) {
+		node := nodes[path]
+		// Checks may stop reading a file early (e.g. after a CSV header), so
+		// each file's stream is canceled when it's closed
+		ctx, cancel := context.WithCancel(pachClient.Ctx())
+		if len(node.FileNode.BlockRefs) > 0 {
+			getBlocksClient, err := pachClient.ObjectAPIClient.GetBlocks(ctx, &pfs.GetBlocksRequest{
+				BlockRefs: node.FileNode.BlockRefs,
+				TotalSize: uint64(node.SubtreeSize),
+			})
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			return grpcutil.NewStreamingBytesReader(getBlocksClient, cancel), nil
+		}
+		getObjectsClient, err := pachClient.ObjectAPIClient.GetObjects(ctx, &pfs.GetObjectsRequest{
+			Objects:   node.FileNode.Objects,
+			TotalSize: uint64(node.SubtreeSize),
+		})
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		return grpcutil.NewStreamingBytesReader(getObjectsClient, cancel), nil
+	})
+}
+
+// egressSQL loads the files in the job's output commit into the table in its
+// SQL egress, in one transaction
+func (a *APIServer) egressSQL(pachClient *client.APIClient, logger *taggedLogger, jobInfo *pps.JobInfo) error {
+	spec := jobInfo.Egress.SQL
+	logger.Logf("Starting egress to %s table %s for job (%v)", spec.Driver, spec.Table, jobInfo)
+	start := time.Now()
+	dsn, ok := os.LookupEnv(client.PPSEgressSQLDSNEnv)
+	if !ok {
+		return errors.Errorf("%s not set; is key %q of secret %q missing?", client.PPSEgressSQLDSNEnv, spec.SecretKey, spec.Secret)
+	}
+	db, err := sql.Open(spec.Driver, dsn)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	// Collect the files first, so that the transaction isn't held open while
+	// walking the commit
+	commit := jobInfo.OutputCommit
+	var files []string
+	if err := pachClient.Walk(commit.Repo.Name, commit.ID, "/", func(fileInfo *pfs.FileInfo) error {
+		if fileInfo.FileType == pfs.FileType_FILE {
+			files = append(files, fileInfo.File.Path)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	rows, err := sqlegress.Load(pachClient.Ctx(), db, spec, func(f func(io.Reader) error) error {
+		for _, file := range files {
+			r, err := pachClient.GetFileReader(commit.Repo.Name, commit.ID, file, 0, 0)
+			if err != nil {
+				return err
+			}
+			if err := f(r); err != nil {
+				return errors.Wrapf(err, "could not load %s", file)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	logger.Logf("Completed egress of %d rows from %d files for job (%v), duration (%v)", rows, len(files), jobInfo, time.Since(start))
+	return nil
+}
+
 func (a *APIServer) receiveSpout(ctx context.Context, logger *taggedLogger) error {
 	return backoff.RetryNotify(func() error {
 		repo := a.pipelineInfo.Pipeline.Name